@@ -5,24 +5,49 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	b "dev_agent/internal/brain"
 	cfg "dev_agent/internal/config"
+	"dev_agent/internal/cost"
+	"dev_agent/internal/exitcodes"
 	"dev_agent/internal/logx"
 	o "dev_agent/internal/orchestrator"
 	"dev_agent/internal/streaming"
+	"dev_agent/internal/taskspec"
 	t "dev_agent/internal/tools"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-result" {
+		os.Exit(runValidateResult(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "stream-lint" {
+		os.Exit(runStreamLint(os.Args[2:]))
+	}
+
 	task := flag.String("task", "", "User task description")
+	taskFile := flag.String("task-file", "", "JSON or YAML task spec with description, acceptance_criteria, constraints and references (instead of --task)")
+	fromPlan := flag.String("from-plan", "", "Dev-task file from plan-agent --emit-dev-tasks; run the step chosen by --step (instead of --task)")
+	planStep := flag.Int("step", 0, "Plan step_id to run with --from-plan")
 	parent := flag.String("parent-branch-id", "", "Parent branch UUID (required)")
 	project := flag.String("project-name", "", "Optional project name override")
 	headless := flag.Bool("headless", false, "Run in headless mode (no chat prints)")
 	streamJSON := flag.Bool("stream-json", false, "Emit orchestration events as NDJSON to stdout (forces headless mode)")
-	flag.Parse()
+	stallWarning := flag.Duration("stall-warning-interval", 5*time.Minute, "Report a stall warning after this long without any event or log line; 0 disables the watchdog")
+	maxDuration := flag.Duration("max-duration", 0, "Abort LLM calls once the run exceeds this duration (e.g. 45m); 0 disables the limit")
+	maxTurns := flag.Int("max-turns", o.DefaultMaxTurns, "Stop with status turn_limit_exceeded after this many LLM turns without a final report, reviews or not")
+	maxToolCalls := flag.Int("max-tool-calls-per-turn", o.DefaultMaxToolCallsPerTurn, "Execute at most this many tool calls of one LLM response; the rest are answered with a batching error")
+	profile := flag.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	scopeDir := flag.String("scope-dir", "", "Restrict agents to this repository subdirectory (e.g. services/api); empty means the whole repo")
+	allowedArtifacts := flag.String("allowed-artifacts", "", "Comma-separated globs that read_artifact paths must match (e.g. \"*.md,docs/*\"); empty allows all")
+	pricesFile := flag.String("prices-file", "", "prices.yaml with input_per_1k and output_per_1k (USD); enables estimated_cost_usd in the report")
+	priceIn := flag.Float64("price-per-1k-input", 0, "USD per 1K prompt tokens; overrides --prices-file")
+	priceOut := flag.Float64("price-per-1k-output", 0, "USD per 1K completion tokens; overrides --prices-file")
+	exitcodes.ParseFlags()
 
 	streamEnabled := streamJSON != nil && *streamJSON
 	if streamEnabled {
@@ -30,10 +55,10 @@ func main() {
 		logx.SetLevel(logx.Error)
 	}
 
-	conf, err := cfg.FromEnv()
+	conf, err := cfg.Load(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
 	if *project != "" {
@@ -41,14 +66,56 @@ func main() {
 	}
 	if conf.ProjectName == "" {
 		fmt.Fprintln(os.Stderr, "Project name must be provided via PROJECT_NAME or --project-name")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 	if *parent == "" {
 		fmt.Fprintln(os.Stderr, "--parent-branch-id is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+	if *priceIn < 0 || *priceOut < 0 {
+		fmt.Fprintln(os.Stderr, "error: token prices must not be negative")
+		os.Exit(exitcodes.Usage)
+	}
+	var prices cost.Prices
+	if *pricesFile != "" {
+		if prices, err = cost.LoadPrices(*pricesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+	}
+	if *priceIn > 0 {
+		prices.InputPer1K = *priceIn
+	}
+	if *priceOut > 0 {
+		prices.OutputPer1K = *priceOut
 	}
 
-	tsk := *task
+	sources := 0
+	for _, s := range []string{*task, *taskFile, *fromPlan} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		fmt.Fprintln(os.Stderr, "error: --task, --task-file and --from-plan are mutually exclusive")
+		os.Exit(exitcodes.Usage)
+	}
+	if (*fromPlan != "") != (*planStep != 0) {
+		fmt.Fprintln(os.Stderr, "error: --from-plan and --step must be given together")
+		os.Exit(exitcodes.Usage)
+	}
+	spec := taskspec.Spec{Description: *task}
+	switch {
+	case *taskFile != "":
+		spec, err = taskspec.Load(*taskFile)
+	case *fromPlan != "":
+		spec, err = taskspec.LoadPlanStep(*fromPlan, *planStep)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitcodes.Usage)
+	}
+	tsk := spec.Description
 	if tsk == "" {
 		promptWriter := os.Stdout
 		if streamEnabled {
@@ -60,8 +127,13 @@ func main() {
 		tsk = strings.TrimSpace(line)
 		if tsk == "" {
 			fmt.Fprintln(os.Stderr, "error: task is required")
-			os.Exit(1)
+			os.Exit(exitcodes.Usage)
 		}
+		spec.Description = tsk
+	}
+
+	if conf.RunSubdir != "" {
+		fmt.Fprintf(os.Stderr, "Using per-run workspace: %s\n", conf.WorkspaceDir)
 	}
 
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
@@ -72,16 +144,28 @@ func main() {
 		PollMax:     conf.PollMax,
 		PollBackoff: conf.PollBackoffFactor,
 	})
+	handler.SetReviewArtifactName(conf.ReviewArtifactName)
+	handler.SetOutputTailBytes(conf.OutputTailBytes)
+	handler.SetBranchSummarizer(o.NewBranchSummarizer(brain))
+	if err := handler.SetScopeDir(*scopeDir); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitcodes.Usage)
+	}
+	if err := handler.SetAllowedArtifacts(strings.Split(*allowedArtifacts, ",")); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitcodes.Usage)
+	}
 
-	msgs := o.BuildInitialMessages(tsk, conf.ProjectName, conf.WorkspaceDir, *parent)
+	msgs := o.BuildSpecMessages(spec, conf.ProjectName, conf.WorkspaceDir, *parent, conf.ReviewArtifactName)
 	publish := o.PublishOptions{
-		GitHubToken:    conf.GitHubToken,
-		WorkspaceDir:   conf.WorkspaceDir,
-		ParentBranchID: *parent,
-		ProjectName:    conf.ProjectName,
-		Task:           tsk,
-		GitUserName:    conf.GitUserName,
-		GitUserEmail:   conf.GitUserEmail,
+		GitHubToken:        conf.GitHubToken,
+		WorkspaceDir:       conf.WorkspaceDir,
+		ParentBranchID:     *parent,
+		ProjectName:        conf.ProjectName,
+		Task:               tsk,
+		GitUserName:        conf.GitUserName,
+		GitUserEmail:       conf.GitUserEmail,
+		ReviewArtifactName: conf.ReviewArtifactName,
 	}
 
 	var streamer *streaming.JSONStreamer
@@ -89,25 +173,34 @@ func main() {
 		streamer = streaming.NewJSONStreamer(true, os.Stdout)
 		streamer.EmitThreadStarted(tsk, conf.ProjectName, *parent, *headless)
 	}
+	handler.SetStreamer(streamer)
+	watchdog := streaming.StartWatchdog(streamer, *stallWarning, logx.LastOutput, func(idle time.Duration) {
+		logx.Warningf("No output for %s; the run is still waiting on a branch or LLM call.", idle.Round(time.Second))
+	})
 
 	opts := o.RunOptions{
-		Publish:  publish,
-		Streamer: streamer,
+		Publish:             publish,
+		Streamer:            streamer,
+		MaxDuration:         *maxDuration,
+		MaxTurns:            *maxTurns,
+		MaxToolCallsPerTurn: *maxToolCalls,
 	}
 
+	started := time.Now()
 	var report map[string]any
 	if *headless {
 		report, err = o.Orchestrate(brain, handler, msgs, opts)
 	} else {
 		report, err = o.ChatLoop(brain, handler, msgs, 0, opts)
 	}
+	watchdog.Stop()
 	if err != nil {
 		if streamer != nil && streamer.Enabled() {
 			streamer.EmitError("cli", err.Error(), nil)
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintln(os.Stderr, err.Error())
-		os.Exit(1)
+		os.Exit(exitcodes.Code(err))
 	}
 
 	// Attach observed branch range and instructions
@@ -121,12 +214,22 @@ func main() {
 	if latest, ok := br["latest_branch_id"]; ok {
 		report["latest_branch_id"] = latest
 	}
+	if conf.RunSubdir != "" {
+		report["workspace_dir"] = conf.WorkspaceDir
+	}
 	if _, ok := report["task"]; !ok {
 		report["task"] = tsk
 	}
 	if instr := o.BuildInstructions(report); instr != "" {
 		report["instructions"] = instr
 	}
+	usage := brain.Usage()
+	report["token_usage"] = usage
+	report["prompt_version"] = o.PromptVersion
+	report["headline"] = o.BuildHeadline(report, time.Since(started))
+	if !prices.IsZero() {
+		report["estimated_cost_usd"] = cost.Estimate(prices, usage)
+	}
 
 	if streamer != nil && streamer.Enabled() {
 		status, _ := report["status"].(string)
@@ -137,3 +240,70 @@ func main() {
 	out, _ := json.MarshalIndent(report, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
 }
+
+// runValidateResult implements the `validate-result --file X` subcommand.
+func runValidateResult(args []string) int {
+	fs := flag.NewFlagSet("validate-result", flag.ContinueOnError)
+	file := fs.String("file", "", "Path to a report JSON file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return exitcodes.Usage
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	if err := o.ValidateResult(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Runtime
+	}
+	fmt.Fprintf(os.Stderr, "%s: valid report\n", *file)
+	return exitcodes.Success
+}
+
+// runStreamLint validates a --stream-json capture and prints the report as JSON.
+func runStreamLint(args []string) int {
+	fs := flag.NewFlagSet("stream-lint", flag.ContinueOnError)
+	file := fs.String("file", "-", "Path to an NDJSON event file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	in := io.Reader(os.Stdin)
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+			return exitcodes.Runtime
+		}
+		defer f.Close()
+		in = f
+	}
+	report, err := streaming.Lint(in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		fmt.Fprintf(os.Stderr, "write report: %v\n", err)
+		return exitcodes.Runtime
+	}
+	if !report.Valid {
+		fmt.Fprintf(os.Stderr, "%s: %d problem(s) in %d event(s)\n", *file, len(report.Issues), report.Events)
+		return exitcodes.Runtime
+	}
+	return exitcodes.Success
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -60,3 +63,105 @@ func TestFromEnv_RespectsLargerPollTimeout(t *testing.T) {
 		t.Fatalf("expected PollTimeout 1h, got %s", conf.PollTimeout)
 	}
 }
+
+func TestFromEnv_WorkspacePerRunUsesSubdir(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKSPACE_PER_RUN", "true")
+
+	conf, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if !strings.HasPrefix(conf.RunSubdir, "run-") {
+		t.Fatalf("expected run- prefixed subdir, got %q", conf.RunSubdir)
+	}
+	if conf.WorkspaceDir != "/tmp/workspace/"+conf.RunSubdir {
+		t.Fatalf("expected workspace scoped to run subdir, got %q", conf.WorkspaceDir)
+	}
+}
+
+func TestFromEnv_WorkspacePerRunRejectsInvalidBool(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKSPACE_PER_RUN", "sometimes")
+
+	if _, err := FromEnv(); err == nil {
+		t.Fatalf("expected error for invalid WORKSPACE_PER_RUN")
+	}
+}
+
+func TestFromEnv_ReviewArtifactName(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("REVIEW_ARTIFACT_NAME", "")
+	conf, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if conf.ReviewArtifactName != "code_review.log" {
+		t.Fatalf("expected default review artifact, got %q", conf.ReviewArtifactName)
+	}
+
+	t.Setenv("REVIEW_ARTIFACT_NAME", " review.md ")
+	if conf, err = FromEnv(); err != nil || conf.ReviewArtifactName != "review.md" {
+		t.Fatalf("expected review.md, got %q (err=%v)", conf.ReviewArtifactName, err)
+	}
+
+	t.Setenv("REVIEW_ARTIFACT_NAME", "logs/review.md")
+	if _, err := FromEnv(); err == nil {
+		t.Fatalf("expected error for a REVIEW_ARTIFACT_NAME path")
+	}
+}
+
+func TestFromEnv_BranchOutputTail(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("BRANCH_OUTPUT_TAIL_KB", "")
+	if conf, err := FromEnv(); err != nil || conf.OutputTailBytes != 0 {
+		t.Fatalf("expected the whole output to be kept by default, got %d (err=%v)", conf.OutputTailBytes, err)
+	}
+	t.Setenv("BRANCH_OUTPUT_TAIL_KB", "64")
+	if conf, err := FromEnv(); err != nil || conf.OutputTailBytes != 64*1024 {
+		t.Fatalf("expected a 64 KB tail, got %d (err=%v)", conf.OutputTailBytes, err)
+	}
+	t.Setenv("BRANCH_OUTPUT_TAIL_KB", "-1")
+	if _, err := FromEnv(); err == nil {
+		t.Fatalf("expected error for a negative BRANCH_OUTPUT_TAIL_KB")
+	}
+}
+
+func TestLoad_ProfileFillsUnsetVariablesOnly(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("MCP_POLL_TIMEOUT_SECONDS", "")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "")
+	t.Setenv("PROD_DEPLOYMENT", "gpt-prod")
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	src := `# endpoints per environment
+local:
+  MCP_BASE_URL: http://localhost:9000/mcp/sse
+prod:
+  AZURE_OPENAI_DEPLOYMENT: "${PROD_DEPLOYMENT}"
+  MCP_BASE_URL: https://mcp.example.com/sse
+  PROJECT_NAME: prod-project
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROFILES_FILE", path)
+	t.Setenv("MCP_BASE_URL", "")
+
+	conf, err := Load("prod")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if conf.AzureDeployment != "gpt-prod" {
+		t.Fatalf("expected ${PROD_DEPLOYMENT} to expand, got %q", conf.AzureDeployment)
+	}
+	if conf.MCPBaseURL != "https://mcp.example.com/sse" {
+		t.Fatalf("expected profile MCP_BASE_URL, got %q", conf.MCPBaseURL)
+	}
+	if conf.ProjectName != "test-project" {
+		t.Fatalf("expected PROJECT_NAME from the environment to win, got %q", conf.ProjectName)
+	}
+
+	if _, err := Load("staging"); err == nil || !strings.Contains(err.Error(), "staging") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package logx
 import (
 	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,9 +19,24 @@ const (
 var current Level = Info
 var loggerName = "dev_agent"
 
+// lastOutput holds the UnixNano time of the most recent line actually printed.
+var lastOutput atomic.Int64
+
 func SetLevel(l Level) { current = l }
 
-func ts() string { return time.Now().Format("15:04:05") }
+// LastOutput reports when a log line was last printed; zero if none yet.
+func LastOutput() time.Time {
+	if n := lastOutput.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
+func ts() string {
+	now := time.Now()
+	lastOutput.Store(now.UnixNano())
+	return now.Format("15:04:05")
+}
 
 func Infof(format string, args ...any) {
 	if current <= Info {
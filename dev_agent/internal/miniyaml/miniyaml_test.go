@@ -0,0 +1,163 @@
+package miniyaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type level struct {
+	Name       string `json:"name"`
+	Label      string `json:"label,omitempty"`
+	Definition string `json:"definition"`
+}
+
+type document struct {
+	Levels  []level           `json:"levels"`
+	Tags    []string          `json:"tags,omitempty"`
+	Limits  map[string]string `json:"limits,omitempty"`
+	Enabled bool              `json:"enabled"`
+	Retries int               `json:"retries"`
+	Rate    float64           `json:"rate"`
+	Note    *string           `json:"note,omitempty"`
+	Skipped string            `json:"-"`
+}
+
+func TestUnmarshalDocument(t *testing.T) {
+	src := `---
+# leading comment
+levels:
+  - name: P0   # inline comment
+    label: "Blocker #1"
+    definition: 'It''s down'
+  - name: P1
+    definition: >
+      Folded onto
+      one line.
+
+      New paragraph.
+tags:
+- a
+- "b, c"
+-
+limits: {}
+enabled: true
+retries: 3 # attempts
+rate: 0.5
+note: ~
+`
+	var doc document
+	if err := Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := document{
+		Levels: []level{
+			{Name: "P0", Label: "Blocker #1", Definition: "It's down"},
+			{Name: "P1", Definition: "Folded onto one line.\nNew paragraph.\n"},
+		},
+		Tags:    []string{"a", "b, c", ""},
+		Limits:  map[string]string{},
+		Enabled: true,
+		Retries: 3,
+		Rate:    0.5,
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Fatalf("unexpected document\n got %#v\nwant %#v", doc, want)
+	}
+}
+
+func TestUnmarshalScalars(t *testing.T) {
+	cases := map[string]string{
+		"key: a$b ${C}\n":                   "a$b ${C}",
+		"key: a#b\n":                        "a#b",
+		"key: a #b\n":                       "a",
+		"key: http://host:8080/x\n":         "http://host:8080/x",
+		`key: "tab\there" # c` + "\n":       "tab\there",
+		"key: |\n  one\n    two\n\n":        "one\n  two\n",
+		"key: |-\n  one\n  two\n":           "one\ntwo",
+		"key: |+\n  one\n\n\nnext: x\n":     "one\n\n\n",
+		"key: >-\n  a\n  b\n":               "a b",
+		"key: > # folded\n  a\n   b\n  c\n": "a\n b\nc\n",
+		"key:\n":                            "",
+		"key: null\n":                       "",
+	}
+	for src, want := range cases {
+		var got map[string]any
+		if err := Unmarshal([]byte(src), &got); err != nil {
+			t.Errorf("%q: unexpected error %v", src, err)
+			continue
+		}
+		val, _ := got["key"].(string)
+		if val != want {
+			t.Errorf("%q: got %q, want %q", src, val, want)
+		}
+	}
+}
+
+func TestUnmarshalSequences(t *testing.T) {
+	var got map[string][]any
+	src := "flow: [a, \"b]\", 'c', ~]\nempty: []\nnested:\n  - x\n  - - y\n"
+	if err := Unmarshal([]byte(src), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := map[string][]any{
+		"flow":   {"a", "b]", "c", nil},
+		"empty":  {},
+		"nested": {"x", []any{"y"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestUnmarshalKeepsEmptyMapKeys(t *testing.T) {
+	var got map[string]map[string]string
+	if err := Unmarshal([]byte("dev:\nprod:\n  A: 1\n"), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if _, ok := got["dev"]; !ok || got["prod"]["A"] != "1" {
+		t.Fatalf("unexpected result %#v", got)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	cases := map[string]string{
+		"duplicate":     "a: 1\na: 2\n",
+		"indentation":   "a: 1\n  b: 2\n",
+		"tab":           "a:\n\tb: 1\n",
+		"orphan item":   "- x\na: 1\n",
+		"item in map":   "a: 1\n- x\n",
+		"no colon":      "just text\n",
+		"unterminated":  "a: \"open\n",
+		"after quote":   "a: \"x\" y\n",
+		"flow map":      "a: {b: 1}\n",
+		"nested flow":   "a: [[1]]\n",
+		"open flow":     "a: [1, 2\n",
+		"anchor":        "a: &x 1\n",
+		"block header":  "a: |2\n  x\n",
+		"unknown field": "levels: []\nseverity: high\n",
+		"field path":    "levels:\n  - name: P0\n    severity: high\n",
+		"scalar list":   "tags: none\n",
+		"list scalar":   "enabled:\n  - true\n",
+		"bad bool":      "enabled: maybe\n",
+		"bad int":       "retries: 1.5\n",
+	}
+	for name, src := range cases {
+		var doc document
+		err := Unmarshal([]byte(src), &doc)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if name == "field path" && !strings.Contains(err.Error(), `levels[0]: unknown field "severity"`) {
+			t.Errorf("%s: error should name the field path, got %v", name, err)
+		}
+		if name == "duplicate" && !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%s: error should name the line, got %v", name, err)
+		}
+	}
+	var doc document
+	if err := Unmarshal([]byte("enabled: true\n"), doc); err == nil {
+		t.Fatal("expected an error for a non-pointer target")
+	}
+}
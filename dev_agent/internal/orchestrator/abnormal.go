@@ -0,0 +1,107 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	b "dev_agent/internal/brain"
+	"dev_agent/internal/logx"
+	t "dev_agent/internal/tools"
+)
+
+// Abnormal step kinds recorded in report["abnormal_steps"].
+const (
+	AbnormalToolError   = "tool_error"
+	AbnormalEmptyOutput = "empty_output"
+	AbnormalRetry       = "retry"
+	AbnormalTimeout     = "timeout"
+	AbnormalSkipped     = "skipped"
+)
+
+// AbnormalStep is one tool call that failed or needed unusual handling, in the
+// spirit of the review runner's statistics.AbnormalSteps.
+type AbnormalStep struct {
+	StepName string `json:"step_name"`
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+}
+
+// abnormalStepName labels a tool call; execute_agent calls carry the agent name
+// since one run issues many of them.
+func abnormalStepName(tool string, args map[string]any) string {
+	if agent, _ := args["agent"].(string); tool == "execute_agent" && agent != "" {
+		return fmt.Sprintf("execute_agent(%s)", agent)
+	}
+	return tool
+}
+
+// detectAbnormalSteps inspects a handler result for errors, timeouts, empty
+// outputs and retried calls.
+func detectAbnormalSteps(step string, result map[string]any) []AbnormalStep {
+	if resultStatus(result) != "success" {
+		kind := AbnormalToolError
+		if errObj, _ := result["error"].(map[string]any); errObj != nil {
+			switch reportString(errObj, "kind") {
+			case t.ErrorKindTimeout:
+				kind = AbnormalTimeout
+			case t.ErrorKindEmptyOutput:
+				kind = AbnormalEmptyOutput
+			}
+		}
+		_, msg, _ := toolInstruction(result)
+		if msg == "" {
+			msg = fmt.Sprintf("tool returned status=%s", resultStatus(result))
+		}
+		return []AbnormalStep{{StepName: step, Kind: kind, Message: msg}}
+	}
+
+	var steps []AbnormalStep
+	data, _ := result["data"].(map[string]any)
+	if retries, _ := data["retries"].(int); retries > 0 {
+		steps = append(steps, AbnormalStep{
+			StepName: step,
+			Kind:     AbnormalRetry,
+			Message:  fmt.Sprintf("succeeded after %d retried call(s)", retries),
+		})
+	}
+	if len(data) == 0 {
+		steps = append(steps, AbnormalStep{StepName: step, Kind: AbnormalEmptyOutput, Message: "tool returned no data"})
+	} else if resp, ok := data["response"].(string); ok && strings.TrimSpace(resp) == "" {
+		steps = append(steps, AbnormalStep{StepName: step, Kind: AbnormalEmptyOutput, Message: "agent returned an empty response"})
+	}
+	return steps
+}
+
+// attachAbnormalSteps stores steps on the report; clean runs leave the key unset.
+func attachAbnormalSteps(report map[string]any, steps []AbnormalStep) {
+	if report == nil || len(steps) == 0 {
+		return
+	}
+	report["abnormal_steps"] = steps
+}
+
+// recordSkippedToolCalls accounts for the calls of a turn that never ran because
+// an earlier call in the same turn stopped the run: their count and names go
+// into the report's error payload, and each one becomes a "skipped" abnormal step.
+func recordSkippedToolCalls(report map[string]any, calls []b.ToolCall) []AbnormalStep {
+	if report == nil || len(calls) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(calls))
+	steps := make([]AbnormalStep, 0, len(calls))
+	for _, tc := range calls {
+		args, _ := parseToolArgs(tc.Function.Arguments)
+		name := abnormalStepName(tc.Function.Name, args)
+		names = append(names, name)
+		steps = append(steps, AbnormalStep{StepName: name, Kind: AbnormalSkipped, Message: "not run: an earlier tool call in the turn stopped the workflow"})
+	}
+	errPayload, _ := report["error"].(map[string]any)
+	if errPayload == nil {
+		errPayload = map[string]any{}
+		report["error"] = errPayload
+	}
+	errPayload["skipped_tool_calls"] = len(calls)
+	errPayload["skipped_tools"] = names
+	logx.Warningf("Tool instruction stopped the turn; skipped %d remaining tool call(s): %s", len(calls), strings.Join(names, ", "))
+	return steps
+}
@@ -1,6 +1,7 @@
 package orchestrator
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,127 +13,35 @@ import (
 	"dev_agent/internal/logx"
 	"dev_agent/internal/streaming"
 
+	"dev_agent/internal/taskspec"
+	"dev_agent/internal/templates"
 	t "dev_agent/internal/tools"
 )
 
-const systemPromptTemplate = `You are a expert software engineer, and a TDD (Test-Drive Development) workflow orchestrator.
-
-### Agents
-- **codex**: Analyze the requirement, Design and Implements solutions and tests. Summarizes work in '%[1]s/worklog.md'.
-- **review_code**: Reviews code for P0/P1 issues. Records findings in '%[1]s/code_review.log'.
-
-### Workflow
-1.  **Implement (codex)**: Implement the solution and matching tests for the user's task.
-2.  **Review (review_code)**: Review the implementation for P0/P1 issues.
-3.  **Fix (codex)**: If issues are found, fix all P0/P1 issues and ensure tests pass.
-4.  Repeat **Review** and **Fix** until 'review_code' agent reports no P0/P1 issues.
-
-### Your Orchestration Rules
-1.  **Single Call Per Turn**: Issue exactly one agent/tool call per assistant response; do not batch tool calls because each subsequent agent needs the prior branch's id to extend the branch lineage correctly.
-2.  **Call Agents**: For each workflow step, the agent is invoked through the 'execute_agent'.
-3.  **Maintain State**: Track branch lineage ('parent_branch_id') and report any tool errors immediately.
-4.  **Local-Only Before Publish**: Implement/Review/Fix phases are strictly local development. You may create/checkout branches and stage/commit locally, but you must **NOT** run 'git push' or create PRs (e.g., via 'gh pr create') in these phases.
-
-### Agent Prompt Templates
-
-Don't go into too much detail. You're just a TDD manager, clearly explain the tasks and let the agent analyze and execute them. So please Use the following prompt, Fill in the correct task and issues.
-Never hard-code absolute filesystem paths; derive locations relative to the repository or the configured workspace root (%[1]s).
-
----
-
-#### Implement (codex)
-
-You are an expert engineer. Your goal is to produce high-quality, verified code based on deep analysis.
-Before you start coding: Read as much as you can, you have unlimited read quotas and available contexts. When you are not sure about something, you must study the code until you figure out.
-
-**User Task**: [The user's original task description - must be passed on exactly as is]
-
-**Instructions**:
-
-1.  **Phase 0: Context Verification (CRITICAL)**
-    * Identify the issue or requirement metioned in the User Task (e.g., GitHub Issue IDs, specific requirement/error messages, requirement doc).
-    * **Abort Condition**: If you cannot verify or locate the specific references (e.g., an Issue ID returns 404, or a mentioned file doesn't exist), you must **STOP IMMEDIATELY**.
-        * Do not proceed to design or code.
-        * Write a "Context Failure Report" to '%[1]s/worklog.md' explaining what was missing.
-        * Inform the user that the task cannot be processed due to missing context.
-
-	Hints: if needed, Use the 'gh' CLI to inspect GitHub issues/PRs just like 'git'; if either tool lacks auth, run '~/.setup-git.sh' to configure both before proceeding.
-
-
-2.  **Phase 1: Analysis & Design** (Only if Phase 0 passes)
-	* Read as much as you can, you have unlimited read quotas and available contexts. When you are not sure about something, you must study the code until you figure out.
-    * **Analyze**:
-        * **For Bugs**: Perform Root Cause Analysis (RCA). Locate the code causing the issue.
-        * **For Features**: Identify all code paths and files that need modification.
-    * **Design**: Outline your solution strategy in '%[1]s/worklog.md'.
-
-3.  **Phase 2: TDD Implementation**
-	* **Test**: Write tests first. For bugs, ensure you have a regression test.
-	* **Code**: Implement the solution according to your design.
-	* **Verify**: Ensure local tests pass.
-
-	* **Git Discipline**: Work locally only. You may create/checkout branches and stage/commit locally, but do **NOT** push, and do **NOT** create PRs (e.g., via 'gh pr create') during this phase.
-
-3.  **Final Step**: Update '%[1]s/worklog.md' with a summary of changes and test results.
-
-Ultrathink! Analyze first, then code. Avoid over-engineering.
----
-
-#### Review (review_code)
-
-**User Task**: [The user's original task description]
-
-**Instructions**:
-1.  **Review Code Changes**: Review the recent modifications and tests to determine if they satisfy the User Task.
-2.  **Scope**: Focus **ONLY** on the changed code and the direct impact of these changes.
-    * **Do NOT** review unrelated legacy code or pre-existing issues unless they are made worse by this change.
-3.  **Report**: Identify and log **P0 (Critical)** or **P1 (Major)** issues to '%[1]s/code_review.log'.
-    * If the code meets the requirements and has no critical/major issues, report "No P0/P1 issues found".
-
-Hints: if needed, Use the 'gh' CLI to inspect GitHub issues/PRs just like 'git'; if either tool lacks auth, run '~/.setup-git.sh' to configure both before proceeding.
-
-Think it hard and
-
----
-
-####  Fix (codex)
-
-Ultrathink! Fix all P0/P1 issues reported in the review.
-
-**Issues to Fix**:
-[List of P0/P1 issues from '%[1]s/code_review.log']
-
-**Original User Task**: [The user's original task description]
-
-**Instructions**:
-1.  **Address Issues**: Systematically fix every P0 and P1 issue listed.
-2.  **Verify**: Ensure existing tests pass and add new tests if the review indicated missing coverage.
-3.  **Update Log**: Append a "Fix Summary" to '%[1]s/worklog.md' explaining what was changed.
-4.  **Git Discipline**: Work locally only. You may create/checkout branches and stage/commit locally, but do **NOT** push, and do **NOT** create PRs (e.g., via 'gh pr create') during this phase.
-
-Hints: if needed, Use the 'gh' CLI to inspect GitHub issues/PRs just like 'git'; if either tool lacks auth, run '~/.setup-git.sh' to configure both before proceeding.
-
-Ultrathink! Analyze first, then code. Avoid over-engineering.
-
-### Completion
-* Stop Condition: Stop when a review_code run reports no P0/P1 issues.
-* Final Output: Reply with JSON only: {"is_finished": true, "task":"<original task>","summary":"<Concise outcome>"}
-`
-
 const (
 	statusCompleted         = "completed"
 	statusIterationLimit    = "iteration_limit"
+	statusTurnLimit         = "turn_limit_exceeded"
 	statusFinishedWithError = "FINISHED_WITH_ERROR"
 
 	iterationLimitSummary = "Reached iteration limit before clean review sign-off."
+	turnLimitSummary      = "Reached turn limit before the orchestrator produced a final report."
 	defaultSuccessSummary = "Workflow completed successfully."
 )
 
 const maxIterations = 8
 
+// PromptVersion identifies the orchestrator's system and task prompt generation.
+// main records it on the final report as prompt_version.
+const PromptVersion = "v1.0"
+
+// DefaultMaxTurns caps LLM turns per run when RunOptions.MaxTurns is unset. The
+// review limit alone cannot stop a model that never reviews or finalizes.
+const DefaultMaxTurns = 40
+
 type publishHandler interface {
 	BranchRange() map[string]string
-	Handle(t.ToolCall) map[string]any
+	Handle(context.Context, t.ToolCall) map[string]any
 }
 
 type PublishOptions struct {
@@ -143,11 +52,64 @@ type PublishOptions struct {
 	Task           string
 	GitUserName    string
 	GitUserEmail   string
+	// ReviewArtifactName is the review log kept out of the publish commit; empty
+	// means t.DefaultReviewArtifactName.
+	ReviewArtifactName string
 }
 
 type RunOptions struct {
 	Publish  PublishOptions
 	Streamer *streaming.JSONStreamer
+	// MaxDuration bounds the LLM calls of the whole run; zero means no limit.
+	MaxDuration time.Duration
+	// MaxTurns caps LLM completions regardless of review progress; zero means
+	// DefaultMaxTurns.
+	MaxTurns int
+	// MaxToolCallsPerTurn is how many tool calls of one assistant response are
+	// executed; the rest are answered with an error instead. Zero means
+	// DefaultMaxToolCallsPerTurn.
+	MaxToolCallsPerTurn int
+}
+
+// DefaultMaxToolCallsPerTurn enforces the system prompt's "Single Call Per Turn"
+// rule: each agent call must see the branch id the previous one produced.
+const DefaultMaxToolCallsPerTurn = 1
+
+func maxToolCallsPerTurn(opts RunOptions) int {
+	if opts.MaxToolCallsPerTurn > 0 {
+		return opts.MaxToolCallsPerTurn
+	}
+	return DefaultMaxToolCallsPerTurn
+}
+
+func maxTurns(opts RunOptions) int {
+	if opts.MaxTurns > 0 {
+		return opts.MaxTurns
+	}
+	return DefaultMaxTurns
+}
+
+// limitReport is the unfinished report for a run stopped by a review or turn
+// limit; turnLimited selects which.
+func limitReport(task string, turnLimited bool) map[string]any {
+	status, summary := statusIterationLimit, iterationLimitSummary
+	if turnLimited {
+		status, summary = statusTurnLimit, turnLimitSummary
+	}
+	return map[string]any{
+		"is_finished": false,
+		"status":      status,
+		"task":        task,
+		"summary":     summary,
+	}
+}
+
+// llmContext returns the context LLM calls run under for the given options.
+func llmContext(opts RunOptions) (context.Context, context.CancelFunc) {
+	if opts.MaxDuration > 0 {
+		return context.WithTimeout(context.Background(), opts.MaxDuration)
+	}
+	return context.WithCancel(context.Background())
 }
 
 func finalizeBranchPush(handler publishHandler, opts PublishOptions, report map[string]any, success bool, emitter *eventEmitter) (string, error) {
@@ -162,41 +124,16 @@ func finalizeBranchPush(handler publishHandler, opts PublishOptions, report map[
 
 	outcome := iterationLimitSummary
 	if success {
-		summary := ""
-		if report != nil {
-			if s, ok := report["summary"].(string); ok && s != "" {
-				summary = s
-			}
-		}
-		if summary != "" {
-			outcome = summary
-		} else {
-			outcome = defaultSuccessSummary
+		outcome = defaultSuccessSummary
+	}
+	if report != nil {
+		if s, ok := report["summary"].(string); ok && s != "" {
+			outcome = s
 		}
 	}
 
 	meta := fmt.Sprintf("commit-meta: start_branch=%s latest_branch=%s", lineage["start_branch_id"], lineage["latest_branch_id"])
-	prompt := fmt.Sprintf(`Finalize the task by committing and pushing the current workspace state.
-
-Task: %[1]s
-Outcome: %[2]s
-Meta (include in the commit message if helpful): %[3]s
-
-The worklog is located into '%[4]s/worklog.md'.
-
-Choose an appropriate git branch name for this task, commit the related file changes, and reply with a concise publish report that MUST include: repository URL, pushed Git branch name, commit hash, and pointers to the latest implementation summary/tests (e.g., '%[4]s/worklog.md' and any test artifact).
-
-Publishing rules:
-- Use existing git identity and credentials. If you hit permission/auth issues, run '~/.setup-git.sh' once to configure git and retry. If it still fails, stop and report the failure.
-- Use the original user task and the latest entries in '%[4]s/worklog.md' to determine the target repository; confirm the repository root with 'git rev-parse --show-toplevel' and verify the remote via 'git remote -v'. Do not operate on an unrelated repo.
-- If you cannot confirm a valid git repository (rev-parse/root or remotes are missing), stop immediately, summarize the delivered work (reference '%[4]s/worklog.md' and tests), and exit instead of attempting any git commands.
-- Stage and commit only the files required for this task; exclude logs, review artifacts, and temporary scratch files.
-- Keep branch names kebab-case and describe the task scope.
-- Keep the commit subject <= 72 characters and meaningful.
-- Git push must be fully non-interactive. Rely on existing credentials or the setup script; do not reveal secrets in logs.
-- Do not stage or commit '%[4]s/worklog.md' or '%[4]s/code_review.log'.
-
-Include a short publish report that states the repository URL, branch name, and a concise PR-style summary.`, opts.Task, outcome, meta, opts.WorkspaceDir, opts.WorkspaceDir)
+	prompt := fmt.Sprintf(templates.Publish, opts.Task, outcome, meta, opts.WorkspaceDir, reviewArtifactName(opts.ReviewArtifactName))
 
 	logx.Infof("Finalizing workflow by asking codex to push from branch %s lineage.", parent)
 	execArgs := map[string]any{
@@ -229,7 +166,9 @@ Include a short publish report that states the repository URL, branch name, and
 		start = time.Now()
 	}
 
-	execResp := handler.Handle(execCall)
+	// Publishing still runs once --max-duration has cut the run short, so it
+	// does not take the run's context.
+	execResp := handler.Handle(context.Background(), execCall)
 	if !start.IsZero() {
 		duration = time.Since(start)
 	}
@@ -272,15 +211,48 @@ Include a short publish report that states the repository URL, branch name, and
 	return branchID, nil
 }
 
-func BuildInitialMessages(task, projectName, workspaceDir, parentBranchID string) []b.ChatMessage {
-	systemPrompt := fmt.Sprintf(systemPromptTemplate, workspaceDir)
+// reviewArtifactName falls back to the handler default for an unset name.
+func reviewArtifactName(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return t.DefaultReviewArtifactName
+}
+
+// BuildInitialMessages renders the system prompt and task payload; reviewArtifact
+// names the review log review_code writes (empty means the default).
+func BuildInitialMessages(task, projectName, workspaceDir, parentBranchID, reviewArtifact string) []b.ChatMessage {
+	return BuildSpecMessages(taskspec.Spec{Description: task}, projectName, workspaceDir, parentBranchID, reviewArtifact)
+}
+
+// BuildSpecMessages is BuildInitialMessages for a structured task. Acceptance
+// criteria, constraints and references become payload fields of their own, and
+// the notes tell the orchestrator to have them verified and reported.
+func BuildSpecMessages(spec taskspec.Spec, projectName, workspaceDir, parentBranchID, reviewArtifact string) []b.ChatMessage {
+	systemPrompt := fmt.Sprintf(templates.System, workspaceDir, reviewArtifactName(reviewArtifact))
+	notes := "For every phase: craft a single execute_agent prompt covering task, phase goal, context. Do not batch tool calls. Track branch lineage and stop when review_code reports no P0/P1 issues."
 	userPayload := map[string]any{
-		"task":             task,
+		"task":             spec.Description,
 		"parent_branch_id": parentBranchID,
 		"project_name":     projectName,
 		"workspace_dir":    workspaceDir,
-		"notes":            "For every phase: craft a single execute_agent prompt covering task, phase goal, context. Do not batch tool calls. Track branch lineage and stop when review_code reports no P0/P1 issues.",
 	}
+	if len(spec.Constraints) > 0 {
+		userPayload["constraints"] = spec.Constraints
+	}
+	if len(spec.References) > 0 {
+		userPayload["references"] = spec.References
+	}
+	if len(spec.Constraints) > 0 || len(spec.References) > 0 {
+		notes += " Pass constraints and references to every codex prompt along with the task."
+	}
+	if len(spec.AcceptanceCriteria) > 0 {
+		userPayload["acceptance_criteria"] = spec.AcceptanceCriteria
+		notes += " List every acceptance criterion verbatim in the Implement and Fix prompts, and have codex address each one explicitly." +
+			" In every review_code prompt, list the criteria and instruct it to verify each one, reporting any unmet criterion as a P1 issue." +
+			` The final JSON report must add "acceptance_criteria": [{"criterion": "<verbatim>", "met": true|false, "evidence": "<test or review finding>"}], one entry per criterion.`
+	}
+	userPayload["notes"] = notes
 	content, _ := json.MarshalIndent(userPayload, "", "  ")
 	return []b.ChatMessage{
 		{Role: "system", Content: systemPrompt},
@@ -308,8 +280,13 @@ func ParseFinalReport(msg b.ChatMessage) (map[string]any, bool) {
 }
 
 func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, opts RunOptions) (map[string]any, error) {
+	if err := templates.Validate(); err != nil {
+		return nil, err
+	}
 	tools := t.GetToolDefinitions()
 	emitter := newEventEmitter(opts.Streamer)
+	ctx, cancel := llmContext(opts)
+	defer cancel()
 	var (
 		finalReport    map[string]any
 		finished       bool
@@ -317,16 +294,25 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 		reviewCount    int
 		totalToolCalls int
 		lastTurn       int
+		turnLimited    bool
+		abnormal       []AbnormalStep
 	)
+	turnLimit := maxTurns(opts)
+	callLimit := maxToolCallsPerTurn(opts)
 
 	for i := 1; ; i++ {
+		if i > turnLimit {
+			logx.Errorf("Reached turn limit (%d) without final report.", turnLimit)
+			turnLimited = true
+			break
+		}
 		lastTurn = i
 		logx.Infof("LLM iteration %d", i)
 		turnID := fmt.Sprintf("turn_%d", i)
 		if emitter != nil {
 			emitter.TurnStarted(turnID, i, len(messages), totalToolCalls)
 		}
-		resp, err := brain.Complete(messages, tools)
+		resp, err := brain.Complete(ctx, messages, tools)
 		if err != nil {
 			if emitter != nil {
 				emitter.EmitError("llm.complete", err.Error(), map[string]any{"iteration": i, "turn_id": turnID})
@@ -340,13 +326,14 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 		}
 
 		if len(choice.ToolCalls) > 0 {
+			warnBatchedCalls(len(choice.ToolCalls), callLimit)
 			turnToolCount := 0
 			reviewCompleted := false
 			stopDueToInstruction := false
-			for _, tc := range choice.ToolCalls {
+			for idx, tc := range choice.ToolCalls {
 				turnToolCount++
 				totalToolCalls++
-				args := parseToolArgs(tc.Function.Arguments)
+				args, argsErr := parseToolArgs(tc.Function.Arguments)
 				var itemArgs map[string]any
 				if emitter != nil {
 					itemArgs = sanitizeToolArgs(tc.Function.Name, args)
@@ -362,7 +349,15 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 				if emitter != nil {
 					start = time.Now()
 				}
-				result := handler.Handle(htc)
+				var result map[string]any
+				switch {
+				case turnToolCount > callLimit:
+					result = batchedCallResult(tc.Function.Name, callLimit)
+				case argsErr != nil:
+					result = invalidArgsResult(tc.Function.Name, argsErr)
+				default:
+					result = handler.Handle(ctx, htc)
+				}
 				var duration time.Duration
 				if emitter != nil {
 					duration = time.Since(start)
@@ -372,12 +367,14 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 				if emitter != nil {
 					emitter.ItemCompleted(itemID, resultStatus(result), duration, eventBranchID(result), summarizeToolResult(result))
 				}
+				abnormal = append(abnormal, detectAbnormalSteps(abnormalStepName(tc.Function.Name, args), result)...)
 
 				if instr, summaryMsg, details := toolInstruction(result); instr != "" {
 					if emitter != nil {
 						emitter.EmitError("tool_instruction", summaryMsg, map[string]any{"instruction": instr})
 					}
 					finalReport = buildErrorFinalReport(opts.Publish.Task, summaryMsg, instr, details)
+					abnormal = append(abnormal, recordSkippedToolCalls(finalReport, choice.ToolCalls[idx+1:])...)
 					finished = true
 					errorState = true
 					stopDueToInstruction = true
@@ -442,6 +439,7 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 	}
 
 	if finished {
+		attachAbnormalSteps(finalReport, abnormal)
 		if errorState {
 			ensureReportDefaults(finalReport, opts.Publish.Task, statusFinishedWithError, true)
 			return finalReport, nil
@@ -457,12 +455,8 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 		return finalReport, nil
 	}
 
-	finalReport = map[string]any{
-		"is_finished": false,
-		"status":      statusIterationLimit,
-		"task":        opts.Publish.Task,
-		"summary":     iterationLimitSummary,
-	}
+	finalReport = limitReport(opts.Publish.Task, turnLimited)
+	attachAbnormalSteps(finalReport, abnormal)
 	branchID, err := runPublish(finalReport, false)
 	if err != nil {
 		if emitter != nil {
@@ -471,26 +465,40 @@ func Orchestrate(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMes
 		return nil, err
 	}
 	if branchID != "" {
-		logx.Infof("Workspace published to branch (branch_id=%s) after iteration limit.", branchID)
+		logx.Infof("Workspace published to branch (branch_id=%s) after %s.", branchID, finalReport["status"])
 	}
 	return finalReport, nil
 }
 
 func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessage, maxIters int, opts RunOptions) (map[string]any, error) {
+	if err := templates.Validate(); err != nil {
+		return nil, err
+	}
 	if maxIters <= 0 {
 		maxIters = maxIterations
 	}
 	tools := t.GetToolDefinitions()
+	ctx, cancel := llmContext(opts)
+	defer cancel()
 	var (
 		finalReport map[string]any
 		finished    bool
 		errorState  bool
 		reviewCount int
+		turnLimited bool
+		abnormal    []AbnormalStep
 	)
+	turnLimit := maxTurns(opts)
+	callLimit := maxToolCallsPerTurn(opts)
 
 	for i := 1; ; i++ {
+		if i > turnLimit {
+			logx.Errorf("Reached turn limit (%d) without final report.", turnLimit)
+			turnLimited = true
+			break
+		}
 		fmt.Printf("[iter %d] requesting completion...\n", i)
-		resp, err := brain.Complete(messages, tools)
+		resp, err := brain.Complete(ctx, messages, tools)
 		if err != nil {
 			return nil, err
 		}
@@ -501,27 +509,35 @@ func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessag
 		messages = append(messages, assistantMessageToDict(choice))
 
 		if len(choice.ToolCalls) > 0 {
+			warnBatchedCalls(len(choice.ToolCalls), callLimit)
 			reviewCompleted := false
 			stopDueToInstruction := false
-			for _, tc := range choice.ToolCalls {
+			for j, tc := range choice.ToolCalls {
 				fmt.Printf("tool> %s %s\n", tc.Function.Name, tc.Function.Arguments)
-				var args map[string]any
-				if tc.Function.Arguments != "" {
-					_ = json.Unmarshal([]byte(tc.Function.Arguments), &args)
-				}
+				args, argsErr := parseToolArgs(tc.Function.Arguments)
 				htc := t.ToolCall{ID: tc.ID, Type: tc.Type}
 				htc.Function.Name = tc.Function.Name
 				htc.Function.Arguments = tc.Function.Arguments
-				result := handler.Handle(htc)
+				var result map[string]any
+				switch {
+				case j >= callLimit:
+					result = batchedCallResult(tc.Function.Name, callLimit)
+				case argsErr != nil:
+					result = invalidArgsResult(tc.Function.Name, argsErr)
+				default:
+					result = handler.Handle(ctx, htc)
+				}
 				js := toJSON(result)
 				if len(js) > 2000 {
 					js = js[:2000]
 				}
 				fmt.Printf("tool< %s\n", js)
 				messages = append(messages, b.ChatMessage{Role: "tool", ToolCallID: tc.ID, Content: toJSON(result)})
+				abnormal = append(abnormal, detectAbnormalSteps(abnormalStepName(tc.Function.Name, args), result)...)
 
 				if instr, summaryMsg, details := toolInstruction(result); instr != "" {
 					finalReport = buildErrorFinalReport(opts.Publish.Task, summaryMsg, instr, details)
+					abnormal = append(abnormal, recordSkippedToolCalls(finalReport, choice.ToolCalls[j+1:])...)
 					finished = true
 					errorState = true
 					stopDueToInstruction = true
@@ -559,6 +575,7 @@ func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessag
 	}
 
 	if finished {
+		attachAbnormalSteps(finalReport, abnormal)
 		if errorState {
 			ensureReportDefaults(finalReport, opts.Publish.Task, statusFinishedWithError, true)
 			return finalReport, nil
@@ -571,12 +588,8 @@ func ChatLoop(brain *b.LLMBrain, handler *t.ToolHandler, messages []b.ChatMessag
 		return finalReport, nil
 	}
 
-	finalReport = map[string]any{
-		"is_finished": false,
-		"status":      statusIterationLimit,
-		"task":        opts.Publish.Task,
-		"summary":     iterationLimitSummary,
-	}
+	finalReport = limitReport(opts.Publish.Task, turnLimited)
+	attachAbnormalSteps(finalReport, abnormal)
 	branchID, err := finalizeBranchPush(handler, opts.Publish, finalReport, false, nil)
 	if err != nil {
 		return nil, err
@@ -633,7 +646,7 @@ func BuildInstructions(report map[string]any) string {
 	}
 
 	switch status {
-	case statusIterationLimit:
+	case statusIterationLimit, statusTurnLimit:
 		target := latest
 		if target == "" {
 			target = start
@@ -654,6 +667,30 @@ func BuildInstructions(report map[string]any) string {
 	return strings.TrimSpace(strings.Join(parts, " "))
 }
 
+// headlineTaskChars caps how much of the task a headline quotes.
+const headlineTaskChars = 60
+
+// BuildHeadline templates a one-sentence summary of the final report, e.g.
+// `Dev task "add retries to the uploader": completed, published (12m3s).`
+func BuildHeadline(report map[string]any, elapsed time.Duration) string {
+	task := strings.Join(strings.Fields(reportString(report, "task")), " ")
+	if runes := []rune(task); len(runes) > headlineTaskChars {
+		task = strings.TrimSpace(string(runes[:headlineTaskChars])) + "..."
+	}
+	status := reportString(report, "status")
+	if status == "" {
+		status = "unknown status"
+	}
+	outcome := []string{status}
+	if finished, _ := report["is_finished"].(bool); !finished {
+		outcome = append(outcome, "not finished")
+	}
+	if reportString(report, "publish_report") != "" {
+		outcome = append(outcome, "published")
+	}
+	return fmt.Sprintf("Dev task %q: %s (%s).", task, strings.Join(outcome, ", "), elapsed.Round(time.Second))
+}
+
 func reportString(report map[string]any, key string) string {
 	if report == nil {
 		return ""
@@ -745,15 +782,51 @@ func (e *eventEmitter) EmitError(scope, message string, extra map[string]any) {
 	e.streamer.EmitError(scope, message, extra)
 }
 
-func parseToolArgs(raw string) map[string]any {
+// parseToolArgs decodes tool-call arguments. Anything but a JSON object (or an
+// empty string) is an error; the returned map is never nil.
+func parseToolArgs(raw string) (map[string]any, error) {
 	if strings.TrimSpace(raw) == "" {
-		return map[string]any{}
+		return map[string]any{}, nil
 	}
-	var args map[string]any
-	if err := json.Unmarshal([]byte(raw), &args); err != nil {
-		return map[string]any{}
+	var decoded any
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return map[string]any{}, fmt.Errorf("arguments are not valid JSON: %v", err)
+	}
+	args, ok := decoded.(map[string]any)
+	if !ok {
+		return map[string]any{}, fmt.Errorf("arguments must be a JSON object, got %s", jsonType(decoded))
+	}
+	return args, nil
+}
+
+// invalidArgsResult is the tool result sent back instead of dispatching a call
+// whose arguments could not be read, so the model can retry with an object.
+func invalidArgsResult(tool string, err error) map[string]any {
+	return map[string]any{
+		"status": "error",
+		"error": map[string]any{
+			"message": fmt.Sprintf("Invalid arguments for %s: %v. Resend the call with its arguments as a JSON object.", tool, err),
+			"kind":    t.ErrorKindInvalidArgs,
+		},
+	}
+}
+
+// batchedCallResult answers a tool call beyond the per-turn limit without running
+// it. Every call still needs a tool message, or the next completion is rejected.
+func batchedCallResult(tool string, limit int) map[string]any {
+	return map[string]any{
+		"status": "error",
+		"error": map[string]any{
+			"message": fmt.Sprintf("%s was not executed: batching is not allowed (at most %d tool call(s) per turn); issue one call per turn and resend it after the previous result.", tool, limit),
+			"kind":    t.ErrorKindBatchedCall,
+		},
+	}
+}
+
+func warnBatchedCalls(calls, limit int) {
+	if calls > limit {
+		logx.Warningf("Assistant batched %d tool calls in one turn; executing the first %d and rejecting the rest.", calls, limit)
 	}
-	return args
 }
 
 func sanitizeToolArgs(name string, args map[string]any) map[string]any {
@@ -779,6 +852,8 @@ func sanitizeToolArgs(name string, args map[string]any) map[string]any {
 	case "branch_output":
 		copyStringField(out, args, "branch_id")
 		copyBoolField(out, args, "full_output")
+	case "summarize_branch":
+		copyStringField(out, args, "branch_id")
 	default:
 		for k, v := range args {
 			switch val := v.(type) {
@@ -1,8 +1,18 @@
 package orchestrator
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	b "dev_agent/internal/brain"
+	"dev_agent/internal/taskspec"
+	"dev_agent/internal/tools"
 )
 
 func TestToolInstructionExtractsInstruction(t *testing.T) {
@@ -73,3 +83,292 @@ func TestBuildInstructionsMentionsErrorState(t *testing.T) {
 		t.Fatalf("instructions should mention latest branch, got %q", out)
 	}
 }
+
+func TestBuildHeadline(t *testing.T) {
+	report := map[string]any{
+		"task":           "add retries\nto the uploader",
+		"status":         statusCompleted,
+		"is_finished":    true,
+		"publish_report": "pushed to feature/retries",
+	}
+	want := `Dev task "add retries to the uploader": completed, published (12m3s).`
+	if got := BuildHeadline(report, 12*time.Minute+3*time.Second); got != want {
+		t.Fatalf("BuildHeadline = %q, want %q", got, want)
+	}
+
+	report = map[string]any{"task": "add retries", "status": statusTurnLimit, "is_finished": false}
+	want = `Dev task "add retries": turn_limit_exceeded, not finished (45s).`
+	if got := BuildHeadline(report, 45*time.Second); got != want {
+		t.Fatalf("BuildHeadline = %q, want %q", got, want)
+	}
+}
+
+func TestDetectAbnormalStepsClassifiesToolResults(t *testing.T) {
+	cases := []struct {
+		name   string
+		result map[string]any
+		want   []string
+	}{
+		{"clean", map[string]any{"status": "success", "data": map[string]any{"response": "done"}}, nil},
+		{"timeout", map[string]any{"status": "error", "error": map[string]any{"message": "Timed out waiting for branch b1", "kind": "timeout"}}, []string{AbnormalTimeout}},
+		{"empty", map[string]any{"status": "error", "error": map[string]any{"message": "branch_output returned no textual output", "kind": "empty_output"}}, []string{AbnormalEmptyOutput}},
+		{"error", map[string]any{"status": "error", "error": "Unsupported tool: nope"}, []string{AbnormalToolError}},
+		{"retried", map[string]any{"status": "success", "data": map[string]any{"response": "done", "retries": 2}}, []string{AbnormalRetry}},
+		{"no data", map[string]any{"status": "success", "data": map[string]any{}}, []string{AbnormalEmptyOutput}},
+	}
+	for _, tc := range cases {
+		steps := detectAbnormalSteps("execute_agent(codex)", tc.result)
+		if len(steps) != len(tc.want) {
+			t.Errorf("%s: got %#v, want kinds %v", tc.name, steps, tc.want)
+			continue
+		}
+		for i, step := range steps {
+			if step.Kind != tc.want[i] || step.StepName != "execute_agent(codex)" || step.Message == "" {
+				t.Errorf("%s: unexpected step %#v", tc.name, step)
+			}
+		}
+	}
+	if got := abnormalStepName("execute_agent", map[string]any{"agent": "review_code"}); got != "execute_agent(review_code)" {
+		t.Fatalf("unexpected step name %q", got)
+	}
+}
+
+func TestAttachAbnormalStepsSkipsCleanRuns(t *testing.T) {
+	report := map[string]any{}
+	attachAbnormalSteps(report, nil)
+	if _, ok := report["abnormal_steps"]; ok {
+		t.Fatalf("clean run should not carry abnormal_steps")
+	}
+	attachAbnormalSteps(report, []AbnormalStep{{StepName: "check_status", Kind: AbnormalTimeout, Message: "timed out"}})
+	if err := ValidateResult([]byte(`{"task":"t","status":"completed","is_finished":true,"abnormal_steps":[{"step_name":"check_status","kind":"timeout","message":"timed out"}]}`)); err != nil {
+		t.Fatalf("abnormal_steps should validate: %v", err)
+	}
+	if steps, _ := report["abnormal_steps"].([]AbnormalStep); len(steps) != 1 {
+		t.Fatalf("expected one attached step, got %#v", report["abnormal_steps"])
+	}
+}
+
+func TestRecordSkippedToolCallsAddsToErrorPayload(t *testing.T) {
+	report := buildErrorFinalReport("task", "review_code failed", "STOP", nil)
+	skipped := []b.ToolCall{
+		{ID: "2", Function: b.ToolFunction{Name: "execute_agent", Arguments: `{"agent":"codex"}`}},
+		{ID: "3", Function: b.ToolFunction{Name: "check_status", Arguments: `not json`}},
+	}
+	steps := recordSkippedToolCalls(report, skipped)
+	errPayload, _ := report["error"].(map[string]any)
+	if errPayload["skipped_tool_calls"] != 2 || errPayload["message"] != "review_code failed" {
+		t.Fatalf("unexpected error payload %#v", errPayload)
+	}
+	names, _ := errPayload["skipped_tools"].([]string)
+	if len(names) != 2 || names[0] != "execute_agent(codex)" || names[1] != "check_status" {
+		t.Fatalf("unexpected skipped tool names %v", names)
+	}
+	if len(steps) != 2 || steps[0].Kind != AbnormalSkipped {
+		t.Fatalf("expected a skipped abnormal step per call, got %#v", steps)
+	}
+	if recordSkippedToolCalls(report, nil) != nil {
+		t.Fatal("a stop on the turn's last call skips nothing")
+	}
+}
+
+func TestParseToolArgsRejectsNonObjectArguments(t *testing.T) {
+	args, err := parseToolArgs(`[{"agent":"review_code"}]`)
+	if err == nil || !strings.Contains(err.Error(), "got array") {
+		t.Fatalf("expected array arguments to be rejected, got %v", err)
+	}
+	if args == nil {
+		t.Fatal("args should be an empty map, not nil")
+	}
+	for _, raw := range []string{`"codex"`, `42`, `{"agent":`} {
+		if _, err := parseToolArgs(raw); err == nil {
+			t.Errorf("%s: expected error", raw)
+		}
+	}
+	if args, err := parseToolArgs(`{"agent":"codex"}`); err != nil || args["agent"] != "codex" {
+		t.Fatalf("object arguments should parse, got %#v, %v", args, err)
+	}
+
+	result := invalidArgsResult("execute_agent", err)
+	instr, msg, _ := toolInstruction(result)
+	if instr != "" {
+		t.Fatalf("invalid arguments should not halt the workflow, got instruction %q", instr)
+	}
+	if !strings.Contains(msg, "execute_agent") || !strings.Contains(msg, "JSON object") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if kind := result["error"].(map[string]any)["kind"]; kind != "invalid_args" {
+		t.Fatalf("unexpected kind %v", kind)
+	}
+}
+
+func TestBuildInitialMessagesUsesReviewArtifactName(t *testing.T) {
+	msgs := BuildInitialMessages("task", "proj", "/ws", "parent", "review.md")
+	system := msgs[0].Content
+	if !strings.Contains(system, "'/ws/review.md'") || strings.Contains(system, "code_review.log") {
+		t.Fatalf("system prompt should name the configured artifact only:\n%s", system)
+	}
+	if !strings.Contains(BuildInitialMessages("task", "proj", "/ws", "parent", "")[0].Content, "'/ws/code_review.log'") {
+		t.Fatal("empty artifact name should fall back to code_review.log")
+	}
+}
+
+// publishOnlyClient lets the publish step after a limit succeed immediately.
+type publishOnlyClient struct{ explores int }
+
+func (c *publishOnlyClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	c.explores++
+	return map[string]any{"branch_id": "publish"}, nil
+}
+
+func (c *publishOnlyClient) GetBranch(branchID string) (map[string]any, error) {
+	return map[string]any{"id": branchID, "status": "succeed"}, nil
+}
+
+func (c *publishOnlyClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	return map[string]any{"content": ""}, nil
+}
+
+func (c *publishOnlyClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	return map[string]any{"output": "pushed"}, nil
+}
+
+func TestOrchestrateStopsAtTurnLimitWithoutReviews(t *testing.T) {
+	var completions atomic.Int32
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		completions.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Still planning the next step."}}]}`))
+	}))
+	defer llm.Close()
+
+	client := &publishOnlyClient{}
+	handler := tools.NewToolHandler(client, "proj", "parent", "/ws", nil)
+	brain := b.NewLLMBrain("key", llm.URL, "deployment", "2024-01-01", 1)
+	messages := []b.ChatMessage{{Role: "user", Content: "task"}}
+
+	report, err := Orchestrate(brain, handler, messages, RunOptions{Publish: PublishOptions{Task: "task", ParentBranchID: "parent"}, MaxTurns: 3})
+	if err != nil {
+		t.Fatalf("Orchestrate error: %v", err)
+	}
+	if got := completions.Load(); got != 3 {
+		t.Fatalf("expected exactly 3 LLM turns, got %d", got)
+	}
+	if report["status"] != statusTurnLimit || report["is_finished"] != false {
+		t.Fatalf("expected an unfinished %s report, got %v", statusTurnLimit, report)
+	}
+	if client.explores != 1 || report["publish_report"] != "pushed" {
+		t.Fatalf("expected the workspace to be published once, got %d explores and %v", client.explores, report)
+	}
+	report["latest_branch_id"] = "publish"
+	if !strings.Contains(BuildInstructions(report), "--parent-branch-id publish") {
+		t.Fatalf("expected rerun instructions for a turn-limited run, got %q", BuildInstructions(report))
+	}
+}
+
+func TestOrchestrateRejectsToolCallsBeyondPerTurnLimit(t *testing.T) {
+	var (
+		requests [][]b.ChatMessage
+		mu       sync.Mutex
+	)
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []b.ChatMessage `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		requests = append(requests, req.Messages)
+		first := len(requests) == 1
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if first {
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","tool_calls":[` +
+				`{"id":"call_1","type":"function","function":{"name":"get_lineage","arguments":"{}"}},` +
+				`{"id":"call_2","type":"function","function":{"name":"get_lineage","arguments":"{}"}}]}}]}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"is_finished\": true, \"summary\": \"done\"}"}}]}`))
+	}))
+	defer llm.Close()
+
+	handler := tools.NewToolHandler(&publishOnlyClient{}, "proj", "parent", "/ws", nil)
+	brain := b.NewLLMBrain("key", llm.URL, "deployment", "2024-01-01", 1)
+	report, err := Orchestrate(brain, handler, []b.ChatMessage{{Role: "user", Content: "task"}}, RunOptions{Publish: PublishOptions{Task: "task", ParentBranchID: "parent"}})
+	if err != nil {
+		t.Fatalf("Orchestrate error: %v", err)
+	}
+	if report["status"] != statusCompleted {
+		t.Fatalf("expected the run to complete, got %v", report)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 LLM requests, got %d", len(requests))
+	}
+	toolMsgs := map[string]string{}
+	for _, msg := range requests[1] {
+		if msg.Role == "tool" {
+			toolMsgs[msg.ToolCallID] = msg.Content
+		}
+	}
+	if strings.Contains(toolMsgs["call_1"], "batched_call") || !strings.Contains(toolMsgs["call_1"], "latest_branch_id") {
+		t.Fatalf("expected the first call to run, got %s", toolMsgs["call_1"])
+	}
+	if !strings.Contains(toolMsgs["call_2"], "batched_call") || !strings.Contains(toolMsgs["call_2"], "one call per turn") {
+		t.Fatalf("expected the second call to be rejected, got %q", toolMsgs["call_2"])
+	}
+	steps, _ := report["abnormal_steps"].([]AbnormalStep)
+	if len(steps) != 1 || steps[0].StepName != "get_lineage" {
+		t.Fatalf("expected the rejected call to be recorded as abnormal, got %v", report["abnormal_steps"])
+	}
+}
+
+func TestChatLoopRecordsAbnormalSteps(t *testing.T) {
+	var turns atomic.Int32
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if turns.Add(1) == 1 {
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","tool_calls":[` +
+				`{"id":"call_1","type":"function","function":{"name":"get_lineage","arguments":"{}"}},` +
+				`{"id":"call_2","type":"function","function":{"name":"get_lineage","arguments":"{}"}}]}}]}`))
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"is_finished\": true, \"summary\": \"done\"}"}}]}`))
+	}))
+	defer llm.Close()
+
+	handler := tools.NewToolHandler(&publishOnlyClient{}, "proj", "parent", "/ws", nil)
+	brain := b.NewLLMBrain("key", llm.URL, "deployment", "2024-01-01", 1)
+	report, err := ChatLoop(brain, handler, []b.ChatMessage{{Role: "user", Content: "task"}}, 0, RunOptions{Publish: PublishOptions{Task: "task", ParentBranchID: "parent"}})
+	if err != nil {
+		t.Fatalf("ChatLoop error: %v", err)
+	}
+	steps, _ := report["abnormal_steps"].([]AbnormalStep)
+	if len(steps) != 1 || steps[0].StepName != "get_lineage" {
+		t.Fatalf("expected the rejected call to be recorded as abnormal, got %v", report["abnormal_steps"])
+	}
+}
+
+func TestBuildSpecMessagesAddsStructuredFields(t *testing.T) {
+	spec := taskspec.Spec{
+		Description:        "Add rate limiting",
+		AcceptanceCriteria: []string{"Requests over 100/min get HTTP 429"},
+		References:         []string{"docs/api.md"},
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(BuildSpecMessages(spec, "proj", "/ws", "parent", "")[1].Content), &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["task"] != "Add rate limiting" || payload["constraints"] != nil {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+	if criteria, _ := payload["acceptance_criteria"].([]any); len(criteria) != 1 {
+		t.Fatalf("expected the acceptance criteria in the payload, got %v", payload["acceptance_criteria"])
+	}
+	notes, _ := payload["notes"].(string)
+	if !strings.Contains(notes, "review_code") || !strings.Contains(notes, `"acceptance_criteria"`) {
+		t.Fatalf("expected notes to require criteria verification and reporting, got %q", notes)
+	}
+
+	plain := BuildInitialMessages("Add rate limiting", "proj", "/ws", "parent", "")[1].Content
+	if strings.Contains(plain, "acceptance_criteria") || strings.Contains(plain, "references") {
+		t.Fatalf("a bare task should keep the original payload:\n%s", plain)
+	}
+}
@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	b "dev_agent/internal/brain"
+	t "dev_agent/internal/tools"
+)
+
+// maxSummaryInputChars bounds the branch output sent to the summarization call.
+// Agents finish with their conclusions, so longer outputs keep the tail.
+const maxSummaryInputChars = 120000
+
+const branchSummaryPrompt = `You condense the output of a coding agent's branch for an orchestrator that cannot read it in full.
+Report, in at most 15 short bullet points:
+- the outcome (done, partially done, failed, or aborted) and why;
+- key findings, including any P0/P1 issues with their file:line anchors;
+- files created or changed;
+- tests run and their results;
+- open problems or follow-ups the agent reported.
+Quote identifiers, paths and error messages exactly. Do not speculate beyond the output.`
+
+// NewBranchSummarizer returns the summarize_branch backend: a single completion
+// call per branch output, without tools.
+func NewBranchSummarizer(brain *b.LLMBrain) t.BranchSummarizer {
+	return func(ctx context.Context, output string) (string, error) {
+		messages := []b.ChatMessage{
+			{Role: "system", Content: branchSummaryPrompt},
+			{Role: "user", Content: tailForSummary(output, maxSummaryInputChars)},
+		}
+		resp, err := brain.Complete(b.WithCallKind(ctx, b.CallAuxiliary), messages, nil)
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", errors.New("summarization returned no choices")
+		}
+		summary := strings.TrimSpace(resp.Choices[0].Message.Content)
+		if summary == "" {
+			return "", errors.New("summarization returned an empty answer")
+		}
+		return summary, nil
+	}
+}
+
+// tailForSummary keeps the last limit bytes of output, starting on a rune boundary.
+func tailForSummary(output string, limit int) string {
+	if len(output) <= limit {
+		return output
+	}
+	cut := len(output) - limit
+	for cut < len(output) && !utf8.RuneStart(output[cut]) {
+		cut++
+	}
+	return fmt.Sprintf("[... first %d bytes omitted ...]\n", cut) + output[cut:]
+}
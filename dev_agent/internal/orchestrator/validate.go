@@ -0,0 +1,96 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// reportRequiredFields are the keys the CLI always sets on the final report
+// (see ensureReportDefaults and main), mapped to their expected JSON type.
+var reportRequiredFields = map[string]string{
+	"task":        "string",
+	"status":      "string",
+	"is_finished": "boolean",
+}
+
+// reportOptionalFields are keys the CLI may attach; when present they must have this type.
+var reportOptionalFields = map[string]string{
+	"summary":             "string",
+	"instruction":         "string",
+	"instructions":        "string",
+	"start_branch_id":     "string",
+	"latest_branch_id":    "string",
+	"workspace_dir":       "string",
+	"publish_report":      "string",
+	"error":               "object",
+	"abnormal_steps":      "array",
+	"token_usage":         "object",
+	"estimated_cost_usd":  "object",
+	"acceptance_criteria": "array",
+	"prompt_version":      "string",
+	"headline":            "string",
+}
+
+// ValidateResult checks that data is a final report in the shape this version emits.
+// The rest of the report is authored by the orchestrator LLM, so unknown fields are
+// allowed; only missing required fields and mistyped known fields are reported.
+func ValidateResult(data []byte) error {
+	var report map[string]any
+	if err := json.Unmarshal(data, &report); err != nil {
+		return fmt.Errorf("report is not a JSON object: %w", err)
+	}
+	var problems []string
+	for _, name := range sortedKeys(reportRequiredFields) {
+		value, ok := report[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("missing field %s", name))
+			continue
+		}
+		if want := reportRequiredFields[name]; jsonType(value) != want {
+			problems = append(problems, fmt.Sprintf("%s: expected %s", name, want))
+		}
+	}
+	for _, name := range sortedKeys(reportOptionalFields) {
+		value, ok := report[name]
+		if !ok || value == nil {
+			continue
+		}
+		if want := reportOptionalFields[name]; jsonType(value) != want {
+			problems = append(problems, fmt.Sprintf("%s: expected %s", name, want))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New("report schema mismatch: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case nil:
+		return "null"
+	}
+	return "unknown"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateResultAllowsExtraReportFields(t *testing.T) {
+	raw := `{"task":"t","status":"completed","is_finished":true,"start_branch_id":"a","latest_branch_id":"b","pr_url":"https://example"}`
+	if err := ValidateResult([]byte(raw)); err != nil {
+		t.Fatalf("expected valid report, got %v", err)
+	}
+}
+
+func TestValidateResultReportsMissingAndMistypedFields(t *testing.T) {
+	raw := `{"status":"completed","is_finished":"yes","summary":3}`
+	err := ValidateResult([]byte(raw))
+	if err == nil {
+		t.Fatalf("expected schema mismatch")
+	}
+	for _, want := range []string{"missing field task", "is_finished: expected boolean", "summary: expected string"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err.Error(), want)
+		}
+	}
+}
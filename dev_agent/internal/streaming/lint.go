@@ -0,0 +1,199 @@
+package streaming
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// envelopeFields are set by emit on every event.
+var envelopeFields = []string{"type", "timestamp", "sequence", "thread_id"}
+
+// eventFields lists, per event type, the payload fields its Emit helper always
+// sets. It is the schema stream-lint checks lines against.
+var eventFields = map[string][]string{
+	"thread.started":       {"task", "project_name", "parent_branch_id", "headless"},
+	"turn.started":         {"turn_id", "iteration", "message_count", "tool_count"},
+	"assistant.message":    {"turn_id", "preview", "tool_call_count"},
+	"turn.completed":       {"turn_id", "iteration", "tool_call_count", "has_final_report"},
+	"item.started":         {"item_id", "kind", "name", "args"},
+	"item.completed":       {"item_id", "status", "duration_ms"},
+	"poll.tick":            {"branch_id", "attempt", "elapsed_ms", "status"},
+	"thread.completed":     {"status"},
+	"error":                {"scope", "message"},
+	"thread.stall_warning": {"idle_ms"},
+}
+
+// LintIssue is one problem found in an event stream. Line is 1-based; 0 marks
+// a problem with the stream as a whole, such as an item that never completed.
+type LintIssue struct {
+	Line    int    `json:"line"`
+	Type    string `json:"type,omitempty"`
+	Message string `json:"message"`
+}
+
+// LintReport is the result of Lint.
+type LintReport struct {
+	Valid  bool           `json:"valid"`
+	Events int            `json:"events"`
+	Counts map[string]int `json:"counts"`
+	Issues []LintIssue    `json:"issues,omitempty"`
+}
+
+// Lint reads an NDJSON event stream and checks each line against the event
+// schema, then checks that the events pair up: thread.started comes first and
+// thread.completed last, turns do not overlap and complete with the turn_id they
+// started with, and every item.started has exactly one item.completed. Blank
+// lines are ignored; an error is returned only when r cannot be read.
+func Lint(r io.Reader) (*LintReport, error) {
+	l := &linter{
+		report:    &LintReport{Counts: make(map[string]int)},
+		openItems: make(map[string]int),
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		l.check(lineNo, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read event stream: %w", err)
+	}
+	l.finish()
+	l.report.Valid = len(l.report.Issues) == 0
+	return l.report, nil
+}
+
+type linter struct {
+	report *LintReport
+
+	threadID        string
+	threadStarted   bool
+	threadCompleted bool
+	lastSequence    float64
+	openTurn        string
+	openTurnLine    int
+	openItems       map[string]int // item_id -> line of its item.started
+}
+
+func (l *linter) addIssue(line int, eventType, format string, args ...any) {
+	l.report.Issues = append(l.report.Issues, LintIssue{Line: line, Type: eventType, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) check(line int, raw string) {
+	var ev map[string]any
+	if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+		l.addIssue(line, "", "not a JSON object: %v", err)
+		return
+	}
+	l.report.Events++
+	eventType, _ := ev["type"].(string)
+	fields, known := eventFields[eventType]
+	if !known {
+		l.addIssue(line, eventType, "unknown event type %q", eventType)
+	}
+	l.report.Counts[eventType]++
+	for _, group := range [][]string{envelopeFields, fields} {
+		for _, field := range group {
+			if _, ok := ev[field]; !ok {
+				l.addIssue(line, eventType, "missing field %q", field)
+			}
+		}
+	}
+
+	if seq, ok := ev["sequence"].(float64); ok {
+		if seq <= l.lastSequence {
+			l.addIssue(line, eventType, "sequence %v does not follow %v", seq, l.lastSequence)
+		}
+		l.lastSequence = seq
+	}
+	if id, _ := ev["thread_id"].(string); id != "" {
+		if l.threadID == "" {
+			l.threadID = id
+		} else if id != l.threadID {
+			l.addIssue(line, eventType, "thread_id %q differs from %q", id, l.threadID)
+		}
+	}
+
+	if l.threadCompleted {
+		l.addIssue(line, eventType, "event after thread.completed")
+	}
+	switch eventType {
+	case "thread.started":
+		if l.threadStarted || l.report.Events > 1 {
+			l.addIssue(line, eventType, "thread.started must be the first event and appear once")
+		}
+		l.threadStarted = true
+		return
+	case "thread.completed":
+		l.threadCompleted = true
+	}
+	if !l.threadStarted {
+		l.addIssue(line, eventType, "event before thread.started")
+		l.threadStarted = true // report the missing start once
+	}
+
+	turnID, _ := ev["turn_id"].(string)
+	itemID, _ := ev["item_id"].(string)
+	switch eventType {
+	case "turn.started":
+		if l.openTurn != "" {
+			l.addIssue(line, eventType, "turn %q started before turn %q (line %d) completed", turnID, l.openTurn, l.openTurnLine)
+		}
+		l.openTurn, l.openTurnLine = turnID, line
+	case "turn.completed":
+		switch {
+		case l.openTurn == "":
+			l.addIssue(line, eventType, "turn %q completed but no turn is open", turnID)
+		case turnID != l.openTurn:
+			l.addIssue(line, eventType, "turn %q completed while turn %q (line %d) is open", turnID, l.openTurn, l.openTurnLine)
+		}
+		l.openTurn, l.openTurnLine = "", 0
+	case "item.started":
+		if itemID == "" {
+			return
+		}
+		if started, open := l.openItems[itemID]; open {
+			l.addIssue(line, eventType, "item %q started again before it completed (line %d)", itemID, started)
+		}
+		l.openItems[itemID] = line
+	case "item.completed":
+		if itemID == "" {
+			return
+		}
+		if _, open := l.openItems[itemID]; !open {
+			l.addIssue(line, eventType, "item %q completed without a matching item.started", itemID)
+		}
+		delete(l.openItems, itemID)
+	}
+}
+
+// finish reports what the stream left open.
+func (l *linter) finish() {
+	if l.report.Events == 0 {
+		l.addIssue(0, "", "stream has no events")
+		return
+	}
+	if l.openTurn != "" {
+		l.addIssue(0, "turn.started", "turn %q (line %d) never completed", l.openTurn, l.openTurnLine)
+	}
+	ids := make([]string, 0, len(l.openItems))
+	for id := range l.openItems {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return l.openItems[ids[i]] < l.openItems[ids[j]] })
+	for _, id := range ids {
+		l.addIssue(0, "item.started", "item %q (line %d) never completed", id, l.openItems[id])
+	}
+	if !l.threadCompleted {
+		l.addIssue(0, "thread.completed", "stream ends without thread.completed")
+	}
+}
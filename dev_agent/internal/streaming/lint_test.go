@@ -0,0 +1,65 @@
+package streaming
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLintAcceptsStreamerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONStreamer(true, &buf)
+	s.EmitThreadStarted("task", "proj", "parent", true)
+	s.EmitTurnStarted("turn_1", 1, 2, 0)
+	s.EmitAssistantMessage("turn_1", "calling codex", 1)
+	s.EmitItemStarted("item_1", "tool_call", "execute_agent", nil)
+	s.EmitPollTick("b1", 1, time.Second, "running")
+	s.EmitItemCompleted("item_1", "success", time.Second, "b1", "done")
+	s.EmitTurnCompleted("turn_1", 1, 1, false)
+	s.EmitThreadCompleted("completed", "done", nil)
+
+	report, err := Lint(&buf)
+	if err != nil {
+		t.Fatalf("Lint error: %v", err)
+	}
+	if !report.Valid || report.Events != 8 || report.Counts["item.started"] != 1 {
+		t.Fatalf("expected a valid 8-event report, got %+v", report)
+	}
+}
+
+func TestLintReportsUnbalancedEvents(t *testing.T) {
+	stream := strings.Join([]string{
+		`{"type":"turn.started","timestamp":"t","sequence":1,"thread_id":"a","turn_id":"turn_1","iteration":1,"message_count":1,"tool_count":0}`,
+		`{"type":"item.started","timestamp":"t","sequence":2,"thread_id":"a","item_id":"item_1","kind":"tool_call","name":"x","args":{}}`,
+		`{"type":"item.completed","timestamp":"t","sequence":3,"thread_id":"a","item_id":"item_9","status":"success","duration_ms":1}`,
+		`{"type":"turn.completed","timestamp":"t","sequence":3,"thread_id":"a","turn_id":"turn_2","iteration":1,"tool_call_count":1}`,
+		`not json`,
+	}, "\n")
+	report, err := Lint(strings.NewReader(stream))
+	if err != nil {
+		t.Fatalf("Lint error: %v", err)
+	}
+	if report.Valid {
+		t.Fatal("expected the stream to be invalid")
+	}
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Message)
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"event before thread.started",
+		`item "item_9" completed without a matching item.started`,
+		`missing field "has_final_report"`,
+		"sequence 3 does not follow 3",
+		`turn "turn_2" completed while turn "turn_1" (line 1) is open`,
+		"not a JSON object",
+		`item "item_1" (line 2) never completed`,
+		"stream ends without thread.completed",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing issue %q in:\n%s", want, got)
+		}
+	}
+}
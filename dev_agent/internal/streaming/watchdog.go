@@ -0,0 +1,94 @@
+package streaming
+
+import "time"
+
+// Watchdog notices long quiet stretches in a run, typically a branch being polled
+// with logs silenced, and reports them so a slow run is not mistaken for a dead one.
+// Any event or printed log line resets the quiet timer.
+type Watchdog struct {
+	streamer *JSONStreamer
+	interval time.Duration
+	// lastLog reports when a log line was last printed (logx.LastOutput).
+	lastLog func() time.Time
+	// warn reports stalls when streaming is disabled, usually as a log line.
+	warn func(idle time.Duration)
+	// ticks drives the stall checks, each value being the check's current time;
+	// StartWatchdog feeds it from a ticker, tests feed it directly.
+	ticks <-chan time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartWatchdog begins watching for quiet periods of at least interval. It returns
+// nil when interval is not positive; Stop is safe to call on a nil Watchdog.
+func StartWatchdog(s *JSONStreamer, interval time.Duration, lastLog func() time.Time, warn func(idle time.Duration)) *Watchdog {
+	if interval <= 0 {
+		return nil
+	}
+	tick := interval / 4
+	if tick <= 0 {
+		tick = interval
+	}
+	ticker := time.NewTicker(tick)
+	w := newWatchdog(s, interval, lastLog, warn, ticker.C)
+	go func() {
+		defer ticker.Stop()
+		w.loop(time.Now())
+	}()
+	return w
+}
+
+// newWatchdog returns a watchdog that checks for a stall on every value from
+// ticks once its loop runs.
+func newWatchdog(s *JSONStreamer, interval time.Duration, lastLog func() time.Time, warn func(idle time.Duration), ticks <-chan time.Time) *Watchdog {
+	return &Watchdog{
+		streamer: s,
+		interval: interval,
+		lastLog:  lastLog,
+		warn:     warn,
+		ticks:    ticks,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Stop ends the watchdog and waits for its goroutine to exit.
+func (w *Watchdog) Stop() {
+	if w == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+}
+
+func (w *Watchdog) loop(started time.Time) {
+	defer close(w.done)
+	lastWarn := started
+	for {
+		select {
+		case <-w.stop:
+			return
+		case now := <-w.ticks:
+			last := lastWarn
+			if t := w.streamer.LastEmit(); t.After(last) {
+				last = t
+			}
+			if w.lastLog != nil {
+				if t := w.lastLog(); t.After(last) {
+					last = t
+				}
+			}
+			idle := now.Sub(last)
+			if idle < w.interval {
+				continue
+			}
+			lastWarn = now
+			if w.streamer.Enabled() {
+				w.streamer.EmitStallWarning(idle)
+			} else if w.warn != nil {
+				w.warn(idle)
+			}
+		}
+	}
+}
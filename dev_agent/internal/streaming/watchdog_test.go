@@ -0,0 +1,103 @@
+package streaming
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func stallWarnings(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var ev map[string]any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if ev["type"] == "thread.stall_warning" {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// runWatchdog runs a watchdog started at start through one check per tick and
+// stops it once every check is done.
+func runWatchdog(s *JSONStreamer, interval time.Duration, lastLog func() time.Time, warn func(time.Duration), start time.Time, ticks ...time.Time) {
+	ch := make(chan time.Time)
+	w := newWatchdog(s, interval, lastLog, warn, ch)
+	go w.loop(start)
+	for _, tick := range ticks {
+		ch <- tick
+	}
+	w.Stop()
+}
+
+func TestWatchdogEmitsStallWarningWithLastItem(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONStreamer(true, &buf)
+	s.EmitItemStarted("item_1", "tool_call", "execute_agent", nil)
+	last := s.LastEmit()
+
+	runWatchdog(s, time.Minute, nil, nil, last, last.Add(30*time.Second), last.Add(90*time.Second))
+
+	warnings := stallWarnings(t, buf.String())
+	if len(warnings) != 1 {
+		t.Fatalf("expected one stall warning, got output %q", buf.String())
+	}
+	first := warnings[0]
+	if first["last_item"] != "execute_agent" || first["last_item_running"] != true {
+		t.Fatalf("stall warning should describe the running item: %#v", first)
+	}
+	if idle, _ := first["idle_ms"].(float64); idle != 90000 {
+		t.Fatalf("idle_ms=%v, want 90000", first["idle_ms"])
+	}
+}
+
+func TestWatchdogStaysQuietWhileEventsFlow(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONStreamer(true, &buf)
+	start := time.Now()
+
+	ch := make(chan time.Time)
+	w := newWatchdog(s, time.Minute, nil, nil, ch)
+	go w.loop(start)
+	for i := 0; i < 5; i++ {
+		s.EmitTurnStarted("turn", i, 0, 0)
+		ch <- s.LastEmit().Add(59 * time.Second)
+	}
+	w.Stop()
+
+	if warnings := stallWarnings(t, buf.String()); len(warnings) != 0 {
+		t.Fatalf("expected no stall warnings while events flow, got %d", len(warnings))
+	}
+}
+
+func TestWatchdogWarnsThroughCallbackWithoutStreaming(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	lastLog := func() time.Time { return start.Add(2 * time.Minute) }
+	var warned []time.Duration
+	warn := func(idle time.Duration) { warned = append(warned, idle) }
+
+	// A log line at +2m resets the quiet timer; the warning at +4m restarts it.
+	runWatchdog(NewJSONStreamer(false, nil), time.Minute, lastLog, warn, start,
+		start.Add(150*time.Second), start.Add(4*time.Minute), start.Add(270*time.Second), start.Add(5*time.Minute))
+
+	want := []time.Duration{2 * time.Minute, time.Minute}
+	if len(warned) != len(want) || warned[0] != want[0] || warned[1] != want[1] {
+		t.Fatalf("warnings = %v, want %v", warned, want)
+	}
+}
+
+func TestStartWatchdogDisabledForNonPositiveInterval(t *testing.T) {
+	if w := StartWatchdog(NewJSONStreamer(true, &bytes.Buffer{}), 0, nil, nil); w != nil {
+		t.Fatalf("expected nil watchdog for zero interval")
+	}
+	var w *Watchdog
+	w.Stop()
+}
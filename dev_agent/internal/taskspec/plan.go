@@ -0,0 +1,70 @@
+package taskspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// planFile mirrors the document `plan-agent --emit-dev-tasks` writes.
+type planFile struct {
+	Query  string     `json:"query"`
+	PlanID int        `json:"plan_id"`
+	Tasks  []planTask `json:"tasks"`
+}
+
+type planTask struct {
+	StepID             int      `json:"step_id"`
+	Title              string   `json:"title"`
+	Detail             string   `json:"detail"`
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+	References         []string `json:"references,omitempty"`
+}
+
+// LoadPlanStep reads a plan-agent dev-task file and returns step as a Spec. The
+// overall query is kept as context, and a constraint keeps the run to this one
+// step since the other steps are handed out separately.
+func LoadPlanStep(path string, step int) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("read plan: %w", err)
+	}
+	var plan planFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&plan); err != nil {
+		return Spec{}, fmt.Errorf("parse plan %s: %w", path, err)
+	}
+	var ids []string
+	for _, task := range plan.Tasks {
+		if task.StepID != step {
+			ids = append(ids, fmt.Sprint(task.StepID))
+			continue
+		}
+		var desc strings.Builder
+		desc.WriteString(strings.TrimSpace(task.Title))
+		if detail := strings.TrimSpace(task.Detail); detail != "" && detail != strings.TrimSpace(task.Title) {
+			desc.WriteString("\n\n")
+			desc.WriteString(detail)
+		}
+		if query := strings.TrimSpace(plan.Query); query != "" {
+			fmt.Fprintf(&desc, "\n\nThis is step %d of plan %d for: %s", step, plan.PlanID, query)
+		}
+		spec := Spec{
+			Description:        strings.TrimSpace(desc.String()),
+			AcceptanceCriteria: nonEmpty(task.AcceptanceCriteria),
+			Constraints:        []string{fmt.Sprintf("Implement only plan step %d; the other steps are run separately.", step)},
+			References:         nonEmpty(task.References),
+		}
+		if spec.Description == "" {
+			return Spec{}, fmt.Errorf("plan %s: step %d has no title or detail", path, step)
+		}
+		return spec, nil
+	}
+	if len(ids) == 0 {
+		return Spec{}, fmt.Errorf("plan %s has no tasks", path)
+	}
+	return Spec{}, fmt.Errorf("plan %s has no step %d (steps: %s)", path, step, strings.Join(ids, ", "))
+}
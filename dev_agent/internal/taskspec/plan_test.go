@@ -0,0 +1,46 @@
+package taskspec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadPlanStep(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	src := `{
+  "query": "Add authentication",
+  "plan_id": 2,
+  "tasks": [
+    {"step_id": 1, "title": "Implement user model", "detail": "Implement and test the user model", "acceptance_criteria": ["User model with tests"]},
+    {"step_id": 2, "title": "Implement login API", "detail": "Implement login API", "references": ["plan step 1: Implement user model"]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := LoadPlanStep(path, 2)
+	if err != nil {
+		t.Fatalf("LoadPlanStep error: %v", err)
+	}
+	want := Spec{
+		Description: "Implement login API\n\nThis is step 2 of plan 2 for: Add authentication",
+		Constraints: []string{"Implement only plan step 2; the other steps are run separately."},
+		References:  []string{"plan step 1: Implement user model"},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+
+	spec, err = LoadPlanStep(path, 1)
+	if err != nil || !strings.HasPrefix(spec.Description, "Implement user model\n\nImplement and test the user model") || len(spec.AcceptanceCriteria) != 1 {
+		t.Fatalf("unexpected step 1 spec %+v, %v", spec, err)
+	}
+
+	if _, err := LoadPlanStep(path, 5); err == nil || !strings.Contains(err.Error(), "steps: 1, 2") {
+		t.Fatalf("expected a missing-step error listing the steps, got %v", err)
+	}
+}
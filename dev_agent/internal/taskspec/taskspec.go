@@ -0,0 +1,59 @@
+// Package taskspec reads a structured dev-agent task: a description plus the
+// acceptance criteria, constraints and references the run is checked against.
+package taskspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dev_agent/internal/miniyaml"
+)
+
+// Spec is the content of a --task-file.
+type Spec struct {
+	Description        string   `json:"description"`
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+	Constraints        []string `json:"constraints,omitempty"`
+	References         []string `json:"references,omitempty"`
+}
+
+// Load reads a spec from a .json file, or otherwise from YAML. A description is
+// required; blank list entries are dropped.
+func Load(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, fmt.Errorf("read task file: %w", err)
+	}
+	var spec Spec
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&spec); err != nil {
+			return Spec{}, fmt.Errorf("parse task file %s: %w", path, err)
+		}
+	} else if err := miniyaml.Unmarshal(data, &spec); err != nil {
+		return Spec{}, fmt.Errorf("parse task file %s: %w", path, err)
+	}
+	spec.Description = strings.TrimSpace(spec.Description)
+	spec.AcceptanceCriteria = nonEmpty(spec.AcceptanceCriteria)
+	spec.Constraints = nonEmpty(spec.Constraints)
+	spec.References = nonEmpty(spec.References)
+	if spec.Description == "" {
+		return Spec{}, fmt.Errorf("task file %s has no description", path)
+	}
+	return spec, nil
+}
+
+func nonEmpty(items []string) []string {
+	var out []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
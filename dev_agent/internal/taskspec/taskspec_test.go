@@ -0,0 +1,68 @@
+package taskspec
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.yaml")
+	src := `# feature request
+description: >
+  Add rate limiting
+  to the public API.
+acceptance_criteria:
+  - "Requests over 100/min get HTTP 429"
+  - Limits are per API key
+  -
+constraints: []
+references:
+- docs/api.md
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error: %v", err)
+	}
+	want := Spec{
+		Description:        "Add rate limiting to the public API.",
+		AcceptanceCriteria: []string{"Requests over 100/min get HTTP 429", "Limits are per API key"},
+		References:         []string{"docs/api.md"},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+}
+
+func TestLoadJSONAndRejections(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"ok.json":      `{"description": "Fix the login redirect", "constraints": ["no new dependencies"]}`,
+		"unknown.json": `{"description": "x", "owner": "me"}`,
+		"empty.yaml":   "acceptance_criteria:\n  - something\n",
+		"badkey.yaml":  "description: x\npriority: high\n",
+		"scalar.yaml":  "description: x\nconstraints: none\n",
+		"orphan.yaml":  "- stray item\ndescription: x\n",
+	}
+	for name, content := range cases {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	spec, err := Load(filepath.Join(dir, "ok.json"))
+	if err != nil || spec.Description != "Fix the login redirect" || len(spec.Constraints) != 1 {
+		t.Fatalf("unexpected result %+v, %v", spec, err)
+	}
+	for name := range cases {
+		if name == "ok.json" {
+			continue
+		}
+		if _, err := Load(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAcceptsShippedTemplates(t *testing.T) {
+	if err := Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+}
+
+func TestValidateRejectsMalformedTemplates(t *testing.T) {
+	cases := map[string]template{
+		"missing arg":  {name: "missing", text: "dir %[1]s, log %[2]s", args: 1},
+		"wrong verb":   {name: "verb", text: "count %[1]d", args: 1},
+		"unused arg":   {name: "unused", text: "dir %[1]s", args: 2},
+		"bare percent": {name: "percent", text: "100% done in %[1]s", args: 1},
+	}
+	for label, tmpl := range cases {
+		err := validate([]template{tmpl})
+		if err == nil || !strings.Contains(err.Error(), tmpl.name) {
+			t.Errorf("%s: expected an error naming %s, got %v", label, tmpl.name, err)
+		}
+	}
+}
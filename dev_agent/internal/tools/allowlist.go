@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"dev_agent/internal/logx"
+)
+
+// SetAllowedArtifacts limits read_artifact to paths matching one of globs
+// (filepath.Match syntax). A glob without a slash matches the file's base name,
+// e.g. "*.md"; one with a slash matches the whole path, relative to the
+// workspace directory when the path lies under it, e.g. "docs/*.md". No globs
+// allows every path.
+func (h *ToolHandler) SetAllowedArtifacts(globs []string) error {
+	var cleaned []string
+	for _, glob := range globs {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+		if _, err := path.Match(glob, ""); err != nil {
+			return fmt.Errorf("invalid artifact glob %q: %w", glob, err)
+		}
+		cleaned = append(cleaned, strings.TrimPrefix(glob, "./"))
+	}
+	h.allowedArtifacts = cleaned
+	return nil
+}
+
+// workspaceRelative returns p relative to the workspace directory when it lies
+// under it, so "docs/*" matches both "docs/a.md" and "<workspace>/docs/a.md".
+// Other paths come back cleaned but otherwise unchanged.
+func (h *ToolHandler) workspaceRelative(p string) string {
+	p = filepath.Clean(p)
+	if h.workspaceDir == "" || !filepath.IsAbs(p) {
+		return p
+	}
+	rel, err := filepath.Rel(filepath.Clean(h.workspaceDir), p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return rel
+}
+
+// checkArtifactAllowed rejects target (shown to the model as requested) when an
+// allowlist is set and no glob matches it.
+func (h *ToolHandler) checkArtifactAllowed(tool, requested, target string) error {
+	if len(h.allowedArtifacts) == 0 {
+		return nil
+	}
+	target = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(target)), "./")
+	base := path.Base(target)
+	for _, glob := range h.allowedArtifacts {
+		candidate := base
+		if strings.Contains(glob, "/") {
+			candidate = target
+		}
+		if ok, _ := path.Match(glob, candidate); ok {
+			return nil
+		}
+	}
+	logx.Warningf("%s denied %q: no --allowed-artifacts glob matches", tool, requested)
+	return ToolExecutionError{Msg: fmt.Sprintf("access to %q is not permitted: it matches none of the allowed artifact globs (%s)", requested, strings.Join(h.allowedArtifacts, ", "))}
+}
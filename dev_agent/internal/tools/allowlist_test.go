@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type recordingClient struct {
+	agentClient
+	reads []string
+}
+
+func (c *recordingClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	c.reads = append(c.reads, filePath)
+	return map[string]any{"content": "ok"}, nil
+}
+
+func TestAllowedArtifactsGateReadArtifact(t *testing.T) {
+	dir := "/home/pan/workspace"
+	client := &recordingClient{}
+	h := NewToolHandler(client, "proj", "start", dir, nil)
+	if err := h.SetAllowedArtifacts([]string{"*.log", " docs/*", ""}); err != nil {
+		t.Fatalf("SetAllowedArtifacts error: %v", err)
+	}
+
+	for path, allowed := range map[string]bool{
+		filepath.Join(dir, "code_review.log"): true,
+		filepath.Join(dir, "docs/design.md"):  true,
+		"docs/design.md":                      true,
+		filepath.Join(dir, ".env"):            false,
+		filepath.Join(dir, "docs/../.env"):    false,
+		"/elsewhere/docs/design.md":           false,
+	} {
+		_, err := h.readArtifact(map[string]any{"branch_id": "b", "path": path})
+		if denied := err != nil; denied == allowed {
+			t.Fatalf("read_artifact %q: allowed=%v, got err=%v", path, allowed, err)
+		}
+	}
+	if len(client.reads) != 3 {
+		t.Fatalf("expected only the allowed artifacts to reach the client, got %v", client.reads)
+	}
+
+	if err := h.SetAllowedArtifacts([]string{"[oops"}); err == nil {
+		t.Fatal("expected a malformed glob to be rejected")
+	}
+}
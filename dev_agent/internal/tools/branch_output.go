@@ -0,0 +1,409 @@
+package tools
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// branchOutputStreamer is implemented by clients that can hand back branch output
+// incrementally; the handler falls back to BranchOutput for clients that cannot.
+type branchOutputStreamer interface {
+	BranchOutputStream(branchID string) (io.ReadCloser, error)
+}
+
+var _ branchOutputStreamer = (*MCPClient)(nil)
+
+// BranchOutputStream issues branch_output with full_output and returns a reader
+// over the decoded "output" text. The response is scanned as it arrives, so other
+// fields (including the text copy in "content") are skipped without being buffered.
+// Opening the stream is retried like any other call; a failure while reading it
+// is left to the caller.
+func (c *MCPClient) BranchOutputStream(branchID string) (io.ReadCloser, error) {
+	const method = "tools/call"
+	payload := c.rpcPayload(method, map[string]any{
+		"name":      "branch_output",
+		"arguments": map[string]any{"branch_id": branchID, "full_output": true},
+	})
+	var stream io.ReadCloser
+	err := c.withRetries(method, c.maxRetries, func() error {
+		resp, cancel, err := c.rpcPost(c.rpcURL, payload, c.timeout)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+			resp.Body.Close()
+			cancel()
+			return fmt.Errorf("MCP HTTP %d: %s", resp.StatusCode, string(body))
+		}
+		var body io.Reader = resp.Body
+		if strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+			body = newSSEDataReader(resp.Body)
+		}
+		stream = &streamCloser{
+			Reader: newJSONStringFieldReader(body, "output"),
+			close: func() error {
+				defer cancel()
+				return resp.Body.Close()
+			},
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+type streamCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (s *streamCloser) Close() error { return s.close() }
+
+// sseDataReader yields the payloads of an SSE stream's data: lines without
+// holding a whole line in memory.
+type sseDataReader struct {
+	src    *bufio.Reader
+	inData bool
+}
+
+func newSSEDataReader(r io.Reader) *sseDataReader {
+	return &sseDataReader{src: bufio.NewReaderSize(r, 64*1024)}
+}
+
+func (s *sseDataReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if !s.inData {
+			if n > 0 {
+				return n, nil
+			}
+			if err := s.nextDataLine(); err != nil {
+				return 0, err
+			}
+		}
+		c, err := s.src.ReadByte()
+		if err != nil {
+			return n, err
+		}
+		switch c {
+		case '\n':
+			s.inData = false
+		case '\r':
+		default:
+			p[n] = c
+			n++
+		}
+	}
+	return n, nil
+}
+
+// nextDataLine drops lines until a data: line and consumes its field prefix.
+func (s *sseDataReader) nextDataLine() error {
+	for {
+		if prefix, _ := s.src.Peek(5); strings.EqualFold(string(prefix), "data:") {
+			s.src.Discard(5)
+			if b, err := s.src.Peek(1); err == nil && b[0] == ' ' {
+				s.src.Discard(1)
+			}
+			s.inData = true
+			return nil
+		}
+		for {
+			c, err := s.src.ReadByte()
+			if err != nil {
+				return err
+			}
+			if c == '\n' {
+				break
+			}
+		}
+	}
+}
+
+// jsonStringFieldReader scans a JSON document for the first string value stored
+// under key (at any depth) and streams its decoded contents. Only object keys are
+// buffered, and only up to maxFieldKeyLen bytes.
+type jsonStringFieldReader struct {
+	src     *bufio.Reader
+	key     string
+	found   bool
+	done    bool
+	pending []byte // decoded bytes of an escape that did not fit the last Read
+	err     error
+}
+
+const maxFieldKeyLen = 64
+
+var errFieldNotFound = errors.New("field not found in response")
+
+func newJSONStringFieldReader(r io.Reader, key string) *jsonStringFieldReader {
+	return &jsonStringFieldReader{src: bufio.NewReaderSize(r, 64*1024), key: key}
+}
+
+func (j *jsonStringFieldReader) Read(p []byte) (int, error) {
+	n := copy(p, j.pending)
+	j.pending = j.pending[n:]
+	if j.err != nil {
+		return n, j.err
+	}
+	if j.done {
+		if n == 0 && len(j.pending) == 0 {
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+	if !j.found {
+		if err := j.seek(); err != nil {
+			if err == io.EOF {
+				err = errFieldNotFound
+			}
+			j.err = err
+			return n, err
+		}
+		j.found = true
+	}
+	for n < len(p) {
+		if n > 0 && j.src.Buffered() == 0 {
+			break
+		}
+		c, err := j.src.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			j.err = err
+			return n, err
+		}
+		switch c {
+		case '"':
+			j.done = true
+			if n == 0 {
+				return 0, io.EOF
+			}
+			return n, nil
+		case '\\':
+			decoded, err := j.unescape()
+			if err != nil {
+				j.err = err
+				return n, err
+			}
+			m := copy(p[n:], decoded)
+			n += m
+			if m < len(decoded) {
+				j.pending = []byte(decoded[m:])
+				return n, nil
+			}
+		default:
+			p[n] = c
+			n++
+		}
+	}
+	return n, nil
+}
+
+// unescape decodes the escape sequence after a backslash.
+func (j *jsonStringFieldReader) unescape() (string, error) {
+	c, err := j.src.ReadByte()
+	if err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	switch c {
+	case '"', '\\', '/':
+		return string(c), nil
+	case 'b':
+		return "\b", nil
+	case 'f':
+		return "\f", nil
+	case 'n':
+		return "\n", nil
+	case 'r':
+		return "\r", nil
+	case 't':
+		return "\t", nil
+	case 'u':
+		r, err := j.hex4()
+		if err != nil {
+			return "", err
+		}
+		if r >= 0xD800 && r < 0xDC00 {
+			// High surrogate: pair it with the following \uXXXX when present.
+			if next, _ := j.src.Peek(2); len(next) == 2 && next[0] == '\\' && next[1] == 'u' {
+				j.src.Discard(2)
+				low, err := j.hex4()
+				if err != nil {
+					return "", err
+				}
+				return string(rune((r-0xD800)<<10 + (low - 0xDC00) + 0x10000)), nil
+			}
+			return string(utf8.RuneError), nil
+		}
+		return string(rune(r)), nil
+	}
+	return "", fmt.Errorf("invalid escape \\%c in output", c)
+}
+
+func (j *jsonStringFieldReader) hex4() (int32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(j.src, buf[:]); err != nil {
+		return 0, io.ErrUnexpectedEOF
+	}
+	v, err := strconv.ParseUint(string(buf[:]), 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid \\u escape in output: %v", err)
+	}
+	return int32(v), nil
+}
+
+// seek advances past the opening quote of the wanted field's string value.
+func (j *jsonStringFieldReader) seek() error {
+	var (
+		stack     []byte // '{' or '['
+		expectKey bool
+	)
+	for {
+		c, err := j.src.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '{':
+			stack = append(stack, c)
+			expectKey = true
+		case '[':
+			stack = append(stack, c)
+			expectKey = false
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			expectKey = false
+		case ',':
+			expectKey = len(stack) > 0 && stack[len(stack)-1] == '{'
+		case '"':
+			if !expectKey {
+				if err := j.skipString(); err != nil {
+					return err
+				}
+				continue
+			}
+			key, err := j.readKey()
+			if err != nil {
+				return err
+			}
+			expectKey = false
+			if key != j.key {
+				continue
+			}
+			if err := j.skipToValue(); err != nil {
+				return err
+			}
+			b, err := j.src.Peek(1)
+			if err != nil {
+				return err
+			}
+			if b[0] == '"' {
+				j.src.Discard(1)
+				return nil
+			}
+		}
+	}
+}
+
+// readKey reads an object key, keeping at most maxFieldKeyLen bytes of it.
+func (j *jsonStringFieldReader) readKey() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := j.src.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if _, err := j.src.ReadByte(); err != nil {
+				return "", err
+			}
+			sb.WriteByte('\\')
+		default:
+			if sb.Len() < maxFieldKeyLen {
+				sb.WriteByte(c)
+			}
+		}
+	}
+}
+
+func (j *jsonStringFieldReader) skipString() error {
+	for {
+		c, err := j.src.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '"':
+			return nil
+		case '\\':
+			if _, err := j.src.ReadByte(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skipToValue consumes the colon and whitespace between a key and its value.
+func (j *jsonStringFieldReader) skipToValue() error {
+	for {
+		b, err := j.src.Peek(1)
+		if err != nil {
+			return err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n', ':':
+			j.src.Discard(1)
+		default:
+			return nil
+		}
+	}
+}
+
+// readTail reads r to the end, keeping only the last limit bytes. The tail starts
+// on a rune boundary; truncated reports whether anything was dropped.
+func readTail(r io.Reader, limit int) (string, bool, error) {
+	if limit <= 0 {
+		data, err := io.ReadAll(r)
+		return string(data), false, err
+	}
+	ring := make([]byte, 0, limit)
+	buf := make([]byte, 32*1024)
+	truncated := false
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			ring = append(ring, buf[:n]...)
+			if len(ring) > limit {
+				truncated = true
+				ring = append(ring[:0], ring[len(ring)-limit:]...)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", truncated, err
+		}
+	}
+	if truncated {
+		for len(ring) > 0 && !utf8.RuneStart(ring[0]) {
+			ring = ring[1:]
+		}
+	}
+	return string(ring), truncated, nil
+}
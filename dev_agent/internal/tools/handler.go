@@ -1,7 +1,9 @@
 package tools
 
 import (
+	"context"
 	"dev_agent/internal/logx"
+	"dev_agent/internal/streaming"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -13,10 +15,20 @@ type ToolExecutionError struct {
 	Msg         string
 	Instruction string
 	Details     map[string]any
+	// Kind classifies the failure (see ErrorKindMCP); empty when unclassified.
+	Kind string
 }
 
 func (e ToolExecutionError) Error() string { return e.Msg }
 
+// Error kinds for failures raised by the handler itself rather than the MCP server.
+const (
+	ErrorKindTimeout     = "timeout"
+	ErrorKindEmptyOutput = "empty_output"
+	ErrorKindInvalidArgs = "invalid_args"
+	ErrorKindBatchedCall = "batched_call"
+)
+
 type agentClient interface {
 	ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error)
 	GetBranch(branchID string) (map[string]any, error)
@@ -28,7 +40,6 @@ var _ agentClient = (*MCPClient)(nil)
 
 const (
 	reviewCodeAgent            = "review_code"
-	reviewArtifactName         = "code_review.log"
 	reviewMaxAttempts          = 3
 	instructionFinishedWithErr = "FINISHED_WITH_ERROR"
 	defaultPollTimeout         = 60 * time.Minute
@@ -38,12 +49,17 @@ const (
 )
 
 type BranchTracker struct {
-	start  string
-	latest string
+	start   string
+	latest  string
+	history []string
 }
 
 func NewBranchTracker(start string) *BranchTracker {
-	return &BranchTracker{start: start, latest: start}
+	t := &BranchTracker{start: start, latest: start}
+	if start != "" {
+		t.history = []string{start}
+	}
+	return t
 }
 
 func (t *BranchTracker) Record(id string) {
@@ -54,23 +70,55 @@ func (t *BranchTracker) Record(id string) {
 		t.start = id
 	}
 	t.latest = id
+	for _, seen := range t.history {
+		if seen == id {
+			return
+		}
+	}
+	t.history = append(t.history, id)
 }
 
 func (t *BranchTracker) Range() map[string]string {
 	return map[string]string{"start_branch_id": t.start, "latest_branch_id": t.latest}
 }
 
+// Lineage returns the recorded branch ids in first-seen order, without duplicates.
+func (t *BranchTracker) Lineage() []string {
+	out := make([]string, len(t.history))
+	copy(out, t.history)
+	return out
+}
+
+// DefaultReviewArtifactName is the review log review_code must write when no
+// REVIEW_ARTIFACT_NAME is configured.
+const DefaultReviewArtifactName = "code_review.log"
+
 type ToolHandler struct {
-	client        agentClient
-	defaultProj   string
-	branchTracker *BranchTracker
-	workspaceDir  string
-	pollTimeout   time.Duration
-	pollInitial   time.Duration
-	pollMax       time.Duration
-	pollBackoff   float64
-	nowFunc       func() time.Time
-	sleepFunc     func(time.Duration)
+	client         agentClient
+	defaultProj    string
+	branchTracker  *BranchTracker
+	workspaceDir   string
+	reviewArtifact string
+	pollTimeout    time.Duration
+	pollInitial    time.Duration
+	pollMax        time.Duration
+	pollBackoff    float64
+	nowFunc        func() time.Time
+	sleepFunc      func(time.Duration)
+	transformers   []ResponseTransformer
+
+	// outputTailBytes keeps only the end of large branch outputs; zero reads them whole.
+	outputTailBytes int
+
+	// streamer receives poll.tick events while checkStatus waits; nil disables them.
+	streamer *streaming.JSONStreamer
+	// scopeDir is the repository subdirectory agents are restricted to; empty means the whole repo.
+	scopeDir string
+	// summarizer backs summarize_branch; summaries caches its answers by branch id.
+	summarizer BranchSummarizer
+	summaries  map[string]string
+	// allowedArtifacts are the globs read_artifact paths must match; empty allows all.
+	allowedArtifacts []string
 }
 
 // ToolHandlerTiming configures the default polling behavior for branch status checks.
@@ -114,8 +162,100 @@ func NewToolHandler(client agentClient, defaultProject string, startBranch strin
 	return handler
 }
 
+// SetReviewArtifactName overrides the review log file name (default
+// DefaultReviewArtifactName); an empty name restores the default.
+func (h *ToolHandler) SetReviewArtifactName(name string) {
+	h.reviewArtifact = strings.TrimSpace(name)
+}
+
+// SetOutputTailBytes caps agent responses to the last n bytes of the branch output,
+// read as a stream so huge logs are never held in memory; zero reads outputs whole.
+func (h *ToolHandler) SetOutputTailBytes(n int) {
+	if n < 0 {
+		n = 0
+	}
+	h.outputTailBytes = n
+}
+
 func (h *ToolHandler) BranchRange() map[string]string { return h.branchTracker.Range() }
 
+// SetStreamer makes checkStatus emit a poll.tick event on each poll of a branch.
+func (h *ToolHandler) SetStreamer(streamer *streaming.JSONStreamer) {
+	h.streamer = streamer
+}
+
+// SetScopeDir restricts every execute_agent prompt to dir, a path relative to
+// the repository root; an empty dir lifts the restriction.
+func (h *ToolHandler) SetScopeDir(dir string) error {
+	scope, err := normalizeScopeDir(dir)
+	if err != nil {
+		return err
+	}
+	h.scopeDir = scope
+	return nil
+}
+
+// normalizeScopeDir cleans a repo-relative directory and rejects paths that
+// would leave the repository.
+func normalizeScopeDir(dir string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(dir) {
+		return "", fmt.Errorf("scope dir %q must be relative to the repository root", dir)
+	}
+	clean := filepath.ToSlash(filepath.Clean(dir))
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("scope dir %q leaves the repository", dir)
+	}
+	if clean == "." {
+		return "", nil
+	}
+	return clean, nil
+}
+
+// scopedPrompt appends the scope restriction to an agent prompt.
+func (h *ToolHandler) scopedPrompt(prompt string) string {
+	if h.scopeDir == "" {
+		return prompt
+	}
+	return strings.TrimRight(prompt, "\n") + "\n\n**SCOPE**\n" +
+		fmt.Sprintf("- Restrict all analysis and changes to %s/ (relative to the repository root).\n", h.scopeDir) +
+		"- Only read code outside it to understand a dependency; never modify files outside it.\n" +
+		fmt.Sprintf("- When inspecting diffs, limit them to the scope (e.g. git diff <base> -- %s).\n", h.scopeDir)
+}
+
+// BranchSummarizer condenses a branch's output into its key findings. ctx is
+// the run's context, so --max-duration bounds the call.
+type BranchSummarizer func(ctx context.Context, output string) (string, error)
+
+// SetBranchSummarizer enables the summarize_branch tool; nil disables it.
+func (h *ToolHandler) SetBranchSummarizer(fn BranchSummarizer) {
+	h.summarizer = fn
+}
+
+// ResponseTransformer rewrites the response text extracted from an agent run, e.g.
+// to strip ANSI codes or redact sandbox paths, before callers see it.
+type ResponseTransformer func(agent, response string) string
+
+// AddResponseTransformer registers fn to post-process agent responses. Transformers
+// run in registration order; register them before the handler is in use.
+func (h *ToolHandler) AddResponseTransformer(fn ResponseTransformer) {
+	if fn != nil {
+		h.transformers = append(h.transformers, fn)
+	}
+}
+
+func (h *ToolHandler) transformResponse(agent, response string) string {
+	for _, fn := range h.transformers {
+		response = fn(agent, response)
+	}
+	return response
+}
+
+func (h *ToolHandler) BranchLineage() []string { return h.branchTracker.Lineage() }
+
 // ToolCall mirrors brain.ToolCall, but we keep it generic here if needed.
 type ToolCall struct {
 	ID       string `json:"id"`
@@ -126,7 +266,9 @@ type ToolCall struct {
 	} `json:"function"`
 }
 
-func (h *ToolHandler) Handle(call ToolCall) map[string]any {
+// Handle runs one tool call. ctx bounds the tools that make LLM calls
+// (summarize_branch); MCP calls keep their own timeouts.
+func (h *ToolHandler) Handle(ctx context.Context, call ToolCall) map[string]any {
 	name := call.Function.Name
 	if name == "" {
 		return h.errorPayload(ToolExecutionError{Msg: "Missing tool name in call."})
@@ -134,7 +276,7 @@ func (h *ToolHandler) Handle(call ToolCall) map[string]any {
 	var args map[string]any
 	if call.Function.Arguments != "" {
 		if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
-			return h.errorPayload(ToolExecutionError{Msg: fmt.Sprintf("Invalid JSON arguments: %v", err)})
+			return h.errorPayload(ToolExecutionError{Msg: fmt.Sprintf("Invalid JSON arguments: %v", err), Kind: ErrorKindInvalidArgs})
 		}
 	} else {
 		args = map[string]any{}
@@ -151,6 +293,10 @@ func (h *ToolHandler) Handle(call ToolCall) map[string]any {
 		res, err = h.readArtifact(args)
 	case "branch_output":
 		res, err = h.branchOutput(args)
+	case "get_lineage":
+		res, err = h.getLineage()
+	case "summarize_branch":
+		res, err = h.summarizeBranch(ctx, args)
 	default:
 		err = ToolExecutionError{Msg: fmt.Sprintf("Unsupported tool: %s", name)}
 	}
@@ -173,6 +319,7 @@ func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, er
 		return nil, ToolExecutionError{Msg: "missing required arguments"}
 	}
 
+	prompt = h.scopedPrompt(prompt)
 	if agent == reviewCodeAgent {
 		return h.executeReviewAgent(project, parent, prompt)
 	}
@@ -180,20 +327,35 @@ func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, er
 	return result, err
 }
 
+// startAgent issues parallel_explore, re-issuing it while the server reports a
+// retryable error. It also returns how many times the call was re-issued.
+func (h *ToolHandler) startAgent(agent, project, parent, prompt string) (map[string]any, int, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
+		if err != nil {
+			return nil, attempt - 1, ToolExecutionError{
+				Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
+				Instruction: instructionFinishedWithErr,
+			}
+		}
+		if isErr, ok := resp["isError"].(bool); !ok || !isErr {
+			return resp, attempt - 1, nil
+		}
+		te := newMCPToolError(resp)
+		if te.Kind != ErrorKindMCPRetryable || attempt >= mcpRetryAttempts {
+			return nil, attempt - 1, te
+		}
+		wait := mcpRetryWait(attempt)
+		logx.Warningf("ParallelExplore reported a retryable error (attempt %d/%d): %s. Retrying in %ds...", attempt, mcpRetryAttempts, te.Msg, int(wait.Seconds()))
+		h.sleepFunc(wait)
+	}
+}
+
 func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
 	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
-	resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
+	resp, retries, err := h.startAgent(agent, project, parent, prompt)
 	if err != nil {
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
-			Instruction: instructionFinishedWithErr,
-		}
-	}
-	if isErr, ok := resp["isError"].(bool); ok && isErr {
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore returned error: %v", resp["error"]),
-			Instruction: instructionFinishedWithErr,
-		}
+		return nil, "", err
 	}
 	branchID := ExtractBranchID(resp)
 	if branchID == "" {
@@ -205,6 +367,9 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	// Don't record branch ID yet - wait until checkStatus succeeds
 
 	result := map[string]any{"parallel_explore": resp, "branch_id": branchID}
+	if retries > 0 {
+		result["retries"] = retries
+	}
 
 	logx.Infof("Waiting for branch %s to complete.", branchID)
 	statusResp, err := h.checkStatus(map[string]any{"branch_id": branchID})
@@ -242,27 +407,59 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 		}
 	}
 
-	branchOutputResponse, err := h.client.BranchOutput(branchID, true)
+	branchOutput, err := h.fetchBranchOutput(branchID)
 	if err != nil {
 		return nil, "", err
-	} else {
-		branchOutput := branchOutputString(branchOutputResponse)
-		if branchOutput != "" {
-			responseText = branchOutput
-		}
+	}
+	if branchOutput != "" {
+		responseText = branchOutput
 	}
 	if strings.TrimSpace(responseText) == "" {
-		return nil, "", ToolExecutionError{Msg: "branch_output returned no textual output"}
+		return nil, "", ToolExecutionError{Msg: "branch_output returned no textual output", Kind: ErrorKindEmptyOutput}
 	}
-	result["response"] = strings.TrimSpace(responseText)
+	result["response"] = h.transformResponse(agent, strings.TrimSpace(responseText))
 
 	return result, branchID, nil
 }
 
+// outputTruncatedMarker prefixes a branch output cut down to its tail.
+const outputTruncatedMarker = "[... earlier output omitted; showing the last %d KB ...]\n"
+
+// fetchBranchOutput returns the branch's full output, or only its last
+// outputTailBytes when a tail is configured and the client can stream.
+func (h *ToolHandler) fetchBranchOutput(branchID string) (string, error) {
+	if streamer, ok := h.client.(branchOutputStreamer); ok && h.outputTailBytes > 0 {
+		tail, truncated, err := streamBranchTail(streamer, branchID, h.outputTailBytes)
+		if err == nil {
+			tail = strings.TrimSpace(tail)
+			if truncated {
+				logx.Infof("Branch %s output exceeds %d bytes; keeping the tail only.", branchID, h.outputTailBytes)
+				tail = fmt.Sprintf(outputTruncatedMarker, h.outputTailBytes/1024) + tail
+			}
+			return tail, nil
+		}
+		logx.Warningf("Streaming branch_output for %s failed; reading it whole. err=%v", branchID, err)
+	}
+	resp, err := h.client.BranchOutput(branchID, true)
+	if err != nil {
+		return "", err
+	}
+	return branchOutputString(resp), nil
+}
+
+func streamBranchTail(streamer branchOutputStreamer, branchID string, limit int) (string, bool, error) {
+	stream, err := streamer.BranchOutputStream(branchID)
+	if err != nil {
+		return "", false, err
+	}
+	defer stream.Close()
+	return readTail(stream, limit)
+}
+
 func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[string]any, error) {
 	artifactPath := h.reviewLogPath()
 	if artifactPath == "" {
-		return nil, ToolExecutionError{Msg: "workspace directory not configured for review_code validation"}
+		return nil, ToolExecutionError{Msg: fmt.Sprintf("workspace dir is not configured, so review_code's %s cannot be checked: set WORKSPACE_DIR to the agent's workspace path", h.ReviewArtifactName())}
 	}
 	var lastBranch string
 	for attempt := 1; attempt <= reviewMaxAttempts; attempt++ {
@@ -271,6 +468,10 @@ func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[st
 			return nil, err
 		}
 		lastBranch = branchID
+		if attempt > 1 {
+			prior, _ := result["retries"].(int)
+			result["retries"] = prior + attempt - 1
+		}
 		if artifact, err := h.client.BranchReadFile(branchID, artifactPath); err == nil {
 			if content, ok := artifact["content"].(string); ok && strings.TrimSpace(content) != "" {
 				result["review_report"] = content
@@ -303,9 +504,20 @@ func (h *ToolHandler) reviewLogPath() string {
 	if strings.TrimSpace(h.workspaceDir) == "" {
 		return ""
 	}
-	return filepath.Join(h.workspaceDir, reviewArtifactName)
+	return filepath.Join(h.workspaceDir, h.ReviewArtifactName())
 }
 
+// ReviewArtifactName is the file name review_code runs are expected to write.
+func (h *ToolHandler) ReviewArtifactName() string {
+	if h.reviewArtifact != "" {
+		return h.reviewArtifact
+	}
+	return DefaultReviewArtifactName
+}
+
+// checkStatus polls branch_id until it finishes. The first GetBranch is issued
+// without delay, so a branch that is already done costs no sleep; the backoff
+// schedule (initial, *backoff, capped at max) only starts after a miss.
 func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, error) {
 	branchID, _ := arguments["branch_id"].(string)
 	if branchID == "" {
@@ -328,6 +540,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 	sleep := poll
 
 	logx.Infof("Checking status for branch %s (timeout=%ds)", branchID, int(timeout.Seconds()))
+	pollStart := h.now()
 	for attempt := 1; ; attempt++ {
 		resp, err := h.client.GetBranch(branchID)
 		if err != nil {
@@ -354,6 +567,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 		}
 
 		status := stringsLower(resp["status"])
+		h.streamer.EmitPollTick(branchID, attempt, h.now().Sub(pollStart), status)
 		latest_snap_id := stringsLower(resp["latest_snap_id"])
 		hasNewSnapshot := true
 		parent_branch_id := stringsLower(resp["parent_id"])
@@ -402,6 +616,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 			return nil, ToolExecutionError{
 				Msg:         fmt.Sprintf("Timed out waiting for branch %s (last status=%s)", branchID, status),
 				Instruction: instructionFinishedWithErr,
+				Kind:        ErrorKindTimeout,
 			}
 		}
 		logx.Infof("Branch %s still active (status=%s). Sleeping %.1fs.", branchID, status, sleep.Seconds())
@@ -418,6 +633,9 @@ func (h *ToolHandler) readArtifact(arguments map[string]any) (map[string]any, er
 	if branchID == "" || path == "" {
 		return nil, ToolExecutionError{Msg: "`branch_id` and `path` are required"}
 	}
+	if err := h.checkArtifactAllowed("read_artifact", path, h.workspaceRelative(path)); err != nil {
+		return nil, err
+	}
 	logx.Infof("Reading artifact %s from branch %s", path, branchID)
 	return h.client.BranchReadFile(branchID, path)
 }
@@ -440,42 +658,71 @@ func (h *ToolHandler) branchOutput(arguments map[string]any) (map[string]any, er
 	return h.client.BranchOutput(branchID, fullOutput)
 }
 
-func ExtractBranchID(m map[string]any) string {
-	if m == nil {
-		return ""
+// summarizeBranch returns a findings summary of the branch's output instead of
+// the raw text. Branch output does not change once a branch finishes, so a
+// finished branch is summarized at most once; a branch that is still running is
+// summarized afresh on every call.
+func (h *ToolHandler) summarizeBranch(ctx context.Context, arguments map[string]any) (map[string]any, error) {
+	rawBranchID, _ := arguments["branch_id"].(string)
+	branchID := strings.TrimSpace(rawBranchID)
+	if branchID == "" {
+		return nil, ToolExecutionError{Msg: "`branch_id` is required"}
 	}
-
-	if pe, ok := m["parallel_explore"].(map[string]any); ok {
-		if branches, ok := pe["branches"].([]any); ok {
-			for _, item := range branches {
-				if nested, _ := item.(map[string]any); nested != nil {
-					if id := ExtractBranchID(nested); id != "" {
-						return id
-					}
-				}
-			}
-		}
+	if h.summarizer == nil {
+		return nil, ToolExecutionError{Msg: "summarize_branch is not configured; use branch_output instead"}
 	}
-	if branches, ok := m["branches"].([]any); ok {
-		for _, item := range branches {
-			if nested, _ := item.(map[string]any); nested != nil {
-				if id := ExtractBranchID(nested); id != "" {
-					return id
-				}
-			}
-		}
+	if summary, ok := h.summaries[branchID]; ok {
+		logx.Infof("Using cached summary for branch %s", branchID)
+		return map[string]any{"branch_id": branchID, "summary": summary, "cached": true}, nil
 	}
-	if b, ok := m["branch"].(map[string]any); ok {
-		if id := ExtractBranchID(b); id != "" {
-			return id
-		}
+	finished := h.branchFinished(branchID)
+	output, err := h.fetchBranchOutput(branchID)
+	if err != nil {
+		return nil, err
 	}
-	for _, k := range []string{"branch_id", "id"} {
-		if v, ok := m[k].(string); ok && v != "" {
-			return v
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return nil, ToolExecutionError{Msg: "branch_output returned no textual output", Kind: ErrorKindEmptyOutput}
+	}
+	logx.Infof("Summarizing %d chars of output from branch %s", len(output), branchID)
+	summary, err := h.summarizer(ctx, output)
+	if err != nil {
+		return nil, ToolExecutionError{Msg: fmt.Sprintf("summarize branch %s: %v", branchID, err)}
+	}
+	summary = strings.TrimSpace(summary)
+	if finished {
+		if h.summaries == nil {
+			h.summaries = make(map[string]string)
 		}
+		h.summaries[branchID] = summary
 	}
-	return ""
+	return map[string]any{"branch_id": branchID, "summary": summary, "cached": false, "output_chars": len(output)}, nil
+}
+
+// branchFinished reports whether the branch has succeeded or failed, i.e. its
+// output can no longer change. A status lookup failure counts as not finished.
+func (h *ToolHandler) branchFinished(branchID string) bool {
+	resp, err := h.client.GetBranch(branchID)
+	if err != nil {
+		logx.Warningf("GetBranch failed for %s; not caching its summary: %v", branchID, err)
+		return false
+	}
+	status := stringsLower(resp["status"])
+	return status == "succeed" || status == "failed"
+}
+
+func (h *ToolHandler) getLineage() (map[string]any, error) {
+	lineage := h.BranchRange()
+	branches := h.BranchLineage()
+	items := make([]any, 0, len(branches))
+	for _, id := range branches {
+		items = append(items, id)
+	}
+	return map[string]any{
+		"start_branch_id":  lineage["start_branch_id"],
+		"latest_branch_id": lineage["latest_branch_id"],
+		"branches":         items,
+	}, nil
 }
 
 func branchOutputString(payload map[string]any) string {
@@ -503,6 +750,9 @@ func (h *ToolHandler) errorPayload(err error) map[string]any {
 		if len(te.Details) > 0 {
 			payload["details"] = te.Details
 		}
+		if te.Kind != "" {
+			payload["kind"] = te.Kind
+		}
 		if len(payload) == 0 {
 			payload["message"] = "tool execution error"
 		}
@@ -639,6 +889,31 @@ func GetToolDefinitions() []map[string]any {
 				},
 			},
 		},
+		{
+			"type": "function",
+			"function": map[string]any{
+				"name":        "summarize_branch",
+				"description": "Summarize what a branch produced (outcome, key findings, files touched, open problems) without returning its full output. Prefer this over branch_output when the findings are enough.",
+				"parameters": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"branch_id": map[string]any{"type": "string", "description": "Branch whose output to summarize."},
+					},
+					"required": []any{"branch_id"},
+				},
+			},
+		},
+		{
+			"type": "function",
+			"function": map[string]any{
+				"name":        "get_lineage",
+				"description": "Return the start/latest branch ids and the ordered list of branches recorded so far. Use latest_branch_id as the next parent_branch_id.",
+				"parameters": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{},
+				},
+			},
+		},
 	}
 }
 
@@ -1,8 +1,13 @@
 package tools
 
 import (
+	"bytes"
+	"context"
+	"dev_agent/internal/streaming"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -54,6 +59,63 @@ func TestExecuteAgentReviewCodeRetriesMissingLog(t *testing.T) {
 	}
 }
 
+func TestExecuteAgentReviewCodeUsesConfiguredArtifactName(t *testing.T) {
+	client := &fakeMCPClient{
+		readResults: []branchReadResult{{data: map[string]any{"content": "No P0/P1 issues found"}}},
+	}
+	handler := NewToolHandler(client, "proj", "parent", "/workspace", nil)
+	handler.SetReviewArtifactName("review.md")
+
+	_, err := handler.executeAgent(map[string]any{
+		"agent":            "review_code",
+		"prompt":           "review the latest changes",
+		"parent_branch_id": "parent",
+	})
+	if err != nil {
+		t.Fatalf("executeAgent returned error: %v", err)
+	}
+	if len(client.branchReadInputs) != 1 || client.branchReadInputs[0].path != "/workspace/review.md" {
+		t.Fatalf("expected the configured artifact to be read, got %#v", client.branchReadInputs)
+	}
+
+	handler.SetReviewArtifactName("")
+	if got := handler.ReviewArtifactName(); got != DefaultReviewArtifactName {
+		t.Fatalf("empty name should restore the default, got %q", got)
+	}
+}
+
+func TestExecuteAgentAppendsScopeDir(t *testing.T) {
+	client := &fakeMCPClient{
+		readResults: []branchReadResult{{data: map[string]any{"content": "No P0/P1 issues found"}}},
+	}
+	handler := NewToolHandler(client, "proj", "parent", "/workspace", nil)
+	if err := handler.SetScopeDir("./services/api/"); err != nil {
+		t.Fatalf("SetScopeDir: %v", err)
+	}
+
+	_, err := handler.executeAgent(map[string]any{
+		"agent":            "review_code",
+		"prompt":           "review the latest changes",
+		"parent_branch_id": "parent",
+	})
+	if err != nil {
+		t.Fatalf("executeAgent returned error: %v", err)
+	}
+	if len(client.explorePrompts) != 1 {
+		t.Fatalf("expected 1 prompt, got %d", len(client.explorePrompts))
+	}
+	prompt := client.explorePrompts[0]
+	if !strings.HasPrefix(prompt, "review the latest changes") || !strings.Contains(prompt, "Restrict all analysis and changes to services/api/") {
+		t.Fatalf("prompt missing scope restriction:\n%s", prompt)
+	}
+
+	for _, bad := range []string{"../other", "/abs/path"} {
+		if err := handler.SetScopeDir(bad); err == nil {
+			t.Fatalf("expected SetScopeDir(%q) to fail", bad)
+		}
+	}
+}
+
 func TestExecuteAgentReviewCodeFailsAfterMaxAttempts(t *testing.T) {
 	client := &fakeMCPClient{
 		readResults: []branchReadResult{
@@ -105,7 +167,7 @@ func TestHandleBranchOutputRequiresBranchID(t *testing.T) {
 	call.Function.Name = "branch_output"
 	call.Function.Arguments = "{}"
 
-	res := handler.Handle(call)
+	res := handler.Handle(context.Background(), call)
 	if status := res["status"]; status != "error" {
 		t.Fatalf("expected status error, got %#v", status)
 	}
@@ -127,7 +189,7 @@ func TestHandleBranchOutputCallsClient(t *testing.T) {
 	call.Function.Name = "branch_output"
 	call.Function.Arguments = `{"branch_id":"branch-123","full_output":true}`
 
-	res := handler.Handle(call)
+	res := handler.Handle(context.Background(), call)
 	if status := res["status"]; status != "success" {
 		t.Fatalf("expected status success, got %#v", status)
 	}
@@ -155,7 +217,7 @@ func TestHandleBranchOutputDefaultsFullOutputFalse(t *testing.T) {
 	call.Function.Name = "branch_output"
 	call.Function.Arguments = `{"branch_id":"branch-234"}`
 
-	_ = handler.Handle(call)
+	_ = handler.Handle(context.Background(), call)
 	if len(client.branchOutputInputs) != 1 {
 		t.Fatalf("expected 1 branch_output call, got %d", len(client.branchOutputInputs))
 	}
@@ -164,6 +226,115 @@ func TestHandleBranchOutputDefaultsFullOutputFalse(t *testing.T) {
 	}
 }
 
+func TestHandleSummarizeBranchCachesPerBranch(t *testing.T) {
+	client := &fakeMCPClient{
+		branchOutputResult: map[string]any{"output": "ran 40 tests; TestPut fails with nil map write"},
+	}
+	handler := &ToolHandler{
+		client:        client,
+		branchTracker: NewBranchTracker("parent"),
+	}
+	call := ToolCall{}
+	call.Function.Name = "summarize_branch"
+	call.Function.Arguments = `{"branch_id":"branch-7"}`
+
+	if res := handler.Handle(context.Background(), call); res["status"] != "error" {
+		t.Fatalf("expected an error without a summarizer, got %#v", res)
+	}
+
+	var inputs []string
+	handler.SetBranchSummarizer(func(_ context.Context, output string) (string, error) {
+		inputs = append(inputs, output)
+		return "- TestPut fails: nil map write", nil
+	})
+	for i := 0; i < 2; i++ {
+		res := handler.Handle(context.Background(), call)
+		if res["status"] != "success" {
+			t.Fatalf("call %d: expected success, got %#v", i+1, res)
+		}
+		data, _ := res["data"].(map[string]any)
+		if data["summary"] != "- TestPut fails: nil map write" || data["cached"] != (i == 1) {
+			t.Fatalf("call %d: unexpected data %#v", i+1, data)
+		}
+	}
+	if len(inputs) != 1 || inputs[0] != "ran 40 tests; TestPut fails with nil map write" {
+		t.Fatalf("expected one summarization of the branch output, got %q", inputs)
+	}
+	if len(client.branchOutputInputs) != 1 || !client.branchOutputInputs[0].fullOutput {
+		t.Fatalf("expected one full branch_output fetch, got %#v", client.branchOutputInputs)
+	}
+}
+
+func TestHandleSummarizeBranchDoesNotCacheRunningBranch(t *testing.T) {
+	client := &fakeMCPClient{
+		branchOutputResult: map[string]any{"output": "ran 12 of 40 tests"},
+		getBranchResults: []branchStatusResult{
+			{resp: map[string]any{"status": "running"}},
+			{resp: map[string]any{"status": "succeed"}},
+		},
+	}
+	handler := &ToolHandler{
+		client:        client,
+		branchTracker: NewBranchTracker("parent"),
+	}
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "run")
+	summaries := 0
+	handler.SetBranchSummarizer(func(got context.Context, output string) (string, error) {
+		if got.Value(ctxKey{}) != "run" {
+			t.Fatal("expected the summarizer to get the run's context")
+		}
+		summaries++
+		return "- in progress", nil
+	})
+	call := ToolCall{}
+	call.Function.Name = "summarize_branch"
+	call.Function.Arguments = `{"branch_id":"branch-7"}`
+
+	for i, wantCached := range []bool{false, false, true} {
+		res := handler.Handle(ctx, call)
+		data, _ := res["data"].(map[string]any)
+		if res["status"] != "success" || data["cached"] != wantCached {
+			t.Fatalf("call %d: expected cached=%v, got %#v", i+1, wantCached, res)
+		}
+	}
+	if summaries != 2 {
+		t.Fatalf("expected the running branch to be summarized again once it finished, got %d summaries", summaries)
+	}
+}
+
+func TestHandleGetLineageReturnsOrderedBranches(t *testing.T) {
+	tracker := NewBranchTracker("parent")
+	tracker.Record("branch-1")
+	tracker.Record("branch-2")
+	tracker.Record("branch-1")
+	handler := &ToolHandler{
+		client:        &fakeMCPClient{},
+		branchTracker: tracker,
+	}
+	call := ToolCall{}
+	call.Function.Name = "get_lineage"
+
+	res := handler.Handle(context.Background(), call)
+	if status := res["status"]; status != "success" {
+		t.Fatalf("expected status success, got %#v", status)
+	}
+	data, _ := res["data"].(map[string]any)
+	if data["start_branch_id"] != "parent" || data["latest_branch_id"] != "branch-1" {
+		t.Fatalf("unexpected range in %#v", data)
+	}
+	branches, _ := data["branches"].([]any)
+	want := []string{"parent", "branch-1", "branch-2"}
+	if len(branches) != len(want) {
+		t.Fatalf("expected branches %v, got %#v", want, branches)
+	}
+	for i, id := range want {
+		if branches[i] != id {
+			t.Fatalf("branch %d: expected %s, got %#v", i, id, branches[i])
+		}
+	}
+}
+
 func TestReadArtifactHandlesErrorPayload(t *testing.T) {
 	client := &fakeMCPClient{
 		readResults: []branchReadResult{
@@ -178,7 +349,7 @@ func TestReadArtifactHandlesErrorPayload(t *testing.T) {
 	call.Function.Name = "read_artifact"
 	call.Function.Arguments = `{"branch_id":"branch-1","path":"/workspace/missing.log"}`
 
-	res := handler.Handle(call)
+	res := handler.Handle(context.Background(), call)
 	if status := res["status"]; status != "error" {
 		t.Fatalf("expected status error, got %#v", status)
 	}
@@ -228,6 +399,79 @@ func TestCheckStatusUsesConfiguredPollingDefaults(t *testing.T) {
 	}
 }
 
+func TestCheckStatusPollsImmediatelyBeforeBackingOff(t *testing.T) {
+	client := &fakeMCPClient{
+		getBranchResults: []branchStatusResult{
+			{resp: map[string]any{"id": "branch-fast", "status": "succeed"}},
+		},
+	}
+	clock := &fakeClock{}
+	handler := &ToolHandler{
+		client:        client,
+		branchTracker: NewBranchTracker("parent"),
+		pollInitial:   3 * time.Second,
+		pollMax:       30 * time.Second,
+		pollTimeout:   time.Minute,
+		pollBackoff:   1.5,
+		nowFunc:       clock.Now,
+		sleepFunc:     clock.Sleep,
+	}
+
+	if _, err := handler.checkStatus(map[string]any{"branch_id": "branch-fast"}); err != nil {
+		t.Fatalf("checkStatus returned error: %v", err)
+	}
+	if client.getBranchCalls != 1 || len(clock.sleeps) != 0 {
+		t.Fatalf("expected one immediate check and no sleep, got %d calls and sleeps %v", client.getBranchCalls, clock.sleeps)
+	}
+}
+
+func TestCheckStatusEmitsPollTicks(t *testing.T) {
+	client := &fakeMCPClient{
+		getBranchResults: []branchStatusResult{
+			{resp: map[string]any{"id": "branch-123", "status": "running"}},
+			{resp: map[string]any{"id": "branch-123", "status": "succeed"}},
+		},
+	}
+	clock := &fakeClock{}
+	var out bytes.Buffer
+	handler := &ToolHandler{
+		client:        client,
+		branchTracker: NewBranchTracker("parent"),
+		pollInitial:   2 * time.Second,
+		pollMax:       5 * time.Second,
+		pollTimeout:   20 * time.Second,
+		pollBackoff:   2.0,
+		nowFunc:       clock.Now,
+		sleepFunc:     clock.Sleep,
+	}
+	handler.SetStreamer(streaming.NewJSONStreamer(true, &out))
+
+	if _, err := handler.checkStatus(map[string]any{"branch_id": "branch-123"}); err != nil {
+		t.Fatalf("checkStatus returned error: %v", err)
+	}
+
+	var ticks []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+		if event["type"] == "poll.tick" {
+			ticks = append(ticks, event)
+		}
+	}
+	if len(ticks) != 2 {
+		t.Fatalf("expected 2 poll.tick events, got %d: %s", len(ticks), out.String())
+	}
+	last := ticks[1]
+	if last["branch_id"] != "branch-123" || last["attempt"] != float64(2) || last["status"] != "succeed" {
+		t.Fatalf("unexpected final tick: %#v", last)
+	}
+	if last["elapsed_ms"] != float64(2000) {
+		t.Fatalf("expected elapsed_ms=2000 after one 2s sleep, got %#v", last["elapsed_ms"])
+	}
+}
+
 func TestCheckStatusTimeoutUsesConfiguredDefaults(t *testing.T) {
 	responses := make([]branchStatusResult, 8)
 	for i := range responses {
@@ -307,6 +551,63 @@ func TestCheckStatusFailedIncludesBranchOutputAndManifestHint(t *testing.T) {
 	}
 }
 
+func TestStartAgentRetriesRetryableMCPError(t *testing.T) {
+	busy := map[string]any{
+		"isError": true,
+		"error":   map[string]any{"code": "capacity", "message": "no runners free", "retryable": true},
+	}
+	client := &fakeMCPClient{exploreErrors: []map[string]any{busy, busy}}
+	clock := &fakeClock{}
+	handler := &ToolHandler{client: client, branchTracker: NewBranchTracker("parent"), sleepFunc: clock.Sleep}
+
+	resp, retries, err := handler.startAgent("codex", "proj", "parent", "prompt")
+	if err != nil {
+		t.Fatalf("startAgent returned error: %v", err)
+	}
+	if client.parallelExploreCalls != 3 || retries != 2 {
+		t.Fatalf("expected 3 parallel_explore calls and 2 retries, got %d calls, %d retries", client.parallelExploreCalls, retries)
+	}
+	if ExtractBranchID(resp) != "branch-3" {
+		t.Fatalf("unexpected response after retries: %#v", resp)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(clock.sleeps) != 2 || clock.sleeps[0] != want[0] || clock.sleeps[1] != want[1] {
+		t.Fatalf("sleeps=%v, want %v", clock.sleeps, want)
+	}
+}
+
+func TestStartAgentPreservesStructuredMCPError(t *testing.T) {
+	client := &fakeMCPClient{exploreErrors: []map[string]any{{
+		"isError": true,
+		"content": []any{map[string]any{"type": "text", "text": `{"code":403,"message":"project quota exceeded","retryable":false}`}},
+	}}}
+	clock := &fakeClock{}
+	handler := &ToolHandler{client: client, branchTracker: NewBranchTracker("parent"), sleepFunc: clock.Sleep}
+
+	_, _, err := handler.startAgent("codex", "proj", "parent", "prompt")
+	var te ToolExecutionError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected ToolExecutionError, got %v", err)
+	}
+	if te.Kind != ErrorKindMCP || te.Instruction != instructionFinishedWithErr {
+		t.Fatalf("unexpected classification: kind=%q instruction=%q", te.Kind, te.Instruction)
+	}
+	if te.Details["code"] != float64(403) || te.Details["message"] != "project quota exceeded" || te.Details["retryable"] != false {
+		t.Fatalf("structured fields not preserved: %#v", te.Details)
+	}
+	if !strings.Contains(te.Msg, "project quota exceeded (code=403)") {
+		t.Fatalf("message should surface server text, got %q", te.Msg)
+	}
+	if client.parallelExploreCalls != 1 || len(clock.sleeps) != 0 {
+		t.Fatalf("non-retryable error must not be retried (calls=%d)", client.parallelExploreCalls)
+	}
+
+	payload := handler.errorPayload(err)
+	errObj, _ := payload["error"].(map[string]any)
+	if errObj["kind"] != ErrorKindMCP || errObj["details"] == nil {
+		t.Fatalf("error payload should expose kind and details: %#v", payload)
+	}
+}
+
 type branchReadInput struct {
 	branchID string
 	path     string
@@ -326,6 +627,8 @@ type fakeMCPClient struct {
 	branchOutputErr      error
 	getBranchResults     []branchStatusResult
 	getBranchCalls       int
+	exploreErrors        []map[string]any
+	explorePrompts       []string
 }
 
 type branchOutputInput struct {
@@ -335,6 +638,12 @@ type branchOutputInput struct {
 
 func (f *fakeMCPClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
 	f.parallelExploreCalls++
+	f.explorePrompts = append(f.explorePrompts, prompts...)
+	if len(f.exploreErrors) > 0 {
+		resp := f.exploreErrors[0]
+		f.exploreErrors = f.exploreErrors[1:]
+		return resp, nil
+	}
 	branchID := fmt.Sprintf("branch-%d", f.parallelExploreCalls)
 	return map[string]any{
 		"branch_id": branchID,
@@ -425,3 +734,73 @@ func (c *fakeClock) Sleep(d time.Duration) {
 	c.sleeps = append(c.sleeps, d)
 	c.now = c.now.Add(d)
 }
+
+func TestRunAgentOnceAppliesResponseTransformersInOrder(t *testing.T) {
+	client := &fakeMCPClient{
+		branchOutputResult: map[string]any{"output": "ok"},
+	}
+	handler := NewToolHandler(client, "proj", "parent", "/workspace", nil)
+	var agents []string
+	handler.AddResponseTransformer(func(agent, response string) string {
+		agents = append(agents, agent)
+		return response + "a"
+	})
+	handler.AddResponseTransformer(func(agent, response string) string { return response + "b" })
+
+	result, _, err := handler.runAgentOnce("codex", "proj", "parent", "prompt")
+	if err != nil {
+		t.Fatalf("runAgentOnce returned error: %v", err)
+	}
+	if got := result["response"]; got != "okab" {
+		t.Fatalf("expected transformers to run in registration order, got %q", got)
+	}
+	if len(agents) != 1 || agents[0] != "codex" {
+		t.Fatalf("expected transformer to see agent codex once, got %v", agents)
+	}
+}
+
+type streamingMCPClient struct {
+	*fakeMCPClient
+	output    string
+	streamErr error
+}
+
+func (s *streamingMCPClient) BranchOutputStream(branchID string) (io.ReadCloser, error) {
+	if s.streamErr != nil {
+		return nil, s.streamErr
+	}
+	return io.NopCloser(strings.NewReader(s.output)), nil
+}
+
+func TestRunAgentOnceKeepsTailOfLargeOutput(t *testing.T) {
+	client := &streamingMCPClient{
+		fakeMCPClient: &fakeMCPClient{},
+		output:        strings.Repeat("noise\n", 1000) + "VERDICT: no P0/P1 issues",
+	}
+	handler := NewToolHandler(client, "proj", "parent", "/workspace", nil)
+	handler.SetOutputTailBytes(1024)
+
+	result, _, err := handler.runAgentOnce("codex", "proj", "parent", "prompt")
+	if err != nil {
+		t.Fatalf("runAgentOnce returned error: %v", err)
+	}
+	response, _ := result["response"].(string)
+	if !strings.HasPrefix(response, "[... earlier output omitted; showing the last 1 KB ...]") || !strings.HasSuffix(response, "VERDICT: no P0/P1 issues") {
+		t.Fatalf("expected the marked tail, got %q", response)
+	}
+	if len(response) > 1200 {
+		t.Fatalf("tail should be bounded, got %d bytes", len(response))
+	}
+	if len(client.branchOutputInputs) != 0 {
+		t.Fatalf("streamed output should not also be read whole, got %#v", client.branchOutputInputs)
+	}
+
+	client.streamErr = errors.New("stream unsupported")
+	result, _, err = handler.runAgentOnce("codex", "proj", "parent", "prompt")
+	if err != nil {
+		t.Fatalf("runAgentOnce returned error: %v", err)
+	}
+	if result["response"] != "ok" || len(client.branchOutputInputs) != 1 {
+		t.Fatalf("expected fallback to BranchOutput, got %#v", result["response"])
+	}
+}
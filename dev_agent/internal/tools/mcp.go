@@ -78,15 +78,27 @@ func (c *MCPClient) call(method string, params map[string]any, timeout time.Dura
 }
 
 func (c *MCPClient) callWithRetries(method string, params map[string]any, timeout time.Duration, maxRetries int) (map[string]any, error) {
-	if maxRetries < 1 {
-		maxRetries = 1
-	}
+	payload := c.rpcPayload(method, params)
+	var result map[string]any
+	err := c.withRetries(method, maxRetries, func() error {
+		obj, err := c.postOnce(method, payload, timeout)
+		if err != nil {
+			return err
+		}
+		result = obj
+		return nil
+	})
+	return result, err
+}
+
+// rpcPayload builds a JSON-RPC request with a fresh id.
+func (c *MCPClient) rpcPayload(method string, params map[string]any) map[string]any {
 	c.requestID++
 	copiedParams := make(map[string]any, len(params))
 	for k, v := range params {
 		copiedParams[k] = v
 	}
-	payload := map[string]any{
+	return map[string]any{
 		"jsonrpc": "2.0",
 		"id":      c.requestID,
 		"method":  method,
@@ -95,68 +107,70 @@ func (c *MCPClient) callWithRetries(method string, params map[string]any, timeou
 			"ai.tidb.pantheon-ai/agent": "dev_agent",
 		},
 	}
+}
+
+// withRetries runs attempt up to maxRetries times, backing off exponentially
+// between failures, and returns the last error.
+func (c *MCPClient) withRetries(method string, maxRetries int, attempt func() error) error {
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
 	var lastErr error
+	for i := 0; i < maxRetries; i++ {
+		logx.Debugf("MCP POST %s attempt %d to %s", method, i+1, c.rpcURL)
+		if lastErr = attempt(); lastErr == nil {
+			return nil
+		}
+		if i < maxRetries-1 {
+			wait := time.Duration(1<<i) * time.Second
+			logx.Warningf("MCP call %s failed (attempt %d/%d): %v. Retrying in %ds...", method, i+1, maxRetries, lastErr, int(wait.Seconds()))
+			time.Sleep(wait)
+		}
+	}
+	return lastErr
+}
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		logx.Debugf("MCP POST %s attempt %d to %s", method, attempt+1, c.rpcURL)
-		resp, cancel, err := c.rpcPost(c.rpcURL, payload, timeout)
+// postOnce sends one request and decodes its JSON or SSE response.
+func (c *MCPClient) postOnce(method string, payload map[string]any, timeout time.Duration) (map[string]any, error) {
+	resp, cancel, err := c.rpcPost(c.rpcURL, payload, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+	defer resp.Body.Close()
+	ct := resp.Header.Get("Content-Type")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		logx.Errorf("MCP HTTP error %d for %s (CT=%s): %.500s", resp.StatusCode, method, ct, string(body))
+		return nil, fmt.Errorf("MCP HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	if strings.Contains(ct, "text/event-stream") {
+		data, preview, err := parseSSEStream(resp.Body)
+		if preview != "" {
+			logx.Debugf("MCP SSE preview: %q", preview)
+		}
 		if err != nil {
-			lastErr = err
-		} else {
-			ct := resp.Header.Get("Content-Type")
-			if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-				body, _ := io.ReadAll(resp.Body)
-				resp.Body.Close()
-				cancel()
-				logx.Errorf("MCP HTTP error %d for %s (CT=%s): %.500s", resp.StatusCode, method, ct, string(body))
-				lastErr = fmt.Errorf("MCP HTTP %d: %s", resp.StatusCode, string(body))
-			} else if strings.Contains(ct, "text/event-stream") {
-				data, preview, err := parseSSEStream(resp.Body)
-				resp.Body.Close()
-				cancel()
-				if preview != "" {
-					logx.Debugf("MCP SSE preview: %q", preview)
-				}
-				if err != nil {
-					logx.Errorf("Failed to parse SSE JSON for %s. Content-Type: %s, Status: %d (%v)", method, ct, resp.StatusCode, err)
-					lastErr = err
-				} else {
-					var obj map[string]any
-					if err := json.Unmarshal(data, &obj); err != nil {
-						logx.Errorf("MCP SSE payload not JSON (status %d, CT=%s). Preview: %.200s", resp.StatusCode, ct, string(data[:min(200, len(data))]))
-						lastErr = err
-					} else {
-						return normalizeRPC(obj), nil
-					}
-				}
-			} else {
-				data, err := io.ReadAll(resp.Body)
-				resp.Body.Close()
-				cancel()
-				if err != nil {
-					logx.Errorf("Failed reading MCP response body for %s: %v (bytes=%d)", method, err, len(data))
-					lastErr = err
-					continue
-				}
-				var obj map[string]any
-				if err := json.Unmarshal(data, &obj); err != nil {
-					logx.Errorf("MCP response not JSON (status %d, CT=%s). First 1000 bytes: %q", resp.StatusCode, ct, string(data[:min(1000, len(data))]))
-					lastErr = err
-				} else {
-					return normalizeRPC(obj), nil
-				}
-			}
+			logx.Errorf("Failed to parse SSE JSON for %s. Content-Type: %s, Status: %d (%v)", method, ct, resp.StatusCode, err)
+			return nil, err
 		}
-		if attempt < maxRetries-1 {
-			wait := time.Duration(1<<attempt) * time.Second
-			logx.Warningf("MCP call %s failed (attempt %d/%d): %v. Retrying in %ds...", method, attempt+1, maxRetries, lastErr, int(wait.Seconds()))
-			time.Sleep(wait)
+		var obj map[string]any
+		if err := json.Unmarshal(data, &obj); err != nil {
+			logx.Errorf("MCP SSE payload not JSON (status %d, CT=%s). Preview: %.200s", resp.StatusCode, ct, string(data[:min(200, len(data))]))
+			return nil, err
 		}
+		return normalizeRPC(obj), nil
 	}
-	if lastErr == nil {
-		lastErr = MCPError{Msg: "Unknown MCP error"}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logx.Errorf("Failed reading MCP response body for %s: %v (bytes=%d)", method, err, len(data))
+		return nil, err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		logx.Errorf("MCP response not JSON (status %d, CT=%s). First 1000 bytes: %q", resp.StatusCode, ct, string(data[:min(1000, len(data))]))
+		return nil, err
 	}
-	return nil, lastErr
+	return normalizeRPC(obj), nil
 }
 
 func normalizeRPC(obj map[string]any) map[string]any {
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Error kinds classify a ToolExecutionError so callers can tell transient MCP
+// failures from ones that will not go away on their own.
+const (
+	ErrorKindMCP          = "mcp_error"
+	ErrorKindMCPRetryable = "mcp_retryable"
+)
+
+// mcpRetryAttempts bounds how often a parallel_explore call is re-issued when the
+// server flags its error as retryable.
+const mcpRetryAttempts = 3
+
+// mcpErrorDetails extracts the structured fields (code, message, retryable) from an
+// isError response. Servers report them under "error", at the top level, or as JSON
+// inside the first text content block; the first source that has a field wins.
+func mcpErrorDetails(resp map[string]any) map[string]any {
+	details := map[string]any{}
+	sources := []map[string]any{}
+	switch errVal := resp["error"].(type) {
+	case map[string]any:
+		sources = append(sources, errVal)
+	case string:
+		if msg := strings.TrimSpace(errVal); msg != "" {
+			details["message"] = msg
+		}
+	}
+	sources = append(sources, resp)
+	if text := mcpErrorText(resp); text != "" {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(text), &obj); err == nil {
+			sources = append(sources, obj)
+		} else {
+			sources = append(sources, map[string]any{"message": text})
+		}
+	}
+	for _, src := range sources {
+		if _, ok := details["code"]; !ok && src["code"] != nil {
+			details["code"] = src["code"]
+		}
+		if _, ok := details["message"]; !ok {
+			if msg, ok := src["message"].(string); ok && strings.TrimSpace(msg) != "" {
+				details["message"] = strings.TrimSpace(msg)
+			}
+		}
+		if _, ok := details["retryable"]; !ok {
+			if retryable, ok := src["retryable"].(bool); ok {
+				details["retryable"] = retryable
+			}
+		}
+	}
+	return details
+}
+
+// mcpErrorText returns the first text block of an MCP tool result, if any.
+func mcpErrorText(resp map[string]any) string {
+	content, _ := resp["content"].([]any)
+	for _, item := range content {
+		block, ok := item.(map[string]any)
+		if !ok || block["type"] != "text" {
+			continue
+		}
+		if text, ok := block["text"].(string); ok && strings.TrimSpace(text) != "" {
+			return strings.TrimSpace(text)
+		}
+	}
+	return ""
+}
+
+// newMCPToolError converts an isError parallel_explore response into a classified
+// ToolExecutionError that keeps the server's structured fields in Details.
+func newMCPToolError(resp map[string]any) ToolExecutionError {
+	details := mcpErrorDetails(resp)
+	kind := ErrorKindMCP
+	if retryable, _ := details["retryable"].(bool); retryable {
+		kind = ErrorKindMCPRetryable
+	}
+	var msg string
+	switch {
+	case details["message"] != nil:
+		msg = fmt.Sprintf("ParallelExplore returned error: %v", details["message"])
+		if code, ok := details["code"]; ok {
+			msg += fmt.Sprintf(" (code=%v)", code)
+		}
+	case resp["error"] != nil:
+		msg = fmt.Sprintf("ParallelExplore returned error: %v", resp["error"])
+	default:
+		msg = fmt.Sprintf("ParallelExplore returned error (details: %v)", resp)
+	}
+	if len(details) == 0 {
+		details = nil
+	}
+	return ToolExecutionError{
+		Msg:         msg,
+		Instruction: instructionFinishedWithErr,
+		Details:     details,
+		Kind:        kind,
+	}
+}
+
+// mcpRetryWait mirrors the MCP client's exponential backoff.
+func mcpRetryWait(attempt int) time.Duration {
+	return time.Duration(1<<(attempt-1)) * time.Second
+}
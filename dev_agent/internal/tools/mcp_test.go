@@ -5,6 +5,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -122,3 +124,87 @@ func TestMCPClientAddsMetaTagToToolCalls(t *testing.T) {
 		})
 	}
 }
+
+func TestBranchOutputStreamDecodesOutputField(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("log line\n", 2000) + "VERDICT: done ✓"
+	encoded, _ := json.Marshal(long)
+	// The text copy in "content" carries an escaped "output" key that must not match.
+	body := `{"jsonrpc":"2.0","result":{"content":[{"type":"text","text":"{\"output\":\"decoy\"}"}],` +
+		`"structuredContent":{"status":"succeed","output":` + string(encoded) + `}}}`
+
+	for _, sse := range []bool{false, true} {
+		sse := sse
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if sse {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte("event: message\ndata: " + body + "\n\n"))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		client := NewMCPClient(srv.URL)
+		client.client = srv.Client()
+
+		stream, err := client.BranchOutputStream("branch-1")
+		if err != nil {
+			t.Fatalf("sse=%v: BranchOutputStream failed: %v", sse, err)
+		}
+		tail, truncated, err := readTail(stream, 64)
+		stream.Close()
+		srv.Close()
+		if err != nil {
+			t.Fatalf("sse=%v: readTail failed: %v", sse, err)
+		}
+		if !truncated || !strings.HasSuffix(long, tail) || !strings.HasSuffix(tail, "VERDICT: done ✓") {
+			t.Fatalf("sse=%v: unexpected tail %q (truncated=%v)", sse, tail, truncated)
+		}
+	}
+}
+
+func TestBranchOutputStreamRetriesFailedRequests(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":{"structuredContent":{"output":"done"}}}`))
+	}))
+	defer srv.Close()
+	client := NewMCPClient(srv.URL)
+	client.client = srv.Client()
+
+	stream, err := client.BranchOutputStream("branch-1")
+	if err != nil {
+		t.Fatalf("BranchOutputStream failed: %v", err)
+	}
+	defer stream.Close()
+	output, _, err := readTail(stream, 0)
+	if err != nil || output != "done" {
+		t.Fatalf("unexpected output %q (err=%v)", output, err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("expected the 503 to be retried once, got %d requests", n)
+	}
+}
+
+func TestReadTailKeepsRuneBoundary(t *testing.T) {
+	t.Parallel()
+
+	tail, truncated, err := readTail(strings.NewReader("héllo wörld"), 7)
+	if err != nil || !truncated {
+		t.Fatalf("unexpected result %q truncated=%v err=%v", tail, truncated, err)
+	}
+	if tail != "wörld" && tail != " wörld" {
+		t.Fatalf("tail should start on a rune boundary, got %q", tail)
+	}
+	if whole, truncated, _ := readTail(strings.NewReader("short"), 0); whole != "short" || truncated {
+		t.Fatalf("zero limit should read everything, got %q", whole)
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	b "plan_agent/internal/brain"
 	cfg "plan_agent/internal/config"
+	"plan_agent/internal/exitcodes"
 	"plan_agent/internal/logx"
 	"plan_agent/internal/plan"
 	"plan_agent/internal/streaming"
@@ -17,6 +21,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-result" {
+		os.Exit(runValidateResult(os.Args[2:]))
+	}
+
 	query := flag.String("query", "", "User query to plan for")
 	parent := flag.String("parent-branch-id", "", "Parent branch id to fork from (required)")
 	project := flag.String("project-name", "", "Override project name")
@@ -24,7 +32,12 @@ func main() {
 	remoteWorkspaceDir := flag.String("remote-workspace-dir", "", "Remote workspace directory on Pantheon branch (default: /home/pan/workspace)")
 	headless := flag.Bool("headless", false, "Headless mode (no interactive prompt)")
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
-	flag.Parse()
+	maxDuration := flag.Duration("max-duration", 0, "Abort LLM calls once the run exceeds this duration (e.g. 20m); 0 disables the limit")
+	stallWarning := flag.Duration("stall-warning-interval", 5*time.Minute, "Report a stall warning after this long without any event or log line; 0 disables the watchdog")
+	profile := flag.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	allowedArtifacts := flag.String("allowed-artifacts", "", "Comma-separated globs that read_file and read_artifact paths must match (e.g. \"*.md,docs/*\"); empty allows all")
+	emitDevTasks := flag.Bool("emit-dev-tasks", false, "Also write the recommended plan's steps to stdout as dev-agent task specs (for dev-agent --from-plan)")
+	exitcodes.ParseFlags()
 
 	streamEnabled := streamJSON != nil && *streamJSON
 	if streamEnabled {
@@ -32,28 +45,35 @@ func main() {
 		logx.SetLevel(logx.Error)
 	}
 
-	conf, err := cfg.FromEnv()
+	conf, err := cfg.Load(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
 	if *project != "" {
 		conf.ProjectName = *project
 	}
 	if *workspaceDir != "" {
-		conf.WorkspaceDir = *workspaceDir
+		conf.WorkspaceDir = filepath.Join(*workspaceDir, conf.RunSubdir)
+	}
+	if conf.RunSubdir != "" {
+		if err := os.MkdirAll(conf.WorkspaceDir, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create per-run workspace %s: %v\n", conf.WorkspaceDir, err)
+			os.Exit(exitcodes.Config)
+		}
+		fmt.Fprintf(os.Stderr, "Using per-run workspace: %s\n", conf.WorkspaceDir)
 	}
 	if *remoteWorkspaceDir != "" {
 		conf.RemoteWorkspaceDir = *remoteWorkspaceDir
 	}
 	if conf.ProjectName == "" {
 		fmt.Fprintln(os.Stderr, "Project name required via PROJECT_NAME or --project-name")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 	if *parent == "" {
 		fmt.Fprintln(os.Stderr, "--parent-branch-id is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 
 	q := strings.TrimSpace(*query)
@@ -65,18 +85,23 @@ func main() {
 	}
 	if q == "" {
 		fmt.Fprintln(os.Stderr, "query is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
 	mcp := t.NewMCPClient(conf.MCPBaseURL)
 	handler := t.NewToolHandlerWithConfig(mcp, &conf, strings.TrimSpace(*parent))
+	if err := handler.SetAllowedArtifacts(strings.Split(*allowedArtifacts, ",")); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitcodes.Usage)
+	}
 
 	var streamer *streaming.JSONStreamer
 	if streamEnabled {
 		streamer = streaming.NewJSONStreamer(true, os.Stdout)
 		streamer.EmitThreadStarted(q, conf.ProjectName, strings.TrimSpace(*parent), *headless)
 	}
+	handler.SetStreamer(streamer)
 
 	runner, err := plan.NewRunner(brain, handler, streamer, plan.Options{
 		Query:              q,
@@ -84,6 +109,7 @@ func main() {
 		ParentBranchID:     strings.TrimSpace(*parent),
 		WorkspaceDir:       conf.WorkspaceDir,
 		RemoteWorkspaceDir: conf.RemoteWorkspaceDir,
+		MaxDuration:        *maxDuration,
 	})
 	if err != nil {
 		if streamer != nil && streamer.Enabled() {
@@ -91,27 +117,72 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
+	watchdog := streaming.StartWatchdog(streamer, *stallWarning, logx.LastOutput, func(idle time.Duration) {
+		logx.Warningf("No output for %s; the run is still waiting on a branch or LLM call.", idle.Round(time.Second))
+	})
 	result, err := runner.Run()
+	watchdog.Stop()
 	if err != nil {
 		if streamer != nil && streamer.Enabled() {
 			streamer.EmitError("workflow", err.Error(), nil)
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Code(err))
 	}
 
 	if streamer != nil && streamer.Enabled() && result != nil {
-		streamer.EmitThreadCompleted("completed", "Plan generated", map[string]any{
+		payload := map[string]any{
 			"query":       result.Query,
 			"project":     result.ProjectName,
 			"plan_result": result.PlanResult,
-		})
+			"headline":    result.Headline,
+		}
+		if *emitDevTasks {
+			payload["dev_tasks"] = plan.BuildDevTasks(result)
+		}
+		streamer.EmitThreadCompleted("completed", result.Headline, payload)
 	}
 
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
+	if *emitDevTasks && !streamEnabled {
+		tasks, _ := json.MarshalIndent(plan.BuildDevTasks(result), "", "  ")
+		fmt.Fprintln(os.Stdout, string(tasks))
+	}
+}
+
+// runValidateResult implements the `validate-result --file X` subcommand.
+func runValidateResult(args []string) int {
+	fs := flag.NewFlagSet("validate-result", flag.ContinueOnError)
+	file := fs.String("file", "", "Path to a result JSON file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return exitcodes.Usage
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	if err := plan.ValidateResult(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Runtime
+	}
+	fmt.Fprintf(os.Stderr, "%s: valid result\n", *file)
+	return exitcodes.Success
 }
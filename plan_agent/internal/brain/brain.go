@@ -2,6 +2,7 @@ package brain
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -66,7 +67,9 @@ type chatCompletionResponse struct {
 	} `json:"choices"`
 }
 
-func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*chatCompletionResponse, error) {
+// Complete sends one chat completion request, retrying transient failures. The
+// request and any backoff between retries are abandoned once ctx is done.
+func (b *LLMBrain) Complete(ctx context.Context, messages []ChatMessage, tools []map[string]any) (*chatCompletionResponse, error) {
 	var lastErr error
 	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", b.endpoint, b.deployment, b.apiVersion)
 
@@ -82,7 +85,7 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 	payload, _ := json.Marshal(body)
 
 	for attempt := 0; attempt < b.maxRetries; attempt++ {
-		req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
 		if err != nil {
 			lastErr = err
 		} else {
@@ -91,6 +94,9 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 
 			resp, err := b.client.Do(req)
 			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, fmt.Errorf("azure openai call aborted: %w", ctxErr)
+				}
 				lastErr = err
 			} else {
 				defer resp.Body.Close()
@@ -116,7 +122,11 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 		if attempt < b.maxRetries-1 {
 			wait := time.Duration(1<<attempt) * time.Second
 			logx.Warningf("Azure OpenAI call failed (attempt %d/%d): %v. Retrying in %ds...", attempt+1, b.maxRetries, lastErr, int(wait.Seconds()))
-			time.Sleep(wait)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("azure openai call aborted: %w", ctx.Err())
+			case <-time.After(wait):
+			}
 		}
 	}
 	if lastErr == nil {
@@ -1,6 +1,13 @@
 package brain
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
 
 func TestCompleteReturnsErrorForInvalidURL(t *testing.T) {
 	brain := NewLLMBrain("key", "http://[::1", "dep", "2024-12-01-preview", 1)
@@ -9,8 +16,32 @@ func TestCompleteReturnsErrorForInvalidURL(t *testing.T) {
 			t.Fatalf("unexpected panic: %v", r)
 		}
 	}()
-	_, err := brain.Complete([]ChatMessage{{Role: "user", Content: "hi"}}, nil)
+	_, err := brain.Complete(context.Background(), []ChatMessage{{Role: "user", Content: "hi"}}, nil)
 	if err == nil {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestCompleteAbortsWhenContextDeadlineExpires(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	brain := NewLLMBrain("key", srv.URL, "dep", "2024-12-01-preview", 3)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := brain.Complete(ctx, []ChatMessage{{Role: "user", Content: "hi"}}, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Complete ignored the deadline, took %s", elapsed)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +26,8 @@ type AgentConfig struct {
 	PollBackoffFactor  float64
 	ProjectName        string
 	WorkspaceDir       string
+	ReviewArtifactName string
+	RunSubdir          string
 	RemoteWorkspaceDir string
 }
 
@@ -103,11 +106,26 @@ func FromEnv() (AgentConfig, error) {
 			workspace = "."
 		}
 	}
+	// WORKSPACE_PER_RUN isolates concurrent runs that share one WORKSPACE_DIR.
+	perRun, err := envBool("WORKSPACE_PER_RUN")
+	if err != nil {
+		return AgentConfig{}, err
+	}
+	runSubdir := ""
+	if perRun {
+		runSubdir = runSubdirName(time.Now())
+		workspace = filepath.Join(workspace, runSubdir)
+	}
 	remoteWorkspace := os.Getenv("REMOTE_WORKSPACE_DIR")
 	if remoteWorkspace == "" {
 		remoteWorkspace = "/home/pan/workspace"
 	}
 
+	reviewArtifact, err := envFileName("REVIEW_ARTIFACT_NAME", "code_review.log")
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
 	return AgentConfig{
 		AzureAPIKey:        apiKey,
 		AzureEndpoint:      endpoint,
@@ -120,10 +138,24 @@ func FromEnv() (AgentConfig, error) {
 		PollBackoffFactor:  backoff,
 		ProjectName:        project,
 		WorkspaceDir:       workspace,
+		ReviewArtifactName: reviewArtifact,
+		RunSubdir:          runSubdir,
 		RemoteWorkspaceDir: remoteWorkspace,
 	}, nil
 }
 
+// envFileName reads a bare file name (no directories) from name, defaulting to def.
+func envFileName(name, def string) (string, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def, nil
+	}
+	if strings.ContainsAny(v, `/\`) || v == "." || v == ".." {
+		return "", fmt.Errorf("%s must be a file name, not a path: %s", name, v)
+	}
+	return v, nil
+}
+
 func envSeconds(name string, def int) (time.Duration, error) {
 	v := os.Getenv(name)
 	if v == "" {
@@ -136,6 +168,24 @@ func envSeconds(name string, def int) (time.Duration, error) {
 	return time.Duration(n) * time.Second, nil
 }
 
+func envBool(name string) (bool, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean for %s: %s", name, v)
+	}
+	return b, nil
+}
+
+// runSubdirName names the per-run workspace directory; the pid keeps runs that
+// start within the same second apart.
+func runSubdirName(now time.Time) string {
+	return fmt.Sprintf("run-%s-%d", now.UTC().Format("20060102T150405Z"), os.Getpid())
+}
+
 func loadDotenv(path string) error {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"plan_agent/internal/miniyaml"
+)
+
+// defaultProfilesFile is read when PROFILES_FILE does not name another file.
+const defaultProfilesFile = "profiles.yaml"
+
+// Load applies the named profile and then reads configuration exactly like
+// FromEnv. Variables already set in the environment or .env win over the
+// profile, and the profile wins over built-in defaults. An empty profile is
+// plain FromEnv.
+func Load(profile string) (AgentConfig, error) {
+	profile = strings.TrimSpace(profile)
+	if profile == "" {
+		return FromEnv()
+	}
+	// .env must be in the environment first so it outranks the profile.
+	_ = loadDotenv(".env")
+	path := strings.TrimSpace(os.Getenv("PROFILES_FILE"))
+	if path == "" {
+		path = defaultProfilesFile
+	}
+	if err := applyProfile(path, profile); err != nil {
+		return AgentConfig{}, err
+	}
+	return FromEnv()
+}
+
+// applyProfile sets every variable of the profile that is not already set,
+// expanding ${NAME} references so secrets can stay in the environment. Every
+// value is resolved before any is set, so one profile variable never expands
+// another and the result does not depend on map order.
+func applyProfile(path, name string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read profiles: %w", err)
+	}
+	var profiles map[string]map[string]string
+	if err := miniyaml.Unmarshal(data, &profiles); err != nil {
+		return fmt.Errorf("parse profiles %s: %w", path, err)
+	}
+	vars, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	resolved := map[string]string{}
+	for key, value := range vars {
+		if os.Getenv(key) == "" {
+			resolved[key] = expandRefs(value)
+		}
+	}
+	for key, value := range resolved {
+		_ = os.Setenv(key, value)
+	}
+	return nil
+}
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandRefs replaces ${NAME} with the environment value of NAME. Any other $
+// is kept literally, so passwords and regexes survive unchanged.
+func expandRefs(value string) string {
+	return envRef.ReplaceAllStringFunc(value, func(ref string) string {
+		return os.Getenv(envRef.FindStringSubmatch(ref)[1])
+	})
+}
@@ -0,0 +1,28 @@
+package exitcodes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeClassifiesErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, Success},
+		{"plain", errors.New("llm call failed"), Runtime},
+		{"config", NewConfigError(errors.New("AZURE_OPENAI_API_KEY must be set")), Config},
+		{"wrapped config", fmt.Errorf("init: %w", NewConfigError(errors.New("bad"))), Config},
+	}
+	for _, tc := range cases {
+		if got := Code(tc.err); got != tc.want {
+			t.Errorf("%s: Code() = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+	if NewConfigError(nil) != nil {
+		t.Fatal("NewConfigError(nil) should stay nil")
+	}
+}
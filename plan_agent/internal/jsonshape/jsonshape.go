@@ -0,0 +1,155 @@
+// Package jsonshape compares a decoded JSON document with the shape
+// encoding/json gives a Go type, so each agent's validate-result subcommand
+// checks its Result against one tested implementation.
+package jsonshape
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Problems lists every way raw, a value decoded by encoding/json into an any,
+// differs from the JSON form of typ: unknown fields, missing required
+// (non-omitempty) fields and values of the wrong JSON type. Paths are dotted,
+// with [i] for array items; a null value matches any type.
+func Problems(raw any, typ reflect.Type) []string {
+	return problems(raw, typ, "")
+}
+
+func problems(raw any, typ reflect.Type, path string) []string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if raw == nil {
+		return nil
+	}
+	label := path
+	if label == "" {
+		label = "<root>"
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object", label)}
+		}
+		var out []string
+		known := map[string]bool{}
+		for _, field := range fields(typ) {
+			known[field.name] = true
+			value, present := obj[field.name]
+			if !present {
+				if !field.omitempty {
+					out = append(out, fmt.Sprintf("missing field %s", joinPath(path, field.name)))
+				}
+				continue
+			}
+			out = append(out, problems(value, field.typ, joinPath(path, field.name))...)
+		}
+		var unknown []string
+		for key := range obj {
+			if !known[key] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			out = append(out, fmt.Sprintf("unknown field %s", joinPath(path, key)))
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		items, ok := raw.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected array", label)}
+		}
+		var out []string
+		for i, item := range items {
+			out = append(out, problems(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return out
+	case reflect.Map:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected object", label)}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var out []string
+		for _, key := range keys {
+			out = append(out, problems(obj[key], typ.Elem(), joinPath(path, key))...)
+		}
+		return out
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected string", label)}
+		}
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected boolean", label)}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, ok := raw.(float64); !ok {
+			return []string{fmt.Sprintf("%s: expected number", label)}
+		}
+	}
+	return nil
+}
+
+// field is one JSON member of a struct.
+type field struct {
+	name      string
+	typ       reflect.Type
+	omitempty bool
+}
+
+// fields returns the JSON members of typ, promoting the fields of untagged
+// embedded structs the way encoding/json does.
+func fields(typ reflect.Type) []field {
+	var out []field
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			embedded := sf.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				out = append(out, fields(embedded)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		omitempty := false
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "omitempty" {
+				omitempty = true
+			}
+		}
+		out = append(out, field{name: name, typ: sf.Type, omitempty: omitempty})
+	}
+	return out
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
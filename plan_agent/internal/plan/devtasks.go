@@ -0,0 +1,84 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DevTask is one plan step rewritten as a dev-agent task spec.
+type DevTask struct {
+	StepID             int      `json:"step_id"`
+	Title              string   `json:"title"`
+	Detail             string   `json:"detail"`
+	AcceptanceCriteria []string `json:"acceptance_criteria,omitempty"`
+	References         []string `json:"references,omitempty"`
+}
+
+// DevTaskFile is the document --emit-dev-tasks writes and
+// `dev-agent --from-plan FILE --step N` reads.
+type DevTaskFile struct {
+	Query  string    `json:"query"`
+	PlanID int       `json:"plan_id"`
+	Tasks  []DevTask `json:"tasks"`
+}
+
+// BuildDevTasks turns the steps of the recommended plan (the first plan when the
+// recommendation names none of them) into dev tasks, in plan order. A step's
+// dependencies become references, so a task run on its own still knows which
+// earlier steps it builds on.
+func BuildDevTasks(result *Result) DevTaskFile {
+	file := DevTaskFile{Tasks: []DevTask{}}
+	if result == nil || len(result.PlanResult.Plans) == 0 {
+		return file
+	}
+	file.Query = result.Query
+	chosen := result.PlanResult.Plans[0]
+	for _, p := range result.PlanResult.Plans {
+		if p.PlanID == result.PlanResult.RecommendedPlanID {
+			chosen = p
+			break
+		}
+	}
+	file.PlanID = chosen.PlanID
+
+	titles := make(map[int]string, len(chosen.Steps))
+	for _, step := range chosen.Steps {
+		titles[step.StepID] = stepTitle(step)
+	}
+	for _, step := range chosen.Steps {
+		task := DevTask{
+			StepID: step.StepID,
+			Title:  titles[step.StepID],
+			Detail: strings.TrimSpace(step.Description),
+		}
+		if prompt, _ := step.ToolArgs["prompt"].(string); strings.TrimSpace(prompt) != "" {
+			task.Detail = strings.TrimSpace(prompt)
+		}
+		if outcome := strings.TrimSpace(step.ExpectedOutcome); outcome != "" {
+			task.AcceptanceCriteria = []string{outcome}
+		}
+		for _, dep := range step.Dependencies {
+			ref := fmt.Sprintf("plan step %d", dep)
+			if title := titles[dep]; title != "" {
+				ref += ": " + title
+			}
+			task.References = append(task.References, ref)
+		}
+		file.Tasks = append(file.Tasks, task)
+	}
+	return file
+}
+
+// stepTitle is the first line of the step's description, falling back to its
+// tool prompt and then to its number.
+func stepTitle(step PlanStep) string {
+	text := strings.TrimSpace(step.Description)
+	if text == "" {
+		text, _ = step.ToolArgs["prompt"].(string)
+		text = strings.TrimSpace(text)
+	}
+	if line, _, _ := strings.Cut(text, "\n"); strings.TrimSpace(line) != "" {
+		return strings.TrimSpace(line)
+	}
+	return fmt.Sprintf("Step %d", step.StepID)
+}
@@ -0,0 +1,39 @@
+package plan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildDevTasksUsesRecommendedPlan(t *testing.T) {
+	result := &Result{
+		Query: "Add authentication",
+		PlanResult: PlanResult{
+			RecommendedPlanID: 2,
+			Plans: []Plan{
+				{PlanID: 1, Steps: []PlanStep{{StepID: 1, Description: "Everything at once"}}},
+				{PlanID: 2, Steps: []PlanStep{
+					{StepID: 1, Description: "Implement user model\nwith bcrypt hashes", ToolArgs: map[string]any{"prompt": "Implement and test the user model", "agent": "tdd"}, ExpectedOutcome: "User model with tests"},
+					{StepID: 2, ToolArgs: map[string]any{"prompt": "Implement login API"}, Dependencies: []int{1, 7}},
+				}},
+			},
+		},
+	}
+	got := BuildDevTasks(result)
+	want := DevTaskFile{
+		Query:  "Add authentication",
+		PlanID: 2,
+		Tasks: []DevTask{
+			{StepID: 1, Title: "Implement user model", Detail: "Implement and test the user model", AcceptanceCriteria: []string{"User model with tests"}},
+			{StepID: 2, Title: "Implement login API", Detail: "Implement login API", References: []string{"plan step 1: Implement user model", "plan step 7"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected dev tasks:\n got %+v\nwant %+v", got, want)
+	}
+
+	result.PlanResult.RecommendedPlanID = 9
+	if got := BuildDevTasks(result); got.PlanID != 1 || len(got.Tasks) != 1 {
+		t.Fatalf("expected a fallback to the first plan, got %+v", got)
+	}
+}
@@ -3,6 +3,7 @@ package plan
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildPlanPromptIncludesCoreSections(t *testing.T) {
@@ -40,3 +41,20 @@ func TestParsePlanResultFromJSONBlock(t *testing.T) {
 		t.Fatalf("expected recommended_plan_id=1, got %d", result.RecommendedPlanID)
 	}
 }
+
+func TestBuildHeadlineNamesRecommendedPlan(t *testing.T) {
+	res := &Result{
+		Query: "add rate limiting\nto the public API",
+		PlanResult: PlanResult{
+			Plans: []Plan{
+				{PlanID: 1, Name: "Middleware", ConfidenceScore: 0.6},
+				{PlanID: 2, Name: "Token bucket", ConfidenceScore: 0.8, Steps: []PlanStep{{}, {}}},
+			},
+			RecommendedPlanID: 2,
+		},
+	}
+	want := `Plan for "add rate limiting to the public API": 2 plans, recommending #2 "Token bucket" (confidence 0.80, 2 steps) (1m4s).`
+	if got := buildHeadline(res, 64*time.Second); got != want {
+		t.Fatalf("buildHeadline = %q, want %q", got, want)
+	}
+}
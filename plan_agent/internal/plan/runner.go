@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"plan_agent/internal/streaming"
 	t "plan_agent/internal/tools"
 	"strings"
+	"time"
 )
 
 type Options struct {
@@ -19,13 +21,21 @@ type Options struct {
 	ParentBranchID     string
 	WorkspaceDir       string
 	RemoteWorkspaceDir string
+	// MaxDuration caps the wall-clock time of the run's LLM calls; zero disables it.
+	MaxDuration time.Duration
 }
 
+// PromptVersion identifies the plan prompt generation recorded on each Result.
+const PromptVersion = "v1.0"
+
 type Result struct {
 	Query          string     `json:"query"`
 	ProjectName    string     `json:"project_name"`
 	ParentBranchID string     `json:"parent_branch_id,omitempty"`
 	PlanResult     PlanResult `json:"plan_result"`
+	PromptVersion  string     `json:"prompt_version,omitempty"`
+	// Headline is a one-sentence TL;DR naming the recommended plan.
+	Headline string `json:"headline,omitempty"`
 }
 
 type Runner struct {
@@ -66,6 +76,13 @@ func NewRunner(brain *brain.LLMBrain, handler *t.ToolHandler, streamer *streamin
 
 func (r *Runner) Run() (*Result, error) {
 	logx.Infof("Starting plan workflow")
+	started := time.Now()
+	ctx := context.Background()
+	if r.opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.MaxDuration)
+		defer cancel()
+	}
 
 	reviewMapContent := ""
 
@@ -140,7 +157,7 @@ Keep the analysis under 2000 words. Focus on information that would help with ta
 	}
 	tools := t.GetToolDefinitions()
 	for i := 0; i < 12; i++ {
-		resp, err := r.brain.Complete(messages, tools)
+		resp, err := r.brain.Complete(ctx, messages, tools)
 		if err != nil {
 			return nil, err
 		}
@@ -175,16 +192,45 @@ Keep the analysis under 2000 words. Focus on information that would help with ta
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse plan result: %w", err)
 		}
-		return &Result{
+		result := &Result{
 			Query:          r.opts.Query,
 			ProjectName:    r.opts.ProjectName,
 			ParentBranchID: r.opts.ParentBranchID,
 			PlanResult:     planResult,
-		}, nil
+			PromptVersion:  PromptVersion,
+		}
+		result.Headline = buildHeadline(result, time.Since(started))
+		return result, nil
 	}
 	return nil, errors.New("plan workflow reached iteration limit")
 }
 
+// headlineQueryChars caps how much of the query a headline quotes.
+const headlineQueryChars = 60
+
+// buildHeadline templates a one-sentence summary of res without an LLM call,
+// e.g. `Plan for "add rate limiting": 3 plans, recommending #2 "Token bucket"
+// (confidence 0.80, 5 steps) (1m4s).`
+func buildHeadline(res *Result, elapsed time.Duration) string {
+	query := strings.Join(strings.Fields(res.Query), " ")
+	if runes := []rune(query); len(runes) > headlineQueryChars {
+		query = strings.TrimSpace(string(runes[:headlineQueryChars])) + "..."
+	}
+	plans := res.PlanResult.Plans
+	noun := "plans"
+	if len(plans) == 1 {
+		noun = "plan"
+	}
+	outcome := fmt.Sprintf("%d %s", len(plans), noun)
+	for _, p := range plans {
+		if p.PlanID == res.PlanResult.RecommendedPlanID {
+			outcome += fmt.Sprintf(", recommending #%d %q (confidence %.2f, %d steps)", p.PlanID, p.Name, p.ConfidenceScore, len(p.Steps))
+			break
+		}
+	}
+	return fmt.Sprintf("Plan for %q: %s (%s).", query, outcome, elapsed.Round(time.Second))
+}
+
 func toJSON(v any) string {
 	data, _ := json.Marshal(v)
 	return string(data)
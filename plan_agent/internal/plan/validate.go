@@ -0,0 +1,26 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"plan_agent/internal/jsonshape"
+)
+
+// ValidateResult checks that data is a Result JSON document in the shape this
+// version emits. Every mismatch is reported: unknown fields, missing required
+// (non-omitempty) fields, and values of the wrong JSON type.
+func ValidateResult(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("result is not valid JSON: %w", err)
+	}
+	problems := jsonshape.Problems(raw, reflect.TypeOf(Result{}))
+	if len(problems) > 0 {
+		return errors.New("result schema mismatch: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package plan
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateResultAcceptsMarshaledResult(t *testing.T) {
+	data, err := json.Marshal(&Result{
+		Query:       "add caching",
+		ProjectName: "proj",
+		PlanResult:  PlanResult{Plans: []Plan{{}}, RecommendedPlanID: 1},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := ValidateResult(data); err != nil {
+		t.Fatalf("expected valid result, got %v", err)
+	}
+}
+
+func TestValidateResultReportsUnknownAndMissingFields(t *testing.T) {
+	err := ValidateResult([]byte(`{"query":"q","plan_result":{"plans":[],"recommended_plan_id":"1","reasoning":""},"extra":1}`))
+	if err == nil {
+		t.Fatal("expected schema mismatch")
+	}
+	for _, want := range []string{"missing field project_name", "plan_result.recommended_plan_id: expected number", "unknown field extra"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err.Error(), want)
+		}
+	}
+}
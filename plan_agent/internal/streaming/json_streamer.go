@@ -23,6 +23,9 @@ type JSONStreamer struct {
 	mu       sync.Mutex
 	sequence int64
 	threadID string
+
+	// lastEmit feeds the stall watchdog.
+	lastEmit time.Time
 }
 
 func NewJSONStreamer(enabled bool, w io.Writer) *JSONStreamer {
@@ -79,6 +82,20 @@ func (s *JSONStreamer) EmitAssistantMessage(turnID, preview string, toolCalls in
 	s.emit("assistant.message", payload)
 }
 
+// EmitPollTick reports one poll of a running branch, so consumers can see
+// progress between item.started and item.completed.
+func (s *JSONStreamer) EmitPollTick(branchID string, attempt int, elapsed time.Duration, status string) {
+	if !s.Enabled() {
+		return
+	}
+	s.emit("poll.tick", map[string]any{
+		"branch_id":  branchID,
+		"attempt":    attempt,
+		"elapsed_ms": elapsed.Milliseconds(),
+		"status":     status,
+	})
+}
+
 func (s *JSONStreamer) EmitThreadCompleted(status, summary string, finalReport map[string]any) {
 	if !s.Enabled() {
 		return
@@ -109,6 +126,25 @@ func (s *JSONStreamer) EmitError(scope, message string, extra map[string]any) {
 	s.emit("error", payload)
 }
 
+// EmitStallWarning reports that the run has been quiet for idle, so consumers
+// can tell a slow branch poll from a dead process.
+func (s *JSONStreamer) EmitStallWarning(idle time.Duration) {
+	if !s.Enabled() {
+		return
+	}
+	s.emit("thread.stall_warning", map[string]any{"idle_ms": idle.Milliseconds()})
+}
+
+// LastEmit returns the time of the most recent event; zero before the first one.
+func (s *JSONStreamer) LastEmit() time.Time {
+	if !s.Enabled() {
+		return time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEmit
+}
+
 func (s *JSONStreamer) emit(eventType string, payload map[string]any) {
 	if !s.Enabled() {
 		return
@@ -116,6 +152,7 @@ func (s *JSONStreamer) emit(eventType string, payload map[string]any) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.sequence++
+	s.lastEmit = time.Now()
 
 	envelope := make(map[string]any, len(payload)+4)
 	for k, v := range payload {
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type recordingClient struct {
+	agentClient
+	reads []string
+}
+
+func (c *recordingClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	c.reads = append(c.reads, filePath)
+	return map[string]any{"content": "ok"}, nil
+}
+
+func TestAllowedArtifactsGateReads(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"review-map.md", ".env", "docs/design.md", "docs/notes.txt"} {
+		full := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client := &recordingClient{}
+	h := NewToolHandler(client, "proj", "start", dir, nil)
+	if err := h.SetAllowedArtifacts([]string{"*.md", " docs/*.txt", ""}); err != nil {
+		t.Fatalf("SetAllowedArtifacts error: %v", err)
+	}
+
+	for path, allowed := range map[string]bool{
+		"review-map.md":                      true,
+		"docs/design.md":                     true,
+		filepath.Join(dir, "docs/notes.txt"): true,
+		".env":                               false,
+		"docs/../.env":                       false,
+	} {
+		_, err := h.readLocalFile(map[string]any{"path": path})
+		if denied := err != nil && strings.Contains(err.Error(), "not permitted"); denied == allowed {
+			t.Fatalf("read_file %q: allowed=%v, got err=%v", path, allowed, err)
+		}
+	}
+
+	for path, allowed := range map[string]bool{
+		filepath.Join(dir, "code_review.md"):  true,
+		filepath.Join(dir, "docs/notes.txt"):  true,
+		"docs/notes.txt":                      true,
+		filepath.Join(dir, ".env"):            false,
+		filepath.Join(dir, "other/notes.txt"): false,
+		"/elsewhere/docs/notes.txt":           false,
+	} {
+		_, err := h.readArtifact(map[string]any{"branch_id": "b", "path": path})
+		if denied := err != nil; denied == allowed {
+			t.Fatalf("read_artifact %q: allowed=%v, got err=%v", path, allowed, err)
+		}
+	}
+	if len(client.reads) != 3 {
+		t.Fatalf("expected only the allowed artifacts to reach the client, got %v", client.reads)
+	}
+
+	if err := h.SetAllowedArtifacts([]string{"[oops"}); err == nil {
+		t.Fatal("expected a malformed glob to be rejected")
+	}
+}
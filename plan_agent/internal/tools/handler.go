@@ -10,12 +10,15 @@ import (
 
 	"plan_agent/internal/config"
 	"plan_agent/internal/logx"
+	"plan_agent/internal/streaming"
 )
 
 type ToolExecutionError struct {
 	Msg         string
 	Instruction string
 	Details     map[string]any
+	// Kind classifies the failure (see ErrorKindMCP); empty when unclassified.
+	Kind string
 }
 
 func (e ToolExecutionError) Error() string { return e.Msg }
@@ -31,7 +34,6 @@ var _ agentClient = (*MCPClient)(nil)
 
 const (
 	instructionFinishedWithErr = "FINISHED_WITH_ERROR"
-	reviewArtifactName         = "code_review.log"
 	reviewMaxAttempts          = 3
 	defaultPollTimeout         = 60 * time.Minute
 	defaultPollInitial         = 3 * time.Second
@@ -62,17 +64,29 @@ func (t *BranchTracker) Range() map[string]string {
 	return map[string]string{"start_branch_id": t.start, "latest_branch_id": t.latest}
 }
 
+// DefaultReviewArtifactName is the review log review_code must write when no
+// REVIEW_ARTIFACT_NAME is configured.
+const DefaultReviewArtifactName = "code_review.log"
+
 type ToolHandler struct {
-	client        agentClient
-	defaultProj   string
-	branchTracker *BranchTracker
-	workspaceDir  string
-	pollTimeout   time.Duration
-	pollInitial   time.Duration
-	pollMax       time.Duration
-	pollBackoff   float64
-	nowFunc       func() time.Time
-	sleepFunc     func(time.Duration)
+	client         agentClient
+	defaultProj    string
+	branchTracker  *BranchTracker
+	workspaceDir   string
+	reviewArtifact string
+	pollTimeout    time.Duration
+	pollInitial    time.Duration
+	pollMax        time.Duration
+	pollBackoff    float64
+	nowFunc        func() time.Time
+	sleepFunc      func(time.Duration)
+	transformers   []ResponseTransformer
+
+	// streamer receives poll.tick events while checkStatus waits; nil disables them.
+	streamer *streaming.JSONStreamer
+	// allowedArtifacts are the globs read_file and read_artifact paths must match;
+	// empty allows all.
+	allowedArtifacts []string
 }
 
 type ToolHandlerTiming struct {
@@ -117,21 +131,46 @@ func NewToolHandler(client agentClient, defaultProject string, startBranch strin
 
 func NewToolHandlerWithConfig(client agentClient, cfg *config.AgentConfig, startBranch string) *ToolHandler {
 	return &ToolHandler{
-		client:        client,
-		defaultProj:   cfg.ProjectName,
-		branchTracker: NewBranchTracker(startBranch),
-		workspaceDir:  strings.TrimSpace(cfg.WorkspaceDir),
-		pollTimeout:   cfg.PollTimeout,
-		pollInitial:   cfg.PollInitial,
-		pollMax:       cfg.PollMax,
-		pollBackoff:   cfg.PollBackoffFactor,
-		nowFunc:       time.Now,
-		sleepFunc:     time.Sleep,
+		client:         client,
+		defaultProj:    cfg.ProjectName,
+		branchTracker:  NewBranchTracker(startBranch),
+		workspaceDir:   strings.TrimSpace(cfg.WorkspaceDir),
+		reviewArtifact: strings.TrimSpace(cfg.ReviewArtifactName),
+		pollTimeout:    cfg.PollTimeout,
+		pollInitial:    cfg.PollInitial,
+		pollMax:        cfg.PollMax,
+		pollBackoff:    cfg.PollBackoffFactor,
+		nowFunc:        time.Now,
+		sleepFunc:      time.Sleep,
 	}
 }
 
 func (h *ToolHandler) BranchRange() map[string]string { return h.branchTracker.Range() }
 
+// SetStreamer makes checkStatus emit a poll.tick event on each poll of a branch.
+func (h *ToolHandler) SetStreamer(streamer *streaming.JSONStreamer) {
+	h.streamer = streamer
+}
+
+// ResponseTransformer rewrites the response text extracted from an agent run, e.g.
+// to strip ANSI codes or redact sandbox paths, before callers see it.
+type ResponseTransformer func(agent, response string) string
+
+// AddResponseTransformer registers fn to post-process agent responses. Transformers
+// run in registration order; register them before the handler is in use.
+func (h *ToolHandler) AddResponseTransformer(fn ResponseTransformer) {
+	if fn != nil {
+		h.transformers = append(h.transformers, fn)
+	}
+}
+
+func (h *ToolHandler) transformResponse(agent, response string) string {
+	for _, fn := range h.transformers {
+		response = fn(agent, response)
+	}
+	return response
+}
+
 func (h *ToolHandler) StartBranchID() string {
 	if h.branchTracker == nil {
 		return ""
@@ -257,27 +296,35 @@ func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, er
 	return result, err
 }
 
-func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
-	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
-	resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
-	if err != nil {
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
-			Instruction: instructionFinishedWithErr,
-		}
-	}
-	if isErr, ok := resp["isError"].(bool); ok && isErr {
-		errMsg := resp["error"]
-		if errMsg == nil {
-			return nil, "", ToolExecutionError{
-				Msg:         fmt.Sprintf("ParallelExplore returned error (details: %v)", resp),
+// startAgent issues parallel_explore, re-issuing it while the server reports a
+// retryable error.
+func (h *ToolHandler) startAgent(agent, project, parent, prompt string) (map[string]any, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
+		if err != nil {
+			return nil, ToolExecutionError{
+				Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
 				Instruction: instructionFinishedWithErr,
 			}
 		}
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore returned error: %v", errMsg),
-			Instruction: instructionFinishedWithErr,
+		if isErr, ok := resp["isError"].(bool); !ok || !isErr {
+			return resp, nil
+		}
+		te := newMCPToolError(resp)
+		if te.Kind != ErrorKindMCPRetryable || attempt >= mcpRetryAttempts {
+			return nil, te
 		}
+		wait := mcpRetryWait(attempt)
+		logx.Warningf("ParallelExplore reported a retryable error (attempt %d/%d): %s. Retrying in %ds...", attempt, mcpRetryAttempts, te.Msg, int(wait.Seconds()))
+		h.sleep(wait)
+	}
+}
+
+func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
+	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
+	resp, err := h.startAgent(agent, project, parent, prompt)
+	if err != nil {
+		return nil, "", err
 	}
 	branchID := ExtractBranchID(resp)
 	if branchID == "" {
@@ -325,7 +372,7 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	if strings.TrimSpace(responseText) == "" {
 		return nil, "", ToolExecutionError{Msg: "branch_output returned no textual output"}
 	}
-	result["response"] = strings.TrimSpace(responseText)
+	result["response"] = h.transformResponse(agent, strings.TrimSpace(responseText))
 
 	return result, branchID, nil
 }
@@ -333,7 +380,7 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[string]any, error) {
 	artifactPath := h.reviewLogPath()
 	if artifactPath == "" {
-		return nil, ToolExecutionError{Msg: "workspace directory not configured for review_code validation"}
+		return nil, ToolExecutionError{Msg: fmt.Sprintf("workspace dir is not configured, so review_code's %s cannot be checked: set WORKSPACE_DIR to the agent's workspace path", h.ReviewArtifactName())}
 	}
 	var lastBranch string
 	for attempt := 1; attempt <= reviewMaxAttempts; attempt++ {
@@ -374,7 +421,15 @@ func (h *ToolHandler) reviewLogPath() string {
 	if strings.TrimSpace(h.workspaceDir) == "" {
 		return ""
 	}
-	return filepath.Join(h.workspaceDir, reviewArtifactName)
+	return filepath.Join(h.workspaceDir, h.ReviewArtifactName())
+}
+
+// ReviewArtifactName is the file name review_code runs are expected to write.
+func (h *ToolHandler) ReviewArtifactName() string {
+	if h.reviewArtifact != "" {
+		return h.reviewArtifact
+	}
+	return DefaultReviewArtifactName
 }
 
 func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, error) {
@@ -398,6 +453,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 	deadline := h.now().Add(timeout)
 	sleep := poll
 
+	pollStart := h.now()
 	attemptNum := 0
 
 	for {
@@ -421,6 +477,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 		}
 
 		status := stringsLower(resp["status"])
+		h.streamer.EmitPollTick(branchID, attemptNum, h.now().Sub(pollStart), status)
 		logx.Infof("Branch %s current status: %s", branchID, status)
 		if status == "succeed" {
 			logx.Infof("Branch %s completed successfully", branchID)
@@ -465,6 +522,9 @@ func (h *ToolHandler) readArtifact(arguments map[string]any) (map[string]any, er
 	if branchID == "" || path == "" {
 		return nil, ToolExecutionError{Msg: "`branch_id` and `path` are required"}
 	}
+	if err := h.checkArtifactAllowed("read_artifact", path, h.workspaceRelative(path)); err != nil {
+		return nil, err
+	}
 	return h.client.BranchReadFile(branchID, path)
 }
 
@@ -485,43 +545,6 @@ func (h *ToolHandler) branchOutput(arguments map[string]any) (map[string]any, er
 	return h.client.BranchOutput(branchID, fullOutput)
 }
 
-func ExtractBranchID(m map[string]any) string {
-	if m == nil {
-		return ""
-	}
-	if pe, ok := m["parallel_explore"].(map[string]any); ok {
-		if branches, ok := pe["branches"].([]any); ok {
-			for _, item := range branches {
-				if nested, _ := item.(map[string]any); nested != nil {
-					if id := ExtractBranchID(nested); id != "" {
-						return id
-					}
-				}
-			}
-		}
-	}
-	if branches, ok := m["branches"].([]any); ok {
-		for _, item := range branches {
-			if nested, _ := item.(map[string]any); nested != nil {
-				if id := ExtractBranchID(nested); id != "" {
-					return id
-				}
-			}
-		}
-	}
-	if b, ok := m["branch"].(map[string]any); ok {
-		if id := ExtractBranchID(b); id != "" {
-			return id
-		}
-	}
-	for _, k := range []string{"branch_id", "id"} {
-		if v, ok := m[k].(string); ok && v != "" {
-			return v
-		}
-	}
-	return ""
-}
-
 func branchOutputString(payload map[string]any) string {
 	if payload == nil {
 		return ""
@@ -547,6 +570,9 @@ func (h *ToolHandler) errorPayload(err error) map[string]any {
 		if len(te.Details) > 0 {
 			payload["details"] = te.Details
 		}
+		if te.Kind != "" {
+			payload["kind"] = te.Kind
+		}
 		if len(payload) == 0 {
 			payload["message"] = "tool execution error"
 		}
@@ -650,11 +676,16 @@ func (h *ToolHandler) readLocalFile(arguments map[string]any) (map[string]any, e
 	}
 	absPath = filepath.Clean(absPath)
 
+	relPath := absPath
 	if h.workspaceDir != "" {
 		wsAbs := filepath.Clean(h.workspaceDir)
 		if !strings.HasPrefix(absPath, wsAbs+string(filepath.Separator)) && absPath != wsAbs {
 			return nil, ToolExecutionError{Msg: fmt.Sprintf("path %q is outside workspace directory", path)}
 		}
+		relPath, _ = filepath.Rel(wsAbs, absPath)
+	}
+	if err := h.checkArtifactAllowed("read_file", path, relPath); err != nil {
+		return nil, err
 	}
 
 	info, err := os.Stat(absPath)
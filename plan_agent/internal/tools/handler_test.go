@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeMCPClient struct {
+	parallelExploreCalls int
+	exploreErrors        []map[string]any
+}
+
+func (f *fakeMCPClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	f.parallelExploreCalls++
+	if len(f.exploreErrors) > 0 {
+		resp := f.exploreErrors[0]
+		f.exploreErrors = f.exploreErrors[1:]
+		return resp, nil
+	}
+	return map[string]any{"branch_id": fmt.Sprintf("branch-%d", f.parallelExploreCalls)}, nil
+}
+
+func (f *fakeMCPClient) GetBranch(branchID string) (map[string]any, error) {
+	return map[string]any{"id": branchID, "status": "succeed"}, nil
+}
+
+func (f *fakeMCPClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	return map[string]any{"content": ""}, nil
+}
+
+func (f *fakeMCPClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	return map[string]any{"output": ""}, nil
+}
+
+func TestStartAgentRetriesRetryableMCPError(t *testing.T) {
+	busy := map[string]any{
+		"isError": true,
+		"error":   map[string]any{"code": "capacity", "message": "no runners free", "retryable": true},
+	}
+	client := &fakeMCPClient{exploreErrors: []map[string]any{busy, busy}}
+	var sleeps []time.Duration
+	handler := &ToolHandler{client: client, branchTracker: NewBranchTracker("parent"), sleepFunc: func(d time.Duration) { sleeps = append(sleeps, d) }}
+
+	resp, err := handler.startAgent("codex", "proj", "parent", "prompt")
+	if err != nil {
+		t.Fatalf("startAgent returned error: %v", err)
+	}
+	if client.parallelExploreCalls != 3 || ExtractBranchID(resp) != "branch-3" {
+		t.Fatalf("expected the third parallel_explore call to succeed, got %d calls and %#v", client.parallelExploreCalls, resp)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(sleeps) != 2 || sleeps[0] != want[0] || sleeps[1] != want[1] {
+		t.Fatalf("sleeps=%v, want %v", sleeps, want)
+	}
+}
@@ -2,21 +2,38 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	b "review_agent/internal/brain"
 	cfg "review_agent/internal/config"
+	"review_agent/internal/cost"
+	"review_agent/internal/exitcodes"
+	"review_agent/internal/ghchecks"
 	"review_agent/internal/logx"
+	"review_agent/internal/progress"
 	"review_agent/internal/prreview"
 	"review_agent/internal/streaming"
 	t "review_agent/internal/tools"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-result" {
+		os.Exit(runValidateResult(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "prompts" {
+		os.Exit(runPrompts(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		os.Exit(runExplain(os.Args[2:]))
+	}
+
 	task := flag.String("task", "", "PR context / task description")
 	parent := flag.String("parent-branch-id", "", "Branch UUID to fork from (required)")
 	project := flag.String("project-name", "", "Override project name")
@@ -24,7 +41,44 @@ func main() {
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
 	skipScout := flag.Bool("skip-scout", true, "Skip the scout change analysis stage")
 	skipTester := flag.Bool("skip-tester", true, "Skip the tester and exchange verification stages")
-	flag.Parse()
+	focusPass := flag.Bool("focus-pass", false, "Run a cheap risk triage before the scout and prioritize its top areas (requires --skip-scout=false)")
+	misalignedPolicy := flag.String("misaligned-confirm-policy", prreview.MisalignedDrop, "When both roles confirm but misalign: drop or report_low_confidence")
+	gradedAlignment := flag.Bool("graded-alignment", false, "Ask the alignment check for a same/related/different relationship and score; related findings go to the exchange, different ones are dropped")
+	alignmentThreshold := flag.Float64("alignment-threshold", prreview.DefaultAlignmentThreshold, "Minimum graded alignment score (0-1) for a \"same\" relationship to confirm (with --graded-alignment)")
+	alignmentParseRetries := flag.Int("alignment-parse-retries", prreview.DefaultAlignmentParseRetries, "Re-prompt a malformed alignment reply for bare JSON this many times (0 disables); after that the transcripts are treated as not aligned instead of failing the issue")
+	verdictConfidence := flag.Float64("verdict-confidence-threshold", 0, "Minimum stated Confidence (0-1) for an explicit VERDICT marker to be final; lower-confidence markers fall back to LLM extraction over the whole transcript (0 accepts all)")
+	tieBreak := flag.String("tie-break", prreview.TieBreakConservative, "When the exchange ends with reviewer and tester disagreeing: conservative, trust_tester or trust_reviewer")
+	maxDuration := flag.Duration("max-duration", 0, "Abort LLM calls and stop waiting on agent branches once the review exceeds this duration (e.g. 30m); 0 disables the limit")
+	stallWarning := flag.Duration("stall-warning-interval", 5*time.Minute, "Report a stall warning after this long without any event or log line; 0 disables the watchdog")
+	severityPolicyPath := flag.String("severity-policy", "", "JSON or YAML file with the severity definitions rendered into the reviewer prompt (default: built-in P0/P1 bar)")
+	maxOpinionChars := flag.Int("max-opinion-chars", 0, "Condense each peer/self opinion in exchange prompts to about this many characters, keeping the verdict; 0 disables the cap")
+	reviewReportFile := flag.String("review-report-file", "", "Re-verify a saved code_review.log instead of running the scout and issue finder (requires --reviewer-branch-id)")
+	reviewerBranch := flag.String("reviewer-branch-id", "", "Branch that produced --review-report-file; verification forks from it")
+	maxAuxInputChars := flag.Int("max-aux-input-chars", prreview.DefaultMaxAuxInputChars, "Split review reports longer than this at section markers before the has_issue call, treating the report as an issue if any chunk has one")
+	jsonMode := flag.Bool("json-mode", false, "Request provider-enforced JSON (response_format json_object) for auxiliary triage/verdict/alignment calls; falls back when unsupported")
+	profile := flag.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	stance := flag.String("stance", prreview.DefaultStance, "Reviewer bias: skeptical (high precision), balanced or confirming (high recall)")
+	normalizeLanguage := flag.Bool("normalize-language", false, "Translate verification transcripts written mostly in Chinese/Japanese/Korean into English (one LLM pass) before parsing verdicts")
+	missingReviewLog := flag.String("treat-missing-review-log-as", prreview.MissingReviewLogError, "When review_code never writes its review log: error (fail the run) or clean (report a clean review if the agent's output says it found no issues)")
+	githubChecks := flag.Bool("github-checks", false, "Publish the result as a GitHub check run with an annotation per confirmed issue (requires --pr-url)")
+	prURL := flag.String("pr-url", "", "Pull request under review, e.g. https://github.com/<owner>/<repo>/pull/<n>; names it in the result headline and is where --github-checks attaches its check run")
+	changedFiles := flag.Int("changed-files", 0, "Number of files the change touches, e.g. from git diff --name-only; adds \"across N changed files\" to the result headline")
+	headSHA := flag.String("sha", "", "Commit to attach the check run to; defaults to the PR's head commit")
+	progressWebhook := flag.String("progress-webhook", "", "POST a JSON snapshot of the evolving result to this URL after the scout, the review and each verified issue, and when the run ends")
+	progressDebounce := flag.Duration("progress-debounce", progress.DefaultDebounce, "Minimum spacing between --progress-webhook POSTs; snapshots arriving sooner are coalesced into the latest")
+	includeBlame := flag.Bool("include-blame", false, "Have the scout label each high-risk hunk as new in this PR or pre-existing (git blame), and the issue finder prioritize new code")
+	commitRange := flag.String("commit-range", "", "Review only the commits in this range (<from>..<to>, e.g. abc123..def456) instead of the diff against the base branch's merge base")
+	scopeDir := flag.String("scope-dir", "", "Restrict the review to this repository subdirectory (e.g. services/api); the scout diff is filtered to it")
+	cacheDir := flag.String("cache-dir", "", "Reuse the branch of an identical focus, scout or finder run (same agent, prompt and parent branch) from an earlier invocation recorded in this directory")
+	cacheTTL := flag.Duration("cache-ttl", prreview.DefaultCacheTTL, "How long a --cache-dir entry stays reusable")
+	refreshCache := flag.Bool("refresh-cache", false, "Ignore existing --cache-dir entries and run every step, recording the new branches")
+	failOnIssues := flag.Bool("fail-on-issues", false, "Exit with status 2 when the review confirms a P0/P1 issue (with or without low confidence), so CI fails the change")
+	pricesFile := flag.String("prices-file", "", "prices.yaml with input_per_1k and output_per_1k (USD); enables estimated_cost_usd in the result")
+	priceIn := flag.Float64("price-per-1k-input", 0, "USD per 1K prompt tokens; overrides --prices-file")
+	priceOut := flag.Float64("price-per-1k-output", 0, "USD per 1K completion tokens; overrides --prices-file")
+	pins := pinFlags{}
+	flag.Var(pins, "pin-step", "Reuse a prior branch's output for a step instead of running it, e.g. scout=branch:<id> (repeatable; steps: focus, scout, finder, reviewer, tester)")
+	exitcodes.ParseFlags()
 
 	streamEnabled := streamJSON != nil && *streamJSON
 	if streamEnabled {
@@ -32,10 +86,49 @@ func main() {
 		logx.SetLevel(logx.Error)
 	}
 
-	conf, err := cfg.FromEnv()
+	conf, err := cfg.Load(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
+	}
+
+	severityPolicy := prreview.DefaultSeverityPolicy()
+	if *severityPolicyPath != "" {
+		severityPolicy, err = prreview.LoadSeverityPolicy(*severityPolicyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+	}
+
+	reviewReport := ""
+	if *reviewReportFile != "" {
+		data, err := os.ReadFile(*reviewReportFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: read review report: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+		reviewReport = string(data)
+	}
+
+	var (
+		checkTarget ghchecks.Target
+		subject     string
+	)
+	if *githubChecks {
+		if *prURL == "" {
+			fmt.Fprintln(os.Stderr, "--github-checks requires --pr-url")
+			os.Exit(exitcodes.Usage)
+		}
+		checkTarget, err = ghchecks.ParsePRURL(*prURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitcodes.Usage)
+		}
+		checkTarget.SHA = strings.TrimSpace(*headSHA)
+	}
+	if target, err := ghchecks.ParsePRURL(*prURL); err == nil {
+		subject = fmt.Sprintf("PR #%d", target.PRNumber)
 	}
 
 	if *project != "" {
@@ -43,11 +136,36 @@ func main() {
 	}
 	if conf.ProjectName == "" {
 		fmt.Fprintln(os.Stderr, "Project name required via PROJECT_NAME or --project-name")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 	if *parent == "" {
 		fmt.Fprintln(os.Stderr, "--parent-branch-id is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+	if *priceIn < 0 || *priceOut < 0 {
+		fmt.Fprintln(os.Stderr, "error: token prices must not be negative")
+		os.Exit(exitcodes.Usage)
+	}
+	if *alignmentParseRetries < 0 {
+		fmt.Fprintln(os.Stderr, "error: --alignment-parse-retries must not be negative")
+		os.Exit(exitcodes.Usage)
+	}
+	if *alignmentParseRetries == 0 {
+		// Options treats zero as "use the default"; a negative count disables re-prompting.
+		*alignmentParseRetries = -1
+	}
+	var prices cost.Prices
+	if *pricesFile != "" {
+		if prices, err = cost.LoadPrices(*pricesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+	}
+	if *priceIn > 0 {
+		prices.InputPer1K = *priceIn
+	}
+	if *priceOut > 0 {
+		prices.OutputPer1K = *priceOut
 	}
 
 	tsk := strings.TrimSpace(*task)
@@ -59,7 +177,11 @@ func main() {
 	}
 	if tsk == "" {
 		fmt.Fprintln(os.Stderr, "task description is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+
+	if conf.RunSubdir != "" {
+		fmt.Fprintf(os.Stderr, "Using per-run workspace: %s\n", conf.WorkspaceDir)
 	}
 
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
@@ -71,14 +193,48 @@ func main() {
 		streamer = streaming.NewJSONStreamer(true, os.Stdout)
 		streamer.EmitThreadStarted(tsk, conf.ProjectName, *parent, *headless)
 	}
+	handler.SetStreamer(streamer)
 
 	opts := prreview.Options{
-		Task:           tsk,
-		ProjectName:    conf.ProjectName,
-		ParentBranchID: *parent,
-		WorkspaceDir:   conf.WorkspaceDir,
-		SkipScout:      *skipScout,
-		SkipTester:     *skipTester,
+		Task:                       tsk,
+		ProjectName:                conf.ProjectName,
+		ParentBranchID:             *parent,
+		WorkspaceDir:               conf.WorkspaceDir,
+		SkipScout:                  *skipScout,
+		SkipTester:                 *skipTester,
+		FocusPass:                  *focusPass,
+		MisalignedConfirmPolicy:    *misalignedPolicy,
+		TieBreak:                   *tieBreak,
+		MaxDuration:                *maxDuration,
+		SeverityPolicy:             severityPolicy,
+		MaxOpinionChars:            *maxOpinionChars,
+		ReviewReport:               reviewReport,
+		ReviewerBranchID:           *reviewerBranch,
+		JSONMode:                   *jsonMode,
+		PinnedSteps:                pins,
+		ReviewArtifactName:         conf.ReviewArtifactName,
+		GradedAlignment:            *gradedAlignment,
+		AlignmentThreshold:         *alignmentThreshold,
+		AlignmentParseRetries:      *alignmentParseRetries,
+		VerdictConfidenceThreshold: *verdictConfidence,
+		AuxSystemPrompts:           conf.AuxSystemPrompts,
+		MaxAuxInputChars:           *maxAuxInputChars,
+		ScopeDir:                   *scopeDir,
+		IncludeBlame:               *includeBlame,
+		CommitRange:                *commitRange,
+		Stance:                     *stance,
+		TreatMissingReviewLogAs:    *missingReviewLog,
+		NormalizeLanguage:          *normalizeLanguage,
+		Subject:                    subject,
+		ChangedFiles:               *changedFiles,
+		CacheDir:                   *cacheDir,
+		CacheTTL:                   *cacheTTL,
+		RefreshCache:               *refreshCache,
+	}
+	var webhook *progress.Webhook
+	if *progressWebhook != "" {
+		webhook = progress.NewWebhook(*progressWebhook, *progressDebounce)
+		opts.OnProgress = func(phase string, partial *prreview.Result) { webhook.Notify(phase, partial) }
 	}
 	runner, err := prreview.NewRunner(brain, handler, streamer, opts)
 	if err != nil {
@@ -87,17 +243,35 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
+	watchdog := streaming.StartWatchdog(streamer, *stallWarning, logx.LastOutput, func(idle time.Duration) {
+		logx.Warningf("No output for %s; the run is still waiting on a branch or LLM call.", idle.Round(time.Second))
+	})
 	result, err := runner.Run()
+	watchdog.Stop()
+	if result != nil {
+		result.TokenUsage = brain.Usage()
+		if !prices.IsZero() {
+			result.EstimatedCostUSD = cost.Estimate(prices, result.TokenUsage)
+		}
+	}
+	if webhook != nil {
+		if err != nil {
+			webhook.Notify("failed", map[string]any{"error": err.Error()})
+		} else {
+			webhook.Notify("completed", result)
+		}
+		webhook.Close()
+	}
 	if err != nil {
 		if streamer != nil && streamer.Enabled() {
 			streamer.EmitError("workflow", err.Error(), nil)
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Code(err))
 	}
 
 	status := "completed"
@@ -106,13 +280,165 @@ func main() {
 	}
 	if streamer != nil && streamer.Enabled() && result != nil {
 		streamer.EmitThreadCompleted(status, result.Summary, map[string]any{
-			"task":    result.Task,
-			"status":  result.Status,
-			"summary": result.Summary,
-			"issues":  result.Issues,
+			"task":     result.Task,
+			"status":   result.Status,
+			"summary":  result.Summary,
+			"headline": result.Headline,
+			"issues":   result.Issues,
 		})
 	}
 
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
+
+	if *githubChecks && result != nil {
+		if err := publishCheckRun(conf, checkTarget, result); err != nil {
+			fmt.Fprintf(os.Stderr, "check run error: %v\n", err)
+			os.Exit(exitcodes.Runtime)
+		}
+	}
+	if *failOnIssues && result.HasFindings() {
+		os.Exit(exitcodes.Findings)
+	}
+}
+
+// publishCheckRun attaches result to the target commit as a GitHub check run,
+// resolving the PR's head commit when no --sha was given.
+func publishCheckRun(conf cfg.AgentConfig, target ghchecks.Target, result *prreview.Result) error {
+	ctx := context.Background()
+	client := ghchecks.NewClient(conf.GitHubAPIURL, conf.GitHubToken)
+	if target.SHA == "" {
+		sha, err := client.HeadSHA(ctx, target)
+		if err != nil {
+			return err
+		}
+		target.SHA = sha
+	}
+	checkURL, err := client.Publish(ctx, target, result)
+	if err != nil {
+		return err
+	}
+	logx.Infof("Published check run for %s: %s", target.SHA, checkURL)
+	return nil
+}
+
+// pinFlags collects repeated --pin-step values keyed by step.
+type pinFlags map[string]string
+
+func (p pinFlags) String() string {
+	parts := make([]string, 0, len(p))
+	for step, branchID := range p {
+		parts = append(parts, step+"=branch:"+branchID)
+	}
+	return strings.Join(parts, ",")
+}
+
+func (p pinFlags) Set(value string) error {
+	step, branchID, err := prreview.ParsePinnedStep(value)
+	if err != nil {
+		return err
+	}
+	p[step] = branchID
+	return nil
+}
+
+// runValidateResult implements the `validate-result --file X` subcommand.
+func runValidateResult(args []string) int {
+	fs := flag.NewFlagSet("validate-result", flag.ContinueOnError)
+	file := fs.String("file", "", "Path to a result JSON file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return exitcodes.Usage
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	if err := prreview.ValidateResult(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Runtime
+	}
+	fmt.Fprintf(os.Stderr, "%s: valid result\n", *file)
+	return exitcodes.Success
+}
+
+// runPrompts implements the `prompts --stage X` subcommand, printing the rendered
+// prompt so it can be diffed against the v1.1 binary's output.
+func runPrompts(args []string) int {
+	fs := flag.NewFlagSet("prompts", flag.ContinueOnError)
+	version := fs.String("version", prreview.PromptVersion, "Prompt generation to render; this binary renders "+prreview.PromptVersion)
+	stage := fs.String("stage", prreview.StageFinder, "Prompt to render: finder, scout, reviewer or tester")
+	task := fs.String("task", "", "Task text to render into the prompt (default: a built-in sample)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if v := strings.TrimSpace(*version); v != prreview.PromptVersion {
+		fmt.Fprintf(os.Stderr, "this binary renders %s prompts; build review_agent_v1.1/cmd/review-agent to render %s\n", prreview.PromptVersion, v)
+		return exitcodes.Usage
+	}
+	prompt, err := prreview.RenderPrompt(*stage, *task)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Usage
+	}
+	fmt.Println(prompt)
+	return exitcodes.Success
+}
+
+// runExplain implements the `explain --issue-file X` subcommand: it prints why each
+// saved issue report was confirmed or dropped, without calling any agent.
+func runExplain(args []string) int {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	file := fs.String("issue-file", "", "Path to a saved issue report or result JSON file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--issue-file is required")
+		return exitcodes.Usage
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	reports, err := prreview.LoadIssueReports(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	if len(reports) == 0 {
+		fmt.Fprintf(os.Stderr, "%s: no issues to explain\n", *file)
+		return exitcodes.Success
+	}
+	for i, report := range reports {
+		if i > 0 {
+			fmt.Println()
+		}
+		if len(reports) > 1 {
+			fmt.Printf("## Issue %d of %d\n", i+1, len(reports))
+		}
+		fmt.Print(prreview.ExplainIssue(report))
+	}
+	return exitcodes.Success
 }
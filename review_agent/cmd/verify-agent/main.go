@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	cfg "review_agent/internal/config"
+	"review_agent/internal/exitcodes"
 	"review_agent/internal/logx"
 	"review_agent/internal/prreview"
 	"review_agent/internal/streaming"
@@ -23,7 +24,10 @@ func main() {
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
 	flag.String("code-context", "", "Optional: additional code context")
 	flag.Bool("false-positive", false, "Treat bug as false positive (虚假报警) - agent will try to refute it")
-	flag.Parse()
+	severityPolicyPath := flag.String("severity-policy", "", "JSON or YAML file with the severity definitions rendered into the reviewer prompt (default: built-in P0/P1 bar)")
+	stanceFlag := flag.String("stance", prreview.DefaultStance, "Reviewer bias: skeptical (high precision), balanced or confirming (high recall)")
+	profile := flag.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	exitcodes.ParseFlags()
 
 	streamEnabled := streamJSON != nil && *streamJSON
 	if streamEnabled {
@@ -31,10 +35,25 @@ func main() {
 		logx.SetLevel(logx.Error)
 	}
 
-	conf, err := cfg.FromEnv()
+	conf, err := cfg.Load(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
+	}
+
+	severityPolicy := prreview.DefaultSeverityPolicy()
+	if *severityPolicyPath != "" {
+		severityPolicy, err = prreview.LoadSeverityPolicy(*severityPolicyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+	}
+
+	stance, err := prreview.ParseStance(*stanceFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitcodes.Usage)
 	}
 
 	if *project != "" {
@@ -42,11 +61,11 @@ func main() {
 	}
 	if conf.ProjectName == "" {
 		fmt.Fprintln(os.Stderr, "Project name required via PROJECT_NAME or --project-name")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 	if *parent == "" {
 		fmt.Fprintln(os.Stderr, "--parent-branch-id is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 
 	bug := strings.TrimSpace(*bugDesc)
@@ -58,7 +77,7 @@ func main() {
 	}
 	if bug == "" {
 		fmt.Fprintln(os.Stderr, "bug text is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 
 	var streamer *streaming.JSONStreamer
@@ -67,10 +86,11 @@ func main() {
 		streamer.EmitThreadStarted(bug, conf.ProjectName, *parent, *headless)
 	}
 
-	prompt := prreview.BuildLogicAnalystPrompt(bug)
+	prompt := prreview.BuildLogicAnalystPrompt(bug, severityPolicy, stance)
 
 	mcp := t.NewMCPClient(conf.MCPBaseURL)
 	handler := t.NewToolHandlerWithConfig(mcp, &conf, *parent)
+	handler.SetStreamer(streamer)
 
 	branchID, analysis, err := executeOnce(handler, "codex", prompt, conf.ProjectName, *parent)
 	if err != nil {
@@ -79,7 +99,7 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Code(err))
 	}
 
 	if streamer != nil && streamer.Enabled() {
@@ -0,0 +1,96 @@
+package brain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestCompleteJSONModeFallsBackWhenRejected(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		formats []any
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		formats = append(formats, body["response_format"])
+		mu.Unlock()
+		if body["response_format"] != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Unrecognized request argument supplied: response_format"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"has_issue\":true}"}}]}`))
+	}))
+	defer srv.Close()
+
+	brain := NewLLMBrain("key", srv.URL, "dep", "2024-12-01-preview", 1)
+	msgs := []ChatMessage{{Role: "user", Content: "hi"}}
+	resp, err := brain.Complete(context.Background(), msgs, nil, WithJSONMode())
+	if err != nil {
+		t.Fatalf("expected fallback to succeed, got %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != `{"has_issue":true}` {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if _, err := brain.Complete(context.Background(), msgs, nil, WithJSONMode()); err != nil {
+		t.Fatalf("second call failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(formats) != 3 {
+		t.Fatalf("expected 3 requests (rejected, fallback, remembered), got %d", len(formats))
+	}
+	if formats[0] == nil || formats[1] != nil || formats[2] != nil {
+		t.Fatalf("unexpected response_format sequence: %v", formats)
+	}
+}
+
+func TestRequireNonEmptyChoice(t *testing.T) {
+	var blank, filled chatCompletionResponse
+	if err := json.Unmarshal([]byte(`{"choices":[{"message":{"role":"assistant","content":"  \n"}}]}`), &blank); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"has_issue\": true}"}}]}`), &filled); err != nil {
+		t.Fatal(err)
+	}
+	for name, resp := range map[string]*chatCompletionResponse{"nil": nil, "no choices": {}, "blank": &blank} {
+		if _, err := RequireNonEmptyChoice(resp); !errors.Is(err, ErrEmptyContent) {
+			t.Errorf("%s: expected ErrEmptyContent, got %v", name, err)
+		}
+	}
+	content, err := RequireNonEmptyChoice(&filled)
+	if err != nil || content != `{"has_issue": true}` {
+		t.Fatalf("expected the first choice's content, got %q (%v)", content, err)
+	}
+}
+
+func TestCompleteCountsUsageByCallKind(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":120,"completion_tokens":30}}`))
+	}))
+	defer srv.Close()
+
+	brain := NewLLMBrain("key", srv.URL, "dep", "2024-12-01-preview", 1)
+	msgs := []ChatMessage{{Role: "user", Content: "hi"}}
+	aux := WithCallKind(context.Background(), CallAuxiliary)
+	for _, ctx := range []context.Context{aux, aux, context.Background()} {
+		if _, err := brain.Complete(ctx, msgs, nil); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+	}
+	usage := brain.Usage()
+	if got := usage[CallAuxiliary]; got != (Usage{PromptTokens: 240, CompletionTokens: 60, Calls: 2}) {
+		t.Fatalf("unexpected auxiliary usage %+v", got)
+	}
+	if got := usage[CallOrchestration]; got != (Usage{PromptTokens: 120, CompletionTokens: 30, Calls: 1}) {
+		t.Fatalf("unexpected orchestration usage %+v", got)
+	}
+}
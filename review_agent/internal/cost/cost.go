@@ -0,0 +1,57 @@
+// Package cost turns the token usage of a run into a dollar estimate from a
+// per-1K-token price table.
+package cost
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	b "review_agent/internal/brain"
+	"review_agent/internal/miniyaml"
+)
+
+// Prices is the USD cost of 1,000 prompt (input) and completion (output) tokens.
+type Prices struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+}
+
+// IsZero reports whether no price was configured.
+func (p Prices) IsZero() bool { return p.InputPer1K == 0 && p.OutputPer1K == 0 }
+
+// LoadPrices reads a prices.yaml with input_per_1k and output_per_1k keys,
+// e.g. "input_per_1k: 0.0025".
+func LoadPrices(path string) (Prices, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Prices{}, fmt.Errorf("read prices: %w", err)
+	}
+	var p Prices
+	if err := miniyaml.Unmarshal(data, &p); err != nil {
+		return Prices{}, fmt.Errorf("parse prices %s: %w", path, err)
+	}
+	if p.InputPer1K < 0 || p.OutputPer1K < 0 {
+		return Prices{}, fmt.Errorf("prices %s: prices must not be negative", path)
+	}
+	return p, nil
+}
+
+// Estimate prices usage per call kind; the "total" key sums them. Agent branches
+// run on the MCP server do not report tokens and are not included.
+func Estimate(p Prices, usage map[string]b.Usage) map[string]float64 {
+	out := map[string]float64{"total": 0}
+	for kind, u := range usage {
+		usd := roundUSD(float64(u.PromptTokens)/1000*p.InputPer1K + float64(u.CompletionTokens)/1000*p.OutputPer1K)
+		out[kind] = usd
+		out["total"] += usd
+	}
+	out["total"] = roundUSD(out["total"])
+	return out
+}
+
+// roundUSD keeps four decimals: enough for sub-cent auxiliary calls without
+// printing float noise.
+func roundUSD(usd float64) float64 {
+	return math.Round(usd*10000) / 10000
+}
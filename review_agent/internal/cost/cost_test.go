@@ -0,0 +1,48 @@
+package cost
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	b "review_agent/internal/brain"
+)
+
+func TestEstimateBreaksDownByCallKind(t *testing.T) {
+	prices := Prices{InputPer1K: 0.0025, OutputPer1K: 0.01}
+	got := Estimate(prices, map[string]b.Usage{
+		b.CallOrchestration: {PromptTokens: 200000, CompletionTokens: 10000, Calls: 12},
+		b.CallAuxiliary:     {PromptTokens: 4000, CompletionTokens: 500, Calls: 2},
+	})
+	if got[b.CallOrchestration] != 0.6 || got[b.CallAuxiliary] != 0.015 || got["total"] != 0.615 {
+		t.Fatalf("unexpected estimate %v", got)
+	}
+}
+
+func TestLoadPrices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	if err := os.WriteFile(path, []byte("# gpt deployment\ninput_per_1k: 0.0025 # USD\noutput_per_1k: \"0.01\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prices, err := LoadPrices(path)
+	if err != nil {
+		t.Fatalf("LoadPrices error: %v", err)
+	}
+	if prices != (Prices{InputPer1K: 0.0025, OutputPer1K: 0.01}) {
+		t.Fatalf("unexpected prices %+v", prices)
+	}
+
+	if err := os.WriteFile(path, []byte("input_per_1M: 2.5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrices(path); err == nil {
+		t.Fatal("expected an error for an unknown key")
+	}
+
+	if err := os.WriteFile(path, []byte("input_per_1k: -1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrices(path); err == nil {
+		t.Fatal("expected an error for a negative price")
+	}
+}
@@ -0,0 +1,65 @@
+// Package exitcodes defines the process exit statuses shared by the agent
+// binaries, so shell and CI callers can tell a setup problem (fix the
+// environment) from a failed run (retry) from a run that reported findings.
+package exitcodes
+
+import (
+	"errors"
+	"flag"
+	"os"
+)
+
+const (
+	Success  = 0
+	Runtime  = 1
+	Findings = 2
+	Usage    = 64 // EX_USAGE from sysexits.h
+	Config   = 78 // EX_CONFIG from sysexits.h
+	// Interrupted is the shell's 128+SIGINT, for a run stopped by a signal that
+	// still wrote its partial results.
+	Interrupted = 130
+)
+
+// ConfigError marks a failure caused by configuration rather than by the run
+// itself; Code maps it to Config wherever it surfaces.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string { return e.Err.Error() }
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// NewConfigError wraps err as a ConfigError; nil stays nil.
+func NewConfigError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ConfigError{Err: err}
+}
+
+// Code returns the exit status for err: Success for nil, Config for a
+// ConfigError anywhere in the chain, Runtime otherwise.
+func Code(err error) int {
+	if err == nil {
+		return Success
+	}
+	var cfgErr *ConfigError
+	if errors.As(err, &cfgErr) {
+		return Config
+	}
+	return Runtime
+}
+
+// ParseFlags is flag.Parse with exit statuses that don't collide with
+// Findings: -h exits with Success and malformed flags with Usage, where the
+// flag package would exit 2 for both.
+func ParseFlags() {
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(Success)
+		}
+		os.Exit(Usage)
+	}
+}
@@ -0,0 +1,353 @@
+// Package ghchecks publishes review results as a GitHub check run, attaching
+// each reported issue with a file:line anchor as an annotation.
+package ghchecks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"review_agent/internal/prreview"
+)
+
+// CheckName is the name the check run is created under.
+const CheckName = "review-agent"
+
+// maxAnnotationsPerRequest is the GitHub Checks API limit on annotations in a
+// single create or update call; larger sets are sent in batches.
+const maxAnnotationsPerRequest = 50
+
+// GitHub rejects output fields past these lengths.
+const (
+	maxTitleLen   = 255
+	maxMessageLen = 64 * 1024
+	maxSummaryLen = 65535
+)
+
+// Annotation levels accepted by the Checks API.
+const (
+	LevelNotice  = "notice"
+	LevelWarning = "warning"
+	LevelFailure = "failure"
+)
+
+// Target is the commit a check run is attached to.
+type Target struct {
+	Owner    string
+	Repo     string
+	PRNumber int
+	SHA      string
+}
+
+var prURLPath = regexp.MustCompile(`^/([^/]+)/([^/]+)/pull/(\d+)/?$`)
+
+// ParsePRURL reads owner, repository and PR number from a pull request URL such
+// as https://github.com/acme/api/pull/42.
+func ParsePRURL(raw string) (Target, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return Target{}, fmt.Errorf("parse PR URL: %w", err)
+	}
+	m := prURLPath.FindStringSubmatch(u.Path)
+	if u.Host == "" || m == nil {
+		return Target{}, fmt.Errorf("PR URL %q: want https://<host>/<owner>/<repo>/pull/<number>", raw)
+	}
+	number, _ := strconv.Atoi(m[3])
+	return Target{Owner: m[1], Repo: m[2], PRNumber: number}, nil
+}
+
+// Annotation is one entry of a check run's output.annotations.
+type Annotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+var (
+	anchorLine = regexp.MustCompile(`(?im)^\s*[*_-]*\s*anchor\s*[*_]*\s*:\s*(.+)$`)
+	fileLine   = regexp.MustCompile(`([A-Za-z0-9_./-]+\.[A-Za-z0-9]+):(\d+)(?:-(\d+))?`)
+	severity   = regexp.MustCompile(`\bP([0-3])\b`)
+)
+
+// ParseAnchor finds the file:line an issue points at, preferring an explicit
+// "Anchor:" line over the first file:line mentioned anywhere in the text.
+func ParseAnchor(text string) (path string, start, end int, ok bool) {
+	candidates := []string{}
+	if m := anchorLine.FindStringSubmatch(text); m != nil {
+		candidates = append(candidates, m[1])
+	}
+	candidates = append(candidates, text)
+	for _, candidate := range candidates {
+		m := fileLine.FindStringSubmatch(candidate)
+		if m == nil {
+			continue
+		}
+		start, _ = strconv.Atoi(m[2])
+		if start == 0 {
+			continue
+		}
+		end = start
+		if m[3] != "" {
+			if n, _ := strconv.Atoi(m[3]); n >= start {
+				end = n
+			}
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(m[1], "./"), "/")
+		return path, start, end, true
+	}
+	return "", 0, 0, false
+}
+
+// annotationLevel maps the issue's severity label to an annotation level: P0 is
+// a failure, P1 a warning and anything else a notice. Low-confidence findings
+// never fail the check.
+func annotationLevel(issue prreview.IssueReport) string {
+	level := LevelNotice
+	if m := severity.FindStringSubmatch(issue.IssueText); m != nil {
+		switch m[1] {
+		case "0":
+			level = LevelFailure
+		case "1":
+			level = LevelWarning
+		}
+	}
+	if level == LevelFailure && issue.Status != prreview.StatusConfirmed {
+		level = LevelWarning
+	}
+	return level
+}
+
+// reportable reports whether the issue was confirmed, with or without full confidence.
+func reportable(issue prreview.IssueReport) bool {
+	return issue.Status == prreview.StatusConfirmed || issue.Status == prreview.StatusConfirmedLowConfidence
+}
+
+// BuildAnnotations turns the confirmed issues into annotations. Confirmed issues
+// without a file:line anchor are returned separately for the summary text.
+func BuildAnnotations(issues []prreview.IssueReport) ([]Annotation, []prreview.IssueReport) {
+	var (
+		annotations []Annotation
+		unanchored  []prreview.IssueReport
+	)
+	for _, issue := range issues {
+		if !reportable(issue) {
+			continue
+		}
+		path, start, end, ok := ParseAnchor(issue.IssueText)
+		if !ok {
+			unanchored = append(unanchored, issue)
+			continue
+		}
+		annotations = append(annotations, Annotation{
+			Path:            path,
+			StartLine:       start,
+			EndLine:         end,
+			AnnotationLevel: annotationLevel(issue),
+			Title:           truncate(issueTitle(issue), maxTitleLen),
+			Message:         truncate(strings.TrimSpace(issue.IssueText), maxMessageLen),
+		})
+	}
+	return annotations, unanchored
+}
+
+// Conclusion is "failure" when any issue was confirmed outright, "neutral" when
+// only low-confidence findings remain and "success" otherwise.
+func Conclusion(issues []prreview.IssueReport) string {
+	conclusion := "success"
+	for _, issue := range issues {
+		switch issue.Status {
+		case prreview.StatusConfirmed:
+			return "failure"
+		case prreview.StatusConfirmedLowConfidence:
+			conclusion = "neutral"
+		}
+	}
+	return conclusion
+}
+
+// Summary renders the check run's output summary: the review summary followed by
+// the confirmed issues that could not be placed on a line.
+func Summary(result *prreview.Result, unanchored []prreview.IssueReport) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(result.Summary))
+	sb.WriteString("\n")
+	if len(unanchored) > 0 {
+		sb.WriteString("\n## Issues without a file:line anchor\n")
+		for _, issue := range unanchored {
+			fmt.Fprintf(&sb, "\n### [%s] %s\n\n%s\n", issue.Status, issueTitle(issue), strings.TrimSpace(issue.IssueText))
+		}
+	}
+	return truncate(sb.String(), maxSummaryLen)
+}
+
+func issueTitle(issue prreview.IssueReport) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(issue.IssueText), "\n")
+	return strings.TrimSpace(line)
+}
+
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	const marker = "\n…(truncated)"
+	cut := limit - len(marker)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + marker
+}
+
+// Client talks to the GitHub REST API.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient returns a client for the API rooted at baseURL
+// (https://api.github.com for github.com).
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// HeadSHA returns the head commit of the target's pull request.
+func (c *Client) HeadSHA(ctx context.Context, target Target) (string, error) {
+	var pr struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", target.Owner, target.Repo, target.PRNumber)
+	if err := c.do(ctx, http.MethodGet, path, nil, &pr); err != nil {
+		return "", fmt.Errorf("fetch PR head: %w", err)
+	}
+	if pr.Head.SHA == "" {
+		return "", errors.New("fetch PR head: response has no head sha")
+	}
+	return pr.Head.SHA, nil
+}
+
+type checkOutput struct {
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Publish creates a check run on target.SHA for result and returns its URL. The
+// run is created in progress, annotations are attached in batches of 50, and a
+// final update sets the conclusion.
+func (c *Client) Publish(ctx context.Context, target Target, result *prreview.Result) (string, error) {
+	if target.SHA == "" {
+		return "", errors.New("check run needs a commit sha")
+	}
+	annotations, unanchored := BuildAnnotations(result.Issues)
+	output := checkOutput{
+		Title:   truncate(strings.TrimSpace(result.Summary), maxTitleLen),
+		Summary: Summary(result, unanchored),
+	}
+	if output.Title == "" {
+		output.Title = "Review " + result.Status
+	}
+	batches := batchAnnotations(annotations)
+
+	create := map[string]any{
+		"name":     CheckName,
+		"head_sha": target.SHA,
+		"status":   "in_progress",
+		"output":   withAnnotations(output, batches, 0),
+	}
+	var run struct {
+		ID      int64  `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+	runsPath := fmt.Sprintf("/repos/%s/%s/check-runs", target.Owner, target.Repo)
+	if err := c.do(ctx, http.MethodPost, runsPath, create, &run); err != nil {
+		return "", fmt.Errorf("create check run: %w", err)
+	}
+	runPath := fmt.Sprintf("%s/%d", runsPath, run.ID)
+	for i := 1; i < len(batches); i++ {
+		update := map[string]any{"output": withAnnotations(output, batches, i)}
+		if err := c.do(ctx, http.MethodPatch, runPath, update, nil); err != nil {
+			return run.HTMLURL, fmt.Errorf("add annotations batch %d/%d: %w", i+1, len(batches), err)
+		}
+	}
+	complete := map[string]any{
+		"status":     "completed",
+		"conclusion": Conclusion(result.Issues),
+		"output":     output,
+	}
+	if err := c.do(ctx, http.MethodPatch, runPath, complete, nil); err != nil {
+		return run.HTMLURL, fmt.Errorf("complete check run: %w", err)
+	}
+	return run.HTMLURL, nil
+}
+
+func batchAnnotations(annotations []Annotation) [][]Annotation {
+	var batches [][]Annotation
+	for len(annotations) > maxAnnotationsPerRequest {
+		batches = append(batches, annotations[:maxAnnotationsPerRequest])
+		annotations = annotations[maxAnnotationsPerRequest:]
+	}
+	if len(annotations) > 0 {
+		batches = append(batches, annotations)
+	}
+	return batches
+}
+
+func withAnnotations(output checkOutput, batches [][]Annotation, i int) checkOutput {
+	if i < len(batches) {
+		output.Annotations = batches[i]
+	}
+	return output
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return fmt.Errorf("GitHub HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,145 @@
+package ghchecks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"review_agent/internal/prreview"
+)
+
+func TestParsePRURL(t *testing.T) {
+	target, err := ParsePRURL("https://github.com/acme/api/pull/42")
+	if err != nil {
+		t.Fatalf("ParsePRURL error: %v", err)
+	}
+	if target.Owner != "acme" || target.Repo != "api" || target.PRNumber != 42 {
+		t.Fatalf("unexpected target: %+v", target)
+	}
+	for _, bad := range []string{"acme/api#42", "https://github.com/acme/api/issues/42", "https://github.com/acme/pull/42"} {
+		if _, err := ParsePRURL(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParseAnchorPrefersAnchorLine(t *testing.T) {
+	text := "ISSUE: P0 nil map write\nSee also docs/notes.md:3\nAnchor: ./internal/cache/put.go:120-124\n"
+	path, start, end, ok := ParseAnchor(text)
+	if !ok || path != "internal/cache/put.go" || start != 120 || end != 124 {
+		t.Fatalf("unexpected anchor: %q %d-%d ok=%v", path, start, end, ok)
+	}
+
+	path, start, end, ok = ParseAnchor("P1: retry loop in client/retry.go:88 never backs off")
+	if !ok || path != "client/retry.go" || start != 88 || end != 88 {
+		t.Fatalf("unexpected fallback anchor: %q %d-%d ok=%v", path, start, end, ok)
+	}
+
+	if _, _, _, ok := ParseAnchor("P1: retry loop never backs off\nAnchor: unknown"); ok {
+		t.Fatal("expected no anchor")
+	}
+}
+
+func TestBuildAnnotationsLevelsAndUnanchored(t *testing.T) {
+	issues := []prreview.IssueReport{
+		{IssueText: "P0: nil map write\nAnchor: cache.go:10", Status: prreview.StatusConfirmed},
+		{IssueText: "P0: maybe racy\nAnchor: pool.go:5", Status: prreview.StatusConfirmedLowConfidence},
+		{IssueText: "P1: slow path\nAnchor: path.go:7", Status: prreview.StatusConfirmed},
+		{IssueText: "P1: no anchor here", Status: prreview.StatusConfirmed},
+		{IssueText: "P0: unresolved\nAnchor: x.go:1", Status: prreview.StatusUnresolved},
+	}
+	annotations, unanchored := BuildAnnotations(issues)
+	want := []string{LevelFailure, LevelWarning, LevelWarning}
+	if len(annotations) != len(want) {
+		t.Fatalf("expected %d annotations, got %+v", len(want), annotations)
+	}
+	for i, level := range want {
+		if annotations[i].AnnotationLevel != level {
+			t.Errorf("annotation %d level=%q, want %q", i, annotations[i].AnnotationLevel, level)
+		}
+	}
+	if len(unanchored) != 1 || unanchored[0].IssueText != "P1: no anchor here" {
+		t.Fatalf("unexpected unanchored issues: %+v", unanchored)
+	}
+	if got := Conclusion(issues); got != "failure" {
+		t.Fatalf("conclusion=%q, want failure", got)
+	}
+	if got := Conclusion(issues[1:2]); got != "neutral" {
+		t.Fatalf("conclusion=%q, want neutral", got)
+	}
+}
+
+type recordedRequest struct {
+	method string
+	path   string
+	body   map[string]any
+}
+
+func TestPublishBatchesAnnotations(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []recordedRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("missing token on %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{method: r.Method, path: r.URL.Path, body: body})
+		mu.Unlock()
+		fmt.Fprint(w, `{"id": 7, "html_url": "https://github.com/acme/api/runs/7"}`)
+	}))
+	defer server.Close()
+
+	result := &prreview.Result{Status: "issues_found", Summary: "Identified 121 P0/P1 issue."}
+	for i := 0; i < 120; i++ {
+		result.Issues = append(result.Issues, prreview.IssueReport{
+			IssueText: fmt.Sprintf("P1: issue %d\nAnchor: pkg/file%d.go:%d", i, i, i+1),
+			Status:    prreview.StatusConfirmed,
+		})
+	}
+	result.Issues = append(result.Issues, prreview.IssueReport{IssueText: "P1: design-level concern", Status: prreview.StatusConfirmed})
+
+	client := NewClient(server.URL, "tok")
+	checkURL, err := client.Publish(context.Background(), Target{Owner: "acme", Repo: "api", SHA: "abc123"}, result)
+	if err != nil {
+		t.Fatalf("Publish error: %v", err)
+	}
+	if checkURL != "https://github.com/acme/api/runs/7" {
+		t.Fatalf("unexpected check URL %q", checkURL)
+	}
+
+	if len(requests) != 4 {
+		t.Fatalf("expected create + 2 batch updates + completion, got %d requests", len(requests))
+	}
+	create := requests[0]
+	if create.method != http.MethodPost || create.path != "/repos/acme/api/check-runs" || create.body["head_sha"] != "abc123" {
+		t.Fatalf("unexpected create request: %+v", create)
+	}
+	for i, want := range []int{50, 50, 20} {
+		req := requests[i]
+		if i > 0 && (req.method != http.MethodPatch || req.path != "/repos/acme/api/check-runs/7") {
+			t.Fatalf("request %d: unexpected %s %s", i, req.method, req.path)
+		}
+		output, _ := req.body["output"].(map[string]any)
+		annotations, _ := output["annotations"].([]any)
+		if len(annotations) != want {
+			t.Fatalf("request %d carried %d annotations, want %d", i, len(annotations), want)
+		}
+	}
+	final := requests[3]
+	if final.body["status"] != "completed" || final.body["conclusion"] != "failure" {
+		t.Fatalf("unexpected completion request: %+v", final.body)
+	}
+	output, _ := final.body["output"].(map[string]any)
+	if summary, _ := output["summary"].(string); !strings.Contains(summary, "P1: design-level concern") {
+		t.Fatalf("unanchored issue missing from summary: %q", summary)
+	}
+}
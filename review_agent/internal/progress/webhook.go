@@ -0,0 +1,140 @@
+// Package progress posts snapshots of a run's evolving result to a webhook at
+// phase boundaries, coalescing bursts so a receiver sees at most one POST per
+// debounce interval.
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"review_agent/internal/logx"
+)
+
+// DefaultDebounce is the minimum spacing between two POSTs.
+const DefaultDebounce = 2 * time.Second
+
+// Snapshot is the JSON body of each POST.
+type Snapshot struct {
+	Phase    string    `json:"phase"`
+	Sequence int       `json:"sequence"`
+	SentAt   time.Time `json:"sent_at"`
+	Result   any       `json:"result"`
+}
+
+// Webhook delivers snapshots in the background. When several arrive within the
+// debounce interval only the latest is sent; Close always delivers the last one.
+type Webhook struct {
+	url      string
+	debounce time.Duration
+	client   *http.Client
+
+	mu       sync.Mutex
+	pending  []byte
+	sequence int
+	closed   bool
+
+	wake      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWebhook starts a poster for url; a non-positive debounce means DefaultDebounce.
+func NewWebhook(url string, debounce time.Duration) *Webhook {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	w := &Webhook{
+		url:      strings.TrimSpace(url),
+		debounce: debounce,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.loop()
+	return w
+}
+
+// Notify queues a snapshot of result for phase. The result is serialized before
+// Notify returns, so the caller may keep mutating it.
+func (w *Webhook) Notify(phase string, result any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.sequence++
+	body, err := json.Marshal(Snapshot{Phase: phase, Sequence: w.sequence, SentAt: time.Now().UTC(), Result: result})
+	if err != nil {
+		logx.Warningf("Progress webhook: cannot encode %s snapshot: %v", phase, err)
+		return
+	}
+	w.pending = body
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close delivers any snapshot still waiting out the debounce and stops the poster.
+func (w *Webhook) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.stop)
+	})
+	<-w.done
+}
+
+func (w *Webhook) loop() {
+	defer close(w.done)
+	for {
+		select {
+		case <-w.wake:
+		case <-w.stop:
+			w.flush()
+			return
+		}
+		w.flush()
+		select {
+		case <-time.After(w.debounce):
+		case <-w.stop:
+			w.flush()
+			return
+		}
+	}
+}
+
+func (w *Webhook) flush() {
+	w.mu.Lock()
+	body := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+	if body == nil {
+		return
+	}
+	if err := w.post(body); err != nil {
+		logx.Warningf("Progress webhook POST failed: %v", err)
+	}
+}
+
+func (w *Webhook) post(body []byte) error {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package progress
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookCoalescesBurstsAndFlushesOnClose(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		posts []Snapshot
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var snap Snapshot
+		if err := json.NewDecoder(r.Body).Decode(&snap); err != nil {
+			t.Errorf("decode snapshot: %v", err)
+		}
+		mu.Lock()
+		posts = append(posts, snap)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	hook := NewWebhook(server.URL, time.Hour)
+	partial := map[string]any{"issues": 0}
+	for i := 1; i <= 5; i++ {
+		partial["issues"] = i
+		hook.Notify("issue_verified", partial)
+	}
+	partial["issues"] = 99 // snapshots are taken at Notify time
+	hook.Close()
+	hook.Notify("late", partial)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posts) == 0 || len(posts) > 2 {
+		t.Fatalf("expected the burst to be coalesced into at most 2 POSTs, got %d", len(posts))
+	}
+	last := posts[len(posts)-1]
+	result, _ := last.Result.(map[string]any)
+	if last.Sequence != 5 || last.Phase != "issue_verified" || result["issues"] != float64(5) {
+		t.Fatalf("expected the final snapshot to be the 5th, got %+v", last)
+	}
+}
@@ -0,0 +1,69 @@
+package prreview
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// revisionRe matches one side of a commit range: a SHA, branch, tag or a
+// revision expression such as HEAD~3 or main@{1}. A leading "-" is refused so
+// the value can never be read as a git option.
+var revisionRe = regexp.MustCompile(`^[A-Za-z0-9_.][A-Za-z0-9_./~^@{}-]*$`)
+
+// normalizeCommitRange validates Options.CommitRange, "<from>..<to>"; the empty
+// string keeps the merge-base diff.
+func normalizeCommitRange(spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return "", nil
+	}
+	if strings.Contains(spec, "...") {
+		return "", fmt.Errorf("commit range %q: symmetric ranges (A...B) are not supported; use A..B", spec)
+	}
+	from, to, ok := strings.Cut(spec, "..")
+	if !ok || from == "" || to == "" {
+		return "", fmt.Errorf("commit range %q: want <from>..<to>, e.g. abc123..def456", spec)
+	}
+	for _, rev := range []string{from, to} {
+		if !revisionRe.MatchString(rev) || strings.Contains(rev, "..") {
+			return "", fmt.Errorf("commit range %q: %q is not a git revision", spec, rev)
+		}
+	}
+	return spec, nil
+}
+
+// writeDiffSteps tells an agent how to get the diff under review: the explicit
+// commit range when one is set, otherwise the merge base with the base branch.
+// stat asks for the cheap --stat summary instead of the full diff.
+func writeDiffSteps(sb *strings.Builder, commitRange, scopeDir string, stat bool) {
+	pathspec := diffPathspec(scopeDir)
+	full := "git diff "
+	if stat {
+		full = "git diff --stat "
+	}
+	if commitRange != "" {
+		sb.WriteString("  1) Review ONLY the commits in " + commitRange + "; do NOT compute a merge base or diff against the base branch.\n")
+		sb.WriteString("  2) Run: " + full + commitRange + pathspec + " and git diff --name-status " + commitRange + pathspec + "\n")
+	} else if stat {
+		sb.WriteString("  1) Find MERGE_BASE_SHA with: git merge-base HEAD BASE_BRANCH (BASE_BRANCH is main or master unless the task says otherwise)\n")
+		sb.WriteString("  2) Run: git diff --stat MERGE_BASE_SHA" + pathspec + " and git diff --name-status MERGE_BASE_SHA" + pathspec + "\n")
+	} else {
+		sb.WriteString("  1) Find the merge-base SHA for this comparison:\n")
+		sb.WriteString("     - Try: git merge-base HEAD BASE_BRANCH\n")
+		sb.WriteString("     - If that fails, try: git merge-base HEAD \"BASE_BRANCH@{upstream}\"\n")
+		sb.WriteString("     - If still failing, inspect refs/remotes and pick the correct remote-tracking ref, then re-run merge-base.\n\n")
+		sb.WriteString("  2) Once you have MERGE_BASE_SHA, inspect changes relative to the base branch:\n")
+		sb.WriteString("     - Run: git diff MERGE_BASE_SHA" + pathspec + "\n")
+		sb.WriteString("     - Also run: git diff --name-status MERGE_BASE_SHA" + pathspec + "\n")
+	}
+	if scopeDir != "" && !stat {
+		sb.WriteString("     - Only files under " + scopeDir + "/ are in scope; ignore changes elsewhere.\n")
+	}
+}
+
+// commitRangeBlock tells the issue finder which commits it is accountable for.
+func commitRangeBlock(commitRange string) string {
+	return "Review scope: ONLY the changes in commit range " + commitRange + " (git diff " + commitRange + "). " +
+		"Code outside the range is context; report an issue there only if the range makes it reachable or worse.\n\n"
+}
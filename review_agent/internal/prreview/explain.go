@@ -0,0 +1,105 @@
+package prreview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LoadIssueReports decodes a saved issue report. data may hold a single
+// IssueReport, a JSON array of them, or a whole Result, whose issues are returned.
+func LoadIssueReports(data []byte) ([]IssueReport, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("issue file is empty")
+	}
+	if trimmed[0] == '[' {
+		var reports []IssueReport
+		if err := json.Unmarshal(trimmed, &reports); err != nil {
+			return nil, fmt.Errorf("parse issue reports: %w", err)
+		}
+		return reports, nil
+	}
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return nil, fmt.Errorf("parse issue report: %w", err)
+	}
+	if _, ok := probe["issue_text"]; ok {
+		var report IssueReport
+		if err := json.Unmarshal(trimmed, &report); err != nil {
+			return nil, fmt.Errorf("parse issue report: %w", err)
+		}
+		return []IssueReport{report}, nil
+	}
+	if _, ok := probe["issues"]; ok {
+		var result Result
+		if err := json.Unmarshal(trimmed, &result); err != nil {
+			return nil, fmt.Errorf("parse result: %w", err)
+		}
+		return result.Issues, nil
+	}
+	return nil, errors.New("issue file holds neither an issue report nor a result with issues")
+}
+
+// ExplainIssue renders why a report ended in its status from the data saved with
+// it: the consensus outcome, the verdict explanation, the alignment rationale,
+// each role's final verdict and the branches of every round.
+func ExplainIssue(report IssueReport) string {
+	var sb strings.Builder
+	sb.WriteString("Issue: ")
+	sb.WriteString(firstLine(report.IssueText))
+	sb.WriteString("\n")
+	fmt.Fprintf(&sb, "Status: %s\n", orNone(report.Status))
+	if report.Consensus != "" {
+		fmt.Fprintf(&sb, "Consensus: %s\n", report.Consensus)
+	}
+	fmt.Fprintf(&sb, "Why: %s\n", orNone(report.VerdictExplanation))
+	if report.Alignment != "" || report.AlignmentRelationship != "" {
+		sb.WriteString("Alignment: ")
+		if report.AlignmentRelationship != "" {
+			sb.WriteString("[" + report.AlignmentRelationship)
+			if report.AlignmentScore != nil {
+				fmt.Fprintf(&sb, ", score %.2f", *report.AlignmentScore)
+			}
+			sb.WriteString("] ")
+		}
+		sb.WriteString(orNone(report.Alignment))
+		sb.WriteString("\n")
+	}
+	if report.TieBreak != "" {
+		fmt.Fprintf(&sb, "Tie-break: %s\n", report.TieBreak)
+	}
+
+	sb.WriteString("\nFinal verdicts:\n")
+	for _, tr := range []Transcript{report.Alpha, report.Beta} {
+		if tr.Agent == "" && tr.Verdict == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s (round %d): %s", orNone(tr.Agent), tr.Round, orNone(tr.Verdict))
+		if reason := strings.TrimSpace(tr.VerdictReason); reason != "" {
+			fmt.Fprintf(&sb, " — %s", reason)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\nBranches:\n")
+	fmt.Fprintf(&sb, "- Round 1: reviewer %s, tester %s\n", orNone(report.ReviewerRound1BranchID), orNone(report.TesterRound1BranchID))
+	if report.ExchangeRounds > 0 {
+		fmt.Fprintf(&sb, "- Round 2: reviewer %s, tester %s\n", orNone(report.ReviewerRound2BranchID), orNone(report.TesterRound2BranchID))
+	}
+	return sb.String()
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return orNone(strings.TrimSpace(line))
+}
+
+func orNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package prreview
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExplainIssueFromSavedResult(t *testing.T) {
+	result := Result{
+		Task:   "review",
+		Status: "completed",
+		Issues: []IssueReport{{
+			IssueText: "ISSUE: nil map write in cache.Put\nmore detail",
+			Status:    commentConfirmed,
+			Alpha: Transcript{Agent: "codex", Round: 2, BranchID: "rev-2",
+				Verdict: "confirmed", VerdictReason: "Put writes to a nil map on first use"},
+			Beta: Transcript{Agent: "claude_code", Round: 2, BranchID: "test-2",
+				Verdict: "confirmed", VerdictReason: "reproduced with TestPutEmpty"},
+			ReviewerRound1BranchID: "rev-1",
+			TesterRound1BranchID:   "test-1",
+			ReviewerRound2BranchID: "rev-2",
+			TesterRound2BranchID:   "test-2",
+			ExchangeRounds:         1,
+			VerdictExplanation:     "Round 2: Both confirmed and aligned: same nil map",
+			Alignment:              "same nil map",
+			Consensus:              ConsensusAgreedConfirmAligned,
+		}},
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err := LoadIssueReports(data)
+	if err != nil {
+		t.Fatalf("load result: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+	out := ExplainIssue(reports[0])
+	for _, want := range []string{
+		"Issue: ISSUE: nil map write in cache.Put\n",
+		"Status: confirmed\n",
+		"Consensus: agreed_confirm_aligned\n",
+		"Why: Round 2: Both confirmed and aligned: same nil map\n",
+		"Alignment: same nil map\n",
+		"- codex (round 2): confirmed — Put writes to a nil map on first use\n",
+		"- claude_code (round 2): confirmed — reproduced with TestPutEmpty\n",
+		"- Round 1: reviewer rev-1, tester test-1\n",
+		"- Round 2: reviewer rev-2, tester test-2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("explanation missing %q:\n%s", want, out)
+		}
+	}
+
+	single, err := json.Marshal(result.Issues[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports, err = LoadIssueReports(single)
+	if err != nil || len(reports) != 1 || reports[0].TesterRound2BranchID != "test-2" {
+		t.Fatalf("load single report: %v %+v", err, reports)
+	}
+	if _, err := LoadIssueReports([]byte(`{"task":"x"}`)); err == nil {
+		t.Fatal("expected error for a file without issues")
+	}
+}
@@ -0,0 +1,34 @@
+package prreview
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeadline(t *testing.T) {
+	elapsed := 8*time.Minute + 32*time.Second + 400*time.Millisecond
+	issues := []IssueReport{
+		{IssueText: "[P0] nil map write", Status: commentConfirmed},
+		{IssueText: "P0: race on close", Status: commentConfirmed},
+		{IssueText: "**P1** leaked file handle", Status: commentUnresolved},
+		{IssueText: "untitled finding", Status: commentConfirmed},
+		{IssueText: "[P1] rejected finding", Status: "rejected"},
+	}
+	cases := []struct {
+		opts Options
+		res  *Result
+		want string
+	}{
+		{Options{Subject: "PR #123", ChangedFiles: 4}, &Result{Status: statusIssues, Issues: issues},
+			"Review of PR #123: 2 confirmed P0, 1 confirmed P0/P1, 1 unresolved P1 across 4 changed files (8m32s)."},
+		{Options{ParentBranchID: "abc", ChangedFiles: 1}, &Result{Status: statusClean},
+			"Review of branch abc: clean, no blocking P0/P1 issues across 1 changed file (8m32s)."},
+		{Options{ParentBranchID: "abc"}, &Result{Status: statusIssues, Issues: issues[4:]},
+			"Review of branch abc: 1 reported P0/P1 issue, none confirmed (8m32s)."},
+	}
+	for _, tc := range cases {
+		if got := (&Runner{opts: tc.opts}).headline(tc.res, elapsed); got != tc.want {
+			t.Errorf("headline = %q, want %q", got, tc.want)
+		}
+	}
+}
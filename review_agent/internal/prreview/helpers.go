@@ -2,9 +2,13 @@ package prreview
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 const outputAwarenessBlock = "**COMMAND OUTPUT AWARENESS**\n" +
@@ -41,7 +45,10 @@ const bugFinderSOPBlock = "**SOP (use only when the matching pattern appears in
 	"- Non-local state time consistency: when reading/writing ctx/session/global, trace the read/write order; suspect irreversible decisions based on pre-write assumptions.\n" +
 	"- Minimal counterexample: for each guard, try a case where the guard triggers but later falls back / becomes irrelevant; if possible, treat it as a behavior-change point.\n"
 
-func buildIssueFinderPrompt(task string, changeAnalysisPath string) string {
+// buildIssueFinderPrompt asks review_code for the issue report; with
+// includeBlame it is told to rank newly-introduced code above inherited code,
+// and a non-empty commitRange limits it to those commits.
+func buildIssueFinderPrompt(task string, changeAnalysisPath string, includeBlame bool, commitRange string) string {
 	var sb strings.Builder
 	sb.WriteString("Task: ")
 	sb.WriteString(task)
@@ -50,18 +57,31 @@ func buildIssueFinderPrompt(task string, changeAnalysisPath string) string {
 	sb.WriteString("\n\n")
 	sb.WriteString(bugFinderSOPBlock)
 	sb.WriteString("\n\n")
+	if commitRange != "" {
+		sb.WriteString(commitRangeBlock(commitRange))
+	}
 	if strings.TrimSpace(changeAnalysisPath) != "" {
 		sb.WriteString("Reference (read-only): Change Analysis at: ")
 		sb.WriteString(changeAnalysisPath)
 		sb.WriteString("\n\n")
 	}
+	if includeBlame {
+		sb.WriteString("Provenance priority:\n")
+		sb.WriteString("- The Change Analysis marks each high-risk hunk NEW (introduced by this PR) or PRE-EXISTING (unchanged since the base branch).\n")
+		sb.WriteString("- Spend your effort on NEW code first; that is what this PR is accountable for.\n")
+		sb.WriteString("- Report an issue in PRE-EXISTING code only if this PR makes it reachable or worse, and say so in the report.\n\n")
+	}
 	sb.WriteString("FINAL RESPONSE:\n")
 	sb.WriteString("- Provide a critical P0/P1/P2 issue report (include severity, impact, evidence, and a plausible fix).\n")
 	sb.WriteString("- If no P0/P1/P2 issues exist, write exactly: \"No P0/P1 issues found\".\n\n")
 	return sb.String()
 }
 
-func buildScoutPrompt(task string, outputPath string) string {
+// buildScoutPrompt asks for the change analysis; a non-empty scopeDir limits the
+// diff it is based on to files under that directory, a non-empty commitRange
+// replaces the merge-base diff with that range, and includeBlame adds a git
+// blame pass labelling each high-risk hunk as new or pre-existing.
+func buildScoutPrompt(task string, outputPath string, focusAreas []string, scopeDir string, includeBlame bool, commitRange string) string {
 	var sb strings.Builder
 	sb.WriteString("Role: SCOUT\n\n")
 	sb.WriteString(universalStudyLine)
@@ -69,17 +89,33 @@ func buildScoutPrompt(task string, outputPath string) string {
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
 	sb.WriteString("\n\n")
+	if len(focusAreas) > 0 {
+		sb.WriteString("Focus areas (from a quick triage of the diff; prioritize these areas, but still cover the rest briefly):\n")
+		for i, area := range focusAreas {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, area))
+		}
+		sb.WriteString("\n")
+	}
 	sb.WriteString("Requirement: Write a Change Analysis that helps to improve subsequent review + testing.\n")
 	sb.WriteString("Goal: high-signal summary + impact/risk analysis (NOT a line-by-line commentary).\n")
-	sb.WriteString("You MUST base the analysis on an actual diff against base branch (main or master), not assumptions.\n\n")
+	if commitRange != "" {
+		sb.WriteString("You MUST base the analysis on an actual diff of commit range " + commitRange + ", not assumptions.\n\n")
+	} else {
+		sb.WriteString("You MUST base the analysis on an actual diff against base branch (main or master), not assumptions.\n\n")
+	}
 	sb.WriteString("Get the diff:\n")
-	sb.WriteString("  1) Find the merge-base SHA for this comparison:\n")
-	sb.WriteString("     - Try: git merge-base HEAD BASE_BRANCH\n")
-	sb.WriteString("     - If that fails, try: git merge-base HEAD \"BASE_BRANCH@{upstream}\"\n")
-	sb.WriteString("     - If still failing, inspect refs/remotes and pick the correct remote-tracking ref, then re-run merge-base.\n\n")
-	sb.WriteString("  2) Once you have MERGE_BASE_SHA, inspect changes relative to the base branch:\n")
-	sb.WriteString("     - Run: git diff MERGE_BASE_SHA\n")
-	sb.WriteString("     - Also run: git diff --name-status MERGE_BASE_SHA\n\n")
+	writeDiffSteps(&sb, commitRange, scopeDir, false)
+	sb.WriteString("\n")
+	if includeBlame {
+		blameRange, introducedBy := "MERGE_BASE_SHA..HEAD", "this PR"
+		if commitRange != "" {
+			blameRange, introducedBy = commitRange, "the range"
+		}
+		sb.WriteString("  3) For each high-risk hunk, determine its provenance with git blame:\n")
+		sb.WriteString("     - Run: git blame -w " + blameRange + " -L START,END -- FILE\n")
+		sb.WriteString("     - Lines prefixed with ^ date from before " + introducedBy + "; all others were introduced by " + introducedBy + ".\n")
+		sb.WriteString("     - Label the hunk NEW if its risky lines were introduced by " + introducedBy + ", otherwise PRE-EXISTING.\n\n")
+	}
 	sb.WriteString("Analysis guidance:\n")
 	sb.WriteString("- Focus on behavior, invariants, error semantics, edge cases, concurrency, compatibility.\n")
 	sb.WriteString("- If defaults/contracts/config/env/flags changed, treat it as high risk; and find likely call sites.\n")
@@ -103,11 +139,69 @@ func buildScoutPrompt(task string, outputPath string) string {
 	sb.WriteString("## High-Risk Areas (ranked)\n")
 	sb.WriteString("For each item, include: What changed (anchor), Before -> After, Who/what is impacted, How to verify.\n")
 	sb.WriteString("Mark each KEY item and provide deeper analysis there; keep non-KEY items brief.\n")
+	if includeBlame {
+		sb.WriteString("Also give each item a Provenance line: NEW or PRE-EXISTING, with the blame evidence.\n")
+	}
 	sb.WriteString("## Impacted Call Sites / Code Paths\n")
 	sb.WriteString("## Appendix: Change Surface\n")
 	return sb.String()
 }
 
+// buildFocusPrompt asks for a cheap triage of the diff (of commitRange when
+// set): the few highest-risk areas, written as JSON so the runner can feed them
+// to the scout.
+func buildFocusPrompt(task string, outputPath string, scopeDir string, commitRange string) string {
+	var sb strings.Builder
+	sb.WriteString("Role: FOCUS (quick risk triage)\n\n")
+	sb.WriteString("Task / PR context:\n")
+	sb.WriteString(task)
+	sb.WriteString("\n\n")
+	sb.WriteString("Goal: identify the 2-3 highest-risk areas of this change so a later deep analysis can prioritize them.\n")
+	sb.WriteString("Keep this pass cheap: work from the diff summary, do NOT run builds or tests, and only open a file when the summary is ambiguous.\n\n")
+	sb.WriteString("Get the diff summary:\n")
+	writeDiffSteps(&sb, commitRange, scopeDir, true)
+	sb.WriteString("\n")
+	sb.WriteString("Rank areas by the chance of a P0/P1 defect (changed contracts/defaults, concurrency, error handling, persistence, security).\n\n")
+	sb.WriteString("Write ONLY this JSON to: ")
+	sb.WriteString(outputPath)
+	sb.WriteString("\n")
+	sb.WriteString("{\"areas\":[{\"area\":\"<file, package or symbol>\",\"reason\":\"<why it is risky>\"}]}\n")
+	return sb.String()
+}
+
+type focusReport struct {
+	Areas []struct {
+		Area   string `json:"area"`
+		Reason string `json:"reason"`
+	} `json:"areas"`
+}
+
+// parseFocusAreas returns up to limit "area (reason)" lines from focus.json content.
+func parseFocusAreas(raw string, limit int) ([]string, error) {
+	var report focusReport
+	if err := json.Unmarshal([]byte(extractJSONBlock(raw)), &report); err != nil {
+		return nil, fmt.Errorf("parse focus areas: %w; raw=%s", err, truncateForError(raw))
+	}
+	var out []string
+	for _, a := range report.Areas {
+		area := strings.TrimSpace(a.Area)
+		if area == "" {
+			continue
+		}
+		if reason := strings.TrimSpace(a.Reason); reason != "" {
+			area = fmt.Sprintf("%s (%s)", area, reason)
+		}
+		out = append(out, area)
+		if len(out) == limit {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("focus report lists no areas")
+	}
+	return out, nil
+}
+
 func buildHasRealIssuePrompt(reportText string) string {
 	var sb strings.Builder
 	sb.WriteString("You are a strict triage parser for code review reports.\n\n")
@@ -122,19 +216,20 @@ func buildHasRealIssuePrompt(reportText string) string {
 	return sb.String()
 }
 
-func BuildLogicAnalystPrompt(issueText string) string {
-	return buildLogicAnalystPrompt(issueText)
+func BuildLogicAnalystPrompt(issueText string, policy SeverityPolicy, stance string) string {
+	return buildLogicAnalystPrompt(issueText, policy, stance)
 }
 
 // buildReviewerPrompt creates the prompt for the Reviewer role (logic analysis).
-func buildLogicAnalystPrompt(issueText string) string {
+// policy supplies the severity definitions the reviewer measures issues against, and
+// stance how readily it accepts them (see StanceSkeptical).
+func buildLogicAnalystPrompt(issueText string, policy SeverityPolicy, stance string) string {
 	var sb strings.Builder
 	sb.WriteString("Verification Role: REVIEWER\n\n")
-	sb.WriteString("You will review an opponent's Issue List. Your default stance is: each issue may be a misread, a misunderstanding, or an edge case--unless the code evidence forces you to accept it.\n\n")
-	sb.WriteString("Reference severity definitions (guidance, not a hard rule):\n")
-	sb.WriteString("- P0 (Critical/Blocker): Reachable under default production configuration, and causes production unavailability; severe data loss/corruption; a security vulnerability; or a primary workflow is completely blocked with no practical workaround. Must be fixed immediately.\n")
-	sb.WriteString("- P1 (High): Reachable in realistic production scenarios (default or commonly enabled configs), and significantly impairs core/major functionality or violates user-facing contracts relied upon (including user-visible correctness errors), or causes a severe performance regression that impacts use; a workaround may exist but is costly/risky/high-friction. Must be fixed before release.\n")
-	sb.WriteString("- Lightweight evidence bar (guidance): A P0/P1 claim must be backed by clear code-causal evidence and an explicit blast-radius assessment; if it’s borderline between P1 and P2, default to P1 unless the impact is clearly narrow or edge-case only.\n\n")
+	sb.WriteString("You will review an opponent's Issue List.\n\n")
+	sb.WriteString(stanceBlock(stance))
+	sb.WriteString("\n")
+	policy.writeTo(&sb)
 	sb.WriteString("Goal: For each issue, run an adversarial / rebuttal-style review. Try hard to find weaknesses that would prevent it from being legitimately classified as P0/P1. If you cannot find such a weakness, be honest and acknowledge it as a real P0/P1 issue.\n\n")
 	sb.WriteString("How to work (principles, not rigid steps):\n")
 	sb.WriteString("- Evidence-first: Conclusions must come from code and build/runtime-path facts, not experience or speculation.\n")
@@ -166,8 +261,38 @@ func buildLogicAnalystPrompt(issueText string) string {
 	return sb.String()
 }
 
+// changeAnalysis is how verification prompts refer to the scout's analysis:
+// by path when the verification forks can read it, or inlined as Content when
+// the file did not carry forward to them. The zero value means no analysis.
+type changeAnalysis struct {
+	Path    string
+	Content string
+}
+
+// maxInlineAnalysisChars caps an inlined change analysis per prompt.
+const maxInlineAnalysisChars = 24000
+
+func writeChangeAnalysis(sb *strings.Builder, analysis changeAnalysis) {
+	switch {
+	case strings.TrimSpace(analysis.Content) != "":
+		content := analysis.Content
+		if len(content) > maxInlineAnalysisChars {
+			content = content[:maxInlineAnalysisChars] + "\n[TRUNCATED]"
+		}
+		sb.WriteString("Reference (read-only): Change Analysis (inlined; ")
+		sb.WriteString(analysis.Path)
+		sb.WriteString(" is not present on this branch):\n<<<CHANGE ANALYSIS>>>\n")
+		sb.WriteString(strings.TrimSpace(content))
+		sb.WriteString("\n<<<END CHANGE ANALYSIS>>>\n\n")
+	case strings.TrimSpace(analysis.Path) != "":
+		sb.WriteString("Reference (read-only): Change Analysis at: ")
+		sb.WriteString(analysis.Path)
+		sb.WriteString("\n\n")
+	}
+}
+
 // buildTesterPrompt creates the prompt for the Tester role (reproduction).
-func buildTesterPrompt(task string, issueText string, changeAnalysisPath string) string {
+func buildTesterPrompt(task string, issueText string, analysis changeAnalysis) string {
 	var sb strings.Builder
 	sb.WriteString("Verification Role: TESTER\n\n")
 	sb.WriteString(universalStudyLine)
@@ -177,11 +302,7 @@ func buildTesterPrompt(task string, issueText string, changeAnalysisPath string)
 	sb.WriteString("\n\nIssue under review:\n")
 	sb.WriteString(issueText)
 	sb.WriteString("\n\n")
-	if strings.TrimSpace(changeAnalysisPath) != "" {
-		sb.WriteString("Reference (read-only): Change Analysis at: ")
-		sb.WriteString(changeAnalysisPath)
-		sb.WriteString("\n\n")
-	}
+	writeChangeAnalysis(&sb, analysis)
 	sb.WriteString("YOUR ROLE: Simulate a QA engineer who verifies bugs by running real tests.\n\n")
 	sb.WriteString("CRITICAL: You MUST actually run code to collect evidence.\n")
 	sb.WriteString("Do NOT fabricate test results or mock behavior.\n\n")
@@ -221,7 +342,8 @@ func buildTesterPrompt(task string, issueText string, changeAnalysisPath string)
 }
 
 // buildExchangePrompt creates the prompt for Round 2 (exchange opinions).
-func buildExchangePrompt(role string, task string, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string) string {
+// maxOpinionChars caps each embedded opinion (see condenseOpinion); 0 embeds them whole.
+func buildExchangePrompt(role string, task string, issueText string, analysis changeAnalysis, selfOpinion string, peerOpinion string, maxOpinionChars int) string {
 	normalizedRole := strings.ToLower(strings.TrimSpace(role))
 	displayRole := strings.ToUpper(role)
 	var sb strings.Builder
@@ -233,10 +355,11 @@ func buildExchangePrompt(role string, task string, issueText string, changeAnaly
 	sb.WriteString("\n\nIssue under review:\n")
 	sb.WriteString(issueText)
 	sb.WriteString("\n\n")
-	if strings.TrimSpace(changeAnalysisPath) != "" {
-		sb.WriteString("Reference (read-only): Change Analysis at: ")
-		sb.WriteString(changeAnalysisPath)
-		sb.WriteString("\n\n")
+	writeChangeAnalysis(&sb, analysis)
+	selfOpinion, selfCut := condenseOpinion(selfOpinion, maxOpinionChars)
+	peerOpinion, peerCut := condenseOpinion(peerOpinion, maxOpinionChars)
+	if selfCut || peerCut {
+		sb.WriteString("NOTE: Opinions marked [TRUNCATED] were condensed to fit the prompt budget; the verdict line and leading sections are kept verbatim.\n\n")
 	}
 	sb.WriteString("YOUR PREVIOUS OPINION:\n<<<SELF>>>\n")
 	sb.WriteString(selfOpinion)
@@ -293,10 +416,138 @@ func buildExchangePrompt(role string, task string, issueText string, changeAnaly
 type verdictDecision struct {
 	Verdict string `json:"verdict"`
 	Reason  string `json:"reason"`
+	// Confidence is the 0-1 value of a "Confidence:" line next to an explicit
+	// marker; nil when the transcript states none.
+	Confidence *float64 `json:"confidence,omitempty"`
+}
+
+// opinionTruncatedMarker is appended to an opinion condensed by condenseOpinion.
+const opinionTruncatedMarker = "[TRUNCATED: %d of %d characters omitted]"
+
+// opinionSection is a markdown heading plus the lines up to the next heading.
+type opinionSection struct {
+	text     string
+	priority int
+}
+
+// condenseOpinion shrinks a transcript to roughly limit bytes while keeping its
+// structure rather than cutting blindly: sections carrying the verdict or severity
+// are kept first, then the remaining sections in order, and out-of-scope additions
+// only if budget is left. The result is marked [TRUNCATED] when anything was dropped.
+func condenseOpinion(text string, limit int) (string, bool) {
+	text = strings.TrimSpace(text)
+	if limit <= 0 || len(text) <= limit {
+		return text, false
+	}
+	sections := splitOpinionSections(text)
+	marker := fmt.Sprintf(opinionTruncatedMarker, len(text), len(text))
+	budget := limit - len(marker) - 1
+	if budget < 0 {
+		budget = 0
+	}
+
+	keep := make([]string, len(sections))
+	for prio := 0; prio <= 2 && budget > 0; prio++ {
+		for i, sec := range sections {
+			if sec.priority != prio || keep[i] != "" || budget <= 0 {
+				continue
+			}
+			if len(sec.text)+1 <= budget {
+				keep[i] = sec.text
+				budget -= len(sec.text) + 1
+				continue
+			}
+			cut := sec.text
+			if sec.priority == 0 {
+				cut = opinionKeyLines(cut)
+			}
+			keep[i] = truncateAtRune(cut, budget-1)
+			budget = 0
+		}
+	}
+
+	var parts []string
+	for _, k := range keep {
+		if strings.TrimSpace(k) != "" {
+			parts = append(parts, k)
+		}
+	}
+	out := strings.Join(parts, "\n")
+	omitted := len(text) - len(out)
+	return out + "\n" + fmt.Sprintf(opinionTruncatedMarker, omitted, len(text)), true
+}
+
+// splitOpinionSections breaks a transcript at markdown headings and ranks each
+// piece: 0 when it holds a verdict or severity line, 2 for out-of-scope
+// additions, 1 for everything else.
+func splitOpinionSections(text string) []opinionSection {
+	var (
+		sections []opinionSection
+		current  []string
+	)
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		body := strings.Join(current, "\n")
+		prio := 1
+		head := strings.ToLower(strings.TrimSpace(current[0]))
+		switch {
+		case strings.Contains(head, "addition"):
+			prio = 2
+		case opinionKeyLines(body) != "":
+			prio = 0
+		}
+		sections = append(sections, opinionSection{text: body, priority: prio})
+		current = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			flush()
+		}
+		current = append(current, line)
+	}
+	flush()
+	return sections
+}
+
+// opinionKeyLines returns the verdict, Claim, Anchor and Severity lines of text.
+func opinionKeyLines(text string) string {
+	var keep []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if verdictLineRe.MatchString(trimmed) ||
+			strings.HasPrefix(trimmed, "Claim:") ||
+			strings.HasPrefix(trimmed, "Anchor:") ||
+			strings.HasPrefix(trimmed, "Severity:") {
+			keep = append(keep, trimmed)
+		}
+	}
+	return strings.Join(keep, "\n")
+}
+
+// truncateAtRune cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncateAtRune(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
 }
 
 var verdictLineRe = regexp.MustCompile(`(?i)^\s*#?\s*verdict\s*:\s*\[?\s*(confirmed|rejected)\s*\]?\s*$`)
 
+var confidenceLineRe = regexp.MustCompile(`(?i)^[\s#*_-]*confidence[*_]*\s*:\s*[*_]*\s*(\d+(?:\.\d+)?\s*%?|low|medium|high)\b`)
+
+// verdictConfidenceWindow is how many lines before or after an explicit marker a
+// "Confidence:" line is still read as qualifying it.
+const verdictConfidenceWindow = 3
+
 type verdictExtractionResponse struct {
 	Verdict    string `json:"verdict"`
 	Evidence   string `json:"evidence,omitempty"`
@@ -363,20 +614,158 @@ func extractTranscriptVerdict(transcript string) (verdictDecision, bool) {
 			continue
 		}
 		return verdictDecision{
-			Verdict: strings.ToLower(strings.TrimSpace(matches[1])),
-			Reason:  "explicit transcript verdict marker",
+			Verdict:    strings.ToLower(strings.TrimSpace(matches[1])),
+			Reason:     "explicit transcript verdict marker",
+			Confidence: markerConfidence(lines, i),
 		}, true
 	}
 	return verdictDecision{}, false
 }
 
+// markerConfidence reads the nearest "Confidence:" line around the marker at
+// lines[at]. Percentages and values above 1 are scaled to 0-1; the words low,
+// medium and high map to 0.3, 0.6 and 0.9.
+func markerConfidence(lines []string, at int) *float64 {
+	for dist := 1; dist <= verdictConfidenceWindow; dist++ {
+		for _, i := range []int{at + dist, at - dist} {
+			if i < 0 || i >= len(lines) {
+				continue
+			}
+			matches := confidenceLineRe.FindStringSubmatch(strings.TrimSpace(lines[i]))
+			if len(matches) != 2 {
+				continue
+			}
+			raw := strings.ToLower(strings.TrimSpace(matches[1]))
+			var value float64
+			switch raw {
+			case "low":
+				value = 0.3
+			case "medium":
+				value = 0.6
+			case "high":
+				value = 0.9
+			default:
+				percent := strings.HasSuffix(raw, "%")
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(raw, "%")), 64)
+				if err != nil {
+					continue
+				}
+				if percent || parsed > 1 {
+					parsed /= 100
+				}
+				value = parsed
+				if value > 1 {
+					value = 1
+				}
+			}
+			return &value
+		}
+	}
+	return nil
+}
+
+// minCJKShare is the share of letters that must be Chinese, Japanese or Korean
+// for a transcript to count as written in the wrong language.
+const minCJKShare = 0.3
+
+// needsLanguageNormalization reports whether text lacks an English verdict
+// marker and is mostly CJK, i.e. the agent answered in the prompt's other
+// language. A marker in English means the parsers can already read it.
+func needsLanguageNormalization(text string) bool {
+	if _, ok := extractTranscriptVerdict(text); ok {
+		return false
+	}
+	letters, cjk := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		}
+	}
+	return letters > 0 && float64(cjk)/float64(letters) >= minCJKShare
+}
+
+func buildLanguageNormalizationPrompt(transcript Transcript) string {
+	var sb strings.Builder
+	sb.WriteString("The verification transcript below was written (partly) in a language other than English.\n")
+	sb.WriteString("Translate it into English for automated parsing. Keep every code identifier, file path, line number and command unchanged, and do not add, drop or soften any claim.\n\n")
+	sb.WriteString("Start the translation with the structure the parsers expect, using the verdict the transcript actually reaches:\n")
+	sb.WriteString("# VERDICT: CONFIRMED or # VERDICT: REJECTED\n\nClaim: <one sentence>\nAnchor: <file:line or unknown>\n\n")
+	sb.WriteString("If the transcript reaches no verdict, omit the VERDICT line rather than guessing. Then translate the rest, keeping its headings.\n\n")
+	sb.WriteString("Transcript:\n<<<TRANSCRIPT>>>\n")
+	sb.WriteString(transcript.Text)
+	sb.WriteString("\n<<<END TRANSCRIPT>>>\n\n")
+	sb.WriteString("Reply ONLY JSON: {\"text\":\"<the English transcript>\"}.\n")
+	return sb.String()
+}
+
+func parseLanguageNormalizationResponse(raw string) (string, error) {
+	var out struct {
+		Text string `json:"text"`
+	}
+	jsonBlock := extractJSONBlock(strings.TrimSpace(raw))
+	if err := json.Unmarshal([]byte(jsonBlock), &out); err != nil {
+		return "", fmt.Errorf("invalid normalization JSON: %v (raw=%q)", err, truncateForError(raw))
+	}
+	text := strings.TrimSpace(out.Text)
+	if text == "" {
+		return "", fmt.Errorf("empty normalized transcript (raw=%q)", truncateForError(raw))
+	}
+	return text, nil
+}
+
+// Alignment relationships returned by the graded alignment check.
+const (
+	AlignmentSame      = "same"
+	AlignmentRelated   = "related"
+	AlignmentDifferent = "different"
+)
+
 type alignmentVerdict struct {
 	Agree       bool   `json:"agree"`
 	Explanation string `json:"explanation"`
+	// Relationship and Score are only requested in graded mode; Score is nil when
+	// the model left it out.
+	Relationship string   `json:"relationship,omitempty"`
+	Score        *float64 `json:"score,omitempty"`
 }
 
 func buildAlignmentPrompt(issueText string, alpha Transcript, beta Transcript) string {
 	var sb strings.Builder
+	writeAlignmentTask(&sb, issueText, alpha, beta)
+	sb.WriteString("Reply ONLY JSON: {\"agree\":true/false,\"explanation\":\"...\"}.\n")
+	sb.WriteString("agree=true ONLY if both transcripts are clearly talking about the same underlying defect described by issueText.\n")
+	sb.WriteString("If uncertain, return agree=false.\n")
+	return sb.String()
+}
+
+// buildGradedAlignmentPrompt asks for a relationship and a 0-1 score on top of
+// the boolean, so near-miss alignments can be told apart from unrelated ones.
+func buildGradedAlignmentPrompt(issueText string, alpha Transcript, beta Transcript) string {
+	var sb strings.Builder
+	writeAlignmentTask(&sb, issueText, alpha, beta)
+	sb.WriteString("Reply ONLY JSON: {\"agree\":true/false,\"relationship\":\"same|related|different\",\"score\":0.0-1.0,\"explanation\":\"...\"}.\n")
+	sb.WriteString("relationship=same: both describe the same underlying defect described by issueText.\n")
+	sb.WriteString("relationship=related: the defects overlap (same code path, same root cause seen from different angles, or one is a symptom of the other) but are not clearly identical.\n")
+	sb.WriteString("relationship=different: the transcripts describe unrelated defects.\n")
+	sb.WriteString("score is your confidence (0.0-1.0) that both transcripts describe the same defect; agree=true ONLY for relationship=same.\n")
+	return sb.String()
+}
+
+// buildAlignmentReprompt follows up an alignment reply that parseAlignment
+// rejected, asking for the bare JSON object and nothing else.
+func buildAlignmentReprompt(graded bool, parseErr error) string {
+	shape := `{"agree":true/false,"explanation":"..."}`
+	if graded {
+		shape = `{"agree":true/false,"relationship":"same|related|different","score":0.0-1.0,"explanation":"..."}`
+	}
+	return fmt.Sprintf("Your reply could not be parsed (%s).\nReturn ONLY %s with no prose and no code fences.", truncateForError(parseErr.Error()), shape)
+}
+
+func writeAlignmentTask(sb *strings.Builder, issueText string, alpha Transcript, beta Transcript) {
 	sb.WriteString("You are aligning two verification transcripts (Reviewer vs Tester) for the SAME issue.\n\n")
 	sb.WriteString("Issue under review (issueText):\n")
 	sb.WriteString(issueText)
@@ -388,10 +777,6 @@ func buildAlignmentPrompt(issueText string, alpha Transcript, beta Transcript) s
 	sb.WriteString("Task:\n")
 	sb.WriteString("- Decide whether A and B are confirming/rejecting the SAME issueText claim (same defect).\n")
 	sb.WriteString("- Ignore any \"Additions (out of scope)\" sections; they must not affect alignment.\n\n")
-	sb.WriteString("Reply ONLY JSON: {\"agree\":true/false,\"explanation\":\"...\"}.\n")
-	sb.WriteString("agree=true ONLY if both transcripts are clearly talking about the same underlying defect described by issueText.\n")
-	sb.WriteString("If uncertain, return agree=false.\n")
-	return sb.String()
 }
 
 func parseAlignment(raw string) (alignmentVerdict, error) {
@@ -404,6 +789,15 @@ func parseAlignment(raw string) (alignmentVerdict, error) {
 	if err := json.Unmarshal([]byte(jsonBlock), &verdict); err != nil {
 		return alignmentVerdict{}, fmt.Errorf("invalid alignment JSON: %v (json=%q raw=%q)", err, truncateForError(jsonBlock), truncateForError(trimmed))
 	}
+	verdict.Relationship = strings.ToLower(strings.TrimSpace(verdict.Relationship))
+	switch verdict.Relationship {
+	case "", AlignmentSame, AlignmentRelated, AlignmentDifferent:
+	default:
+		return alignmentVerdict{}, fmt.Errorf("unknown alignment relationship %q (raw=%q)", verdict.Relationship, truncateForError(trimmed))
+	}
+	if verdict.Score != nil && (*verdict.Score < 0 || *verdict.Score > 1) {
+		return alignmentVerdict{}, fmt.Errorf("alignment score %v outside 0-1 (raw=%q)", *verdict.Score, truncateForError(trimmed))
+	}
 	return verdict, nil
 }
 
@@ -7,7 +7,7 @@ import (
 
 func TestBuildIssueFinderPromptContainsInstructions(t *testing.T) {
 	task := "https://github.com/org/repo/pull/42"
-	got := buildIssueFinderPrompt(task, "/workspace/change_analysis.md")
+	got := buildIssueFinderPrompt(task, "/workspace/change_analysis.md", false, "")
 
 	required := []string{
 		"Task: " + task,
@@ -51,7 +51,7 @@ func TestBuildHasRealIssuePromptContainsContractAndSentinel(t *testing.T) {
 
 func TestBuildReviewerPromptContainsRoleDirectives(t *testing.T) {
 	issueText := "some issue"
-	prompt := buildLogicAnalystPrompt(issueText)
+	prompt := buildLogicAnalystPrompt(issueText, DefaultSeverityPolicy(), "")
 	requiredPhrases := []string{
 		"opponent's Issue List",
 		"adversarial / rebuttal-style review",
@@ -69,8 +69,24 @@ func TestBuildReviewerPromptContainsRoleDirectives(t *testing.T) {
 	}
 }
 
+func TestBuildReviewerPromptStance(t *testing.T) {
+	if prompt := buildLogicAnalystPrompt("issue", DefaultSeverityPolicy(), ""); !strings.Contains(prompt, stanceBlocks[DefaultStance]) {
+		t.Fatalf("default reviewer prompt should be skeptical: %q", prompt)
+	}
+	prompt := buildLogicAnalystPrompt("issue", DefaultSeverityPolicy(), StanceConfirming)
+	if !strings.Contains(prompt, stanceBlocks[StanceConfirming]) || strings.Contains(prompt, stanceBlocks[StanceSkeptical]) {
+		t.Fatalf("confirming stance not applied: %q", prompt)
+	}
+	if stance, err := ParseStance(" Balanced "); err != nil || stance != StanceBalanced {
+		t.Fatalf("ParseStance should normalize case and space, got %q, %v", stance, err)
+	}
+	if _, err := ParseStance("lenient"); err == nil {
+		t.Fatal("expected an unknown stance to be rejected")
+	}
+}
+
 func TestBuildTesterPromptContainsRoleDirectives(t *testing.T) {
-	prompt := buildTesterPrompt("some task", "some issue", "/workspace/change_analysis.md")
+	prompt := buildTesterPrompt("some task", "some issue", changeAnalysis{Path: "/workspace/change_analysis.md"})
 	requiredPhrases := []string{
 		"TESTER",
 		universalStudyLine,
@@ -94,7 +110,7 @@ func TestBuildTesterPromptContainsRoleDirectives(t *testing.T) {
 }
 
 func TestBuildExchangePromptIncludesSelfPeerAndReviewerGuidance(t *testing.T) {
-	prompt := buildExchangePrompt("reviewer", "task", "issue", "/workspace/change_analysis.md", "my old verdict", "peer said hello")
+	prompt := buildExchangePrompt("reviewer", "task", "issue", changeAnalysis{Path: "/workspace/change_analysis.md"}, "my old verdict", "peer said hello", 0)
 	required := []string{
 		"my old verdict",
 		"peer said hello",
@@ -112,7 +128,7 @@ func TestBuildExchangePromptIncludesSelfPeerAndReviewerGuidance(t *testing.T) {
 }
 
 func TestBuildExchangePromptProvidesTesterGuidance(t *testing.T) {
-	prompt := buildExchangePrompt("tester", "task", "issue", "/workspace/change_analysis.md", "my reproduction log", "peer logic view")
+	prompt := buildExchangePrompt("tester", "task", "issue", changeAnalysis{Path: "/workspace/change_analysis.md"}, "my reproduction log", "peer logic view", 0)
 	required := []string{
 		"my reproduction log",
 		"peer logic view",
@@ -129,6 +145,33 @@ func TestBuildExchangePromptProvidesTesterGuidance(t *testing.T) {
 	}
 }
 
+func TestBuildExchangePromptCondensesLongOpinionsKeepingVerdict(t *testing.T) {
+	peer := "## Reasoning\n" + strings.Repeat("long trace line\n", 200) +
+		"# VERDICT: CONFIRMED\n\nClaim: cache key drops tenant\nAnchor: cache.go:42\nSeverity: P1\n" +
+		"\n## Additions (out of scope)\n" + strings.Repeat("unrelated note\n", 50)
+	prompt := buildExchangePrompt("reviewer", "task", "issue", changeAnalysis{Path: "/workspace/change_analysis.md"}, "short self", peer, 400)
+
+	for _, needle := range []string{"# VERDICT: CONFIRMED", "Anchor: cache.go:42", "Severity: P1", "[TRUNCATED:", "condensed to fit"} {
+		if !strings.Contains(prompt, needle) {
+			t.Fatalf("condensed exchange prompt missing %q: %q", needle, prompt)
+		}
+	}
+	if strings.Contains(prompt, "unrelated note") {
+		t.Fatalf("out-of-scope additions should be dropped before other sections: %q", prompt)
+	}
+	if !strings.Contains(prompt, "<<<SELF>>>\nshort self") {
+		t.Fatalf("opinion under the cap should be kept verbatim: %q", prompt)
+	}
+
+	condensed, truncated := condenseOpinion(peer, 400)
+	if !truncated || len(condensed) > 400 {
+		t.Fatalf("condenseOpinion returned %d bytes (truncated=%v), want <= 400", len(condensed), truncated)
+	}
+	if _, truncated := condenseOpinion(peer, 0); truncated {
+		t.Fatal("zero limit should disable condensing")
+	}
+}
+
 func TestBuildAlignmentPromptContainsInputs(t *testing.T) {
 	alpha := Transcript{Text: "A says # VERDICT: CONFIRMED"}
 	beta := Transcript{Text: "B says # VERDICT: CONFIRMED"}
@@ -149,7 +192,7 @@ func TestBuildAlignmentPromptContainsInputs(t *testing.T) {
 }
 
 func TestBuildScoutPromptWritesToPath(t *testing.T) {
-	prompt := buildScoutPrompt("task", "/workspace/change_analysis.md")
+	prompt := buildScoutPrompt("task", "/workspace/change_analysis.md", nil, "", false, "")
 	required := []string{
 		"Role: SCOUT",
 		universalStudyLine,
@@ -170,6 +213,58 @@ func TestBuildScoutPromptWritesToPath(t *testing.T) {
 	}
 }
 
+func TestIncludeBlameAddsProvenanceToScoutAndFinder(t *testing.T) {
+	plain := buildScoutPrompt("task", "/workspace/change_analysis.md", nil, "", false, "")
+	if strings.Contains(plain, "git blame") || strings.Contains(plain, "Provenance") {
+		t.Fatalf("scout prompt should not mention blame unless asked: %q", plain)
+	}
+	scout := buildScoutPrompt("task", "/workspace/change_analysis.md", nil, "", true, "")
+	for _, needle := range []string{"git blame -w MERGE_BASE_SHA..HEAD", "Provenance line: NEW or PRE-EXISTING"} {
+		if !strings.Contains(scout, needle) {
+			t.Fatalf("scout prompt missing %q: %q", needle, scout)
+		}
+	}
+	if strings.Contains(buildIssueFinderPrompt("task", "/workspace/change_analysis.md", false, ""), "Provenance priority") {
+		t.Fatal("finder prompt should not prioritize by provenance unless asked")
+	}
+	finder := buildIssueFinderPrompt("task", "/workspace/change_analysis.md", true, "")
+	if !strings.Contains(finder, "Provenance priority") || !strings.Contains(finder, "NEW code first") {
+		t.Fatalf("finder prompt missing provenance priority: %q", finder)
+	}
+}
+
+func TestNormalizeCommitRange(t *testing.T) {
+	for _, spec := range []string{"abc123..def456", " main~3..HEAD ", "v1.2.0..release/1.3"} {
+		if _, err := normalizeCommitRange(spec); err != nil {
+			t.Fatalf("normalizeCommitRange(%q) error: %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"abc123", "abc123..", "..def456", "abc...def", "--output=x..HEAD", "a b..c", "a..b..c"} {
+		if _, err := normalizeCommitRange(spec); err == nil {
+			t.Fatalf("normalizeCommitRange(%q) should fail", spec)
+		}
+	}
+}
+
+func TestCommitRangeReplacesMergeBaseDiscovery(t *testing.T) {
+	prompts := map[string]string{
+		"scout":  buildScoutPrompt("task", "/workspace/change_analysis.md", nil, "", true, "abc123..def456"),
+		"focus":  buildFocusPrompt("task", "/workspace/focus.json", "", "abc123..def456"),
+		"finder": buildIssueFinderPrompt("task", "/workspace/change_analysis.md", false, "abc123..def456"),
+	}
+	for name, prompt := range prompts {
+		if !strings.Contains(prompt, "git diff abc123..def456") && !strings.Contains(prompt, "git diff --stat abc123..def456") {
+			t.Fatalf("%s prompt does not diff the range: %q", name, prompt)
+		}
+		if strings.Contains(prompt, "git merge-base") {
+			t.Fatalf("%s prompt still asks for the merge base: %q", name, prompt)
+		}
+	}
+	if !strings.Contains(prompts["scout"], "git blame -w abc123..def456") {
+		t.Fatalf("scout blame step should use the range: %q", prompts["scout"])
+	}
+}
+
 func TestExtractTranscriptVerdict(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -226,3 +321,42 @@ func TestExtractTranscriptVerdict(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractTranscriptVerdictReadsNearbyConfidence(t *testing.T) {
+	cases := []struct {
+		input string
+		want  float64
+	}{
+		{"# VERDICT: CONFIRMED\nConfidence: 0.4\n## Reasoning", 0.4},
+		{"Confidence: **65%**\n# VERDICT: REJECTED", 0.65},
+		{"# VERDICT: CONFIRMED\n\n- **Confidence:** low (could not run the test)", 0.3},
+		{"# VERDICT: CONFIRMED\nConfidence: 85", 0.85},
+	}
+	for _, tc := range cases {
+		decision, ok := extractTranscriptVerdict(tc.input)
+		if !ok || decision.Confidence == nil || *decision.Confidence != tc.want {
+			t.Fatalf("input %q: expected confidence %v, got %+v", tc.input, tc.want, decision)
+		}
+	}
+	decision, ok := extractTranscriptVerdict("# VERDICT: CONFIRMED\n\n## Reasoning\nok\n\nConfidence: 0.2")
+	if !ok || decision.Confidence != nil {
+		t.Fatalf("expected a confidence line outside the window to be ignored, got %+v", decision)
+	}
+}
+
+func TestNeedsLanguageNormalization(t *testing.T) {
+	cases := map[string]bool{
+		"结论：确认。该问题可以复现。":                                           true,
+		"# VERDICT: CONFIRMED\n\n该问题可以复现。":                         false,
+		"The bug reproduces; see the `缓存` comment in cache.go:42.": false,
+		"": false,
+	}
+	for text, want := range cases {
+		if got := needsLanguageNormalization(text); got != want {
+			t.Fatalf("needsLanguageNormalization(%q) = %v, want %v", text, got, want)
+		}
+	}
+	if _, err := parseLanguageNormalizationResponse(`{"text": "  "}`); err == nil {
+		t.Fatal("expected an empty normalized transcript to be rejected")
+	}
+}
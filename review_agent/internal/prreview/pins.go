@@ -0,0 +1,143 @@
+package prreview
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"review_agent/internal/logx"
+	t "review_agent/internal/tools"
+)
+
+// StageFocus names the optional focus pass; together with the prompt stages it
+// lists the steps Options.PinnedSteps accepts.
+const StageFocus = "focus"
+
+const pinBranchPrefix = "branch:"
+
+// ParsePinnedStep parses a --pin-step value of the form "scout=branch:<id>".
+func ParsePinnedStep(spec string) (string, string, error) {
+	step, target, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok {
+		return "", "", fmt.Errorf("pin %q: want <step>=branch:<id>", spec)
+	}
+	step = strings.ToLower(strings.TrimSpace(step))
+	target = strings.TrimSpace(target)
+	if !strings.HasPrefix(target, pinBranchPrefix) {
+		return "", "", fmt.Errorf("pin %q: target must start with %q", spec, pinBranchPrefix)
+	}
+	branchID := strings.TrimSpace(strings.TrimPrefix(target, pinBranchPrefix))
+	if branchID == "" {
+		return "", "", fmt.Errorf("pin %q: missing branch id", spec)
+	}
+	if !isPinnableStep(step) {
+		return "", "", fmt.Errorf("pin %q: unknown step %q (want focus, scout, finder, reviewer or tester)", spec, step)
+	}
+	return step, branchID, nil
+}
+
+func isPinnableStep(step string) bool {
+	switch step {
+	case StageFocus, StageScout, StageFinder, StageReviewer, StageTester:
+		return true
+	}
+	return false
+}
+
+// normalizePinnedSteps validates the pins and switches on the stages they name,
+// since scout and tester are skipped by default.
+func normalizePinnedSteps(opts *Options) error {
+	if len(opts.PinnedSteps) == 0 {
+		return nil
+	}
+	pins := make(map[string]string, len(opts.PinnedSteps))
+	for step, branchID := range opts.PinnedSteps {
+		step = strings.ToLower(strings.TrimSpace(step))
+		branchID = strings.TrimSpace(branchID)
+		if !isPinnableStep(step) {
+			return fmt.Errorf("unknown pinned step %q (want focus, scout, finder, reviewer or tester)", step)
+		}
+		if branchID == "" {
+			return fmt.Errorf("pinned step %s has no branch id", step)
+		}
+		pins[step] = branchID
+	}
+	if opts.ReviewReport != "" {
+		for _, step := range []string{StageFocus, StageScout, StageFinder} {
+			if _, ok := pins[step]; ok {
+				return fmt.Errorf("pinned %s step conflicts with replaying a saved review report", step)
+			}
+		}
+	}
+	if _, ok := pins[StageFocus]; ok {
+		opts.FocusPass = true
+	}
+	if _, ok := pins[StageScout]; ok {
+		opts.SkipScout = false
+	}
+	if _, ok := pins[StageTester]; ok {
+		opts.SkipTester = false
+	}
+	opts.PinnedSteps = pins
+	return nil
+}
+
+// runStep executes agent for step, or reuses the output of a pinned or cached
+// branch in the shape execute_agent would have returned. ctx bounds the agent run.
+func (r *Runner) runStep(ctx context.Context, step, agent, prompt, parentBranchID string) (map[string]any, error) {
+	if branchID, ok := r.opts.PinnedSteps[step]; ok {
+		logx.Infof("Using pinned branch %s for the %s step instead of running %s.", branchID, step, agent)
+		data, err := r.reuseBranch(ctx, step, branchID)
+		if err != nil {
+			return nil, fmt.Errorf("pinned %s step: %w", step, err)
+		}
+		return data, nil
+	}
+	if !r.cachesStep(step) {
+		return r.executeAgent(ctx, agent, prompt, parentBranchID)
+	}
+	key := stepCacheKey(agent, withScope(prompt, r.opts.ScopeDir), parentBranchID)
+	if entry, ok := r.cachedStepBranch(step, key); ok {
+		data, err := r.reuseBranch(ctx, step, entry.BranchID)
+		if err == nil {
+			logx.Infof("Reusing cached branch %s for the %s step (cached %s).", entry.BranchID, step, entry.CreatedAt.Format(time.RFC3339))
+			return data, nil
+		}
+		logx.Warningf("Cached %s branch %s is unusable (%v); running %s.", step, entry.BranchID, err, agent)
+	}
+	data, err := r.executeAgent(ctx, agent, prompt, parentBranchID)
+	if err != nil {
+		return nil, err
+	}
+	// A finder run without a review log has nothing a later run could read back.
+	if branchID := t.ExtractBranchID(data); branchID != "" && data["review_log_synthesized"] != true {
+		r.storeStepBranch(key, stepCacheEntry{Step: step, Agent: agent, ParentBranchID: parentBranchID, BranchID: branchID})
+	}
+	return data, nil
+}
+
+// reuseBranch reads branchID's output for step, plus the review log for the finder.
+func (r *Runner) reuseBranch(ctx context.Context, step, branchID string) (map[string]any, error) {
+	out, err := r.callTool(ctx, "branch_output", map[string]any{"branch_id": branchID, "full_output": true})
+	if err != nil {
+		return nil, err
+	}
+	response := strings.TrimSpace(stringField(out, "output"))
+	if response == "" {
+		return nil, fmt.Errorf("branch %s has no output", branchID)
+	}
+	data := map[string]any{"branch_id": branchID, "response": response}
+	if step == StageFinder {
+		artifact, err := r.callTool(ctx, "read_artifact", map[string]any{
+			"branch_id": branchID,
+			"path":      filepath.Join(r.opts.WorkspaceDir, r.opts.ReviewArtifactName),
+		})
+		if err != nil {
+			return nil, err
+		}
+		data["review_report"] = stringField(artifact, "content")
+	}
+	return data, nil
+}
@@ -0,0 +1,48 @@
+package prreview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromptVersion names the prompt generation this package renders (the SOP-based
+// finder). review_agent_v1.1 carries the quantity-mandate generation. Each
+// Result records it as prompt_version.
+const PromptVersion = "v1.0"
+
+// Prompt stages that RenderPrompt can render.
+const (
+	StageFinder   = "finder"
+	StageScout    = "scout"
+	StageReviewer = "reviewer"
+	StageTester   = "tester"
+)
+
+// SampleTask is the task RenderPrompt falls back to when none is given.
+const SampleTask = "Review the PR that adds exponential backoff to the HTTP client's retry loop."
+
+const (
+	sampleIssueText    = "ISSUE: retry loop never resets the backoff after a success (client/retry.go:42), so later failures wait the maximum delay. Severity: P1"
+	sampleAnalysisPath = "/workspace/" + changeAnalysisFilename
+)
+
+// RenderPrompt returns the prompt this version sends for stage, filled with task
+// and fixed sample inputs, so the prompt generations can be compared in isolation.
+func RenderPrompt(stage, task string) (string, error) {
+	task = strings.TrimSpace(task)
+	if task == "" {
+		task = SampleTask
+	}
+	switch strings.ToLower(strings.TrimSpace(stage)) {
+	case StageFinder:
+		return buildIssueFinderPrompt(task, sampleAnalysisPath, false, ""), nil
+	case StageScout:
+		return buildScoutPrompt(task, sampleAnalysisPath, nil, "", false, ""), nil
+	case StageReviewer:
+		return buildLogicAnalystPrompt(sampleIssueText, DefaultSeverityPolicy(), ""), nil
+	case StageTester:
+		return buildTesterPrompt(task, sampleIssueText, changeAnalysis{Path: sampleAnalysisPath}), nil
+	default:
+		return "", fmt.Errorf("unknown prompt stage %q (want finder, scout, reviewer or tester)", stage)
+	}
+}
@@ -0,0 +1,25 @@
+package prreview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPromptCoversEachStage(t *testing.T) {
+	for _, stage := range []string{StageFinder, StageScout, StageReviewer, StageTester} {
+		prompt, err := RenderPrompt(stage, "")
+		if err != nil {
+			t.Fatalf("%s: %v", stage, err)
+		}
+		if strings.TrimSpace(prompt) == "" {
+			t.Fatalf("%s: empty prompt", stage)
+		}
+	}
+	finder, _ := RenderPrompt("Finder", "custom task")
+	if !strings.Contains(finder, "custom task") || strings.Contains(finder, SampleTask) {
+		t.Fatalf("finder prompt should use the given task: %q", finder)
+	}
+	if _, err := RenderPrompt("summary", ""); err == nil {
+		t.Fatal("expected unknown stage to be rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package prreview
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	b "review_agent/internal/brain"
+	"review_agent/internal/exitcodes"
 	"review_agent/internal/logx"
 	"review_agent/internal/streaming"
 	t "review_agent/internal/tools"
@@ -21,8 +23,54 @@ const (
 	statusIssues      = "issues_found"
 	commentConfirmed  = "confirmed"
 	commentUnresolved = "unresolved"
+
+	commentConfirmedLowConfidence = "confirmed_low_confidence"
+)
+
+// Issue statuses as they appear in IssueReport.Status, for consumers of a Result.
+const (
+	StatusConfirmed              = commentConfirmed
+	StatusConfirmedLowConfidence = commentConfirmedLowConfidence
+	StatusUnresolved             = commentUnresolved
+)
+
+// Misaligned confirm policies decide what happens when both roles confirm an issue
+// in Round 2 but the alignment check says they describe different defects.
+const (
+	MisalignedDrop                = "drop"
+	MisalignedReportLowConfidence = "report_low_confidence"
+)
+
+// Tie-break policies settle an issue when the exchange ends with the reviewer and
+// tester still on different verdicts.
+const (
+	TieBreakConservative  = "conservative"
+	TieBreakTrustTester   = "trust_tester"
+	TieBreakTrustReviewer = "trust_reviewer"
+)
+
+// Missing-review-log policies decide what a review_code run that never writes its
+// review log amounts to once its retries are used up.
+const (
+	MissingReviewLogError = "error"
+	MissingReviewLogClean = "clean"
+)
+
+// Progress phases passed to Options.OnProgress, in the order a run reaches them.
+const (
+	PhaseScout         = "scout"
+	PhaseReview        = "review"
+	PhaseIssueVerified = "issue_verified"
 )
 
+// DefaultAlignmentThreshold is the graded alignment score a "same" relationship
+// must reach to count as agreement.
+const DefaultAlignmentThreshold = 0.7
+
+// DefaultAlignmentParseRetries is the number of alignment re-prompts when
+// Options.AlignmentParseRetries is unset.
+const DefaultAlignmentParseRetries = 1
+
 // Options configures the PR review workflow.
 type Options struct {
 	Task           string
@@ -31,6 +79,100 @@ type Options struct {
 	WorkspaceDir   string
 	SkipScout      bool
 	SkipTester     bool
+	// FocusPass runs a cheap risk triage before the scout and feeds its top areas into the scout prompt.
+	FocusPass bool
+	// MisalignedConfirmPolicy is MisalignedDrop (default) or MisalignedReportLowConfidence.
+	MisalignedConfirmPolicy string
+	// TieBreak is TieBreakConservative (default), TieBreakTrustTester or TieBreakTrustReviewer.
+	TieBreak string
+	// SeverityPolicy is rendered into the reviewer prompt; empty means DefaultSeverityPolicy.
+	SeverityPolicy SeverityPolicy
+	// MaxDuration bounds Run, both its LLM calls and its waits on agent branches;
+	// zero leaves them unbounded.
+	MaxDuration time.Duration
+	// MaxOpinionChars caps each opinion embedded in an exchange prompt; zero disables the cap.
+	MaxOpinionChars int
+	// ReviewReport replays a saved code_review.log instead of running the scout and
+	// issue finder; verification forks from ReviewerBranchID. Both must be set together.
+	ReviewReport     string
+	ReviewerBranchID string
+	// JSONMode requests provider-enforced JSON for the auxiliary triage, verdict and
+	// alignment calls; deployments without support fall back to plain completions.
+	JSONMode bool
+	// PinnedSteps maps a step (focus, scout, finder, reviewer or tester) to a prior
+	// branch whose output is reused instead of running the agent. Pinning a step
+	// enables its stage; reviewer and tester pins cover Round 1 only.
+	PinnedSteps map[string]string
+	// ReviewArtifactName is the review log a pinned finder branch is read from;
+	// empty means the tool handler's configured name.
+	ReviewArtifactName string
+	// GradedAlignment asks the alignment check for a relationship and score instead
+	// of a bare boolean: "same" at or above AlignmentThreshold confirms, "related"
+	// is sent to the exchange round (or the misaligned policy after it), and
+	// "different" drops the issue.
+	GradedAlignment bool
+	// AlignmentThreshold is the minimum graded score for agreement; zero means
+	// DefaultAlignmentThreshold.
+	AlignmentThreshold float64
+	// AlignmentParseRetries is how many times an empty or malformed alignment
+	// reply is re-prompted for bare JSON. Once they are used up the transcripts
+	// are treated as not aligned rather than failing the issue. Zero means
+	// DefaultAlignmentParseRetries; a negative value disables re-prompting.
+	AlignmentParseRetries int
+	// VerdictConfidenceThreshold is the lowest stated confidence (0-1) at which an
+	// explicit VERDICT marker is taken as final. A marker whose "Confidence:" line
+	// falls below it is treated as hedged, and the verdict is extracted from the
+	// whole transcript instead. Zero accepts every marker.
+	VerdictConfidenceThreshold float64
+	// AuxSystemPrompts replaces the system prompt of the named auxiliary JSON calls
+	// (AuxIssueCheck, AuxVerdict, AuxAlignment, AuxLanguage); unset calls use the
+	// built-ins.
+	AuxSystemPrompts map[string]string
+	// MaxAuxInputChars is the longest report the has_issue call receives in one
+	// piece; longer reports are split at section markers and any chunk with an
+	// issue counts. Zero means DefaultMaxAuxInputChars.
+	MaxAuxInputChars int
+	// ScopeDir restricts every agent to a repository subdirectory (e.g. for one
+	// package of a monorepo), and the scout and focus diffs to files under it.
+	ScopeDir string
+	// IncludeBlame has the scout label each high-risk hunk as new in this PR or
+	// pre-existing (via git blame against the merge base), and the issue finder
+	// prioritize the new code.
+	IncludeBlame bool
+	// CommitRange ("<from>..<to>", e.g. abc123..def456) reviews exactly those
+	// commits: the focus, scout and finder prompts diff the range instead of
+	// discovering the merge base with the base branch.
+	CommitRange string
+	// Stance is the reviewer's bias: StanceSkeptical (default), StanceBalanced or
+	// StanceConfirming.
+	Stance string
+	// TreatMissingReviewLogAs is MissingReviewLogError (default), which fails the
+	// run when review_code never writes its log, or MissingReviewLogClean, which
+	// instead reports a clean review if the agent's output says it found no issues.
+	TreatMissingReviewLogAs string
+	// OnProgress, when set, receives the partial result after the scout, the issue
+	// finder and each verified issue. It runs synchronously on the workflow and
+	// must not keep the result past the call.
+	OnProgress func(phase string, partial *Result)
+	// NormalizeLanguage rewrites a verification transcript that is mostly CJK and
+	// has no English VERDICT marker into English with one LLM pass, so the
+	// verdict, Claim and Anchor parsers can read it. The original text is kept
+	// in Transcript.OriginalText; a failed pass keeps the transcript as is.
+	NormalizeLanguage bool
+	// CacheDir keeps the branch of each focus, scout and finder run, keyed by
+	// agent, prompt hash and parent branch, so an identical step in a later run
+	// reuses that branch's output instead of spawning a new one. Empty disables it.
+	CacheDir string
+	// CacheTTL is how long a cached step stays reusable; zero means DefaultCacheTTL.
+	CacheTTL time.Duration
+	// RefreshCache ignores existing CacheDir entries (new runs are still recorded).
+	RefreshCache bool
+	// Subject names the reviewed change in Result.Headline, e.g. "PR #123"; empty
+	// means the parent branch.
+	Subject string
+	// ChangedFiles is how many files the change touches, when the caller knows;
+	// zero leaves the count out of Result.Headline.
+	ChangedFiles int
 }
 
 // Result captures the high-level outcome plus supporting artifacts.
@@ -42,12 +184,40 @@ type Result struct {
 	Issues         []IssueReport `json:"issues"`
 	StartBranchID  string        `json:"start_branch_id,omitempty"`
 	LatestBranchID string        `json:"latest_branch_id,omitempty"`
+	PromptVersion  string        `json:"prompt_version,omitempty"`
+	// Headline is a one-sentence TL;DR templated from the outcome, for
+	// notifications and quick scanning.
+	Headline string `json:"headline,omitempty"`
+	// TokenUsage counts this process's own LLM calls, all auxiliary JSON calls;
+	// the review itself runs in MCP branches, which report no tokens.
+	TokenUsage map[string]b.Usage `json:"token_usage,omitempty"`
+	// EstimatedCostUSD prices TokenUsage when a price table was given.
+	EstimatedCostUSD map[string]float64 `json:"estimated_cost_usd,omitempty"`
+}
+
+// HasFindings reports whether the review confirmed at least one issue, with or
+// without low confidence; issues the verification rejected or left unresolved
+// don't count. With --fail-on-issues the CLI turns it into the Findings exit
+// status.
+func (r *Result) HasFindings() bool {
+	if r == nil {
+		return false
+	}
+	for _, issue := range r.Issues {
+		if issue.Status == commentConfirmed || issue.Status == commentConfirmedLowConfidence {
+			return true
+		}
+	}
+	return false
 }
 
 // ReviewerLog records the raw output from each review_code run.
 type ReviewerLog struct {
 	BranchID string `json:"branch_id"`
 	Report   string `json:"report"`
+	// Synthesized marks a report made up from the agent's output because it never
+	// wrote the review log (see Options.TreatMissingReviewLogAs).
+	Synthesized bool `json:"synthesized,omitempty"`
 }
 
 // Transcript records a codex agent's reasoning for an issue confirmation attempt.
@@ -58,6 +228,20 @@ type Transcript struct {
 	Text          string `json:"text"`
 	Verdict       string `json:"verdict,omitempty"`
 	VerdictReason string `json:"verdict_reason,omitempty"`
+	// OriginalText is the agent's own response when Text is its English rewrite.
+	OriginalText string `json:"original_text,omitempty"`
+}
+
+// headlineStatuses are the issue statuses Result.Headline counts, in order.
+var headlineStatuses = []headlineStatus{
+	{commentConfirmed, "confirmed"},
+	{commentConfirmedLowConfidence, "low-confidence"},
+	{commentUnresolved, "unresolved"},
+}
+
+// issuePriority is the headline bucket of an issue, read from its text.
+func issuePriority(issue IssueReport) string {
+	return priorityFromText(issue.IssueText)
 }
 
 // IssueReport stores the consensus outcome for a single ISSUE block.
@@ -72,6 +256,41 @@ type IssueReport struct {
 	TesterRound2BranchID   string     `json:"tester_round2_branch_id,omitempty"`
 	ExchangeRounds         int        `json:"exchange_rounds"`
 	VerdictExplanation     string     `json:"verdict_explanation,omitempty"`
+	TieBreak               string     `json:"tie_break,omitempty"`
+	// Alignment is the latest cross-transcript alignment rationale, kept apart
+	// from VerdictExplanation so `explain` can show it on its own.
+	Alignment string `json:"alignment,omitempty"`
+	// AlignmentRelationship and AlignmentScore accompany Alignment in graded mode.
+	AlignmentRelationship string   `json:"alignment_relationship,omitempty"`
+	AlignmentScore        *float64 `json:"alignment_score,omitempty"`
+	// Consensus classifies how the roles' final verdicts related when the status
+	// was decided; VerdictExplanation says the same in prose.
+	Consensus string `json:"consensus,omitempty"`
+}
+
+// Consensus outcomes recorded in IssueReport.Consensus.
+const (
+	ConsensusAgreedReject            = "agreed_reject"
+	ConsensusAgreedConfirmAligned    = "agreed_confirm_aligned"
+	ConsensusAgreedConfirmMisaligned = "agreed_confirm_misaligned"
+	ConsensusDisagreed               = "disagreed"
+	// ConsensusUndetermined: neither a disagreement nor a shared verdict, e.g.
+	// both roles' verdicts could not be extracted.
+	ConsensusUndetermined = "undetermined"
+	// ConsensusReviewerOnly: SkipTester left the Reviewer as the only role.
+	ConsensusReviewerOnly = "reviewer_only"
+)
+
+// consensusOf classifies two final verdicts that did not both confirm.
+func consensusOf(reviewerVerdict, testerVerdict string) string {
+	switch {
+	case reviewerVerdict != testerVerdict:
+		return ConsensusDisagreed
+	case reviewerVerdict == "rejected":
+		return ConsensusAgreedReject
+	default:
+		return ConsensusUndetermined
+	}
 }
 
 // Runner executes the two-phase PR review workflow.
@@ -88,6 +307,8 @@ type Runner struct {
 	hasRealIssueOverride func(reportText string) (bool, error)
 	// verdictOverride is a test hook to avoid network calls in determineVerdict().
 	verdictOverride func(transcript Transcript) (verdictDecision, error)
+	// languageOverride is a test hook replacing the language normalization call.
+	languageOverride func(transcript Transcript) (string, error)
 }
 
 // NewRunner validates options and constructs a workflow runner.
@@ -99,9 +320,101 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 		return nil, errors.New("tool handler is required")
 	}
 	opts.Task = strings.TrimSpace(opts.Task)
+	opts.Subject = strings.TrimSpace(opts.Subject)
 	opts.ProjectName = strings.TrimSpace(opts.ProjectName)
 	opts.ParentBranchID = strings.TrimSpace(opts.ParentBranchID)
 	opts.WorkspaceDir = strings.TrimSpace(opts.WorkspaceDir)
+	opts.ReviewReport = strings.TrimSpace(opts.ReviewReport)
+	opts.ReviewerBranchID = strings.TrimSpace(opts.ReviewerBranchID)
+	if (opts.ReviewReport == "") != (opts.ReviewerBranchID == "") {
+		return nil, errors.New("a saved review report and its reviewer branch id must be provided together")
+	}
+	opts.ReviewArtifactName = strings.TrimSpace(opts.ReviewArtifactName)
+	if opts.ReviewArtifactName == "" {
+		opts.ReviewArtifactName = handler.ReviewArtifactName()
+	}
+	opts.TreatMissingReviewLogAs = strings.ToLower(strings.TrimSpace(opts.TreatMissingReviewLogAs))
+	switch opts.TreatMissingReviewLogAs {
+	case "":
+		opts.TreatMissingReviewLogAs = MissingReviewLogError
+	case MissingReviewLogError, MissingReviewLogClean:
+	default:
+		return nil, fmt.Errorf("unknown missing review log policy %q (want error or clean)", opts.TreatMissingReviewLogAs)
+	}
+	handler.SetMissingReviewLogClean(opts.TreatMissingReviewLogAs == MissingReviewLogClean)
+	scopeDir, err := normalizeScopeDir(opts.ScopeDir)
+	if err != nil {
+		return nil, err
+	}
+	opts.ScopeDir = scopeDir
+	if opts.CommitRange, err = normalizeCommitRange(opts.CommitRange); err != nil {
+		return nil, err
+	}
+	if err := normalizePinnedSteps(&opts); err != nil {
+		return nil, err
+	}
+	opts.MisalignedConfirmPolicy = strings.ToLower(strings.TrimSpace(opts.MisalignedConfirmPolicy))
+	switch opts.MisalignedConfirmPolicy {
+	case "":
+		opts.MisalignedConfirmPolicy = MisalignedDrop
+	case MisalignedDrop, MisalignedReportLowConfidence:
+	default:
+		return nil, fmt.Errorf("unknown misaligned confirm policy %q (want drop or report_low_confidence)", opts.MisalignedConfirmPolicy)
+	}
+	opts.TieBreak = strings.ToLower(strings.TrimSpace(opts.TieBreak))
+	switch opts.TieBreak {
+	case "":
+		opts.TieBreak = TieBreakConservative
+	case TieBreakConservative, TieBreakTrustTester, TieBreakTrustReviewer:
+	default:
+		return nil, fmt.Errorf("unknown tie-break policy %q (want conservative, trust_tester or trust_reviewer)", opts.TieBreak)
+	}
+	stance, err := ParseStance(opts.Stance)
+	if err != nil {
+		return nil, err
+	}
+	opts.Stance = stance
+	if len(opts.SeverityPolicy.Levels) == 0 {
+		opts.SeverityPolicy = DefaultSeverityPolicy()
+	} else if err := opts.SeverityPolicy.Validate(); err != nil {
+		return nil, err
+	}
+	auxPrompts, err := normalizeAuxSystemPrompts(opts.AuxSystemPrompts)
+	if err != nil {
+		return nil, err
+	}
+	opts.AuxSystemPrompts = auxPrompts
+	if opts.MaxAuxInputChars < 0 {
+		return nil, fmt.Errorf("max aux input chars must not be negative (got %d)", opts.MaxAuxInputChars)
+	}
+	if opts.MaxAuxInputChars == 0 {
+		opts.MaxAuxInputChars = DefaultMaxAuxInputChars
+	}
+	if opts.AlignmentThreshold < 0 || opts.AlignmentThreshold > 1 {
+		return nil, fmt.Errorf("alignment threshold must be between 0 and 1 (got %v)", opts.AlignmentThreshold)
+	}
+	if opts.AlignmentThreshold == 0 {
+		opts.AlignmentThreshold = DefaultAlignmentThreshold
+	}
+	switch {
+	case opts.AlignmentParseRetries == 0:
+		opts.AlignmentParseRetries = DefaultAlignmentParseRetries
+	case opts.AlignmentParseRetries < 0:
+		opts.AlignmentParseRetries = 0
+	}
+	if opts.VerdictConfidenceThreshold < 0 || opts.VerdictConfidenceThreshold > 1 {
+		return nil, fmt.Errorf("verdict confidence threshold must be between 0 and 1 (got %v)", opts.VerdictConfidenceThreshold)
+	}
+	opts.CacheDir = strings.TrimSpace(opts.CacheDir)
+	if opts.CacheTTL < 0 {
+		return nil, fmt.Errorf("cache ttl must not be negative (got %s)", opts.CacheTTL)
+	}
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.MaxOpinionChars < 0 {
+		return nil, fmt.Errorf("max opinion chars must not be negative (got %d)", opts.MaxOpinionChars)
+	}
 	if opts.Task == "" {
 		return nil, errors.New("task description is required")
 	}
@@ -111,6 +424,9 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	if opts.ParentBranchID == "" {
 		return nil, errors.New("parent branch id is required")
 	}
+	if err := checkWorkspaceDir(opts); err != nil {
+		return nil, err
+	}
 	return &Runner{
 		brain:    brain,
 		handler:  handler,
@@ -120,68 +436,126 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	}, nil
 }
 
+// checkWorkspaceDir fails when a stage the options enable writes or reads an
+// artifact under the workspace dir and none is set, so the run stops before
+// spawning branches rather than at that stage.
+func checkWorkspaceDir(opts Options) error {
+	if strings.TrimSpace(opts.WorkspaceDir) != "" {
+		return nil
+	}
+	var users []string
+	if opts.ReviewReport == "" {
+		users = append(users, "the issue finder (reads "+opts.ReviewArtifactName+")")
+	}
+	if !opts.SkipScout {
+		if opts.FocusPass {
+			users = append(users, "the focus pass (writes "+focusFilename+")")
+		}
+		users = append(users, "the scout (writes "+changeAnalysisFilename+")")
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	return workspaceDirError(strings.Join(users, ", "))
+}
+
+// workspaceDirError says which stages need the workspace dir and how to set it.
+func workspaceDirError(users string) error {
+	return exitcodes.NewConfigError(fmt.Errorf("workspace dir is required by %s: set WORKSPACE_DIR to the agent's workspace path", users))
+}
+
 // Run executes the workflow and returns the structured result.
 func (r *Runner) Run() (*Result, error) {
 	logx.Infof("Starting PR review workflow for parent %s", r.opts.ParentBranchID)
+	ctx := context.Background()
+	if r.opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.MaxDuration)
+		defer cancel()
+	}
+	started := time.Now()
 	parent := r.opts.ParentBranchID
 
 	result := &Result{
-		Task:         r.opts.Task,
-		ReviewerLogs: []ReviewerLog{},
-		Issues:       []IssueReport{},
+		Task:          r.opts.Task,
+		ReviewerLogs:  []ReviewerLog{},
+		Issues:        []IssueReport{},
+		PromptVersion: PromptVersion,
 	}
 
-	scoutBranchID := parent
-	analysisPath := ""
-	if r.opts.SkipScout {
-		logx.Infof("Skipping scout stage by request.")
+	var (
+		reviewLog     ReviewerLog
+		scoutBranchID string
+		analysisPath  string
+		err           error
+	)
+	if r.opts.ReviewReport != "" {
+		logx.Infof("Replaying saved review report from branch %s; skipping scout and issue finder.", r.opts.ReviewerBranchID)
+		reviewLog, err = r.replayReview(ctx)
 	} else {
-		if branchID, path, err := r.runScout(parent); err != nil {
-			logx.Warningf("SCOUT soft-failed; continuing without change analysis. err=%v", err)
-		} else {
-			scoutBranchID = branchID
-			analysisPath = path
+		scoutBranchID, analysisPath = r.prepareChangeAnalysis(ctx, parent)
+		if !r.opts.SkipScout {
+			r.reportProgress(PhaseScout, result)
 		}
+		reviewLog, err = r.runSingleReview(ctx, scoutBranchID, analysisPath)
 	}
-
-	reviewLog, err := r.runSingleReview(scoutBranchID, analysisPath)
 	if err != nil {
 		return nil, err
 	}
 	result.ReviewerLogs = append(result.ReviewerLogs, reviewLog)
+	r.reportProgress(PhaseReview, result)
 
-	if strings.TrimSpace(reviewLog.Report) == "" {
+	if strings.TrimSpace(reviewLog.Report) == "" || reviewLog.Synthesized {
 		result.Status = statusClean
 		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
-		r.attachBranchRange(result)
-		return result, nil
+		return r.finish(result, started), nil
 	}
 
 	// Check if the report actually describes a real issue
-	hasIssue, err := r.hasRealIssue(reviewLog.Report)
+	hasIssue, err := r.hasRealIssue(ctx, reviewLog.Report)
 	if err != nil {
 		return nil, err
 	}
 	if !hasIssue {
 		result.Status = statusClean
 		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
-		r.attachBranchRange(result)
-		return result, nil
+		return r.finish(result, started), nil
 	}
 
 	issueText := reviewLog.Report
 
 	// Pass the reviewer's branch ID to start the verification chain
-	report, err := r.confirmIssue(issueText, reviewLog.BranchID, analysisPath)
+	analysis := r.resolveChangeAnalysis(ctx, scoutBranchID, reviewLog.BranchID, analysisPath)
+	report, err := r.confirmIssue(ctx, issueText, reviewLog.BranchID, analysis)
 	if err != nil {
 		return nil, err
 	}
 	result.Issues = append(result.Issues, report)
-	confirmed, unresolved := summarizeIssueCounts(result.Issues)
+	r.reportProgress(PhaseIssueVerified, result)
+	confirmed, lowConfidence, unresolved := summarizeIssueCounts(result.Issues)
 	result.Status = statusIssues
-	result.Summary = fmt.Sprintf("Identified %d P0/P1 issue (%d confirmed, %d unresolved).", len(result.Issues), confirmed, unresolved)
+	if lowConfidence > 0 {
+		result.Summary = fmt.Sprintf("Identified %d P0/P1 issue (%d confirmed, %d low confidence, %d unresolved).", len(result.Issues), confirmed, lowConfidence, unresolved)
+	} else {
+		result.Summary = fmt.Sprintf("Identified %d P0/P1 issue (%d confirmed, %d unresolved).", len(result.Issues), confirmed, unresolved)
+	}
+	return r.finish(result, started), nil
+}
+
+// finish stamps the branch range and the headline on a completed result.
+func (r *Runner) finish(result *Result, started time.Time) *Result {
+	r.attachBranchRange(result)
+	result.Headline = r.headline(result, time.Since(started))
+	return result
+}
+
+// reportProgress hands the result so far to Options.OnProgress.
+func (r *Runner) reportProgress(phase string, result *Result) {
+	if r.opts.OnProgress == nil {
+		return
+	}
 	r.attachBranchRange(result)
-	return result, nil
+	r.opts.OnProgress(phase, result)
 }
 
 func (r *Runner) attachBranchRange(res *Result) {
@@ -200,24 +574,101 @@ func (r *Runner) attachBranchRange(res *Result) {
 	}
 }
 
-func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath string) (ReviewerLog, error) {
-	prompt := buildIssueFinderPrompt(r.opts.Task, changeAnalysisPath)
-	data, err := r.executeAgent("review_code", prompt, parentBranchID)
+// prepareChangeAnalysis runs the optional focus pass and scout, returning the branch
+// the issue finder should fork from and the change analysis path (empty when skipped).
+func (r *Runner) prepareChangeAnalysis(ctx context.Context, parent string) (string, string) {
+	scoutBranchID := parent
+	analysisPath := ""
+	if r.opts.SkipScout {
+		logx.Infof("Skipping scout stage by request.")
+		if r.opts.FocusPass {
+			logx.Warningf("Focus pass requested but scout is skipped; the focus pass only scopes the scout.")
+		}
+	} else {
+		var focusAreas []string
+		if r.opts.FocusPass {
+			if branchID, areas, err := r.runFocus(ctx, parent); err != nil {
+				logx.Warningf("FOCUS soft-failed; running scout without focus areas. err=%v", err)
+			} else {
+				scoutBranchID = branchID
+				focusAreas = areas
+			}
+		}
+		if branchID, path, err := r.runScout(ctx, scoutBranchID, focusAreas); err != nil {
+			logx.Warningf("SCOUT soft-failed; continuing without change analysis. err=%v", err)
+		} else {
+			scoutBranchID = branchID
+			analysisPath = path
+		}
+	}
+	return scoutBranchID, analysisPath
+}
+
+// resolveChangeAnalysis decides how verification prompts reference the scout's
+// analysis. The role forks descend from verifyBranchID, so the path is only
+// passed on when the file is readable there; otherwise the content read from
+// the scout branch is inlined, and with neither the reference is dropped rather
+// than pointing the roles at a missing file.
+func (r *Runner) resolveChangeAnalysis(ctx context.Context, scoutBranchID, verifyBranchID, path string) changeAnalysis {
+	if path == "" {
+		return changeAnalysis{}
+	}
+	if content, err := r.readArtifact(ctx, verifyBranchID, path); err == nil && strings.TrimSpace(content) != "" {
+		return changeAnalysis{Path: path}
+	}
+	content, err := r.readArtifact(ctx, scoutBranchID, path)
+	if err != nil || strings.TrimSpace(content) == "" {
+		logx.Warningf("Change analysis %s is readable from neither branch %s nor scout branch %s; verifying without it. err=%v", path, verifyBranchID, scoutBranchID, err)
+		return changeAnalysis{}
+	}
+	logx.Warningf("Change analysis %s did not carry forward to branch %s; inlining it from scout branch %s.", path, verifyBranchID, scoutBranchID)
+	return changeAnalysis{Path: path, Content: content}
+}
+
+func (r *Runner) readArtifact(ctx context.Context, branchID, path string) (string, error) {
+	artifact, err := r.callTool(ctx, "read_artifact", map[string]any{
+		"branch_id": branchID,
+		"path":      path,
+	})
+	if err != nil {
+		return "", err
+	}
+	return stringField(artifact, "content"), nil
+}
+
+// replayReview turns the saved report into the reviewer log verification starts
+// from, after confirming the reviewer branch still exists.
+func (r *Runner) replayReview(ctx context.Context) (ReviewerLog, error) {
+	branchID := r.opts.ReviewerBranchID
+	if _, err := r.callTool(ctx, "check_status", map[string]any{"branch_id": branchID}); err != nil {
+		return ReviewerLog{}, fmt.Errorf("reviewer branch %s: %w", branchID, err)
+	}
+	return ReviewerLog{
+		BranchID: branchID,
+		Report:   r.opts.ReviewReport,
+	}, nil
+}
+
+func (r *Runner) runSingleReview(ctx context.Context, parentBranchID string, changeAnalysisPath string) (ReviewerLog, error) {
+	prompt := buildIssueFinderPrompt(r.opts.Task, changeAnalysisPath, r.opts.IncludeBlame, r.opts.CommitRange)
+	data, err := r.runStep(ctx, StageFinder, "review_code", prompt, parentBranchID)
 	if err != nil {
 		return ReviewerLog{}, err
 	}
 	branchID := stringField(data, "branch_id")
 	reviewLog := strings.TrimSpace(stringField(data, "review_report"))
 	if reviewLog == "" {
-		return ReviewerLog{}, fmt.Errorf("review_code did not include code_review.log contents")
+		return ReviewerLog{}, fmt.Errorf("review_code did not include %s contents", r.opts.ReviewArtifactName)
 	}
+	synthesized, _ := data["review_log_synthesized"].(bool)
 	return ReviewerLog{
-		BranchID: branchID,
-		Report:   reviewLog,
+		BranchID:    branchID,
+		Report:      reviewLog,
+		Synthesized: synthesized,
 	}, nil
 }
 
-func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnalysisPath string) (IssueReport, error) {
+func (r *Runner) confirmIssue(ctx context.Context, issueText string, startBranchID string, analysis changeAnalysis) (IssueReport, error) {
 	// V2 Flow: Reviewer + Tester with two-round unanimous consensus
 
 	type roleRun struct {
@@ -226,13 +677,13 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 		err        error
 	}
 
-	runRoleWithVerdict := func(role string, parent string, out *roleRun) {
-		transcript, err := r.runRole(role, issueText, changeAnalysisPath, parent)
+	runRoleWithVerdict := func(ctx context.Context, role string, parent string, out *roleRun) {
+		transcript, err := r.runRole(ctx, role, issueText, analysis, parent)
 		if err != nil {
 			out.err = err
 			return
 		}
-		decision, err := r.determineVerdict(transcript)
+		decision, err := r.determineVerdict(ctx, transcript)
 		if err != nil {
 			out.err = fmt.Errorf("%s verdict: %w", role, err)
 			return
@@ -244,12 +695,12 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 	}
 
 	runExchangeWithVerdict := func(role string, selfOpinion string, peerOpinion string, parent string, out *roleRun) {
-		transcript, err := r.runExchange(role, issueText, changeAnalysisPath, selfOpinion, peerOpinion, parent)
+		transcript, err := r.runExchange(ctx, role, issueText, analysis, selfOpinion, peerOpinion, parent)
 		if err != nil {
 			out.err = err
 			return
 		}
-		decision, err := r.determineVerdict(transcript)
+		decision, err := r.determineVerdict(ctx, transcript)
 		if err != nil {
 			out.err = fmt.Errorf("%s round 2 verdict: %w", role, err)
 			return
@@ -262,7 +713,7 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 
 	if r.opts.SkipTester {
 		var reviewerRun roleRun
-		runRoleWithVerdict("reviewer", startBranchID, &reviewerRun)
+		runRoleWithVerdict(ctx, "reviewer", startBranchID, &reviewerRun)
 		if reviewerRun.err != nil {
 			return IssueReport{}, reviewerRun.err
 		}
@@ -273,6 +724,7 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 			Alpha:                  reviewer,
 			ReviewerRound1BranchID: reviewer.BranchID,
 			ExchangeRounds:         0,
+			Consensus:              ConsensusReviewerOnly,
 		}
 		if reviewerVerdict.Verdict == "confirmed" {
 			report.Status = commentConfirmed
@@ -287,24 +739,38 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 		return report, nil
 	}
 
-	// Round 1: Independent review (parallel + double-blind fork)
+	// Round 1: Independent review (parallel + double-blind fork). The first role
+	// to fail stops the wait for its sibling, whose result would be discarded
+	// anyway; the sibling's branch itself is left running on the server.
 	var reviewerRun, testerRun roleRun
+	roundCtx, cancelRound := context.WithCancel(ctx)
+	defer cancelRound()
+	var (
+		firstErr  error
+		firstOnce sync.Once
+	)
+	runParallel := func(role string, out *roleRun) {
+		runRoleWithVerdict(roundCtx, role, startBranchID, out)
+		if out.err != nil {
+			firstOnce.Do(func() {
+				firstErr = out.err
+				cancelRound()
+			})
+		}
+	}
 	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		runRoleWithVerdict("reviewer", startBranchID, &reviewerRun)
+		runParallel("reviewer", &reviewerRun)
 	}()
 	go func() {
 		defer wg.Done()
-		runRoleWithVerdict("tester", startBranchID, &testerRun)
+		runParallel("tester", &testerRun)
 	}()
 	wg.Wait()
-	if reviewerRun.err != nil {
-		return IssueReport{}, reviewerRun.err
-	}
-	if testerRun.err != nil {
-		return IssueReport{}, testerRun.err
+	if firstErr != nil {
+		return IssueReport{}, firstErr
 	}
 	reviewer := reviewerRun.transcript
 	tester := testerRun.transcript
@@ -325,19 +791,28 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 	// - If both confirm: require cross-transcript alignment before confirming
 	if reviewerVerdict.Verdict == testerVerdict.Verdict && reviewerVerdict.Verdict == "rejected" {
 		report.Status = commentUnresolved
+		report.Consensus = ConsensusAgreedReject
 		report.VerdictExplanation = "Round 1: Both Reviewer and Tester rejected the issue"
 		return report, nil
 	}
 	if reviewerVerdict.Verdict == testerVerdict.Verdict && reviewerVerdict.Verdict == "confirmed" {
-		aligned, err := r.checkAlignment(issueText, reviewer, tester)
+		aligned, err := r.checkAlignment(ctx, issueText, reviewer, tester)
 		if err != nil {
 			return IssueReport{}, err
 		}
+		recordAlignment(&report, aligned)
 		if aligned.Agree {
 			report.Status = commentConfirmed
+			report.Consensus = ConsensusAgreedConfirmAligned
 			report.VerdictExplanation = fmt.Sprintf("Round 1: Both confirmed and aligned: %s", strings.TrimSpace(aligned.Explanation))
 			return report, nil
 		}
+		if aligned.Relationship == AlignmentDifferent {
+			report.Status = commentUnresolved
+			report.Consensus = ConsensusAgreedConfirmMisaligned
+			report.VerdictExplanation = fmt.Sprintf("Round 1: Both confirmed but describe different defects (存疑不报): %s", strings.TrimSpace(aligned.Explanation))
+			return report, nil
+		}
 		// If both confirmed but did not align on the same defect, do NOT confirm; proceed to exchange.
 	}
 
@@ -369,21 +844,41 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 	report.TesterRound2BranchID = testerR2.BranchID
 
 	if reviewerR2Verdict.Verdict == testerR2Verdict.Verdict && reviewerR2Verdict.Verdict == "confirmed" {
-		aligned, err := r.checkAlignment(issueText, reviewerR2, testerR2)
+		aligned, err := r.checkAlignment(ctx, issueText, reviewerR2, testerR2)
 		if err != nil {
 			return IssueReport{}, err
 		}
+		recordAlignment(&report, aligned)
 		if aligned.Agree {
 			report.Status = commentConfirmed
+			report.Consensus = ConsensusAgreedConfirmAligned
 			report.VerdictExplanation = fmt.Sprintf("Round 2: Both confirmed and aligned: %s", strings.TrimSpace(aligned.Explanation))
 			return report, nil
 		}
+		// Every remaining outcome of this branch has both roles confirming without alignment.
+		report.Consensus = ConsensusAgreedConfirmMisaligned
+		if aligned.Relationship == AlignmentDifferent {
+			report.Status = commentUnresolved
+			report.VerdictExplanation = fmt.Sprintf("Round 2: Both confirmed but describe different defects (存疑不报): %s", strings.TrimSpace(aligned.Explanation))
+			return report, nil
+		}
+		if r.opts.MisalignedConfirmPolicy == MisalignedReportLowConfidence {
+			report.Status = commentConfirmedLowConfidence
+			report.VerdictExplanation = fmt.Sprintf("Round 2: Both confirmed but misaligned; reported with low confidence, the roles may describe different defects: %s", strings.TrimSpace(aligned.Explanation))
+			return report, nil
+		}
 		// Both say confirmed, but not aligned => unresolved (存疑不报).
 		report.Status = commentUnresolved
 		report.VerdictExplanation = fmt.Sprintf("Round 2: Confirmed but misaligned (存疑不报): %s", strings.TrimSpace(aligned.Explanation))
 		return report, nil
 	}
 
+	report.Consensus = consensusOf(reviewerR2Verdict.Verdict, testerR2Verdict.Verdict)
+	if reviewerR2Verdict.Verdict != testerR2Verdict.Verdict {
+		r.applyTieBreak(&report, reviewerR2Verdict.Verdict, testerR2Verdict.Verdict)
+		return report, nil
+	}
+
 	// 存疑不报: If still no unanimous confirmation, don't post
 	report.Status = commentUnresolved
 	report.VerdictExplanation = "Round 2: No unanimous confirmation (存疑不报)"
@@ -391,56 +886,80 @@ func (r *Runner) confirmIssue(issueText string, startBranchID string, changeAnal
 	return report, nil
 }
 
+// applyTieBreak settles an issue whose exchange ended with the roles disagreeing,
+// recording the policy used. Conservative keeps the 存疑不报 outcome; the trust
+// policies adopt one role's final verdict.
+func (r *Runner) applyTieBreak(report *IssueReport, reviewerVerdict string, testerVerdict string) {
+	report.TieBreak = r.opts.TieBreak
+	var role, deciding string
+	switch r.opts.TieBreak {
+	case TieBreakTrustTester:
+		role, deciding = "Tester", testerVerdict
+	case TieBreakTrustReviewer:
+		role, deciding = "Reviewer", reviewerVerdict
+	default:
+		report.Status = commentUnresolved
+		report.VerdictExplanation = fmt.Sprintf("Round 2: No unanimous confirmation (Reviewer %s, Tester %s); conservative tie-break (存疑不报)", reviewerVerdict, testerVerdict)
+		return
+	}
+	if deciding == "confirmed" {
+		report.Status = commentConfirmed
+	} else {
+		report.Status = commentUnresolved
+	}
+	report.VerdictExplanation = fmt.Sprintf("Round 2: No consensus (Reviewer %s, Tester %s); %s tie-break adopted the %s verdict", reviewerVerdict, testerVerdict, r.opts.TieBreak, role)
+}
+
 // runRole executes a role-based verification (Reviewer or Tester).
-func (r *Runner) runRole(role string, issueText string, changeAnalysisPath string, parentBranchID string) (Transcript, error) {
+func (r *Runner) runRole(ctx context.Context, role string, issueText string, analysis changeAnalysis, parentBranchID string) (Transcript, error) {
 	var prompt string
 	if role == "reviewer" {
-		prompt = buildLogicAnalystPrompt(issueText)
+		prompt = buildLogicAnalystPrompt(issueText, r.opts.SeverityPolicy, r.opts.Stance)
 	} else {
-		prompt = buildTesterPrompt(r.opts.Task, issueText, changeAnalysisPath)
+		prompt = buildTesterPrompt(r.opts.Task, issueText, analysis)
 	}
 
-	agent := "codex"
-	data, err := r.executeAgent(agent, prompt, parentBranchID)
+	data, err := r.runStep(ctx, role, "codex", prompt, parentBranchID)
 	if err != nil {
 		return Transcript{}, err
 	}
-	return Transcript{
+	return r.normalizeLanguage(ctx, Transcript{
 		Agent:    role,
 		Round:    1,
 		BranchID: stringField(data, "branch_id"),
 		Text:     strings.TrimSpace(stringField(data, "response")),
-	}, nil
+	}), nil
 }
 
 // runExchange executes Round 2 with both the agent's and peer's opinions.
-func (r *Runner) runExchange(role string, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string, parentBranchID string) (Transcript, error) {
-	prompt := buildExchangePrompt(role, r.opts.Task, issueText, changeAnalysisPath, selfOpinion, peerOpinion)
+func (r *Runner) runExchange(ctx context.Context, role string, issueText string, analysis changeAnalysis, selfOpinion string, peerOpinion string, parentBranchID string) (Transcript, error) {
+	prompt := buildExchangePrompt(role, r.opts.Task, issueText, analysis, selfOpinion, peerOpinion, r.opts.MaxOpinionChars)
 
 	agent := "codex"
-	data, err := r.executeAgent(agent, prompt, parentBranchID)
+	data, err := r.executeAgent(ctx, agent, prompt, parentBranchID)
 	if err != nil {
 		return Transcript{}, err
 	}
-	return Transcript{
+	return r.normalizeLanguage(ctx, Transcript{
 		Agent:    role,
 		Round:    2,
 		BranchID: stringField(data, "branch_id"),
 		Text:     strings.TrimSpace(stringField(data, "response")),
-	}, nil
+	}), nil
 }
 
-func (r *Runner) executeAgent(agent, prompt, parentBranchID string) (map[string]any, error) {
+// executeAgent runs agent until it finishes or ctx ends.
+func (r *Runner) executeAgent(ctx context.Context, agent, prompt, parentBranchID string) (map[string]any, error) {
 	args := map[string]any{
 		"agent":            agent,
-		"prompt":           prompt,
+		"prompt":           withScope(prompt, r.opts.ScopeDir),
 		"project_name":     r.opts.ProjectName,
 		"parent_branch_id": parentBranchID,
 	}
-	return r.callTool("execute_agent", args)
+	return r.callTool(ctx, "execute_agent", args)
 }
 
-func (r *Runner) callTool(name string, args map[string]any) (map[string]any, error) {
+func (r *Runner) callTool(ctx context.Context, name string, args map[string]any) (map[string]any, error) {
 	payload, _ := json.Marshal(args)
 	tc := t.ToolCall{Type: "function"}
 	tc.Function.Name = name
@@ -455,7 +974,7 @@ func (r *Runner) callTool(name string, args map[string]any) (map[string]any, err
 		}
 	}()
 
-	resp := r.handler.Handle(tc)
+	resp := r.handler.HandleContext(ctx, tc)
 	if resp == nil {
 		return nil, errors.New("tool handler returned nil response")
 	}
@@ -479,19 +998,50 @@ func (r *Runner) callTool(name string, args map[string]any) (map[string]any, err
 
 const changeAnalysisFilename = "change_analysis.md"
 
-func (r *Runner) runScout(parentBranchID string) (string, string, error) {
+const (
+	focusFilename = "focus.json"
+	maxFocusAreas = 3
+)
+
+// runFocus runs the optional triage pass and returns its branch plus the ranked areas.
+func (r *Runner) runFocus(ctx context.Context, parentBranchID string) (string, []string, error) {
+	if strings.TrimSpace(r.opts.WorkspaceDir) == "" {
+		return "", nil, workspaceDirError("the focus pass (writes " + focusFilename + ")")
+	}
+	focusPath := filepath.Join(r.opts.WorkspaceDir, focusFilename)
+	resp, err := r.runStep(ctx, StageFocus, "codex", buildFocusPrompt(r.opts.Task, focusPath, r.opts.ScopeDir, r.opts.CommitRange), parentBranchID)
+	if err != nil {
+		return "", nil, err
+	}
+	branchID := stringField(resp, "branch_id")
+	artifact, err := r.callTool(ctx, "read_artifact", map[string]any{
+		"branch_id": branchID,
+		"path":      focusPath,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	areas, err := parseFocusAreas(stringField(artifact, "content"), maxFocusAreas)
+	if err != nil {
+		return "", nil, err
+	}
+	logx.Infof("Focus pass ranked %d area(s) on branch %s", len(areas), branchID)
+	return branchID, areas, nil
+}
+
+func (r *Runner) runScout(ctx context.Context, parentBranchID string, focusAreas []string) (string, string, error) {
 	if strings.TrimSpace(r.opts.WorkspaceDir) == "" {
-		return "", "", errors.New("workspace dir is required for scout output")
+		return "", "", workspaceDirError("the scout (writes " + changeAnalysisFilename + ")")
 	}
 	analysisPath := filepath.Join(r.opts.WorkspaceDir, changeAnalysisFilename)
-	prompt := buildScoutPrompt(r.opts.Task, analysisPath)
+	prompt := buildScoutPrompt(r.opts.Task, analysisPath, focusAreas, r.opts.ScopeDir, r.opts.IncludeBlame, r.opts.CommitRange)
 
-	resp, err := r.executeAgent("codex", prompt, parentBranchID)
+	resp, err := r.runStep(ctx, StageScout, "codex", prompt, parentBranchID)
 	if err != nil {
 		return "", "", err
 	}
 	branchID := stringField(resp, "branch_id")
-	artifact, err := r.callTool("read_artifact", map[string]any{
+	artifact, err := r.callTool(ctx, "read_artifact", map[string]any{
 		"branch_id": branchID,
 		"path":      analysisPath,
 	})
@@ -505,22 +1055,34 @@ func (r *Runner) runScout(parentBranchID string) (string, string, error) {
 	return branchID, analysisPath, nil
 }
 
-func (r *Runner) hasRealIssue(reportText string) (bool, error) {
+// hasRealIssue asks whether the report describes any real issue; reports over the
+// auxiliary input limit are checked chunk by chunk, and any positive chunk wins.
+func (r *Runner) hasRealIssue(ctx context.Context, reportText string) (bool, error) {
+	return withChunking(reportText, r.opts.MaxAuxInputChars, func(chunk string) (bool, error) {
+		return r.hasRealIssueChunk(ctx, chunk)
+	}, anyTrue)
+}
+
+func (r *Runner) hasRealIssueChunk(ctx context.Context, reportText string) (bool, error) {
 	if r.hasRealIssueOverride != nil {
 		return r.hasRealIssueOverride(reportText)
 	}
 	prompt := buildHasRealIssuePrompt(reportText)
-	resp, err := r.brain.Complete([]b.ChatMessage{
-		{Role: "system", Content: "Analyze code review reports. Reply only with JSON."},
+	resp, err := r.brain.Complete(b.WithCallKind(ctx, b.CallAuxiliary), []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxIssueCheck)},
 		{Role: "user", Content: prompt},
-	}, nil)
+	}, nil, r.jsonCallOptions()...)
 	if err != nil {
 		return false, err
 	}
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		return false, fmt.Errorf("issue check: %w", err)
+	}
 	type issueCheck struct {
 		HasIssue bool `json:"has_issue"`
 	}
-	jsonBlock := extractJSONBlock(resp.Choices[0].Message.Content)
+	jsonBlock := extractJSONBlock(content)
 	var check issueCheck
 	if err := json.Unmarshal([]byte(jsonBlock), &check); err != nil {
 		return false, err
@@ -528,11 +1090,51 @@ func (r *Runner) hasRealIssue(reportText string) (bool, error) {
 	return check.HasIssue, nil
 }
 
-func (r *Runner) determineVerdict(transcript Transcript) (verdictDecision, error) {
+// normalizeLanguage applies Options.NormalizeLanguage to a fresh transcript.
+func (r *Runner) normalizeLanguage(ctx context.Context, transcript Transcript) Transcript {
+	if !r.opts.NormalizeLanguage || !needsLanguageNormalization(transcript.Text) {
+		return transcript
+	}
+	logx.Infof("Transcript for %s (Round %d) is not in English; normalizing it before parsing.", transcript.Agent, transcript.Round)
+	text, err := r.translateTranscript(ctx, transcript)
+	if err != nil {
+		logx.Warningf("Language normalization failed for %s (Round %d); parsing the original. err=%v", transcript.Agent, transcript.Round, err)
+		return transcript
+	}
+	transcript.OriginalText = transcript.Text
+	transcript.Text = text
+	return transcript
+}
+
+func (r *Runner) translateTranscript(ctx context.Context, transcript Transcript) (string, error) {
+	if r.languageOverride != nil {
+		return r.languageOverride(transcript)
+	}
+	if r.brain == nil {
+		return "", errors.New("LLM brain unavailable")
+	}
+	resp, err := r.brain.Complete(b.WithCallKind(ctx, b.CallAuxiliary), []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxLanguage)},
+		{Role: "user", Content: buildLanguageNormalizationPrompt(transcript)},
+	}, nil, r.jsonCallOptions()...)
+	if err != nil {
+		return "", err
+	}
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		return "", err
+	}
+	return parseLanguageNormalizationResponse(content)
+}
+
+func (r *Runner) determineVerdict(ctx context.Context, transcript Transcript) (verdictDecision, error) {
 	// 1. Try to extract explicit verdict from regex
 	if decision, ok := extractTranscriptVerdict(transcript.Text); ok {
-		logx.Infof("Parsed explicit verdict for %s (Round %d): %s", transcript.Agent, transcript.Round, decision.Verdict)
-		return decision, nil
+		if decision.Confidence == nil || *decision.Confidence >= r.opts.VerdictConfidenceThreshold {
+			logx.Infof("Parsed explicit verdict for %s (Round %d): %s", transcript.Agent, transcript.Round, decision.Verdict)
+			return decision, nil
+		}
+		logx.Infof("Explicit verdict for %s (Round %d) is %s at confidence %.2f, below %.2f; reading the whole transcript instead.", transcript.Agent, transcript.Round, decision.Verdict, *decision.Confidence, r.opts.VerdictConfidenceThreshold)
 	}
 
 	// 2. Fallback: use LLM to infer the intended verdict from the transcript text.
@@ -549,17 +1151,18 @@ func (r *Runner) determineVerdict(transcript Transcript) (verdictDecision, error
 		return verdictDecision{Verdict: "unknown", Reason: "verdict marker missing and LLM brain unavailable"}, nil
 	}
 	prompt := buildVerdictExtractionPrompt(transcript)
-	resp, err := r.brain.Complete([]b.ChatMessage{
-		{Role: "system", Content: "Extract the transcript's final verdict. Reply ONLY with JSON."},
+	resp, err := r.brain.Complete(b.WithCallKind(ctx, b.CallAuxiliary), []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxVerdict)},
 		{Role: "user", Content: prompt},
-	}, nil)
+	}, nil, r.jsonCallOptions()...)
 	if err != nil {
 		logx.Warningf("LLM verdict extraction failed for %s (Round %d): %v", transcript.Agent, transcript.Round, err)
 		return verdictDecision{Verdict: "unknown", Reason: fmt.Sprintf("llm verdict extraction failed: %v", err)}, nil
 	}
-	content := ""
-	if resp != nil && len(resp.Choices) > 0 {
-		content = resp.Choices[0].Message.Content
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		logx.Warningf("LLM verdict extraction failed for %s (Round %d): %v", transcript.Agent, transcript.Round, err)
+		return verdictDecision{Verdict: "unknown", Reason: fmt.Sprintf("llm verdict extraction failed: %v", err)}, nil
 	}
 	decision, parseErr := parseVerdictExtractionResponse(content)
 	if parseErr != nil {
@@ -570,7 +1173,61 @@ func (r *Runner) determineVerdict(transcript Transcript) (verdictDecision, error
 	return decision, nil
 }
 
-func (r *Runner) checkAlignment(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+// checkAlignment judges whether the two transcripts describe the same defect. In
+// graded mode Agree is derived from the relationship and score, so callers can
+// keep treating it as the yes/no answer.
+func (r *Runner) checkAlignment(ctx context.Context, issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+	verdict, err := r.judgeAlignment(ctx, issueText, alpha, beta)
+	if errors.Is(err, errAlignmentUnparsable) {
+		// Not aligned is the conservative reading: the issue goes on to the
+		// exchange round (or the misaligned policy) instead of aborting the run.
+		logx.Warningf("Treating the transcripts as not aligned: %v", err)
+		verdict, err = alignmentVerdict{Explanation: "Alignment check returned no parsable verdict; treated as not aligned."}, nil
+	}
+	if err != nil {
+		return alignmentVerdict{}, err
+	}
+	if !r.opts.GradedAlignment {
+		return alignmentVerdict{Agree: verdict.Agree, Explanation: verdict.Explanation}, nil
+	}
+	return gradeAlignment(verdict, r.opts.AlignmentThreshold), nil
+}
+
+// gradeAlignment fills in a relationship the model left out from its boolean and
+// recomputes Agree against threshold. A "same" without a score keeps the model's
+// own agree flag.
+func gradeAlignment(verdict alignmentVerdict, threshold float64) alignmentVerdict {
+	if verdict.Relationship == "" {
+		verdict.Relationship = AlignmentRelated
+		if verdict.Agree {
+			verdict.Relationship = AlignmentSame
+		}
+	}
+	switch {
+	case verdict.Relationship != AlignmentSame:
+		verdict.Agree = false
+	case verdict.Score != nil:
+		verdict.Agree = *verdict.Score >= threshold
+	}
+	if verdict.Relationship == AlignmentSame && !verdict.Agree {
+		// Below the bar: treat as a near miss rather than a match.
+		verdict.Relationship = AlignmentRelated
+	}
+	return verdict
+}
+
+// recordAlignment keeps the latest alignment outcome on the report.
+func recordAlignment(report *IssueReport, aligned alignmentVerdict) {
+	report.Alignment = strings.TrimSpace(aligned.Explanation)
+	report.AlignmentRelationship = aligned.Relationship
+	report.AlignmentScore = aligned.Score
+}
+
+// errAlignmentUnparsable marks an alignment check whose every reply was empty
+// or malformed; checkAlignment turns it into a not-aligned verdict.
+var errAlignmentUnparsable = errors.New("alignment response could not be parsed")
+
+func (r *Runner) judgeAlignment(ctx context.Context, issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
 	if r.alignmentOverride != nil {
 		return r.alignmentOverride(issueText, alpha, beta)
 	}
@@ -578,27 +1235,46 @@ func (r *Runner) checkAlignment(issueText string, alpha Transcript, beta Transcr
 		return alignmentVerdict{}, errors.New("brain is required for alignment check")
 	}
 	prompt := buildAlignmentPrompt(issueText, alpha, beta)
-	resp, err := r.brain.Complete([]b.ChatMessage{
-		{Role: "system", Content: "Return JSON alignment verdicts for two transcripts. Reply only with JSON."},
-		{Role: "user", Content: prompt},
-	}, nil)
-	if err != nil {
-		return alignmentVerdict{}, err
+	if r.opts.GradedAlignment {
+		prompt = buildGradedAlignmentPrompt(issueText, alpha, beta)
 	}
-	content := ""
-	if resp != nil && len(resp.Choices) > 0 {
-		content = resp.Choices[0].Message.Content
+	messages := []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxAlignment)},
+		{Role: "user", Content: prompt},
 	}
-	if strings.TrimSpace(content) == "" {
-		logx.Errorf("Alignment LLM returned empty content (issue=%q)", streaming.PromptPreview(issueText))
-		return alignmentVerdict{}, errors.New("alignment returned empty content")
+	for attempt := 0; ; attempt++ {
+		resp, err := r.brain.Complete(b.WithCallKind(ctx, b.CallAuxiliary), messages, nil, r.jsonCallOptions()...)
+		if err != nil {
+			return alignmentVerdict{}, err
+		}
+		content, err := b.RequireNonEmptyChoice(resp)
+		if err != nil {
+			logx.Errorf("Alignment LLM returned empty content (issue=%q)", streaming.PromptPreview(issueText))
+		} else if verdict, parseErr := parseAlignment(content); parseErr != nil {
+			logx.Errorf("Alignment parse failed: %v. Raw response=%q", parseErr, content)
+			err = parseErr
+		} else {
+			return verdict, nil
+		}
+		if attempt >= r.opts.AlignmentParseRetries {
+			return alignmentVerdict{}, fmt.Errorf("%w after %d attempt(s): %v", errAlignmentUnparsable, attempt+1, err)
+		}
+		logx.Warningf("Re-prompting the alignment check for JSON only (retry %d/%d).", attempt+1, r.opts.AlignmentParseRetries)
+		// An empty reply is not echoed back: there is nothing to correct, and
+		// some endpoints reject assistant messages without content.
+		if strings.TrimSpace(content) != "" {
+			messages = append(messages, b.ChatMessage{Role: "assistant", Content: content})
+		}
+		messages = append(messages, b.ChatMessage{Role: "user", Content: buildAlignmentReprompt(r.opts.GradedAlignment, err)})
 	}
-	verdict, err := parseAlignment(content)
-	if err != nil {
-		logx.Errorf("Alignment parse failed: %v. Raw response=%q", err, content)
-		return alignmentVerdict{}, err
+}
+
+// jsonCallOptions returns the Complete options for auxiliary calls that must reply with JSON.
+func (r *Runner) jsonCallOptions() []b.CompleteOption {
+	if !r.opts.JSONMode {
+		return nil
 	}
-	return verdict, nil
+	return []b.CompleteOption{b.WithJSONMode()}
 }
 
 type eventHelper struct {
@@ -692,11 +1368,13 @@ func extractError(resp map[string]any) string {
 	return "unknown error"
 }
 
-func summarizeIssueCounts(reports []IssueReport) (confirmed, unresolved int) {
+func summarizeIssueCounts(reports []IssueReport) (confirmed, lowConfidence, unresolved int) {
 	for _, r := range reports {
 		switch r.Status {
 		case commentConfirmed:
 			confirmed++
+		case commentConfirmedLowConfidence:
+			lowConfidence++
 		case commentUnresolved:
 			unresolved++
 		}
@@ -1,10 +1,15 @@
 package prreview
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	b "review_agent/internal/brain"
 	tools "review_agent/internal/tools"
@@ -100,7 +105,7 @@ func TestConfirmIssueDoesNotConfirmWhenTranscriptsMisaligned(t *testing.T) {
 		Task:           "task",
 		ProjectName:    "proj",
 		ParentBranchID: "start",
-		WorkspaceDir:   "",
+		WorkspaceDir:   "/workspace",
 	})
 	if err != nil {
 		t.Fatalf("NewRunner error: %v", err)
@@ -109,7 +114,7 @@ func TestConfirmIssueDoesNotConfirmWhenTranscriptsMisaligned(t *testing.T) {
 		return alignmentVerdict{Agree: false, Explanation: "test: misaligned"}, nil
 	}
 
-	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	report, err := runner.confirmIssue(context.Background(), "ISSUE: example", "start", changeAnalysis{})
 	if err != nil {
 		t.Fatalf("confirmIssue error: %v", err)
 	}
@@ -118,6 +123,55 @@ func TestConfirmIssueDoesNotConfirmWhenTranscriptsMisaligned(t *testing.T) {
 	}
 }
 
+func TestConfirmIssueReportsLowConfidenceWhenPolicySet(t *testing.T) {
+	reviewer := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed Defect A."
+	tester := "# VERDICT: CONFIRMED\n\nClaim: defect B\nAnchor: beta.go:20\n\n## Reproduction Steps\nConfirmed Defect B."
+	reviewerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Response to Peer\nStill A.\n\n## Final Reasoning\nStill A."
+	testerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect B\nAnchor: beta.go:20\n\n## Response to Peer\nStill B.\n\n## Final Reasoning\nStill B."
+	client := newFakeAgentClient(reviewer, tester, reviewerR2, testerR2)
+	handler := tools.NewToolHandler(client, "proj", "start", "")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:                    "task",
+		ProjectName:             "proj",
+		ParentBranchID:          "start",
+		WorkspaceDir:            "/workspace",
+		MisalignedConfirmPolicy: MisalignedReportLowConfidence,
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+		return alignmentVerdict{Agree: false, Explanation: "test: misaligned"}, nil
+	}
+
+	report, err := runner.confirmIssue(context.Background(), "ISSUE: example", "start", changeAnalysis{})
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentConfirmedLowConfidence || report.Consensus != ConsensusAgreedConfirmMisaligned {
+		t.Fatalf("expected %q, got status=%q consensus=%q explanation=%q", commentConfirmedLowConfidence, report.Status, report.Consensus, report.VerdictExplanation)
+	}
+	if report.ExchangeRounds != 1 {
+		t.Fatalf("expected misaligned round 1 to still go through exchange, got %d rounds", report.ExchangeRounds)
+	}
+	if !strings.Contains(report.VerdictExplanation, "test: misaligned") {
+		t.Fatalf("expected alignment explanation in note, got %q", report.VerdictExplanation)
+	}
+}
+
+func TestNewRunnerRejectsUnknownMisalignedPolicy(t *testing.T) {
+	handler := tools.NewToolHandler(newFakeAgentClient("", "", "", ""), "proj", "start", "")
+	_, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:                    "task",
+		ProjectName:             "proj",
+		ParentBranchID:          "start",
+		MisalignedConfirmPolicy: "maybe",
+	})
+	if err == nil {
+		t.Fatalf("expected error for unknown policy")
+	}
+}
+
 func TestConfirmIssueSkipsTesterWhenFlagSet(t *testing.T) {
 	reviewer := "# VERDICT: CONFIRMED\n\nClaim: issueText describes defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed Defect A."
 	client := newFakeAgentClient(reviewer, "", "", "")
@@ -126,14 +180,14 @@ func TestConfirmIssueSkipsTesterWhenFlagSet(t *testing.T) {
 		Task:           "task",
 		ProjectName:    "proj",
 		ParentBranchID: "start",
-		WorkspaceDir:   "",
+		WorkspaceDir:   "/workspace",
 		SkipTester:     true,
 	})
 	if err != nil {
 		t.Fatalf("NewRunner error: %v", err)
 	}
 
-	report, err := runner.confirmIssue("ISSUE: example", "start", "")
+	report, err := runner.confirmIssue(context.Background(), "ISSUE: example", "start", changeAnalysis{})
 	if err != nil {
 		t.Fatalf("confirmIssue error: %v", err)
 	}
@@ -171,14 +225,14 @@ func TestConfirmIssueUsesDoubleBlindBranchTopology(t *testing.T) {
 		Task:           "task",
 		ProjectName:    "proj",
 		ParentBranchID: "start",
-		WorkspaceDir:   "",
+		WorkspaceDir:   "/workspace",
 	})
 	if err != nil {
 		t.Fatalf("NewRunner error: %v", err)
 	}
 
 	startBranchID := "discovery_branch"
-	report, err := runner.confirmIssue("ISSUE: example", startBranchID, "")
+	report, err := runner.confirmIssue(context.Background(), "ISSUE: example", startBranchID, changeAnalysis{})
 	if err != nil {
 		t.Fatalf("confirmIssue error: %v", err)
 	}
@@ -287,3 +341,385 @@ func pickOutput(role string, round int, reviewerR1, testerR1, reviewerR2, tester
 		return "# VERDICT: REJECTED\n\nClaim: unknown\nAnchor: unknown\n\n## Reasoning\nUnknown role."
 	}
 }
+
+func TestConfirmIssueTieBreakPolicies(t *testing.T) {
+	reviewerR1 := "# VERDICT: REJECTED\n\nClaim: something\nAnchor: unknown\n\n## Reasoning\nNo."
+	testerR1 := "# VERDICT: CONFIRMED\n\nClaim: something\nAnchor: cmd\n\n## Reproduction Steps\nYes."
+	reviewerR2 := "# VERDICT: REJECTED\n\nClaim: something\nAnchor: unknown\n\n## Response to Peer\nStill no.\n\n## Final Reasoning\nStill no."
+	testerR2 := "# VERDICT: CONFIRMED\n\nClaim: something\nAnchor: cmd\n\n## Response to Peer\nStill yes.\n\n## Final Reasoning\nStill yes."
+
+	cases := []struct {
+		policy     string
+		wantStatus string
+	}{
+		{"", commentUnresolved},
+		{TieBreakTrustTester, commentConfirmed},
+		{TieBreakTrustReviewer, commentUnresolved},
+	}
+	for _, tc := range cases {
+		client := newFakeAgentClient(reviewerR1, testerR1, reviewerR2, testerR2)
+		handler := tools.NewToolHandler(client, "proj", "start", "")
+		runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+			Task:           "task",
+			ProjectName:    "proj",
+			ParentBranchID: "start",
+			WorkspaceDir:   "/workspace",
+			TieBreak:       tc.policy,
+		})
+		if err != nil {
+			t.Fatalf("NewRunner(%q) error: %v", tc.policy, err)
+		}
+		report, err := runner.confirmIssue(context.Background(), "ISSUE: example", "discovery_branch", changeAnalysis{})
+		if err != nil {
+			t.Fatalf("confirmIssue(%q) error: %v", tc.policy, err)
+		}
+		wantPolicy := tc.policy
+		if wantPolicy == "" {
+			wantPolicy = TieBreakConservative
+		}
+		if report.Status != tc.wantStatus || report.TieBreak != wantPolicy || report.Consensus != ConsensusDisagreed {
+			t.Fatalf("policy %q: got status=%q tie_break=%q consensus=%q explanation=%q", tc.policy, report.Status, report.TieBreak, report.Consensus, report.VerdictExplanation)
+		}
+	}
+
+	if _, err := NewRunner(&b.LLMBrain{}, tools.NewToolHandler(newFakeAgentClient("", "", "", ""), "proj", "start", ""), nil, Options{
+		Task: "task", ProjectName: "proj", ParentBranchID: "start", TieBreak: "coin_flip",
+	}); err == nil {
+		t.Fatal("expected unknown tie-break policy to be rejected")
+	}
+}
+
+func TestConfirmIssueGradedAlignment(t *testing.T) {
+	reviewer := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed Defect A."
+	tester := "# VERDICT: CONFIRMED\n\nClaim: defect A'\nAnchor: alpha.go:12\n\n## Reproduction Steps\nConfirmed Defect A'."
+	reviewerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Response to Peer\nSame path.\n\n## Final Reasoning\nStill A."
+	testerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Response to Peer\nAgreed.\n\n## Final Reasoning\nA."
+	score := func(v float64) *float64 { return &v }
+
+	cases := []struct {
+		name          string
+		round1        alignmentVerdict
+		round2        alignmentVerdict
+		wantStatus    string
+		wantRounds    int
+		wantConsensus string
+	}{
+		{
+			name:          "same above threshold confirms in round 1",
+			round1:        alignmentVerdict{Agree: true, Relationship: AlignmentSame, Score: score(0.9), Explanation: "same"},
+			wantStatus:    commentConfirmed,
+			wantConsensus: ConsensusAgreedConfirmAligned,
+		},
+		{
+			name:          "different drops without an exchange",
+			round1:        alignmentVerdict{Agree: false, Relationship: AlignmentDifferent, Score: score(0.1), Explanation: "different"},
+			wantStatus:    commentUnresolved,
+			wantConsensus: ConsensusAgreedConfirmMisaligned,
+		},
+		{
+			name:          "related goes to the exchange",
+			round1:        alignmentVerdict{Agree: false, Relationship: AlignmentRelated, Score: score(0.5), Explanation: "related"},
+			round2:        alignmentVerdict{Agree: true, Relationship: AlignmentSame, Score: score(0.8), Explanation: "same after exchange"},
+			wantStatus:    commentConfirmed,
+			wantRounds:    1,
+			wantConsensus: ConsensusAgreedConfirmAligned,
+		},
+		{
+			name:          "same below threshold is a near miss",
+			round1:        alignmentVerdict{Agree: true, Relationship: AlignmentSame, Score: score(0.6), Explanation: "probably same"},
+			round2:        alignmentVerdict{Agree: true, Relationship: AlignmentSame, Score: score(0.65), Explanation: "probably same"},
+			wantStatus:    commentUnresolved,
+			wantRounds:    1,
+			wantConsensus: ConsensusAgreedConfirmMisaligned,
+		},
+	}
+	for _, tc := range cases {
+		client := newFakeAgentClient(reviewer, tester, reviewerR2, testerR2)
+		handler := tools.NewToolHandler(client, "proj", "start", "")
+		runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+			Task:            "task",
+			ProjectName:     "proj",
+			ParentBranchID:  "start",
+			WorkspaceDir:    "/workspace",
+			GradedAlignment: true,
+		})
+		if err != nil {
+			t.Fatalf("%s: NewRunner error: %v", tc.name, err)
+		}
+		calls := 0
+		runner.alignmentOverride = func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+			calls++
+			if calls == 1 {
+				return tc.round1, nil
+			}
+			return tc.round2, nil
+		}
+		report, err := runner.confirmIssue(context.Background(), "ISSUE: example", "start", changeAnalysis{})
+		if err != nil {
+			t.Fatalf("%s: confirmIssue error: %v", tc.name, err)
+		}
+		if report.Status != tc.wantStatus || report.ExchangeRounds != tc.wantRounds || report.Consensus != tc.wantConsensus {
+			t.Fatalf("%s: got status=%q rounds=%d consensus=%q explanation=%q", tc.name, report.Status, report.ExchangeRounds, report.Consensus, report.VerdictExplanation)
+		}
+		if report.AlignmentRelationship == "" || report.AlignmentScore == nil {
+			t.Fatalf("%s: graded alignment not recorded: %+v", tc.name, report)
+		}
+	}
+
+	if _, err := NewRunner(&b.LLMBrain{}, tools.NewToolHandler(newFakeAgentClient("", "", "", ""), "proj", "start", ""), nil, Options{
+		Task: "task", ProjectName: "proj", ParentBranchID: "start", AlignmentThreshold: 1.5,
+	}); err == nil {
+		t.Fatal("expected out-of-range alignment threshold to be rejected")
+	}
+}
+
+func TestParseAlignmentGradedFields(t *testing.T) {
+	verdict, err := parseAlignment(`{"agree":false,"relationship":"Related","score":0.4,"explanation":"overlap"}`)
+	if err != nil {
+		t.Fatalf("parseAlignment error: %v", err)
+	}
+	if verdict.Relationship != AlignmentRelated || verdict.Score == nil || *verdict.Score != 0.4 {
+		t.Fatalf("unexpected verdict: %+v", verdict)
+	}
+	for _, raw := range []string{
+		`{"agree":true,"relationship":"identical","explanation":"x"}`,
+		`{"agree":true,"relationship":"same","score":1.2,"explanation":"x"}`,
+	} {
+		if _, err := parseAlignment(raw); err == nil {
+			t.Errorf("expected error for %s", raw)
+		}
+	}
+	if got := gradeAlignment(alignmentVerdict{Agree: true}, DefaultAlignmentThreshold); !got.Agree || got.Relationship != AlignmentSame {
+		t.Fatalf("boolean-only reply should grade as same: %+v", got)
+	}
+}
+
+// stuckTesterClient keeps the Round 1 tester branch running and fails the
+// reviewer's launch once the tester is being polled.
+type stuckTesterClient struct {
+	*fakeAgentClient
+	testerPolled chan struct{}
+	pollOnce     sync.Once
+
+	mu       sync.Mutex
+	testerID string
+}
+
+func (c *stuckTesterClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	role, _ := classifyPrompt(strings.Join(prompts, "\n"))
+	if role == "reviewer" {
+		<-c.testerPolled
+		return nil, fmt.Errorf("reviewer launch failed")
+	}
+	resp, err := c.fakeAgentClient.ParallelExplore(projectName, parentBranchID, prompts, agent, numBranches)
+	if err == nil {
+		c.mu.Lock()
+		c.testerID, _ = resp["branch_id"].(string)
+		c.mu.Unlock()
+	}
+	return resp, err
+}
+
+func (c *stuckTesterClient) GetBranch(branchID string) (map[string]any, error) {
+	c.pollOnce.Do(func() { close(c.testerPolled) })
+	return map[string]any{"id": branchID, "status": "running", "latest_snap_id": branchID + "_snap"}, nil
+}
+
+func TestConfirmIssueStopsWaitingForSiblingRoleOnError(t *testing.T) {
+	client := &stuckTesterClient{
+		fakeAgentClient: newFakeAgentClient("", "# VERDICT: CONFIRMED", "", ""),
+		testerPolled:    make(chan struct{}),
+	}
+	handler := tools.NewToolHandler(client, "proj", "start", "")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "start",
+		WorkspaceDir:   "/workspace",
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := runner.confirmIssue(context.Background(), "ISSUE: example", "start", changeAnalysis{})
+		done <- err
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("confirmIssue kept waiting for the tester after the reviewer failed")
+	}
+	if err == nil || !strings.Contains(err.Error(), "reviewer launch failed") {
+		t.Fatalf("expected the reviewer's error, got %v", err)
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.testerID == "" {
+		t.Fatal("expected the tester branch to have been launched")
+	}
+}
+
+func TestConfirmIssueRecordsAgreedRejectConsensus(t *testing.T) {
+	rejected := "# VERDICT: REJECTED\n\nClaim: something\nAnchor: unknown\n\n## Reasoning\nNo."
+	client := newFakeAgentClient(rejected, rejected, "", "")
+	handler := tools.NewToolHandler(client, "proj", "start", "")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{Task: "task", ProjectName: "proj", ParentBranchID: "start", WorkspaceDir: "/workspace"})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	report, err := runner.confirmIssue(context.Background(), "ISSUE: example", "start", changeAnalysis{})
+	if err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+	if report.Status != commentUnresolved || report.Consensus != ConsensusAgreedReject || report.ExchangeRounds != 0 {
+		t.Fatalf("expected a round 1 agreed reject, got status=%q consensus=%q rounds=%d", report.Status, report.Consensus, report.ExchangeRounds)
+	}
+
+	if got := consensusOf("unknown", "unknown"); got != ConsensusUndetermined {
+		t.Fatalf("expected two undetermined verdicts to be %q, got %q", ConsensusUndetermined, got)
+	}
+}
+
+// scoutOnlyClient serves the change analysis from the scout branch only, as when
+// forking does not carry the scout's file forward to the verification branches.
+type scoutOnlyClient struct {
+	*fakeAgentClient
+	scoutBranchID string
+}
+
+func (c *scoutOnlyClient) BranchReadFile(branchID string, filePath string) (map[string]any, error) {
+	if branchID == c.scoutBranchID && strings.HasSuffix(filePath, changeAnalysisFilename) {
+		return map[string]any{"content": "## Change Analysis\nEviction now runs under the cache lock."}, nil
+	}
+	return map[string]any{}, fmt.Errorf("file not found: %s", filePath)
+}
+
+func TestConfirmIssueInlinesUnreadableChangeAnalysis(t *testing.T) {
+	reviewer := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Reasoning\nConfirmed."
+	tester := "# VERDICT: REJECTED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Reproduction Steps\nCould not reproduce."
+	reviewerR2 := "# VERDICT: CONFIRMED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Response to Peer\nStill A.\n\n## Final Reasoning\nStill A."
+	testerR2 := "# VERDICT: REJECTED\n\nClaim: defect A\nAnchor: alpha.go:10\n\n## Response to Peer\nNo.\n\n## Final Reasoning\nNo."
+	client := &scoutOnlyClient{fakeAgentClient: newFakeAgentClient(reviewer, tester, reviewerR2, testerR2), scoutBranchID: "scout"}
+	handler := tools.NewToolHandler(client, "proj", "start", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "start",
+		WorkspaceDir:   "/workspace",
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	path := "/workspace/" + changeAnalysisFilename
+
+	analysis := runner.resolveChangeAnalysis(context.Background(), "scout", "review", path)
+	if analysis.Content == "" || analysis.Path != path {
+		t.Fatalf("expected the scout's analysis to be inlined, got %+v", analysis)
+	}
+	if _, err := runner.confirmIssue(context.Background(), "ISSUE: example", "review", analysis); err != nil {
+		t.Fatalf("confirmIssue error: %v", err)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	referencing := 0
+	for _, call := range client.calls {
+		if call.classifiedRole == "reviewer" && call.classifiedRound == 1 {
+			continue // the Round 1 logic analyst prompt never references the analysis
+		}
+		referencing++
+		if !strings.Contains(call.prompt, "Eviction now runs under the cache lock.") {
+			t.Fatalf("expected %s round %d prompt to inline the analysis, got:\n%s", call.classifiedRole, call.classifiedRound, call.prompt)
+		}
+	}
+	if referencing < 3 {
+		t.Fatalf("expected tester and both exchange prompts, saw %d", referencing)
+	}
+
+	if got := runner.resolveChangeAnalysis(context.Background(), "elsewhere", "review", path); got != (changeAnalysis{}) {
+		t.Fatalf("expected no analysis reference when nothing is readable, got %+v", got)
+	}
+}
+
+func TestCheckAlignmentRepromptsMalformedJSONThenFallsBack(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		replies = []string{"", `{"agree":true,"explanation":"same nil deref"}`}
+		lastMsg string
+		calls   int
+		empty   bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []b.ChatMessage `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		lastMsg = body.Messages[len(body.Messages)-1].Content
+		for _, msg := range body.Messages {
+			if msg.Role == "assistant" && strings.TrimSpace(msg.Content) == "" {
+				empty = true
+			}
+		}
+		reply := "still not JSON"
+		if len(replies) > 0 {
+			reply, replies = replies[0], replies[1:]
+		}
+		out, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": reply}}}})
+		_, _ = w.Write(out)
+	}))
+	defer srv.Close()
+
+	brain := b.NewLLMBrain("key", srv.URL, "dep", "2024-12-01-preview", 1)
+	handler := tools.NewToolHandler(newFakeAgentClient("", "", "", ""), "proj", "start", "")
+	opts := Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "start",
+		WorkspaceDir:   "/workspace",
+	}
+	runner, err := NewRunner(brain, handler, nil, opts)
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	if runner.opts.AlignmentParseRetries != DefaultAlignmentParseRetries {
+		t.Fatalf("expected unset retries to default to %d, got %d", DefaultAlignmentParseRetries, runner.opts.AlignmentParseRetries)
+	}
+	alpha, beta := Transcript{Text: "VERDICT: CONFIRMED"}, Transcript{Text: "VERDICT: CONFIRMED"}
+
+	aligned, err := runner.checkAlignment(context.Background(), "nil deref", alpha, beta)
+	if err != nil || !aligned.Agree {
+		t.Fatalf("expected the re-prompted reply to be used, got %+v err=%v", aligned, err)
+	}
+	if !strings.Contains(lastMsg, "Return ONLY") {
+		t.Fatalf("expected a JSON-only re-prompt, got %q", lastMsg)
+	}
+	if empty {
+		t.Fatal("an empty reply should not be echoed back as an assistant message")
+	}
+
+	aligned, err = runner.checkAlignment(context.Background(), "nil deref", alpha, beta)
+	if err != nil {
+		t.Fatalf("expected repeated malformed replies to fall back, got %v", err)
+	}
+	if aligned.Agree || !strings.Contains(aligned.Explanation, "not aligned") {
+		t.Fatalf("expected a conservative not-aligned verdict, got %+v", aligned)
+	}
+
+	opts.AlignmentParseRetries = -1
+	runner, err = NewRunner(brain, handler, nil, opts)
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	before := calls
+	if aligned, err = runner.checkAlignment(context.Background(), "nil deref", alpha, beta); err != nil || aligned.Agree {
+		t.Fatalf("expected a not-aligned fallback without retries, got %+v err=%v", aligned, err)
+	}
+	if calls-before != 1 {
+		t.Fatalf("expected a negative retry count to disable re-prompting, got %d calls", calls-before)
+	}
+}
@@ -1,12 +1,17 @@
 package prreview
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	b "review_agent/internal/brain"
+	"review_agent/internal/exitcodes"
 	tools "review_agent/internal/tools"
 )
 
@@ -21,6 +26,7 @@ type fakeRunnerClient struct {
 type parallelCall struct {
 	agent  string
 	prompt string
+	parent string
 }
 
 type branchReadInput struct {
@@ -40,6 +46,7 @@ func (c *fakeRunnerClient) ParallelExplore(projectName, parentBranchID string, p
 	c.parallelCalls = append(c.parallelCalls, parallelCall{
 		agent:  agent,
 		prompt: prompt,
+		parent: parentBranchID,
 	})
 	return map[string]any{
 		"branch_id": branchID,
@@ -47,6 +54,9 @@ func (c *fakeRunnerClient) ParallelExplore(projectName, parentBranchID string, p
 }
 
 func (c *fakeRunnerClient) GetBranch(branchID string) (map[string]any, error) {
+	if branchID == "missing-branch" {
+		return map[string]any{"error": "branch not found"}, nil
+	}
 	return map[string]any{
 		"id":     branchID,
 		"status": "succeed",
@@ -63,6 +73,11 @@ func (c *fakeRunnerClient) BranchReadFile(branchID, filePath string) (map[string
 			"content": "No P0/P1 issues found",
 		}, nil
 	}
+	if strings.HasSuffix(filePath, focusFilename) {
+		return map[string]any{
+			"content": `{"areas":[{"area":"internal/cache","reason":"eviction changed"},{"area":"cmd/server"},{"area":"db"},{"area":"extra"}]}`,
+		}, nil
+	}
 	if strings.HasSuffix(filePath, changeAnalysisFilename) {
 		return map[string]any{
 			"content": "analysis",
@@ -101,6 +116,9 @@ func TestRunSkipsScoutWhenFlagSet(t *testing.T) {
 	if result.Status != statusClean {
 		t.Fatalf("expected status %q, got %q", statusClean, result.Status)
 	}
+	if result.PromptVersion != PromptVersion {
+		t.Fatalf("expected prompt_version %q, got %q", PromptVersion, result.PromptVersion)
+	}
 
 	client.mu.Lock()
 	defer client.mu.Unlock()
@@ -124,3 +142,450 @@ func TestRunSkipsScoutWhenFlagSet(t *testing.T) {
 		}
 	}
 }
+
+func TestRunFocusPassFeedsScoutPrompt(t *testing.T) {
+	client := &fakeRunnerClient{}
+	handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		WorkspaceDir:   "/workspace",
+		FocusPass:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	runner.hasRealIssueOverride = func(string) (bool, error) {
+		return false, nil
+	}
+
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if len(client.parallelCalls) < 2 || !strings.Contains(client.parallelCalls[0].prompt, "Role: FOCUS") {
+		t.Fatalf("expected focus pass to run first, got calls: %#v", client.parallelCalls)
+	}
+	scout := client.parallelCalls[1].prompt
+	if !strings.Contains(scout, "Role: SCOUT") {
+		t.Fatalf("expected scout after focus, got %q", scout)
+	}
+	for _, want := range []string{"1. internal/cache (eviction changed)", "2. cmd/server", "3. db"} {
+		if !strings.Contains(scout, want) {
+			t.Fatalf("scout prompt missing focus area %q", want)
+		}
+	}
+	if strings.Contains(scout, "4. extra") {
+		t.Fatalf("expected focus areas capped at %d", maxFocusAreas)
+	}
+}
+
+func TestRunScopeDirRestrictsPromptsAndScoutDiff(t *testing.T) {
+	client := &fakeRunnerClient{}
+	handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		WorkspaceDir:   "/workspace",
+		ScopeDir:       "./services/api/",
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	runner.hasRealIssueOverride = func(string) (bool, error) {
+		return false, nil
+	}
+
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if len(client.parallelCalls) < 2 {
+		t.Fatalf("expected scout and review runs, got %#v", client.parallelCalls)
+	}
+	for _, call := range client.parallelCalls {
+		if !strings.Contains(call.prompt, "Restrict all analysis and changes to services/api/") {
+			t.Fatalf("%s prompt missing scope restriction: %q", call.agent, call.prompt)
+		}
+	}
+	if scout := client.parallelCalls[0].prompt; !strings.Contains(scout, "git diff --name-status MERGE_BASE_SHA -- services/api") {
+		t.Fatalf("scout diff not filtered to the scope: %q", scout)
+	}
+
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		ScopeDir:       "../elsewhere",
+	}); err == nil {
+		t.Fatal("expected a scope dir outside the repository to be rejected")
+	}
+}
+
+func TestRunReplaysSavedReviewReport(t *testing.T) {
+	client := &fakeRunnerClient{}
+	handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+	opts := Options{
+		Task:             "task",
+		ProjectName:      "proj",
+		ParentBranchID:   "parent",
+		WorkspaceDir:     "/workspace",
+		SkipTester:       true,
+		ReviewReport:     "ISSUE: cache key drops tenant\n",
+		ReviewerBranchID: "finder-branch",
+	}
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, opts)
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	runner.hasRealIssueOverride = func(report string) (bool, error) {
+		if report != "ISSUE: cache key drops tenant" {
+			t.Fatalf("expected the saved report, got %q", report)
+		}
+		return true, nil
+	}
+	runner.verdictOverride = func(Transcript) (verdictDecision, error) {
+		return verdictDecision{Verdict: "confirmed"}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if result.Status != statusIssues || len(result.Issues) != 1 || result.Issues[0].Status != commentConfirmed {
+		t.Fatalf("expected one confirmed issue, got %#v", result)
+	}
+	if !strings.HasPrefix(result.Headline, "Review of branch parent: 1 confirmed P0/P1 (") {
+		t.Fatalf("unexpected headline %q", result.Headline)
+	}
+	if got := result.ReviewerLogs[0].BranchID; got != "finder-branch" {
+		t.Fatalf("expected reviewer log from finder-branch, got %q", got)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.parallelCalls) != 1 {
+		t.Fatalf("expected only the verification run, got calls: %#v", client.parallelCalls)
+	}
+	call := client.parallelCalls[0]
+	if call.agent == "review_code" || strings.Contains(call.prompt, "Role: SCOUT") {
+		t.Fatalf("scout/finder should be skipped, saw agent=%q", call.agent)
+	}
+	if call.parent != "finder-branch" {
+		t.Fatalf("verification should fork from finder-branch, got %q", call.parent)
+	}
+
+	opts.ReviewerBranchID = "missing-branch"
+	runner, err = NewRunner(&b.LLMBrain{}, handler, nil, opts)
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	if _, err := runner.Run(); err == nil || !strings.Contains(err.Error(), "missing-branch") {
+		t.Fatalf("expected missing reviewer branch to fail, got %v", err)
+	}
+
+	opts.ReviewerBranchID = ""
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, opts); err == nil {
+		t.Fatal("expected a report without a reviewer branch to be rejected")
+	}
+}
+
+func TestRunUsesPinnedStepOutputs(t *testing.T) {
+	client := &fakeRunnerClient{}
+	handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+	opts := Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		WorkspaceDir:   "/workspace",
+		SkipScout:      true,
+		SkipTester:     true,
+		PinnedSteps:    map[string]string{"Scout": " scout-pin ", StageReviewer: "reviewer-pin"},
+	}
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, opts)
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	if runner.opts.SkipScout {
+		t.Fatal("pinning the scout should enable the scout stage")
+	}
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.verdictOverride = func(Transcript) (verdictDecision, error) {
+		return verdictDecision{Verdict: "confirmed"}, nil
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Alpha.BranchID != "reviewer-pin" || result.Issues[0].Alpha.Text != "ok" {
+		t.Fatalf("expected the reviewer transcript from the pinned branch, got %#v", result.Issues)
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.parallelCalls) != 1 || client.parallelCalls[0].agent != "review_code" {
+		t.Fatalf("expected only the finder to run live, got %#v", client.parallelCalls)
+	}
+	if got := client.parallelCalls[0].parent; got != "scout-pin" {
+		t.Fatalf("finder should fork from the pinned scout branch, got %q", got)
+	}
+	if len(client.branchReadInputs) == 0 || client.branchReadInputs[0].branchID != "scout-pin" {
+		t.Fatalf("expected the change analysis to be read from the pinned scout, got %#v", client.branchReadInputs)
+	}
+}
+
+func TestRunReusesCachedStepBranchesAcrossRuns(t *testing.T) {
+	client := &fakeRunnerClient{}
+	opts := Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		WorkspaceDir:   "/workspace",
+		SkipScout:      false,
+		CacheDir:       t.TempDir(),
+	}
+	run := func(opts Options) int {
+		t.Helper()
+		runner, err := NewRunner(&b.LLMBrain{}, tools.NewToolHandler(client, "proj", "parent", "/workspace"), nil, opts)
+		if err != nil {
+			t.Fatalf("NewRunner error: %v", err)
+		}
+		runner.hasRealIssueOverride = func(string) (bool, error) { return false, nil }
+		client.mu.Lock()
+		before := len(client.parallelCalls)
+		client.mu.Unlock()
+		if _, err := runner.Run(); err != nil {
+			t.Fatalf("Run error: %v", err)
+		}
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		return len(client.parallelCalls) - before
+	}
+
+	if n := run(opts); n != 2 {
+		t.Fatalf("expected the first run to spawn the scout and the finder, got %d branches", n)
+	}
+	if n := run(opts); n != 0 {
+		t.Fatalf("expected an identical run to reuse both cached branches, got %d new branches", n)
+	}
+	refresh := opts
+	refresh.RefreshCache = true
+	if n := run(refresh); n != 2 {
+		t.Fatalf("expected --refresh-cache to bypass the cache, got %d new branches", n)
+	}
+	expired := opts
+	expired.CacheTTL = time.Nanosecond
+	if n := run(expired); n != 2 {
+		t.Fatalf("expected expired entries to be ignored, got %d new branches", n)
+	}
+	entries, _ := filepath.Glob(filepath.Join(opts.CacheDir, "step-*.json"))
+	if len(entries) == 0 {
+		t.Fatal("expected the runs to leave cache entries behind")
+	}
+	for _, path := range entries {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read cache entry: %v", err)
+		}
+		stale := strings.Replace(string(data), `"prompt_version": "`+PromptVersion+`"`, `"prompt_version": "v0.9"`, 1)
+		if err := os.WriteFile(path, []byte(stale), 0o644); err != nil {
+			t.Fatalf("rewrite cache entry: %v", err)
+		}
+	}
+	if n := run(opts); n != 2 {
+		t.Fatalf("expected entries from another prompt version to be ignored, got %d new branches", n)
+	}
+	changed := opts
+	changed.Task = "another task"
+	if n := run(changed); n != 2 {
+		t.Fatalf("expected a different prompt to miss the cache, got %d new branches", n)
+	}
+}
+
+func TestNewRunnerRequiresWorkspaceDirForEnabledStages(t *testing.T) {
+	handler := tools.NewToolHandler(&fakeRunnerClient{}, "proj", "parent", "")
+	opts := Options{Task: "task", ProjectName: "proj", ParentBranchID: "parent", FocusPass: true}
+	_, err := NewRunner(&b.LLMBrain{}, handler, nil, opts)
+	if err == nil {
+		t.Fatal("expected a missing workspace dir to be rejected up front")
+	}
+	if exitcodes.Code(err) != exitcodes.Config {
+		t.Fatalf("expected a missing workspace dir to map to the Config exit status, got %d", exitcodes.Code(err))
+	}
+	for _, want := range []string{"issue finder", "focus pass", "scout", "WORKSPACE_DIR"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected the error to mention %q, got %v", want, err)
+		}
+	}
+
+	opts.SkipScout = true
+	opts.ReviewReport = "ISSUE: cache key drops tenant"
+	opts.ReviewerBranchID = "finder-branch"
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, opts); err != nil {
+		t.Fatalf("replaying a report without the scout needs no workspace dir, got %v", err)
+	}
+}
+
+func TestParsePinnedStep(t *testing.T) {
+	step, branchID, err := ParsePinnedStep(" scout=branch:abc-123 ")
+	if err != nil || step != StageScout || branchID != "abc-123" {
+		t.Fatalf("got step=%q branch=%q err=%v", step, branchID, err)
+	}
+	for _, bad := range []string{"scout", "scout=abc", "scout=branch:", "exchange=branch:x"} {
+		if _, _, err := ParsePinnedStep(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestAuxSystemPromptOverrides(t *testing.T) {
+	handler := tools.NewToolHandler(newFakeAgentClient("", "", "", ""), "proj", "start", "")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:             "task",
+		ProjectName:      "proj",
+		ParentBranchID:   "start",
+		WorkspaceDir:     "/workspace",
+		AuxSystemPrompts: map[string]string{" Alignment ": "Output one JSON object. No prose.", AuxVerdict: "  "},
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	if got := runner.auxSystemPrompt(AuxAlignment); got != "Output one JSON object. No prose." {
+		t.Fatalf("alignment prompt = %q", got)
+	}
+	if got := runner.auxSystemPrompt(AuxVerdict); got != defaultAuxSystemPrompts[AuxVerdict] {
+		t.Fatalf("blank override should keep the default verdict prompt, got %q", got)
+	}
+
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task: "task", ProjectName: "proj", ParentBranchID: "start",
+		AuxSystemPrompts: map[string]string{"severity": "x"},
+	}); err == nil {
+		t.Fatal("expected unknown auxiliary call to be rejected")
+	}
+}
+
+func TestDetermineVerdictFallsThroughOnLowConfidenceMarker(t *testing.T) {
+	handler := tools.NewToolHandler(&fakeRunnerClient{}, "proj", "parent", "/workspace")
+	hedged := Transcript{Agent: "tester", Round: 1, Text: "# VERDICT: CONFIRMED\nConfidence: 0.3\n\nOn reflection the guard makes this unreachable."}
+	for _, tc := range []struct {
+		threshold float64
+		want      string
+	}{
+		{0, "confirmed"},
+		{0.3, "confirmed"},
+		{0.5, "rejected"},
+	} {
+		runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+			Task:                       "task",
+			ProjectName:                "proj",
+			ParentBranchID:             "parent",
+			WorkspaceDir:               "/workspace",
+			VerdictConfidenceThreshold: tc.threshold,
+		})
+		if err != nil {
+			t.Fatalf("NewRunner error: %v", err)
+		}
+		runner.verdictOverride = func(Transcript) (verdictDecision, error) {
+			return verdictDecision{Verdict: "rejected", Reason: "whole transcript"}, nil
+		}
+		decision, err := runner.determineVerdict(context.Background(), hedged)
+		if err != nil || decision.Verdict != tc.want {
+			t.Fatalf("threshold %v: expected %q, got %+v (err=%v)", tc.threshold, tc.want, decision, err)
+		}
+	}
+
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{Task: "task", ProjectName: "proj", ParentBranchID: "parent", VerdictConfidenceThreshold: 1.5}); err == nil {
+		t.Fatal("expected an out-of-range threshold to be rejected")
+	}
+}
+
+func TestHeadlineCountsLowConfidenceIssues(t *testing.T) {
+	res := &Result{Status: statusIssues, Issues: []IssueReport{
+		{IssueText: "[P1] stale cache", Status: commentConfirmedLowConfidence},
+		{IssueText: "[P0] double free", Status: commentConfirmed},
+	}}
+	want := "Review of PR #123: 1 confirmed P0, 1 low-confidence P1 (8m32s)."
+	if got := (&Runner{opts: Options{Subject: "PR #123"}}).headline(res, 8*time.Minute+32*time.Second); got != want {
+		t.Errorf("headline = %q, want %q", got, want)
+	}
+}
+
+func TestResultHasFindingsCountsOnlyConfirmedIssues(t *testing.T) {
+	cases := []struct {
+		statuses []string
+		want     bool
+	}{
+		{nil, false},
+		{[]string{"rejected"}, false},
+		{[]string{commentUnresolved, "rejected"}, false},
+		{[]string{"rejected", commentConfirmedLowConfidence}, true},
+		{[]string{commentConfirmed}, true},
+	}
+	for _, tc := range cases {
+		res := &Result{Status: statusIssues}
+		for _, status := range tc.statuses {
+			res.Issues = append(res.Issues, IssueReport{IssueText: "[P1] finding", Status: status})
+		}
+		if got := res.HasFindings(); got != tc.want {
+			t.Errorf("HasFindings with issue statuses %v = %v, want %v", tc.statuses, got, tc.want)
+		}
+	}
+	if (*Result)(nil).HasFindings() {
+		t.Error("a nil result should have no findings")
+	}
+}
+
+func TestRunMaxDurationBoundsAgentRuns(t *testing.T) {
+	client := &fakeRunnerClient{}
+	handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		WorkspaceDir:   "/workspace",
+		SkipScout:      true,
+		MaxDuration:    time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	runner.hasRealIssueOverride = func(string) (bool, error) {
+		t.Fatal("the run went on to the issue check after its deadline passed")
+		return false, nil
+	}
+
+	if _, err := runner.Run(); err == nil {
+		t.Fatal("expected the expired deadline to fail the run")
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.parallelCalls) != 0 {
+		t.Fatalf("expected no agent to start once the deadline passed, got %#v", client.parallelCalls)
+	}
+}
+
+func TestNewRunnerDefaultsMaxAuxInputChars(t *testing.T) {
+	handler := tools.NewToolHandler(&fakeRunnerClient{}, "proj", "parent", "/workspace")
+	opts := Options{Task: "task", ProjectName: "proj", ParentBranchID: "parent", WorkspaceDir: "/workspace", SkipScout: true}
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, opts)
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	if runner.opts.MaxAuxInputChars != DefaultMaxAuxInputChars {
+		t.Fatalf("expected the default limit %d, got %d", DefaultMaxAuxInputChars, runner.opts.MaxAuxInputChars)
+	}
+	opts.MaxAuxInputChars = -1
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, opts); err == nil {
+		t.Fatal("expected a negative limit to be rejected")
+	}
+}
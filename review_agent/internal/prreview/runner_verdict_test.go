@@ -1,6 +1,9 @@
 package prreview
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestDetermineVerdictFallsBackToLLMOverrideWhenMarkerIsNotRegexParsable(t *testing.T) {
 	called := 0
@@ -19,7 +22,7 @@ func TestDetermineVerdictFallsBackToLLMOverrideWhenMarkerIsNotRegexParsable(t *t
 		Text:  "Wrote the verdict:\n\n- `# VERDICT: CONFIRMED`\n- `Severity: P0`\n",
 	}
 
-	decision, err := r.determineVerdict(transcript)
+	decision, err := r.determineVerdict(context.Background(), transcript)
 	if err != nil {
 		t.Fatalf("determineVerdict error: %v", err)
 	}
@@ -43,7 +46,7 @@ func TestDetermineVerdictPrefersExplicitMarkerOverLLMOverride(t *testing.T) {
 		Round: 1,
 		Text:  "# VERDICT: REJECTED\n\nClaim: test\nAnchor: unknown\n",
 	}
-	decision, err := r.determineVerdict(transcript)
+	decision, err := r.determineVerdict(context.Background(), transcript)
 	if err != nil {
 		t.Fatalf("determineVerdict error: %v", err)
 	}
@@ -54,3 +57,36 @@ func TestDetermineVerdictPrefersExplicitMarkerOverLLMOverride(t *testing.T) {
 		t.Fatalf("expected non-empty reason")
 	}
 }
+
+func TestNormalizeLanguageRewritesCJKTranscriptBeforeParsing(t *testing.T) {
+	calls := 0
+	r := &Runner{
+		opts: Options{NormalizeLanguage: true},
+		languageOverride: func(transcript Transcript) (string, error) {
+			calls++
+			return "# VERDICT: CONFIRMED\n\nClaim: cache.Put writes to a nil map\nAnchor: cache.go:42", nil
+		},
+		verdictOverride: func(Transcript) (verdictDecision, error) {
+			t.Fatalf("verdictOverride should not be needed after normalization")
+			return verdictDecision{}, nil
+		},
+	}
+	original := "# 结论：确认\n\n问题：cache.Put 在 nil map 上写入，会导致 panic。\n位置：cache.go:42"
+	transcript := r.normalizeLanguage(context.Background(), Transcript{Agent: "tester", Round: 1, Text: original})
+	if calls != 1 || transcript.OriginalText != original {
+		t.Fatalf("expected one normalization keeping the original, got calls=%d %+v", calls, transcript)
+	}
+	decision, err := r.determineVerdict(context.Background(), transcript)
+	if err != nil || decision.Verdict != "confirmed" {
+		t.Fatalf("expected the normalized marker to parse, got %+v (err=%v)", decision, err)
+	}
+
+	english := Transcript{Agent: "reviewer", Round: 1, Text: "# VERDICT: REJECTED\n\n注释: 保留中文备注"}
+	if got := r.normalizeLanguage(context.Background(), english); got.Text != english.Text || calls != 1 {
+		t.Fatalf("expected a transcript with an English marker to be left alone, got %+v", got)
+	}
+	r.opts.NormalizeLanguage = false
+	if got := r.normalizeLanguage(context.Background(), Transcript{Text: original}); got.Text != original || calls != 1 {
+		t.Fatalf("expected no normalization when the option is off, got %+v", got)
+	}
+}
@@ -0,0 +1,53 @@
+package prreview
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// normalizeScopeDir cleans Options.ScopeDir into a slash-separated path relative
+// to the repository root; "" and "." both mean the whole repository.
+func normalizeScopeDir(dir string) (string, error) {
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(dir) {
+		return "", fmt.Errorf("scope dir %q must be relative to the repository root", dir)
+	}
+	clean := filepath.ToSlash(filepath.Clean(dir))
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("scope dir %q leaves the repository", dir)
+	}
+	if clean == "." {
+		return "", nil
+	}
+	return clean, nil
+}
+
+// scopeBlock is appended to every agent prompt of a scoped run.
+func scopeBlock(scopeDir string) string {
+	var sb strings.Builder
+	sb.WriteString("**SCOPE**\n")
+	sb.WriteString(fmt.Sprintf("- Restrict all analysis and changes to %s/ (relative to the repository root)\n", scopeDir))
+	sb.WriteString("- Read code outside it only to follow a call or contract the scoped code depends on\n")
+	sb.WriteString("- Do not report issues in files outside the scope\n")
+	return sb.String()
+}
+
+// withScope appends the scope block to prompt when the run is scoped.
+func withScope(prompt, scopeDir string) string {
+	if scopeDir == "" {
+		return prompt
+	}
+	return strings.TrimRight(prompt, "\n") + "\n\n" + scopeBlock(scopeDir)
+}
+
+// diffPathspec limits the scout's git diff commands to the scope.
+func diffPathspec(scopeDir string) string {
+	if scopeDir == "" {
+		return ""
+	}
+	return " -- " + scopeDir
+}
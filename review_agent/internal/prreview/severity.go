@@ -0,0 +1,123 @@
+package prreview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"review_agent/internal/miniyaml"
+)
+
+// SeverityLevel is one rung of the severity ladder shown to the reviewer.
+type SeverityLevel struct {
+	Name       string `json:"name"`
+	Label      string `json:"label,omitempty"`
+	Definition string `json:"definition"`
+}
+
+// SeverityPolicy is the severity bar rendered into the reviewer prompt. Teams can
+// replace the built-in definitions with their own via --severity-policy.
+type SeverityPolicy struct {
+	Levels      []SeverityLevel `json:"levels"`
+	EvidenceBar string          `json:"evidence_bar,omitempty"`
+}
+
+// DefaultSeverityPolicy returns the definitions the reviewer prompt has always used.
+func DefaultSeverityPolicy() SeverityPolicy {
+	return SeverityPolicy{
+		Levels: []SeverityLevel{
+			{
+				Name:       "P0",
+				Label:      "Critical/Blocker",
+				Definition: "Reachable under default production configuration, and causes production unavailability; severe data loss/corruption; a security vulnerability; or a primary workflow is completely blocked with no practical workaround. Must be fixed immediately.",
+			},
+			{
+				Name:       "P1",
+				Label:      "High",
+				Definition: "Reachable in realistic production scenarios (default or commonly enabled configs), and significantly impairs core/major functionality or violates user-facing contracts relied upon (including user-visible correctness errors), or causes a severe performance regression that impacts use; a workaround may exist but is costly/risky/high-friction. Must be fixed before release.",
+			},
+		},
+		EvidenceBar: "A P0/P1 claim must be backed by clear code-causal evidence and an explicit blast-radius assessment; if it’s borderline between P1 and P2, default to P1 unless the impact is clearly narrow or edge-case only.",
+	}
+}
+
+// Validate reports the first structural problem in the policy.
+func (p SeverityPolicy) Validate() error {
+	if len(p.Levels) == 0 {
+		return errors.New("severity policy needs at least one level")
+	}
+	seen := map[string]bool{}
+	for i, level := range p.Levels {
+		name := strings.TrimSpace(level.Name)
+		if name == "" {
+			return fmt.Errorf("severity level %d has no name", i+1)
+		}
+		if strings.TrimSpace(level.Definition) == "" {
+			return fmt.Errorf("severity level %s has no definition", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("severity level %s is defined twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func (p SeverityPolicy) writeTo(sb *strings.Builder) {
+	sb.WriteString("Reference severity definitions (guidance, not a hard rule):\n")
+	for _, level := range p.Levels {
+		sb.WriteString("- ")
+		sb.WriteString(strings.TrimSpace(level.Name))
+		if label := strings.TrimSpace(level.Label); label != "" {
+			sb.WriteString(" (")
+			sb.WriteString(label)
+			sb.WriteString(")")
+		}
+		sb.WriteString(": ")
+		sb.WriteString(strings.TrimSpace(level.Definition))
+		sb.WriteString("\n")
+	}
+	if bar := strings.TrimSpace(p.EvidenceBar); bar != "" {
+		sb.WriteString("- Lightweight evidence bar (guidance): ")
+		sb.WriteString(bar)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
+
+// LoadSeverityPolicy reads a policy from a .json, .yaml or .yml file.
+func LoadSeverityPolicy(path string) (SeverityPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SeverityPolicy{}, fmt.Errorf("read severity policy: %w", err)
+	}
+	var policy SeverityPolicy
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&policy); err != nil {
+			return SeverityPolicy{}, fmt.Errorf("parse severity policy %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := miniyaml.Unmarshal(data, &policy); err != nil {
+			return SeverityPolicy{}, fmt.Errorf("parse severity policy %s: %w", path, err)
+		}
+		// Block scalars keep their final newline; trim so a YAML policy loads
+		// exactly like the same policy written as JSON.
+		policy.EvidenceBar = strings.TrimSpace(policy.EvidenceBar)
+		for i := range policy.Levels {
+			policy.Levels[i].Definition = strings.TrimSpace(policy.Levels[i].Definition)
+		}
+	default:
+		return SeverityPolicy{}, fmt.Errorf("severity policy %s: unsupported extension (want .json, .yaml or .yml)", path)
+	}
+	if err := policy.Validate(); err != nil {
+		return SeverityPolicy{}, fmt.Errorf("severity policy %s: %w", path, err)
+	}
+	return policy, nil
+}
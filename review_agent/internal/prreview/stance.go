@@ -0,0 +1,56 @@
+package prreview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stances set how readily an issue is accepted: skeptical suits high-precision
+// gating, confirming suits high-recall triage. Both review agent versions share
+// these names, the default and the wording; review_agent_v1.1 applies the stance
+// to its verify agent as well as the reviewer.
+const (
+	StanceSkeptical  = "skeptical"
+	StanceBalanced   = "balanced"
+	StanceConfirming = "confirming"
+
+	// DefaultStance applies when no stance is given.
+	DefaultStance = StanceSkeptical
+)
+
+// stanceBlocks holds the directive block each stance injects into the prompt.
+var stanceBlocks = map[string]string{
+	StanceSkeptical: "**SKEPTICAL STANCE**\n" +
+		"- Treat each issue as a possible misread, misunderstanding, or edge case until the code evidence forces you to accept it.\n" +
+		"- CONFIRM only if you can trace a concrete, reachable execution path in default/production configurations and state its real impact.\n" +
+		"- If the evidence stays inconclusive, REJECT.\n",
+	StanceBalanced: "**BALANCED STANCE**\n" +
+		"- Challenge the claim, but if you cannot find strong counter-evidence and can trace a reachable execution path, CONFIRM it.\n" +
+		"- Don't reject issues based on weak assumptions or hypothetical safeguards that may not actually prevent the problem.\n" +
+		"- REJECT only if you can show the issue cannot occur or rests on incorrect assumptions.\n",
+	StanceConfirming: "**CONFIRMING STANCE**\n" +
+		"- If the issue description is plausible and you can trace a real execution path, CONFIRM it.\n" +
+		"- Don't be overly conservative - if there's a reasonable chance the issue could occur in production, CONFIRM it.\n" +
+		"- REJECT only if you can prove the issue cannot occur or has no real impact.\n",
+}
+
+// ParseStance validates a stance name; empty means DefaultStance.
+func ParseStance(stance string) (string, error) {
+	stance = strings.ToLower(strings.TrimSpace(stance))
+	if stance == "" {
+		return DefaultStance, nil
+	}
+	if _, ok := stanceBlocks[stance]; !ok {
+		return "", fmt.Errorf("unknown stance %q (want skeptical, balanced or confirming)", stance)
+	}
+	return stance, nil
+}
+
+// stanceBlock returns the directive block for stance, falling back to
+// DefaultStance for an empty or unknown stance.
+func stanceBlock(stance string) string {
+	if block, ok := stanceBlocks[stance]; ok {
+		return block
+	}
+	return stanceBlocks[DefaultStance]
+}
@@ -0,0 +1,104 @@
+package prreview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"review_agent/internal/logx"
+)
+
+// DefaultCacheTTL is how long a cached step branch is reused when Options.CacheTTL is unset.
+const DefaultCacheTTL = 24 * time.Hour
+
+// stepCacheEntry is the on-disk record of one cached step run, one file per
+// key under Options.CacheDir.
+type stepCacheEntry struct {
+	Step           string    `json:"step"`
+	Agent          string    `json:"agent"`
+	ParentBranchID string    `json:"parent_branch_id"`
+	BranchID       string    `json:"branch_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	PromptVersion  string    `json:"prompt_version,omitempty"`
+}
+
+// cachesStep reports whether step's branch is kept across runs. Only the steps
+// before verification are cached: their prompts depend on the task and the
+// parent branch alone, so an identical run is expected to produce the same output.
+func (r *Runner) cachesStep(step string) bool {
+	if r.opts.CacheDir == "" {
+		return false
+	}
+	switch step {
+	case StageFocus, StageScout, StageFinder:
+		return true
+	}
+	return false
+}
+
+// stepCacheKey hashes what determines a step's output: the agent, the prompt it
+// is sent (scope included) and the branch it forks from.
+func stepCacheKey(agent, prompt, parentBranchID string) string {
+	sum := sha256.Sum256([]byte(agent + "\x00" + parentBranchID + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+func stepCachePath(dir, key string) string {
+	return filepath.Join(dir, "step-"+key+".json")
+}
+
+// cachedStepBranch returns the branch a previous run recorded under key, unless
+// RefreshCache is set or the entry is missing, unreadable, older than CacheTTL
+// or written by another prompt generation.
+func (r *Runner) cachedStepBranch(step, key string) (stepCacheEntry, bool) {
+	if r.opts.RefreshCache {
+		return stepCacheEntry{}, false
+	}
+	path := stepCachePath(r.opts.CacheDir, key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			logx.Warningf("Ignoring unreadable %s step cache: %v", step, err)
+		}
+		return stepCacheEntry{}, false
+	}
+	var entry stepCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.BranchID == "" {
+		logx.Warningf("Ignoring malformed %s step cache %s", step, path)
+		return stepCacheEntry{}, false
+	}
+	if age := time.Since(entry.CreatedAt); age > r.opts.CacheTTL {
+		logx.Infof("Cached %s branch %s expired (%s old); running the step again.", step, entry.BranchID, age.Round(time.Second))
+		return stepCacheEntry{}, false
+	}
+	if entry.PromptVersion != PromptVersion {
+		version := entry.PromptVersion
+		if version == "" {
+			version = "an unversioned prompt"
+		}
+		logx.Warningf("Ignoring cached %s branch %s: it was produced by %s, not %s; running the step again.", step, entry.BranchID, version, PromptVersion)
+		return stepCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// storeStepBranch records branchID under key for later runs. A failed write only
+// costs the next run a cache miss, so it is logged rather than returned.
+func (r *Runner) storeStepBranch(key string, entry stepCacheEntry) {
+	entry.CreatedAt = time.Now().UTC()
+	entry.PromptVersion = PromptVersion
+	path := stepCachePath(r.opts.CacheDir, key)
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err == nil {
+		if err = os.MkdirAll(r.opts.CacheDir, 0o755); err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+	}
+	if err != nil {
+		logx.Warningf("Failed to cache the %s step in %s: %v", entry.Step, path, err)
+	}
+}
@@ -0,0 +1,43 @@
+package prreview
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateResultAcceptsMarshaledResult(t *testing.T) {
+	res := &Result{
+		Task:         "task",
+		Status:       statusIssues,
+		Summary:      "summary",
+		ReviewerLogs: []ReviewerLog{{BranchID: "b1", Report: "report"}},
+		Issues:       []IssueReport{{IssueText: "issue", Status: commentConfirmed}},
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := ValidateResult(data); err != nil {
+		t.Fatalf("expected valid result, got %v", err)
+	}
+}
+
+func TestValidateResultReportsUnknownAndMissingFields(t *testing.T) {
+	raw := `{"task":"t","status":"clean","reviewer_logs":[],"issues":[{"issue_text":"x","status":"confirmed","alpha":{},"beta":{},"exchange_rounds":"1","legacy":true}],"extra":1}`
+	err := ValidateResult([]byte(raw))
+	if err == nil {
+		t.Fatalf("expected schema mismatch")
+	}
+	for _, want := range []string{
+		"missing field summary",
+		"unknown field extra",
+		"unknown field issues[0].legacy",
+		"issues[0].exchange_rounds: expected number",
+		"missing field issues[0].alpha.agent",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err.Error(), want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"reflect"
+
+	"review_agent/internal/logx"
+)
+
+// MaxBranchIDDepth bounds how far ExtractBranchID descends into nested
+// parallel_explore/branches/branch entries.
+const MaxBranchIDDepth = 32
+
+// ExtractBranchID returns the first branch id in an MCP response, searching nested
+// parallel_explore, branches and branch entries up to MaxBranchIDDepth levels.
+func ExtractBranchID(m map[string]any) string {
+	return ExtractBranchIDDepth(m, MaxBranchIDDepth)
+}
+
+// ExtractBranchIDDepth is ExtractBranchID with an explicit nesting limit. Entries
+// nested deeper than maxDepth, or maps that re-nest themselves, are not searched.
+func ExtractBranchIDDepth(m map[string]any, maxDepth int) string {
+	search := branchIDSearch{maxDepth: maxDepth, seen: map[uintptr]bool{}}
+	id := search.find(m, 0)
+	if id == "" && search.truncated {
+		logx.Warningf("Branch id search stopped at nesting depth %d; treating response as having no branch id.", maxDepth)
+	}
+	return id
+}
+
+type branchIDSearch struct {
+	maxDepth  int
+	seen      map[uintptr]bool
+	truncated bool
+}
+
+func (s *branchIDSearch) find(m map[string]any, depth int) string {
+	if m == nil {
+		return ""
+	}
+	if depth > s.maxDepth {
+		s.truncated = true
+		return ""
+	}
+	ptr := reflect.ValueOf(m).Pointer()
+	if s.seen[ptr] {
+		return ""
+	}
+	s.seen[ptr] = true
+
+	if pe, ok := m["parallel_explore"].(map[string]any); ok {
+		if branches, ok := pe["branches"].([]any); ok {
+			for _, item := range branches {
+				if nested, _ := item.(map[string]any); nested != nil {
+					if id := s.find(nested, depth+1); id != "" {
+						return id
+					}
+				}
+			}
+		}
+	}
+	if branches, ok := m["branches"].([]any); ok {
+		for _, item := range branches {
+			if nested, _ := item.(map[string]any); nested != nil {
+				if id := s.find(nested, depth+1); id != "" {
+					return id
+				}
+			}
+		}
+	}
+	if b, ok := m["branch"].(map[string]any); ok {
+		if id := s.find(b, depth+1); id != "" {
+			return id
+		}
+	}
+	for _, k := range []string{"branch_id", "id"} {
+		if v, ok := m[k].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package tools
+
+import "testing"
+
+func TestExtractBranchIDStopsAtMaxDepth(t *testing.T) {
+	nest := func(levels int) map[string]any {
+		payload := map[string]any{"branch_id": "deep-branch"}
+		for i := 0; i < levels; i++ {
+			payload = map[string]any{"branch": payload}
+		}
+		return payload
+	}
+	if got := ExtractBranchID(nest(MaxBranchIDDepth)); got != "deep-branch" {
+		t.Fatalf("expected id at the depth limit to be found, got %q", got)
+	}
+	if got := ExtractBranchID(nest(MaxBranchIDDepth + 1)); got != "" {
+		t.Fatalf("expected empty id past the depth limit, got %q", got)
+	}
+	if got := ExtractBranchIDDepth(nest(MaxBranchIDDepth+1), MaxBranchIDDepth+1); got != "deep-branch" {
+		t.Fatalf("expected a larger explicit limit to find the id, got %q", got)
+	}
+}
+
+func TestExtractBranchIDHandlesSelfReferentialPayload(t *testing.T) {
+	loop := map[string]any{}
+	loop["branch"] = loop
+	loop["branches"] = []any{loop, loop, loop}
+	loop["parallel_explore"] = map[string]any{"branches": []any{loop, loop}}
+	if got := ExtractBranchID(loop); got != "" {
+		t.Fatalf("expected empty id for a cyclic payload, got %q", got)
+	}
+
+	loop["id"] = "cyclic-branch"
+	if got := ExtractBranchID(loop); got != "cyclic-branch" {
+		t.Fatalf("expected the cyclic payload's own id, got %q", got)
+	}
+}
@@ -1,11 +1,14 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"review_agent/internal/config"
 	"review_agent/internal/logx"
+	"review_agent/internal/streaming"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +18,8 @@ type ToolExecutionError struct {
 	Msg         string
 	Instruction string
 	Details     map[string]any
+	// Kind classifies the failure (see ErrorKindMCP); empty when unclassified.
+	Kind string
 }
 
 func (e ToolExecutionError) Error() string { return e.Msg }
@@ -30,7 +35,6 @@ var _ agentClient = (*MCPClient)(nil)
 
 const (
 	reviewCodeAgent            = "review_code"
-	reviewArtifactName         = "code_review.log"
 	reviewMaxAttempts          = 3
 	instructionFinishedWithErr = "FINISHED_WITH_ERROR"
 )
@@ -63,12 +67,27 @@ func (t *BranchTracker) Range() map[string]string {
 	return map[string]string{"start_branch_id": t.start, "latest_branch_id": t.latest}
 }
 
+// DefaultReviewArtifactName is the review log review_code must write when no
+// REVIEW_ARTIFACT_NAME is configured.
+const DefaultReviewArtifactName = "code_review.log"
+
 type ToolHandler struct {
-	client        agentClient
-	cfg           *config.AgentConfig // nil = use defaults (for tests)
-	defaultProj   string
-	branchTracker *BranchTracker
-	workspaceDir  string
+	client         agentClient
+	cfg            *config.AgentConfig // nil = use defaults (for tests)
+	defaultProj    string
+	branchTracker  *BranchTracker
+	workspaceDir   string
+	reviewArtifact string
+	transformers   []ResponseTransformer
+	// sleepFunc replaces sleepContext between MCP retries and status polls; nil
+	// uses sleepContext.
+	sleepFunc func(ctx context.Context, d time.Duration) error
+
+	// streamer receives poll.tick events while checkStatus waits; nil disables them.
+	streamer *streaming.JSONStreamer
+	// missingReviewLogClean turns a review_code run that never wrote its log, but
+	// said in its output that it found nothing, into a clean review.
+	missingReviewLogClean bool
 }
 
 // NewToolHandler creates a handler without config. Uses hardcoded defaults.
@@ -86,16 +105,49 @@ func NewToolHandler(client agentClient, defaultProject string, startBranch strin
 // NewToolHandlerWithConfig creates a handler with config. Use this in production.
 func NewToolHandlerWithConfig(client agentClient, cfg *config.AgentConfig, startBranch string) *ToolHandler {
 	return &ToolHandler{
-		client:        client,
-		cfg:           cfg,
-		defaultProj:   cfg.ProjectName,
-		branchTracker: NewBranchTracker(startBranch),
-		workspaceDir:  strings.TrimSpace(cfg.WorkspaceDir),
+		client:         client,
+		cfg:            cfg,
+		defaultProj:    cfg.ProjectName,
+		branchTracker:  NewBranchTracker(startBranch),
+		workspaceDir:   strings.TrimSpace(cfg.WorkspaceDir),
+		reviewArtifact: strings.TrimSpace(cfg.ReviewArtifactName),
 	}
 }
 
 func (h *ToolHandler) BranchRange() map[string]string { return h.branchTracker.Range() }
 
+// SetStreamer makes checkStatus emit a poll.tick event on each poll of a branch.
+func (h *ToolHandler) SetStreamer(streamer *streaming.JSONStreamer) {
+	h.streamer = streamer
+}
+
+// SetMissingReviewLogClean sets whether review_code may end without its review log
+// when the last attempt's output states that no issues were found. The handler
+// then returns a synthesized clean report, flagged review_log_synthesized, instead
+// of a FINISHED_WITH_ERROR failure.
+func (h *ToolHandler) SetMissingReviewLogClean(clean bool) {
+	h.missingReviewLogClean = clean
+}
+
+// ResponseTransformer rewrites the response text extracted from an agent run, e.g.
+// to strip ANSI codes or redact sandbox paths, before callers see it.
+type ResponseTransformer func(agent, response string) string
+
+// AddResponseTransformer registers fn to post-process agent responses. Transformers
+// run in registration order; register them before the handler is in use.
+func (h *ToolHandler) AddResponseTransformer(fn ResponseTransformer) {
+	if fn != nil {
+		h.transformers = append(h.transformers, fn)
+	}
+}
+
+func (h *ToolHandler) transformResponse(agent, response string) string {
+	for _, fn := range h.transformers {
+		response = fn(agent, response)
+	}
+	return response
+}
+
 // ToolCall mirrors brain.ToolCall, but we keep it generic here if needed.
 type ToolCall struct {
 	ID       string `json:"id"`
@@ -107,6 +159,12 @@ type ToolCall struct {
 }
 
 func (h *ToolHandler) Handle(call ToolCall) map[string]any {
+	return h.HandleContext(context.Background(), call)
+}
+
+// HandleContext is Handle with a context that bounds agent runs: once ctx ends,
+// branch polling stops and the branch is cancelled when the client supports it.
+func (h *ToolHandler) HandleContext(ctx context.Context, call ToolCall) map[string]any {
 	name := call.Function.Name
 	if name == "" {
 		return h.errorPayload(ToolExecutionError{Msg: "Missing tool name in call."})
@@ -124,9 +182,9 @@ func (h *ToolHandler) Handle(call ToolCall) map[string]any {
 	var err error
 	switch name {
 	case "execute_agent":
-		res, err = h.executeAgent(args)
+		res, err = h.executeAgent(ctx, args)
 	case "check_status":
-		res, err = h.checkStatus(args)
+		res, err = h.checkStatus(ctx, args)
 	case "read_artifact":
 		res, err = h.readArtifact(args)
 	case "branch_output":
@@ -140,7 +198,7 @@ func (h *ToolHandler) Handle(call ToolCall) map[string]any {
 	return map[string]any{"status": "success", "data": res}
 }
 
-func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, error) {
+func (h *ToolHandler) executeAgent(ctx context.Context, arguments map[string]any) (map[string]any, error) {
 	agent, _ := arguments["agent"].(string)
 	prompt, _ := arguments["prompt"].(string)
 	project := h.defaultProj
@@ -154,34 +212,47 @@ func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, er
 	}
 
 	if agent == reviewCodeAgent {
-		return h.executeReviewAgent(project, parent, prompt)
+		return h.executeReviewAgent(ctx, project, parent, prompt)
 	}
-	result, _, err := h.runAgentOnce(agent, project, parent, prompt)
+	result, _, err := h.runAgentOnce(ctx, agent, project, parent, prompt)
 	return result, err
 }
 
-func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
-	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
-	resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
-	if err != nil {
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
-			Instruction: instructionFinishedWithErr,
-		}
-	}
-	if isErr, ok := resp["isError"].(bool); ok && isErr {
-		errMsg := resp["error"]
-		if errMsg == nil {
-			return nil, "", ToolExecutionError{
-				Msg:         fmt.Sprintf("ParallelExplore returned error (details: %v)", resp),
+// startAgent issues parallel_explore, re-issuing it while the server reports a
+// retryable error.
+func (h *ToolHandler) startAgent(ctx context.Context, agent, project, parent, prompt string) (map[string]any, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
+		if err != nil {
+			return nil, ToolExecutionError{
+				Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
 				Instruction: instructionFinishedWithErr,
 			}
 		}
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore returned error: %v", errMsg),
-			Instruction: instructionFinishedWithErr,
+		if isErr, ok := resp["isError"].(bool); !ok || !isErr {
+			return resp, nil
+		}
+		te := newMCPToolError(resp)
+		if te.Kind != ErrorKindMCPRetryable || attempt >= mcpRetryAttempts {
+			return nil, te
+		}
+		wait := mcpRetryWait(attempt)
+		logx.Warningf("ParallelExplore reported a retryable error (attempt %d/%d): %s. Retrying in %ds...", attempt, mcpRetryAttempts, te.Msg, int(wait.Seconds()))
+		if err := h.sleep(ctx, wait); err != nil {
+			return nil, cancelledError(fmt.Sprintf("Cancelled before %s could start", agent), err)
 		}
 	}
+}
+
+func (h *ToolHandler) runAgentOnce(ctx context.Context, agent, project, parent, prompt string) (map[string]any, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", cancelledError(fmt.Sprintf("Cancelled before %s could start", agent), err)
+	}
+	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
+	resp, err := h.startAgent(ctx, agent, project, parent, prompt)
+	if err != nil {
+		return nil, "", err
+	}
 	branchID := ExtractBranchID(resp)
 	if branchID == "" {
 		return nil, "", ToolExecutionError{
@@ -194,7 +265,7 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	result := map[string]any{"parallel_explore": resp, "branch_id": branchID}
 
 	logx.Infof("Waiting for branch %s to complete.", branchID)
-	statusResp, err := h.checkStatus(map[string]any{"branch_id": branchID})
+	statusResp, err := h.checkStatus(ctx, map[string]any{"branch_id": branchID})
 	if err != nil {
 		// checkStatus failed - don't record this branch ID
 		if te, ok := err.(ToolExecutionError); ok {
@@ -241,23 +312,27 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	if strings.TrimSpace(responseText) == "" {
 		return nil, "", ToolExecutionError{Msg: "branch_output returned no textual output"}
 	}
-	result["response"] = strings.TrimSpace(responseText)
+	result["response"] = h.transformResponse(agent, strings.TrimSpace(responseText))
 
 	return result, branchID, nil
 }
 
-func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[string]any, error) {
+func (h *ToolHandler) executeReviewAgent(ctx context.Context, project, parent, prompt string) (map[string]any, error) {
 	artifactPath := h.reviewLogPath()
 	if artifactPath == "" {
-		return nil, ToolExecutionError{Msg: "workspace directory not configured for review_code validation"}
+		return nil, ToolExecutionError{Msg: fmt.Sprintf("workspace dir is not configured, so review_code's %s cannot be checked: set WORKSPACE_DIR to the agent's workspace path", h.ReviewArtifactName())}
 	}
-	var lastBranch string
+	var (
+		lastBranch string
+		lastResult map[string]any
+	)
 	for attempt := 1; attempt <= reviewMaxAttempts; attempt++ {
-		result, branchID, err := h.runAgentOnce(reviewCodeAgent, project, parent, prompt)
+		result, branchID, err := h.runAgentOnce(ctx, reviewCodeAgent, project, parent, prompt)
 		if err != nil {
 			return nil, err
 		}
 		lastBranch = branchID
+		lastResult = result
 		if artifact, err := h.client.BranchReadFile(branchID, artifactPath); err == nil {
 			if content, ok := artifact["content"].(string); ok && strings.TrimSpace(content) != "" {
 				result["review_report"] = content
@@ -268,6 +343,14 @@ func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[st
 		}
 		logx.Warningf("review_code attempt %d/%d did not produce %s (branch=%s)", attempt, reviewMaxAttempts, artifactPath, branchID)
 	}
+	if h.missingReviewLogClean && lastResult != nil {
+		if response, _ := lastResult["response"].(string); statesNoIssues(response) {
+			logx.Warningf("review_code never wrote %s, but branch %s reported no issues; treating the review as clean", artifactPath, lastBranch)
+			lastResult["review_report"] = fmt.Sprintf("No P0/P1 issues found.\n\n(Synthesized: review_code did not write %s; the output of branch %s stated that no issues were found.)", artifactPath, lastBranch)
+			lastResult["review_log_synthesized"] = true
+			return lastResult, nil
+		}
+	}
 	details := map[string]any{
 		"attempts":      reviewMaxAttempts,
 		"artifact_path": artifactPath,
@@ -290,10 +373,18 @@ func (h *ToolHandler) reviewLogPath() string {
 	if strings.TrimSpace(h.workspaceDir) == "" {
 		return ""
 	}
-	return filepath.Join(h.workspaceDir, reviewArtifactName)
+	return filepath.Join(h.workspaceDir, h.ReviewArtifactName())
 }
 
-func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, error) {
+// ReviewArtifactName is the file name review_code runs are expected to write.
+func (h *ToolHandler) ReviewArtifactName() string {
+	if h.reviewArtifact != "" {
+		return h.reviewArtifact
+	}
+	return DefaultReviewArtifactName
+}
+
+func (h *ToolHandler) checkStatus(ctx context.Context, arguments map[string]any) (map[string]any, error) {
 	branchID, _ := arguments["branch_id"].(string)
 	if branchID == "" {
 		return nil, ToolExecutionError{Msg: "`branch_id` is required"}
@@ -326,6 +417,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 	sleep := time.Duration(poll * float64(time.Second))
 
 	logx.Infof("Checking status for branch %s (timeout=%ds)", branchID, int(timeout))
+	pollStart := time.Now()
 	for attempt := 1; ; attempt++ {
 		resp, err := h.client.GetBranch(branchID)
 		if err != nil {
@@ -352,6 +444,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 		}
 
 		status := stringsLower(resp["status"])
+		h.streamer.EmitPollTick(branchID, attempt, time.Now().Sub(pollStart), status)
 		latest_snap_id := stringsLower(resp["latest_snap_id"])
 		hasNewSnapshot := true
 		parent_branch_id := stringsLower(resp["parent_id"])
@@ -405,11 +498,42 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 			}
 		}
 		logx.Infof("Branch %s still active (status=%s). Sleeping %.1fs.", branchID, status, sleep.Seconds())
-		time.Sleep(sleep)
+		if err := h.sleep(ctx, sleep); err != nil {
+			// The MCP server has no cancel RPC, so the branch keeps running
+			// remotely; only this wait is abandoned.
+			return nil, cancelledError(fmt.Sprintf("Stopped waiting for branch %s (last status=%s)", branchID, status), err)
+		}
 		sleep = time.Duration(minFloat(float64(sleep/time.Second)*backoffFactor, maxPoll)) * time.Second
 	}
 }
 
+func cancelledError(msg string, cause error) ToolExecutionError {
+	return ToolExecutionError{
+		Msg:         fmt.Sprintf("%s: %v", msg, cause),
+		Instruction: instructionFinishedWithErr,
+		Kind:        ErrorKindCancelled,
+	}
+}
+
+func (h *ToolHandler) sleep(ctx context.Context, d time.Duration) error {
+	if h != nil && h.sleepFunc != nil {
+		return h.sleepFunc(ctx, d)
+	}
+	return sleepContext(ctx, d)
+}
+
+// sleepContext waits for d or until ctx ends, returning ctx's error in that case.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (h *ToolHandler) readArtifact(arguments map[string]any) (map[string]any, error) {
 	branchID, _ := arguments["branch_id"].(string)
 	path, _ := arguments["path"].(string)
@@ -438,44 +562,6 @@ func (h *ToolHandler) branchOutput(arguments map[string]any) (map[string]any, er
 	return h.client.BranchOutput(branchID, fullOutput)
 }
 
-func ExtractBranchID(m map[string]any) string {
-	if m == nil {
-		return ""
-	}
-
-	if pe, ok := m["parallel_explore"].(map[string]any); ok {
-		if branches, ok := pe["branches"].([]any); ok {
-			for _, item := range branches {
-				if nested, _ := item.(map[string]any); nested != nil {
-					if id := ExtractBranchID(nested); id != "" {
-						return id
-					}
-				}
-			}
-		}
-	}
-	if branches, ok := m["branches"].([]any); ok {
-		for _, item := range branches {
-			if nested, _ := item.(map[string]any); nested != nil {
-				if id := ExtractBranchID(nested); id != "" {
-					return id
-				}
-			}
-		}
-	}
-	if b, ok := m["branch"].(map[string]any); ok {
-		if id := ExtractBranchID(b); id != "" {
-			return id
-		}
-	}
-	for _, k := range []string{"branch_id", "id"} {
-		if v, ok := m[k].(string); ok && v != "" {
-			return v
-		}
-	}
-	return ""
-}
-
 func branchOutputString(payload map[string]any) string {
 	if payload == nil {
 		return ""
@@ -501,6 +587,9 @@ func (h *ToolHandler) errorPayload(err error) map[string]any {
 		if len(te.Details) > 0 {
 			payload["details"] = te.Details
 		}
+		if te.Kind != "" {
+			payload["kind"] = te.Kind
+		}
 		if len(payload) == 0 {
 			payload["message"] = "tool execution error"
 		}
@@ -528,6 +617,21 @@ func minFloat(a, b float64) float64 {
 	return b
 }
 
+// noIssuesStatement matches the ways a review agent says it found nothing to
+// report, e.g. "No P0/P1 issues found" or "I found no blocking issues".
+var noIssuesStatement = regexp.MustCompile(`(?i)\bno\s+(?:p0\s*/\s*p1\s+|p[01]\s+|blocking\s+|real\s+|significant\s+)?(?:issues?|bugs?|problems?)\s+(?:were\s+|was\s+)?(?:found|identified|detected)\b|\bfound\s+no\s+(?:\w+\s+)?(?:issues?|bugs?|problems?)\b`)
+
+// priorityFinding matches a listed P0/P1 finding, e.g. "[P0]", "P1:" or
+// "Severity: P0". It does not match the "P0/P1" in "No P0/P1 issues found".
+var priorityFinding = regexp.MustCompile(`(?i)\[P[01]\]|\bP[01]\**\s*[:\-–—]|\bseverity\**\s*:\s*\**P[01]\b`)
+
+// statesNoIssues reports whether an agent's output says no issues were found
+// and lists no P0/P1 findings. Output such as "No blocking issues found, but
+// P1: the retry loop never backs off" is not a clean review.
+func statesNoIssues(output string) bool {
+	return noIssuesStatement.MatchString(output) && !priorityFinding.MatchString(output)
+}
+
 func isNotFoundError(err error) bool {
 	if err == nil {
 		return false
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	b "review_agent/internal/brain"
 	cfg "review_agent/internal/config"
+	"review_agent/internal/cost"
+	"review_agent/internal/exitcodes"
 	"review_agent/internal/logx"
 	"review_agent/internal/prreview"
 	"review_agent/internal/streaming"
@@ -17,6 +21,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-result" {
+		os.Exit(runValidateResult(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "prompts" {
+		os.Exit(runPrompts(os.Args[2:]))
+	}
+
 	task := flag.String("task", "", "PR context / task description")
 	parent := flag.String("parent-branch-id", "", "Branch UUID to fork from (required)")
 	project := flag.String("project-name", "", "Override project name")
@@ -24,7 +35,26 @@ func main() {
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
 	skipScout := flag.Bool("skip-scout", true, "Skip the scout change analysis stage")
 	skipTester := flag.Bool("skip-tester", true, "Skip the tester and exchange verification stages")
-	flag.Parse()
+	studyIntensity := flag.String("study-intensity", prreview.StudyDeep, "Prompt exploration verbosity: light, standard or deep")
+	reclassifySeverity := flag.Bool("reclassify-severity", false, "Re-rate parsed issues with an independent severity prompt and drop those below P1")
+	summaryMode := flag.String("summary-mode", prreview.SummaryOverwrite, "How to write review_summary.md: overwrite or append (prepend a timestamped section, keep history)")
+	maxDuration := flag.Duration("max-duration", 0, "Abort LLM calls once the review exceeds this duration (e.g. 30m); 0 disables the limit")
+	stallWarning := flag.Duration("stall-warning-interval", 5*time.Minute, "Report a stall warning after this long without any event or log line; 0 disables the watchdog")
+	jsonMode := flag.Bool("json-mode", false, "Request provider-enforced JSON (response_format json_object) for auxiliary triage/parse/severity/alignment calls; falls back when unsupported")
+	stance := flag.String("stance", prreview.DefaultStance, "Reviewer/verify-agent bias: skeptical (high precision), balanced or confirming (high recall)")
+	maxAuxInputChars := flag.Int("max-aux-input-chars", prreview.DefaultMaxAuxInputChars, "Split review reports longer than this at section markers before the has_issue and issue-split calls, merging the answers")
+	suppressFile := flag.String("suppress-file", "", "JSON file of known-noise findings (regex pattern or fingerprint) to leave out of the results")
+	baselineBranchID := flag.String("baseline-branch-id", "", "Check each issue found against this base branch and report only those it does not have; pre-existing ones go to pre_existing_issues")
+	baselineCacheDir := flag.String("baseline-cache-dir", "", "Directory caching per-issue baseline verdicts per branch ID, so repeated runs against the same base only check new issues")
+	compareBranches := flag.String("compare-branches", "", "Review two candidate branches A,B instead of --parent-branch-id and print which issues are unique to each and shared")
+	failOnIssues := flag.Bool("fail-on-issues", false, "Exit with status 2 when the review reports a P0/P1 issue, so CI fails the change")
+	pricesFile := flag.String("prices-file", "", "prices.yaml with input_per_1k and output_per_1k (USD); enables estimated_cost_usd in the result")
+	priceIn := flag.Float64("price-per-1k-input", 0, "USD per 1K prompt tokens; overrides --prices-file")
+	priceOut := flag.Float64("price-per-1k-output", 0, "USD per 1K completion tokens; overrides --prices-file")
+	subject := flag.String("subject", "", "Name of the reviewed change in the result headline, e.g. \"PR #123\"; defaults to the parent branch")
+	changedFiles := flag.Int("changed-files", 0, "Number of files the change touches, e.g. from git diff --name-only; adds \"across N changed files\" to the result headline")
+	profile := flag.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	exitcodes.ParseFlags()
 
 	streamEnabled := streamJSON != nil && *streamJSON
 	if streamEnabled {
@@ -32,10 +62,10 @@ func main() {
 		logx.SetLevel(logx.Error)
 	}
 
-	conf, err := cfg.FromEnv()
+	conf, err := cfg.Load(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
 	if *project != "" {
@@ -43,11 +73,39 @@ func main() {
 	}
 	if conf.ProjectName == "" {
 		fmt.Fprintln(os.Stderr, "Project name required via PROJECT_NAME or --project-name")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+	var compared []string
+	if strings.TrimSpace(*compareBranches) != "" {
+		compared = strings.Split(*compareBranches, ",")
+		if len(compared) != 2 || strings.TrimSpace(compared[0]) == "" || strings.TrimSpace(compared[1]) == "" {
+			fmt.Fprintln(os.Stderr, "--compare-branches takes exactly two branch ids: A,B")
+			os.Exit(exitcodes.Usage)
+		}
+		if *parent == "" {
+			*parent = strings.TrimSpace(compared[0])
+		}
 	}
 	if *parent == "" {
 		fmt.Fprintln(os.Stderr, "--parent-branch-id is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+	if *priceIn < 0 || *priceOut < 0 {
+		fmt.Fprintln(os.Stderr, "error: token prices must not be negative")
+		os.Exit(exitcodes.Usage)
+	}
+	var prices cost.Prices
+	if *pricesFile != "" {
+		if prices, err = cost.LoadPrices(*pricesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+	}
+	if *priceIn > 0 {
+		prices.InputPer1K = *priceIn
+	}
+	if *priceOut > 0 {
+		prices.OutputPer1K = *priceOut
 	}
 
 	tsk := strings.TrimSpace(*task)
@@ -59,7 +117,20 @@ func main() {
 	}
 	if tsk == "" {
 		fmt.Fprintln(os.Stderr, "task description is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+
+	var suppressions []prreview.SuppressionRule
+	if *suppressFile != "" {
+		suppressions, err = prreview.LoadSuppressions(*suppressFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(exitcodes.Config)
+		}
+	}
+
+	if conf.RunSubdir != "" {
+		fmt.Fprintf(os.Stderr, "Using per-run workspace: %s\n", conf.WorkspaceDir)
 	}
 
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
@@ -71,14 +142,28 @@ func main() {
 		streamer = streaming.NewJSONStreamer(true, os.Stdout)
 		streamer.EmitThreadStarted(tsk, conf.ProjectName, *parent, *headless)
 	}
+	handler.SetStreamer(streamer)
 
 	opts := prreview.Options{
-		Task:           tsk,
-		ProjectName:    conf.ProjectName,
-		ParentBranchID: *parent,
-		WorkspaceDir:   conf.WorkspaceDir,
-		SkipScout:      *skipScout,
-		SkipTester:     *skipTester,
+		Task:               tsk,
+		ProjectName:        conf.ProjectName,
+		ParentBranchID:     *parent,
+		WorkspaceDir:       conf.WorkspaceDir,
+		SkipScout:          *skipScout,
+		SkipTester:         *skipTester,
+		StudyIntensity:     *studyIntensity,
+		SummaryMode:        *summaryMode,
+		ReclassifySeverity: *reclassifySeverity,
+		MaxDuration:        *maxDuration,
+		JSONMode:           *jsonMode,
+		AuxSystemPrompts:   conf.AuxSystemPrompts,
+		Suppressions:       suppressions,
+		MaxAuxInputChars:   *maxAuxInputChars,
+		Stance:             *stance,
+		BaselineBranchID:   *baselineBranchID,
+		BaselineCacheDir:   *baselineCacheDir,
+		Subject:            *subject,
+		ChangedFiles:       *changedFiles,
 	}
 	runner, err := prreview.NewRunner(brain, handler, streamer, opts)
 	if err != nil {
@@ -87,17 +172,46 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
+	}
+
+	watchdog := streaming.StartWatchdog(streamer, *stallWarning, logx.LastOutput, func(idle time.Duration) {
+		logx.Warningf("No output for %s; the run is still waiting on a branch or LLM call.", idle.Round(time.Second))
+	})
+	if compared != nil {
+		comparison, err := runner.Compare(compared[0], compared[1])
+		watchdog.Stop()
+		if err != nil {
+			if streamer != nil && streamer.Enabled() {
+				streamer.EmitError("workflow", err.Error(), nil)
+				streamer.EmitThreadCompleted("error", err.Error(), nil)
+			}
+			fmt.Fprintf(os.Stderr, "compare error: %v\n", err)
+			os.Exit(exitcodes.Code(err))
+		}
+		if streamer != nil && streamer.Enabled() {
+			streamer.EmitThreadCompleted("completed", comparison.Summary, map[string]any{"comparison": comparison})
+		}
+		out, _ := json.MarshalIndent(comparison, "", "  ")
+		fmt.Fprintln(os.Stderr, string(out))
+		return
 	}
 
 	result, err := runner.Run()
+	watchdog.Stop()
+	if result != nil {
+		result.TokenUsage = brain.Usage()
+		if !prices.IsZero() {
+			result.EstimatedCostUSD = cost.Estimate(prices, result.TokenUsage)
+		}
+	}
 	if err != nil {
 		if streamer != nil && streamer.Enabled() {
 			streamer.EmitError("workflow", err.Error(), nil)
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Code(err))
 	}
 
 	status := "completed"
@@ -106,13 +220,73 @@ func main() {
 	}
 	if streamer != nil && streamer.Enabled() && result != nil {
 		streamer.EmitThreadCompleted(status, result.Summary, map[string]any{
-			"task":    result.Task,
-			"status":  result.Status,
-			"summary": result.Summary,
-			"issues":  result.Issues,
+			"task":     result.Task,
+			"status":   result.Status,
+			"summary":  result.Summary,
+			"headline": result.Headline,
+			"issues":   result.Issues,
 		})
 	}
 
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
+	if *failOnIssues && result.HasFindings() {
+		os.Exit(exitcodes.Findings)
+	}
+}
+
+// runValidateResult implements the `validate-result --file X` subcommand.
+func runValidateResult(args []string) int {
+	fs := flag.NewFlagSet("validate-result", flag.ContinueOnError)
+	file := fs.String("file", "", "Path to a result or --compare-branches JSON file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return exitcodes.Usage
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	if err := prreview.ValidateResult(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Runtime
+	}
+	fmt.Fprintf(os.Stderr, "%s: valid result\n", *file)
+	return exitcodes.Success
+}
+
+// runPrompts implements the `prompts --stage X` subcommand, printing the rendered
+// prompt so it can be diffed against the v1.0 binary's output.
+func runPrompts(args []string) int {
+	fs := flag.NewFlagSet("prompts", flag.ContinueOnError)
+	version := fs.String("version", prreview.PromptVersion, "Prompt generation to render; this binary renders "+prreview.PromptVersion)
+	stage := fs.String("stage", prreview.StageFinder, "Prompt to render: finder, scout, reviewer or tester")
+	task := fs.String("task", "", "Task text to render into the prompt (default: a built-in sample)")
+	intensity := fs.String("study-intensity", prreview.StudyDeep, "Prompt exploration verbosity: light, standard or deep")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if v := strings.TrimSpace(*version); v != prreview.PromptVersion {
+		fmt.Fprintf(os.Stderr, "this binary renders %s prompts; build review_agent/cmd/review-agent to render %s\n", prreview.PromptVersion, v)
+		return exitcodes.Usage
+	}
+	prompt, err := prreview.RenderPrompt(*stage, *task, *intensity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Usage
+	}
+	fmt.Println(prompt)
+	return exitcodes.Success
 }
@@ -2,12 +2,16 @@ package brain
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"review_agent/internal/logx"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -36,6 +40,25 @@ type LLMBrain struct {
 	apiVersion string
 	maxRetries int
 	client     *http.Client
+	// jsonModeRejected is set once the deployment refuses response_format, so later
+	// JSON-mode calls skip the parameter instead of paying for another 400.
+	jsonModeRejected atomic.Bool
+
+	usageMu sync.Mutex
+	usage   map[string]Usage
+}
+
+// CompleteOption adjusts a single Complete call.
+type CompleteOption func(*completeSettings)
+
+type completeSettings struct {
+	jsonMode bool
+}
+
+// WithJSONMode asks the provider to return a JSON object (response_format
+// json_object). Deployments that reject the parameter get the plain request instead.
+func WithJSONMode() CompleteOption {
+	return func(s *completeSettings) { s.jsonMode = true }
 }
 
 func NewLLMBrain(apiKey, endpoint, deployment, apiVersion string, maxRetries int) *LLMBrain {
@@ -58,15 +81,40 @@ type chatCompletionRequest struct {
 	MaxCompletionTokens int              `json:"max_completion_tokens,omitempty"`
 	Tools               []map[string]any `json:"tools,omitempty"`
 	ToolChoice          any              `json:"tool_choice,omitempty"`
+	ResponseFormat      map[string]any   `json:"response_format,omitempty"`
 }
 
 type chatCompletionResponse struct {
 	Choices []struct {
 		Message ChatMessage `json:"message"`
 	} `json:"choices"`
+	Usage Usage `json:"usage"`
 }
 
-func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*chatCompletionResponse, error) {
+// ErrEmptyContent reports a completion with no choices or only whitespace content.
+var ErrEmptyContent = errors.New("LLM returned empty content")
+
+// RequireNonEmptyChoice returns the first choice's content for callers that need
+// text back (auxiliary JSON calls), or ErrEmptyContent instead of indexing an
+// empty Choices slice.
+func RequireNonEmptyChoice(resp *chatCompletionResponse) (string, error) {
+	if resp == nil || len(resp.Choices) == 0 {
+		return "", ErrEmptyContent
+	}
+	content := resp.Choices[0].Message.Content
+	if strings.TrimSpace(content) == "" {
+		return "", ErrEmptyContent
+	}
+	return content, nil
+}
+
+// Complete sends one chat completion request, retrying transient failures. The
+// request and any backoff between retries are abandoned once ctx is done.
+func (b *LLMBrain) Complete(ctx context.Context, messages []ChatMessage, tools []map[string]any, opts ...CompleteOption) (*chatCompletionResponse, error) {
+	var settings completeSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
 	var lastErr error
 	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", b.endpoint, b.deployment, b.apiVersion)
 
@@ -79,15 +127,21 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 		body.Tools = tools
 		body.ToolChoice = "auto"
 	}
+	if settings.jsonMode && !b.jsonModeRejected.Load() {
+		body.ResponseFormat = map[string]any{"type": "json_object"}
+	}
 	payload, _ := json.Marshal(body)
 
 	for attempt := 0; attempt < b.maxRetries; attempt++ {
-		req, _ := http.NewRequest("POST", url, bytes.NewReader(payload))
+		req, _ := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("api-key", b.apiKey)
 
 		resp, err := b.client.Do(req)
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf("azure openai call aborted: %w", ctxErr)
+			}
 			lastErr = err
 		} else {
 			defer resp.Body.Close()
@@ -97,8 +151,16 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 				if err := json.Unmarshal(data, &out); err != nil {
 					lastErr = err
 				} else {
+					b.recordUsage(callKind(ctx), out.Usage)
 					return &out, nil
 				}
+			} else if body.ResponseFormat != nil && resp.StatusCode == http.StatusBadRequest && bytes.Contains(data, []byte("response_format")) {
+				logx.Infof("Deployment %s does not support JSON mode; retrying without response_format.", b.deployment)
+				b.jsonModeRejected.Store(true)
+				body.ResponseFormat = nil
+				payload, _ = json.Marshal(body)
+				attempt--
+				continue
 			} else {
 				lastErr = fmt.Errorf("azure openai error %d: %s", resp.StatusCode, string(data))
 			}
@@ -107,7 +169,11 @@ func (b *LLMBrain) Complete(messages []ChatMessage, tools []map[string]any) (*ch
 		if attempt < b.maxRetries-1 {
 			wait := time.Duration(1<<attempt) * time.Second
 			logx.Warningf("Azure OpenAI call failed (attempt %d/%d): %v. Retrying in %ds...", attempt+1, b.maxRetries, lastErr, int(wait.Seconds()))
-			time.Sleep(wait)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("azure openai call aborted: %w", ctx.Err())
+			case <-time.After(wait):
+			}
 		}
 	}
 	if lastErr == nil {
@@ -0,0 +1,31 @@
+package brain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompleteCountsUsageByCallKind(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":120,"completion_tokens":30}}`))
+	}))
+	defer srv.Close()
+
+	brain := NewLLMBrain("key", srv.URL, "dep", "2024-12-01-preview", 1)
+	msgs := []ChatMessage{{Role: "user", Content: "hi"}}
+	aux := WithCallKind(context.Background(), CallAuxiliary)
+	for _, ctx := range []context.Context{aux, aux, context.Background()} {
+		if _, err := brain.Complete(ctx, msgs, nil); err != nil {
+			t.Fatalf("Complete failed: %v", err)
+		}
+	}
+	usage := brain.Usage()
+	if got := usage[CallAuxiliary]; got != (Usage{PromptTokens: 240, CompletionTokens: 60, Calls: 2}) {
+		t.Fatalf("unexpected auxiliary usage %+v", got)
+	}
+	if got := usage[CallOrchestration]; got != (Usage{PromptTokens: 120, CompletionTokens: 30, Calls: 1}) {
+		t.Fatalf("unexpected orchestration usage %+v", got)
+	}
+}
@@ -0,0 +1,55 @@
+package brain
+
+import "context"
+
+// Call kinds label Complete calls for token accounting. Calls made without
+// WithCallKind count as orchestration.
+const (
+	CallOrchestration = "orchestration"
+	CallAuxiliary     = "auxiliary"
+)
+
+// Usage is the token count reported by the API, summed over Calls completions.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	Calls            int `json:"calls"`
+}
+
+type callKindKey struct{}
+
+// WithCallKind returns a context whose Complete calls are counted under kind.
+func WithCallKind(ctx context.Context, kind string) context.Context {
+	return context.WithValue(ctx, callKindKey{}, kind)
+}
+
+func callKind(ctx context.Context) string {
+	if kind, ok := ctx.Value(callKindKey{}).(string); ok && kind != "" {
+		return kind
+	}
+	return CallOrchestration
+}
+
+func (b *LLMBrain) recordUsage(kind string, u Usage) {
+	b.usageMu.Lock()
+	defer b.usageMu.Unlock()
+	if b.usage == nil {
+		b.usage = make(map[string]Usage)
+	}
+	total := b.usage[kind]
+	total.PromptTokens += u.PromptTokens
+	total.CompletionTokens += u.CompletionTokens
+	total.Calls++
+	b.usage[kind] = total
+}
+
+// Usage returns the tokens consumed so far, by call kind.
+func (b *LLMBrain) Usage() map[string]Usage {
+	b.usageMu.Lock()
+	defer b.usageMu.Unlock()
+	out := make(map[string]Usage, len(b.usage))
+	for kind, u := range b.usage {
+		out[kind] = u
+	}
+	return out
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
@@ -14,21 +15,26 @@ const minPollTimeoutSeconds = 7200
 const minPollTimeout = time.Duration(minPollTimeoutSeconds) * time.Second
 
 type AgentConfig struct {
-	AzureAPIKey       string
-	AzureEndpoint     string
-	AzureDeployment   string
-	AzureAPIVersion   string
-	MCPBaseURL        string
-	PollInitial       time.Duration
-	PollMax           time.Duration
-	PollTimeout       time.Duration
-	PollBackoffFactor float64
-	WorklogFilename   string
-	ProjectName       string
-	WorkspaceDir      string
-	GitHubToken       string
-	GitUserName       string
-	GitUserEmail      string
+	AzureAPIKey        string
+	AzureEndpoint      string
+	AzureDeployment    string
+	AzureAPIVersion    string
+	MCPBaseURL         string
+	PollInitial        time.Duration
+	PollMax            time.Duration
+	PollTimeout        time.Duration
+	PollBackoffFactor  float64
+	WorklogFilename    string
+	ProjectName        string
+	WorkspaceDir       string
+	RunSubdir          string
+	ReviewArtifactName string
+	GitHubToken        string
+	GitUserName        string
+	GitUserEmail       string
+	// AuxSystemPrompts overrides the system prompt of auxiliary JSON calls, keyed
+	// by call name (AUX_SYSTEM_PROMPT_ALIGNMENT sets "alignment").
+	AuxSystemPrompts map[string]string
 }
 
 func FromEnv() (AgentConfig, error) {
@@ -94,6 +100,16 @@ func FromEnv() (AgentConfig, error) {
 	if workspace == "" {
 		workspace = "/home/pan/workspace"
 	}
+	// WORKSPACE_PER_RUN isolates concurrent runs that share one WORKSPACE_DIR.
+	perRun, err := envBool("WORKSPACE_PER_RUN")
+	if err != nil {
+		return AgentConfig{}, err
+	}
+	runSubdir := ""
+	if perRun {
+		runSubdir = runSubdirName(time.Now())
+		workspace = path.Join(workspace, runSubdir)
+	}
 
 	backoff := 2.0
 	if v := os.Getenv("MCP_POLL_BACKOFF_FACTOR"); v != "" {
@@ -118,25 +134,60 @@ func FromEnv() (AgentConfig, error) {
 		return AgentConfig{}, errors.New("GIT_AUTHOR_EMAIL must be set")
 	}
 
+	reviewArtifact, err := envFileName("REVIEW_ARTIFACT_NAME", "code_review.log")
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
 	return AgentConfig{
-		AzureAPIKey:       apiKey,
-		AzureEndpoint:     endpoint,
-		AzureDeployment:   deployment,
-		AzureAPIVersion:   apiVersion,
-		MCPBaseURL:        baseURL,
-		PollInitial:       pollInitial,
-		PollMax:           pollMax,
-		PollTimeout:       pollTimeout,
-		PollBackoffFactor: backoff,
-		WorklogFilename:   "worklog.md",
-		ProjectName:       project,
-		WorkspaceDir:      workspace,
-		GitHubToken:       githubToken,
-		GitUserName:       gitUserName,
-		GitUserEmail:      gitUserEmail,
+		AzureAPIKey:        apiKey,
+		AzureEndpoint:      endpoint,
+		AzureDeployment:    deployment,
+		AzureAPIVersion:    apiVersion,
+		MCPBaseURL:         baseURL,
+		PollInitial:        pollInitial,
+		PollMax:            pollMax,
+		PollTimeout:        pollTimeout,
+		PollBackoffFactor:  backoff,
+		WorklogFilename:    "worklog.md",
+		ProjectName:        project,
+		WorkspaceDir:       workspace,
+		RunSubdir:          runSubdir,
+		ReviewArtifactName: reviewArtifact,
+		GitHubToken:        githubToken,
+		GitUserName:        gitUserName,
+		GitUserEmail:       gitUserEmail,
+		AuxSystemPrompts:   envPrefixed("AUX_SYSTEM_PROMPT_"),
 	}, nil
 }
 
+// envFileName reads a bare file name (no directories) from name, defaulting to def.
+func envFileName(name, def string) (string, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def, nil
+	}
+	if strings.ContainsAny(v, `/\`) || v == "." || v == ".." {
+		return "", fmt.Errorf("%s must be a file name, not a path: %s", name, v)
+	}
+	return v, nil
+}
+
+// envPrefixed collects the non-empty variables starting with prefix, keyed by the
+// lower-cased remainder of their name.
+func envPrefixed(prefix string) map[string]string {
+	out := map[string]string{}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		key, ok := strings.CutPrefix(name, prefix)
+		if !ok || key == "" || strings.TrimSpace(value) == "" {
+			continue
+		}
+		out[strings.ToLower(key)] = value
+	}
+	return out
+}
+
 func envSeconds(name string, def int) (time.Duration, error) {
 	v := os.Getenv(name)
 	if v == "" {
@@ -149,6 +200,24 @@ func envSeconds(name string, def int) (time.Duration, error) {
 	return time.Duration(n) * time.Second, nil
 }
 
+func envBool(name string) (bool, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean for %s: %s", name, v)
+	}
+	return b, nil
+}
+
+// runSubdirName names the per-run workspace directory; the pid keeps runs that
+// start within the same second apart.
+func runSubdirName(now time.Time) string {
+	return fmt.Sprintf("run-%s-%d", now.UTC().Format("20060102T150405Z"), os.Getpid())
+}
+
 // loadDotenv loads key=value pairs into env if not already set.
 func loadDotenv(path string) error {
 	f, err := os.Open(path)
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -60,3 +63,67 @@ func TestFromEnv_RespectsLargerPollTimeout(t *testing.T) {
 		t.Fatalf("expected PollTimeout 3h, got %s", conf.PollTimeout)
 	}
 }
+
+func TestLoad_ProfileFillsUnsetVariablesOnly(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("MCP_POLL_TIMEOUT_SECONDS", "")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "")
+	t.Setenv("PROD_DEPLOYMENT", "gpt-prod")
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	src := `# endpoints per environment
+local:
+  MCP_BASE_URL: http://localhost:9000/mcp/sse
+prod:
+  AZURE_OPENAI_DEPLOYMENT: "${PROD_DEPLOYMENT}"
+  MCP_BASE_URL: https://mcp.example.com/sse
+  PROJECT_NAME: prod-project
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROFILES_FILE", path)
+	t.Setenv("MCP_BASE_URL", "")
+
+	conf, err := Load("prod")
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if conf.AzureDeployment != "gpt-prod" {
+		t.Fatalf("expected ${PROD_DEPLOYMENT} to expand, got %q", conf.AzureDeployment)
+	}
+	if conf.MCPBaseURL != "https://mcp.example.com/sse" {
+		t.Fatalf("expected profile MCP_BASE_URL, got %q", conf.MCPBaseURL)
+	}
+	if conf.ProjectName != "test-project" {
+		t.Fatalf("expected PROJECT_NAME from the environment to win, got %q", conf.ProjectName)
+	}
+
+	if _, err := Load("staging"); err == nil || !strings.Contains(err.Error(), "staging") {
+		t.Fatalf("expected unknown profile error, got %v", err)
+	}
+}
+
+func TestFromEnv_WorkspacePerRunUsesSubdir(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKSPACE_PER_RUN", "true")
+
+	conf, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if !strings.HasPrefix(conf.RunSubdir, "run-") {
+		t.Fatalf("expected run- prefixed subdir, got %q", conf.RunSubdir)
+	}
+	if conf.WorkspaceDir != "/tmp/workspace/"+conf.RunSubdir {
+		t.Fatalf("expected workspace scoped to run subdir, got %q", conf.WorkspaceDir)
+	}
+}
+
+func TestFromEnv_WorkspacePerRunRejectsInvalidBool(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKSPACE_PER_RUN", "sometimes")
+
+	if _, err := FromEnv(); err == nil {
+		t.Fatalf("expected error for invalid WORKSPACE_PER_RUN")
+	}
+}
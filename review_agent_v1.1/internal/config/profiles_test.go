@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyProfileExpandsOnlyBracedReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	src := `empty:
+prod:
+  PROFILE_TEST_PASSWORD: pa$$word$HOME  # inline comment
+  PROFILE_TEST_URL: "https://${PROFILE_TEST_HOST}/x#frag"
+  PROFILE_TEST_CHAIN: ${PROFILE_TEST_URL}
+  PROFILE_TEST_SET: from-profile
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PROFILE_TEST_HOST", "mcp.example.com")
+	t.Setenv("PROFILE_TEST_SET", "from-env")
+	for _, key := range []string{"PROFILE_TEST_PASSWORD", "PROFILE_TEST_URL", "PROFILE_TEST_CHAIN"} {
+		t.Setenv(key, "")
+	}
+
+	if err := applyProfile(path, "prod"); err != nil {
+		t.Fatalf("applyProfile returned error: %v", err)
+	}
+	want := map[string]string{
+		"PROFILE_TEST_PASSWORD": "pa$$word$HOME",
+		"PROFILE_TEST_URL":      "https://mcp.example.com/x#frag",
+		"PROFILE_TEST_CHAIN":    "",
+		"PROFILE_TEST_SET":      "from-env",
+	}
+	for key, value := range want {
+		if got := os.Getenv(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	if err := applyProfile(path, "empty"); err != nil {
+		t.Fatalf("an empty profile should apply cleanly, got %v", err)
+	}
+	if err := os.WriteFile(path, []byte("prod:\n  A: 1\n  A: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyProfile(path, "prod"); err == nil || !strings.Contains(err.Error(), "duplicate") {
+		t.Fatalf("expected a duplicate key error, got %v", err)
+	}
+}
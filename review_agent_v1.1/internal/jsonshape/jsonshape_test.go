@@ -0,0 +1,76 @@
+package jsonshape
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type item struct {
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty"`
+}
+
+type document struct {
+	base
+	Items    []item            `json:"items"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ready    bool              `json:"ready"`
+	Score    float64           `json:"score"`
+	Extra    any               `json:"extra,omitempty"`
+	Ignored  string            `json:"-"`
+	Untagged string
+	hidden   string
+}
+
+func check(t *testing.T, src string) []string {
+	t.Helper()
+	var raw any
+	if err := json.Unmarshal([]byte(src), &raw); err != nil {
+		t.Fatalf("bad fixture: %v", err)
+	}
+	return Problems(raw, reflect.TypeOf(document{}))
+}
+
+func TestProblemsAcceptsMarshaledValue(t *testing.T) {
+	data, err := json.Marshal(document{
+		base:   base{ID: "x"},
+		Items:  []item{{Name: "a", Count: 2}},
+		Labels: map[string]string{"k": "v"},
+		Extra:  []int{1},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if got := check(t, string(data)); len(got) != 0 {
+		t.Fatalf("expected no problems, got %v", got)
+	}
+	if got := check(t, `{"id":null,"items":null,"ready":false,"score":0,"Untagged":""}`); len(got) != 0 {
+		t.Fatalf("null should match any type, got %v", got)
+	}
+}
+
+func TestProblemsReportsEveryMismatch(t *testing.T) {
+	got := check(t, `{"items":[{"name":1,"legacy":true},"x"],"labels":{"k":2},"ready":"yes","score":"high","Untagged":"","hidden":"","zzz":1}`)
+	want := []string{
+		"missing field id",
+		"items[0].name: expected string",
+		"unknown field items[0].legacy",
+		"items[1]: expected object",
+		"labels.k: expected string",
+		"ready: expected boolean",
+		"score: expected number",
+		"unknown field hidden",
+		"unknown field zzz",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+	if got := Problems([]any{}, reflect.TypeOf(&document{})); !reflect.DeepEqual(got, []string{"<root>: expected object"}) {
+		t.Fatalf("unexpected root problems %q", got)
+	}
+}
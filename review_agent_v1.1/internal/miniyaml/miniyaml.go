@@ -0,0 +1,572 @@
+// Package miniyaml decodes the small YAML subset the agents' config files use
+// (profiles, severity policies, task specs, price tables) into Go values, using
+// the same json struct tags encoding/json reads. It is shared so every file is
+// parsed by one tested implementation instead of a hand-rolled reader each.
+//
+// Supported: block maps and sequences (a sequence may sit at the same indent as
+// its key), plain, "double" and 'single' quoted scalars, | and > block scalars
+// with - and + chomping, [] / {} and flat [a, "b"] flow sequences, ~ and null,
+// full-line and inline # comments, and a leading --- marker. Anchors, tags,
+// multi-document streams and multi-line quoted scalars are rejected or not
+// recognised. Scalars are kept as strings until decoding converts them to the
+// target field's kind.
+package miniyaml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses data and stores the result in the value v points to.
+// Unknown struct fields, duplicate keys and scalars that do not convert to the
+// target kind are errors.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("miniyaml: Unmarshal needs a non-nil pointer, got %T", v)
+	}
+	root, err := parse(string(data))
+	if err != nil {
+		return err
+	}
+	return decode(root, rv.Elem(), "")
+}
+
+type line struct {
+	no     int
+	indent int
+	text   string
+}
+
+type parser struct {
+	lines []string
+	pos   int
+}
+
+func parse(src string) (any, error) {
+	p := &parser{lines: strings.Split(src, "\n")}
+	l, ok, err := p.peek()
+	if err != nil || !ok {
+		return nil, err
+	}
+	if l.indent == 0 && l.text == "---" {
+		p.pos++
+		if l, ok, err = p.peek(); err != nil || !ok {
+			return nil, err
+		}
+	}
+	if l.indent != 0 {
+		return nil, fmt.Errorf("line %d: unexpected indentation", l.no)
+	}
+	root, err := p.parseBlock(0)
+	if err != nil {
+		return nil, err
+	}
+	if l, ok, err = p.peek(); err != nil {
+		return nil, err
+	} else if ok {
+		return nil, fmt.Errorf("line %d: unexpected %q", l.no, l.text)
+	}
+	return root, nil
+}
+
+// peek returns the next line that is neither blank nor a comment, without
+// consuming it.
+func (p *parser) peek() (line, bool, error) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := strings.TrimRight(p.lines[p.pos], " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		if text[0] == '\t' {
+			return line{}, false, fmt.Errorf("line %d: tabs are not allowed in indentation", p.pos+1)
+		}
+		return line{no: p.pos + 1, indent: len(raw) - len(text), text: text}, true, nil
+	}
+	return line{}, false, nil
+}
+
+func isSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *parser) parseBlock(indent int) (any, error) {
+	l, _, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if isSeqItem(l.text) {
+		return p.parseSeq(indent, nil)
+	}
+	return p.parseMap(indent, nil)
+}
+
+// parseMap reads "key: value" lines at exactly indent. first, when set, is the
+// key that shares its line with a "- " sequence marker.
+func (p *parser) parseMap(indent int, first *line) (map[string]any, error) {
+	m := map[string]any{}
+	for {
+		var l line
+		if first != nil {
+			l, first = *first, nil
+		} else {
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if !ok || next.indent < indent {
+				return m, nil
+			}
+			if next.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", next.no)
+			}
+			if isSeqItem(next.text) {
+				return nil, fmt.Errorf("line %d: list item where a key was expected", next.no)
+			}
+			l = next
+			p.pos++
+		}
+		key, rest, ok := splitKey(l.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", l.no)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.no, key)
+		}
+		val, err := p.parseValue(rest, indent, l.no, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = val
+	}
+}
+
+// parseSeq reads "- item" lines at exactly indent. first, when set, is the
+// item that shares its line with an outer "- " marker.
+func (p *parser) parseSeq(indent int, first *line) ([]any, error) {
+	var seq []any
+	for {
+		var l line
+		if first != nil {
+			l, first = *first, nil
+		} else {
+			next, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if !ok || next.indent < indent || (next.indent == indent && !isSeqItem(next.text)) {
+				return seq, nil
+			}
+			if next.indent > indent {
+				return nil, fmt.Errorf("line %d: unexpected indentation", next.no)
+			}
+			l = next
+			p.pos++
+		}
+		rest := strings.TrimLeft(l.text[1:], " ")
+		inner := &line{no: l.no, indent: indent + len(l.text) - len(rest), text: rest}
+		var (
+			item any
+			err  error
+		)
+		switch {
+		case isSeqItem(rest):
+			item, err = p.parseSeq(inner.indent, inner)
+		case isMapItem(rest):
+			item, err = p.parseMap(inner.indent, inner)
+		default:
+			item, err = p.parseValue(rest, indent, l.no, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, item)
+	}
+}
+
+// parseValue reads what follows a key or a "- " marker: an inline scalar, a
+// block scalar, or a nested block on the following, deeper lines.
+func (p *parser) parseValue(rest string, indent, no int, sameIndentSeq bool) (any, error) {
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "#") {
+		rest = ""
+	}
+	switch {
+	case rest == "":
+		next, ok, err := p.peek()
+		if err != nil || !ok {
+			return nil, err
+		}
+		if next.indent > indent {
+			return p.parseBlock(next.indent)
+		}
+		if sameIndentSeq && next.indent == indent && isSeqItem(next.text) {
+			return p.parseSeq(indent, nil)
+		}
+		return nil, nil
+	case rest[0] == '|' || rest[0] == '>':
+		header := plain(rest)
+		switch header[1:] {
+		case "", "-", "+":
+		default:
+			return nil, fmt.Errorf("line %d: unsupported block scalar header %q", no, header)
+		}
+		return p.parseBlockScalar(indent, header[0] == '>', header[1:]), nil
+	}
+	return parseScalar(rest, no)
+}
+
+// parseBlockScalar collects the lines indented deeper than indent and applies
+// literal or folded line handling and the chomping indicator.
+func (p *parser) parseBlockScalar(indent int, folded bool, chomp string) string {
+	var body []string
+	blockIndent := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := strings.TrimRight(p.lines[p.pos], "\r")
+		if strings.TrimSpace(raw) == "" {
+			body = append(body, "")
+			continue
+		}
+		n := len(raw) - len(strings.TrimLeft(raw, " "))
+		if blockIndent < 0 {
+			if n <= indent {
+				break
+			}
+			blockIndent = n
+		}
+		if n < blockIndent {
+			break
+		}
+		body = append(body, raw[blockIndent:])
+	}
+	trailing := 0
+	for trailing < len(body) && body[len(body)-1-trailing] == "" {
+		trailing++
+	}
+	body = body[:len(body)-trailing]
+	if len(body) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for i, l := range body {
+		switch {
+		case i == 0:
+		case !folded, l == "", body[i-1] == "":
+			if !folded || l == "" {
+				sb.WriteString("\n")
+			}
+		case strings.HasPrefix(l, " "), strings.HasPrefix(body[i-1], " "):
+			sb.WriteString("\n")
+		default:
+			sb.WriteString(" ")
+		}
+		sb.WriteString(l)
+	}
+	switch chomp {
+	case "-":
+	case "+":
+		sb.WriteString(strings.Repeat("\n", trailing+1))
+	default:
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// splitKey splits "key: value" at the first colon followed by a space or the
+// end of the line, so values such as URLs keep their colons.
+func splitKey(text string) (string, string, bool) {
+	for i := 0; i < len(text); i++ {
+		if text[i] != ':' || (i+1 < len(text) && text[i+1] != ' ') {
+			continue
+		}
+		key := strings.TrimSpace(text[:i])
+		if key == "" || strings.ContainsAny(key[:1], `"'[{#`) {
+			return "", "", false
+		}
+		return key, text[i+1:], true
+	}
+	return "", "", false
+}
+
+func isMapItem(text string) bool {
+	_, _, ok := splitKey(text)
+	return ok
+}
+
+// plain cuts an inline comment off an unquoted scalar: # starts a comment at
+// the beginning or after a space.
+func plain(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] == ' ') {
+			s = s[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+func parseScalar(s string, no int) (any, error) {
+	switch s[0] {
+	case '"', '\'':
+		val, end, err := quoted(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", no, err)
+		}
+		if rest := plain(s[end:]); rest != "" {
+			return nil, fmt.Errorf("line %d: unexpected %q after quoted string", no, rest)
+		}
+		return val, nil
+	case '[':
+		return parseFlowSeq(s, no)
+	case '{':
+		if plain(s) != "{}" {
+			return nil, fmt.Errorf("line %d: flow mappings are not supported", no)
+		}
+		return map[string]any{}, nil
+	case '&', '*', '!':
+		return nil, fmt.Errorf("line %d: anchors, aliases and tags are not supported", no)
+	}
+	switch val := plain(s); val {
+	case "~", "null":
+		return nil, nil
+	default:
+		return val, nil
+	}
+}
+
+// quoted decodes the quoted scalar at the start of s and returns it with the
+// index just past the closing quote.
+func quoted(s string) (string, int, error) {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] != q:
+		case q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case q == '"':
+			val, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", 0, fmt.Errorf("bad double-quoted string: %v", err)
+			}
+			return val, i + 1, nil
+		default:
+			return strings.ReplaceAll(s[1:i], "''", "'"), i + 1, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// parseFlowSeq reads a one-line [a, "b"] sequence of scalars.
+func parseFlowSeq(s string, no int) ([]any, error) {
+	seq := []any{}
+	rest := strings.TrimLeft(s[1:], " ")
+	if strings.HasPrefix(rest, "]") {
+		if extra := plain(rest[1:]); extra != "" {
+			return nil, fmt.Errorf("line %d: unexpected %q after ]", no, extra)
+		}
+		return seq, nil
+	}
+	for {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return nil, fmt.Errorf("line %d: unterminated [ sequence", no)
+		}
+		var item any
+		switch rest[0] {
+		case '[', '{':
+			return nil, fmt.Errorf("line %d: nested flow collections are not supported", no)
+		case '"', '\'':
+			val, end, err := quoted(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", no, err)
+			}
+			item, rest = val, strings.TrimLeft(rest[end:], " ")
+		default:
+			end := strings.IndexAny(rest, ",]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated [ sequence", no)
+			}
+			val := strings.TrimSpace(rest[:end])
+			if val == "" {
+				return nil, fmt.Errorf("line %d: empty item in [ sequence", no)
+			}
+			item, rest = val, rest[end:]
+			if val == "~" || val == "null" {
+				item = nil
+			}
+		}
+		seq = append(seq, item)
+		switch {
+		case strings.HasPrefix(rest, ","):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "]"):
+			if extra := plain(rest[1:]); extra != "" {
+				return nil, fmt.Errorf("line %d: unexpected %q after ]", no, extra)
+			}
+			return seq, nil
+		default:
+			return nil, fmt.Errorf("line %d: expected , or ] in [ sequence", no)
+		}
+	}
+}
+
+// decode stores a parsed node (map[string]any, []any, string or nil) in v.
+func decode(node any, v reflect.Value, path string) error {
+	if node == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("%s: cannot decode into %s", where(path), v.Type())
+		}
+		v.Set(reflect.ValueOf(node))
+		return nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(node, v.Elem(), path)
+	case reflect.Struct:
+		m, ok := node.(map[string]any)
+		if !ok {
+			return mismatch(node, v, path)
+		}
+		fields := structFields(v.Type())
+		for _, key := range sortedKeys(m) {
+			i, ok := fields[key]
+			if !ok {
+				return fmt.Errorf("%s: unknown field %q", where(path), key)
+			}
+			if err := decode(m[key], v.Field(i), join(path, key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		m, ok := node.(map[string]any)
+		if !ok || v.Type().Key().Kind() != reflect.String {
+			return mismatch(node, v, path)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(m)))
+		}
+		for _, key := range sortedKeys(m) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decode(m[key], elem, join(path, key)); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Slice:
+		seq, ok := node.([]any)
+		if !ok {
+			return mismatch(node, v, path)
+		}
+		out := reflect.MakeSlice(v.Type(), len(seq), len(seq))
+		for i, item := range seq {
+			if err := decode(item, out.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(out)
+		return nil
+	}
+	s, ok := node.(string)
+	if !ok {
+		return mismatch(node, v, path)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", where(path), s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %q is not an integer", where(path), s)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %q is not an unsigned integer", where(path), s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", where(path), s)
+		}
+		v.SetFloat(f)
+	default:
+		return mismatch(node, v, path)
+	}
+	return nil
+}
+
+// structFields maps json tag names (or field names) to field indexes.
+func structFields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = i
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func mismatch(node any, v reflect.Value, path string) error {
+	kind := "a scalar"
+	switch node.(type) {
+	case map[string]any:
+		kind = "a map"
+	case []any:
+		kind = "a list"
+	}
+	return fmt.Errorf("%s: cannot use %s as %s", where(path), kind, v.Type())
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func where(path string) string {
+	if path == "" {
+		return "top level"
+	}
+	return path
+}
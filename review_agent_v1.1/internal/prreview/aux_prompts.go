@@ -0,0 +1,60 @@
+package prreview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Auxiliary JSON calls, named so their system prompts can be overridden per
+// deployment. review_agent uses the same names and defaults for the calls both
+// variants make.
+const (
+	AuxIssueCheck = "issue_check"
+	AuxAlignment  = "alignment"
+	AuxIssueSplit = "issue_split"
+	AuxSeverity   = "severity"
+	// AuxIssueMatch pairs the findings of two reviews in Compare (v1.1 only).
+	AuxIssueMatch = "issue_match"
+)
+
+// defaultAuxSystemPrompts holds the built-in system prompt of each auxiliary call.
+var defaultAuxSystemPrompts = map[string]string{
+	AuxIssueCheck: "Analyze code review reports. Reply only with JSON.",
+	AuxAlignment:  "Return JSON alignment verdicts for two transcripts. Reply only with JSON.",
+	AuxIssueSplit: "Parse code review reports and extract individual P0/P1 issues. Reply only with JSON.",
+	AuxSeverity:   "Classify code review issue severity. Reply only with JSON.",
+	AuxIssueMatch: "Match code review findings that describe the same defect. Reply only with JSON.",
+}
+
+// normalizeAuxSystemPrompts drops blank overrides and rejects unknown call names.
+func normalizeAuxSystemPrompts(overrides map[string]string) (map[string]string, error) {
+	out := map[string]string{}
+	for call, prompt := range overrides {
+		call = strings.ToLower(strings.TrimSpace(call))
+		if _, ok := defaultAuxSystemPrompts[call]; !ok {
+			return nil, fmt.Errorf("unknown auxiliary call %q for system prompt override (want %s)", call, auxCallNames())
+		}
+		if prompt = strings.TrimSpace(prompt); prompt != "" {
+			out[call] = prompt
+		}
+	}
+	return out, nil
+}
+
+func auxCallNames() string {
+	names := make([]string, 0, len(defaultAuxSystemPrompts))
+	for name := range defaultAuxSystemPrompts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// auxSystemPrompt returns the system prompt for an auxiliary call.
+func (r *Runner) auxSystemPrompt(call string) string {
+	if prompt, ok := r.opts.AuxSystemPrompts[call]; ok {
+		return prompt
+	}
+	return defaultAuxSystemPrompts[call]
+}
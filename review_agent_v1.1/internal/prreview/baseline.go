@@ -0,0 +1,186 @@
+package prreview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"review_agent/internal/logx"
+	"review_agent/internal/streaming"
+)
+
+// PreExistingIssue is a parsed issue the baseline check found already present in
+// the baseline branch's code; it is left out of Result.Issues when
+// Options.BaselineBranchID is set.
+type PreExistingIssue struct {
+	IssueText   string `json:"issue_text"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// baselineCache is the on-disk shape of a cached baseline check, one file per
+// baseline branch under Options.BaselineCacheDir. Verdicts maps an issue's
+// IssueFingerprint to whether the baseline already has it.
+type baselineCache struct {
+	BranchID      string          `json:"branch_id"`
+	Verdicts      map[string]bool `json:"verdicts"`
+	PromptVersion string          `json:"prompt_version,omitempty"`
+}
+
+// baselineCachePath names the cache file for a baseline branch. Branch IDs are
+// only made filesystem-safe, not hashed, so the files stay recognizable.
+func baselineCachePath(dir, branchID string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, branchID)
+	return filepath.Join(dir, "baseline-"+safe+".json")
+}
+
+// baselinePreExisting returns the fingerprints of the issues the baseline branch
+// already has. Verdicts cached by a previous run are reused; only the issues
+// not seen before are checked on the baseline.
+func (r *Runner) baselinePreExisting(issues []parsedIssue) (map[string]bool, error) {
+	branchID := r.opts.BaselineBranchID
+	cacheDir := strings.TrimSpace(r.opts.BaselineCacheDir)
+	verdicts := map[string]bool{}
+	if cacheDir != "" {
+		cached, err := loadBaselineCache(baselineCachePath(cacheDir, branchID))
+		switch {
+		case err == nil:
+			verdicts = cached
+		case !errors.Is(err, os.ErrNotExist):
+			logx.Warningf("Ignoring unreadable baseline cache: %v", err)
+		}
+	}
+
+	var unchecked []parsedIssue
+	for _, issue := range issues {
+		if _, ok := verdicts[IssueFingerprint(issue.Text)]; !ok {
+			unchecked = append(unchecked, issue)
+		}
+	}
+	if len(unchecked) < len(issues) {
+		logx.Infof("Reusing cached baseline verdicts for %d of %d issue(s) on %s", len(issues)-len(unchecked), len(issues), branchID)
+	}
+	if len(unchecked) > 0 {
+		present, err := r.checkBaseline(branchID, unchecked)
+		if err != nil {
+			return nil, err
+		}
+		for i, issue := range unchecked {
+			verdicts[IssueFingerprint(issue.Text)] = present[i]
+		}
+		if cacheDir != "" {
+			if err := saveBaselineCache(baselineCachePath(cacheDir, branchID), baselineCache{BranchID: branchID, Verdicts: verdicts, PromptVersion: PromptVersion}); err != nil {
+				logx.Warningf("Failed to cache baseline verdicts: %v", err)
+			}
+		}
+	}
+
+	preExisting := map[string]bool{}
+	for fingerprint, present := range verdicts {
+		if present {
+			preExisting[fingerprint] = true
+		}
+	}
+	return preExisting, nil
+}
+
+// checkBaseline asks an agent on the baseline branch whether each issue's defect
+// is already in that code, and returns one verdict per issue. The baseline has
+// no diff of its own to review, so the agent is pointed at the code each issue
+// names instead of running the issue finder there; an issue without a verdict
+// counts as new, so it is still reported.
+func (r *Runner) checkBaseline(branchID string, issues []parsedIssue) ([]bool, error) {
+	logx.Infof("Checking %d issue(s) against baseline branch %s", len(issues), branchID)
+	data, err := r.executeAgent("codex", buildBaselineCheckPrompt(r.opts.Task, issues), branchID)
+	if err != nil {
+		return nil, fmt.Errorf("baseline check: %w", err)
+	}
+	return parseBaselineVerdicts(stringField(data, "response"), len(issues)), nil
+}
+
+// baselineVerdictRe matches the "ISSUE <n>: PRE-EXISTING|NEW" lines the baseline
+// check ends with.
+var baselineVerdictRe = regexp.MustCompile(`(?im)^[\s*#>-]*ISSUE\s+(\d+)\s*:\s*\**\s*(PRE-EXISTING|NEW)\b`)
+
+// parseBaselineVerdicts reads the per-issue verdicts of a baseline check; the
+// last verdict given for an issue wins.
+func parseBaselineVerdicts(text string, n int) []bool {
+	present := make([]bool, n)
+	for _, m := range baselineVerdictRe.FindAllStringSubmatch(text, -1) {
+		idx, err := strconv.Atoi(m[1])
+		if err != nil || idx < 1 || idx > n {
+			continue
+		}
+		present[idx-1] = strings.EqualFold(m[2], "PRE-EXISTING")
+	}
+	return present
+}
+
+// splitPreExisting separates the issues the baseline check found on the baseline.
+func splitPreExisting(issues []parsedIssue, baseline map[string]bool) ([]parsedIssue, []PreExistingIssue) {
+	var (
+		introduced  []parsedIssue
+		preExisting []PreExistingIssue
+	)
+	for _, issue := range issues {
+		fingerprint := IssueFingerprint(issue.Text)
+		if !baseline[fingerprint] {
+			introduced = append(introduced, issue)
+			continue
+		}
+		logx.Infof("Issue %s is pre-existing on the baseline: %s", fingerprint, streaming.PromptPreview(issue.Text))
+		preExisting = append(preExisting, PreExistingIssue{IssueText: issue.Text, Fingerprint: fingerprint})
+	}
+	return introduced, preExisting
+}
+
+// preExistingNote is appended to the run summary when baseline issues were dropped.
+func preExistingNote(preExisting []PreExistingIssue) string {
+	if len(preExisting) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" %d pre-existing finding(s) also present on the baseline.", len(preExisting))
+}
+
+func loadBaselineCache(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cache baselineCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if cache.PromptVersion != PromptVersion {
+		version := cache.PromptVersion
+		if version == "" {
+			version = "unknown"
+		}
+		logx.Warningf("Baseline cache %s was written by prompt version %s, not %s; the pre-existing comparison may be unreliable. Delete it to re-review the baseline.", path, version, PromptVersion)
+	}
+	if cache.Verdicts == nil {
+		cache.Verdicts = map[string]bool{}
+	}
+	return cache.Verdicts, nil
+}
+
+func saveBaselineCache(path string, cache baselineCache) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
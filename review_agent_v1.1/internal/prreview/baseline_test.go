@@ -0,0 +1,124 @@
+package prreview
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	b "review_agent/internal/brain"
+	tools "review_agent/internal/tools"
+)
+
+func TestRunBaselineReportsOnlyNewIssues(t *testing.T) {
+	cacheDir := t.TempDir()
+	headIssues := []parsedIssue{
+		{Text: "P0: new nil map write in cache.Put", Priority: priorityP0},
+		{Text: "P1: legacy retry loop never backs off", Priority: priorityP1},
+	}
+
+	newRunner := func(client *fakeRunnerClient, issues []parsedIssue) *Runner {
+		handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+		runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+			Task:             "task",
+			ProjectName:      "proj",
+			ParentBranchID:   "parent",
+			WorkspaceDir:     "/workspace",
+			SkipScout:        true,
+			BaselineBranchID: "base",
+			BaselineCacheDir: cacheDir,
+		})
+		if err != nil {
+			t.Fatalf("NewRunner error: %v", err)
+		}
+		runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+		runner.parseIssuesOverride = func(string) ([]parsedIssue, error) { return issues, nil }
+		return runner
+	}
+	baselineChecks := func(client *fakeRunnerClient) []parallelCall {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+		var checks []parallelCall
+		for _, call := range client.parallelCalls {
+			if call.parent == "base" {
+				checks = append(checks, call)
+			}
+		}
+		return checks
+	}
+
+	client := &fakeRunnerClient{output: "Evidence: retry.go:40 has the same loop.\nISSUE 1: NEW\nISSUE 2: PRE-EXISTING"}
+	result, err := newRunner(client, headIssues).Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].IssueText != headIssues[0].Text {
+		t.Fatalf("expected only the new issue, got %+v", result.Issues)
+	}
+	if len(result.PreExistingIssues) != 1 || result.PreExistingIssues[0].Fingerprint != IssueFingerprint(headIssues[1].Text) {
+		t.Fatalf("expected the legacy issue as pre-existing, got %+v", result.PreExistingIssues)
+	}
+	checks := baselineChecks(client)
+	if len(checks) != 1 {
+		t.Fatalf("expected one baseline check, got %d", len(checks))
+	}
+	if strings.Contains(checks[0].prompt, "git merge-base") || !strings.Contains(checks[0].prompt, headIssues[1].Text) {
+		t.Fatalf("baseline check should target the issues, not diff the base: %q", checks[0].prompt)
+	}
+	if result.PromptVersion != PromptVersion {
+		t.Fatalf("expected prompt_version %q on the result, got %q", PromptVersion, result.PromptVersion)
+	}
+	cached, err := os.ReadFile(baselineCachePath(cacheDir, "base"))
+	if err != nil || !strings.Contains(string(cached), `"prompt_version": "`+PromptVersion+`"`) {
+		t.Fatalf("expected the baseline cache to record the prompt version, got %s (err=%v)", cached, err)
+	}
+
+	client = &fakeRunnerClient{}
+	result, err = newRunner(client, headIssues).Run()
+	if err != nil {
+		t.Fatalf("cached Run error: %v", err)
+	}
+	if got := len(baselineChecks(client)); got != 0 {
+		t.Fatalf("expected the cached verdicts to skip the baseline check, got %d checks", got)
+	}
+	if len(result.Issues) != 1 || len(result.PreExistingIssues) != 1 {
+		t.Fatalf("unexpected cached split: issues=%+v pre-existing=%+v", result.Issues, result.PreExistingIssues)
+	}
+
+	extra := parsedIssue{Text: "P1: token logged at debug level", Priority: priorityP1}
+	client = &fakeRunnerClient{output: "ISSUE 1: PRE-EXISTING"}
+	result, err = newRunner(client, append(headIssues, extra)).Run()
+	if err != nil {
+		t.Fatalf("incremental Run error: %v", err)
+	}
+	checks = baselineChecks(client)
+	if len(checks) != 1 || !strings.Contains(checks[0].prompt, extra.Text) || strings.Contains(checks[0].prompt, headIssues[0].Text) {
+		t.Fatalf("expected only the uncached issue to be checked, got %+v", checks)
+	}
+	if len(result.PreExistingIssues) != 2 {
+		t.Fatalf("expected the new verdict to join the cached one, got %+v", result.PreExistingIssues)
+	}
+}
+
+func TestParseBaselineVerdicts(t *testing.T) {
+	text := "ISSUE 1: NEW\n**ISSUE 3: PRE-EXISTING**\n- issue 4: pre-existing\nISSUE 9: PRE-EXISTING"
+	got := parseBaselineVerdicts(text, 4)
+	want := []bool{false, false, true, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parseBaselineVerdicts = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNewRunnerRejectsBaselineEqualToParent(t *testing.T) {
+	handler := tools.NewToolHandler(&fakeRunnerClient{}, "proj", "parent", "/workspace")
+	_, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:             "task",
+		ProjectName:      "proj",
+		ParentBranchID:   "parent",
+		BaselineBranchID: " parent ",
+	})
+	if err == nil {
+		t.Fatal("expected an error for a baseline equal to the parent branch")
+	}
+}
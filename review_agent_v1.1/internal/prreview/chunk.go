@@ -0,0 +1,126 @@
+package prreview
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"review_agent/internal/logx"
+)
+
+// DefaultMaxAuxInputChars is the largest report sent to an auxiliary call in
+// one piece when Options.MaxAuxInputChars is zero.
+const DefaultMaxAuxInputChars = 60000
+
+// withChunking runs call on text, or, when text is longer than maxChars, on each
+// chunk of splitReport and combines the per-chunk results with merge. The first
+// chunk error fails the whole call.
+func withChunking[T any](text string, maxChars int, call func(string) (T, error), merge func([]T) T) (T, error) {
+	if maxChars <= 0 || len(text) <= maxChars {
+		return call(text)
+	}
+	chunks := splitReport(text, maxChars)
+	logx.Infof("Report is %d chars (limit %d); running the auxiliary call on %d chunks.", len(text), maxChars, len(chunks))
+	results := make([]T, 0, len(chunks))
+	for _, chunk := range chunks {
+		res, err := call(chunk)
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		results = append(results, res)
+	}
+	return merge(results), nil
+}
+
+// splitReport cuts a review report into chunks of at most maxChars, breaking at
+// structural markers ("## " headings, "### Issue" headings and "---" rules) so
+// an issue stays in one chunk. Sections longer than maxChars are split on line
+// boundaries, and single over-long lines at the last rune boundary that fits.
+func splitReport(text string, maxChars int) []string {
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if strings.TrimSpace(current.String()) != "" {
+			chunks = append(chunks, current.String())
+		}
+		current.Reset()
+	}
+	for _, section := range reportSections(text) {
+		if current.Len()+len(section) <= maxChars {
+			current.WriteString(section)
+			continue
+		}
+		flush()
+		if len(section) <= maxChars {
+			current.WriteString(section)
+			continue
+		}
+		for _, line := range strings.SplitAfter(section, "\n") {
+			for len(line) > maxChars {
+				flush()
+				cut := runeCut(line, maxChars)
+				chunks = append(chunks, line[:cut])
+				line = line[cut:]
+			}
+			if current.Len()+len(line) > maxChars {
+				flush()
+			}
+			current.WriteString(line)
+		}
+	}
+	flush()
+	return chunks
+}
+
+// reportSections splits text before each line that starts a new section.
+func reportSections(text string) []string {
+	var sections []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if isSectionMarker(line) && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+// runeCut returns the largest length of at most maxChars bytes that ends on a
+// rune boundary of s (len(s) > maxChars), or the first rune's length when even
+// that does not fit, so a chunk never splits a multi-byte character.
+func runeCut(s string, maxChars int) int {
+	cut := maxChars
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		_, cut = utf8.DecodeRuneInString(s)
+	}
+	return cut
+}
+
+// isSectionMarker reports whether line starts a section: a "## " or "### Issue"
+// heading, or a line holding only a "---" rule. Markers must start the line, so
+// diff lines such as "--- a/file.go" or "+## heading" in quoted code do not
+// split an issue.
+func isSectionMarker(line string) bool {
+	if strings.HasPrefix(line, "## ") || strings.HasPrefix(line, "### Issue") {
+		return true
+	}
+	rule := strings.TrimRight(line, " \t\r\n")
+	return len(rule) >= 3 && strings.Trim(rule, "-") == ""
+}
+
+// anyTrue merges per-chunk has_issue answers: one real issue is enough.
+func anyTrue(results []bool) bool {
+	for _, r := range results {
+		if r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package prreview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// syntheticReport builds a review report of n issue sections, each padded to
+// roughly padding bytes.
+func syntheticReport(n, padding int) string {
+	var sb strings.Builder
+	sb.WriteString("# Code Review\n\nSummary of findings.\n\n")
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&sb, "### Issue %d\nP1: defect number %d\n", i, i)
+		sb.WriteString(strings.Repeat("evidence line\n", padding/len("evidence line\n")))
+		sb.WriteString("---\n")
+	}
+	return sb.String()
+}
+
+func TestSplitReportKeepsSectionsWhole(t *testing.T) {
+	report := syntheticReport(6, 900)
+	chunks := splitReport(report, 2000)
+	if len(chunks) < 3 {
+		t.Fatalf("expected the report to be split, got %d chunk(s)", len(chunks))
+	}
+	if strings.Join(chunks, "") != report {
+		t.Fatal("chunks do not reassemble the report")
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 2000 {
+			t.Fatalf("chunk %d is %d chars, over the limit", i, len(chunk))
+		}
+		for n := 1; n <= 6; n++ {
+			header := fmt.Sprintf("### Issue %d\n", n)
+			if strings.Contains(chunk, header) && !strings.Contains(chunk, fmt.Sprintf("defect number %d\n", n)) {
+				t.Fatalf("issue %d was split from its header in chunk %d", n, i)
+			}
+		}
+	}
+
+	long := strings.Repeat("x", 2500) + "\n"
+	for _, chunk := range splitReport(long, 1000) {
+		if len(chunk) > 1000 {
+			t.Fatalf("over-long line produced a %d-char chunk", len(chunk))
+		}
+	}
+
+	wide := strings.Repeat("缓存", 500) + "\n"
+	chunks = splitReport(wide, 1000)
+	if strings.Join(chunks, "") != wide {
+		t.Fatal("chunks of a multi-byte line do not reassemble it")
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 1000 || !utf8.ValidString(chunk) {
+			t.Fatalf("chunk %d (%d bytes) splits a multi-byte character", i, len(chunk))
+		}
+	}
+}
+
+func TestIsSectionMarkerIgnoresDiffLines(t *testing.T) {
+	cases := map[string]bool{
+		"## Findings\n":         true,
+		"### Issue 3\n":         true,
+		"---\n":                 true,
+		"-----  \r\n":           true,
+		"--- a/cache.go\n":      false,
+		"+++ b/cache.go\n":      false,
+		"  ## indented quote\n": false,
+		"+## added heading\n":   false,
+		"-- \n":                 false,
+	}
+	for line, want := range cases {
+		if got := isSectionMarker(line); got != want {
+			t.Errorf("isSectionMarker(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestHasRealIssueMergesChunksWithOr(t *testing.T) {
+	report := syntheticReport(8, 900)
+	calls := 0
+	runner := &Runner{opts: Options{MaxAuxInputChars: 2000}}
+	runner.hasRealIssueOverride = func(chunk string) (bool, error) {
+		calls++
+		if len(chunk) > 2000 {
+			t.Fatalf("has_issue received a %d-char chunk", len(chunk))
+		}
+		return strings.Contains(chunk, "defect number 7\n"), nil
+	}
+	has, err := runner.hasRealIssue(context.Background(), report)
+	if err != nil {
+		t.Fatalf("hasRealIssue error: %v", err)
+	}
+	if !has || calls < 2 {
+		t.Fatalf("expected a positive answer over several chunks, got has=%v calls=%d", has, calls)
+	}
+
+	runner.hasRealIssueOverride = func(string) (bool, error) { return false, nil }
+	if has, _ := runner.hasRealIssue(context.Background(), report); has {
+		t.Fatal("expected false when no chunk reports an issue")
+	}
+}
@@ -0,0 +1,237 @@
+package prreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	b "review_agent/internal/brain"
+	"review_agent/internal/logx"
+)
+
+// ComparedIssue is one finding of a branch comparison. Fingerprint is its
+// IssueFingerprint, kept so suppression rules can name it.
+type ComparedIssue struct {
+	IssueText   string `json:"issue_text"`
+	Fingerprint string `json:"fingerprint"`
+	Priority    string `json:"priority,omitempty"`
+	// MatchedText is the other branch's wording of a shared finding.
+	MatchedText string `json:"matched_text,omitempty"`
+}
+
+// ComparativeResult reports the issue finder's findings on two candidate
+// implementations of the same task.
+type ComparativeResult struct {
+	Task    string          `json:"task"`
+	BranchA string          `json:"branch_a"`
+	BranchB string          `json:"branch_b"`
+	OnlyA   []ComparedIssue `json:"only_a"`
+	OnlyB   []ComparedIssue `json:"only_b"`
+	Shared  []ComparedIssue `json:"shared"`
+	// Safer is the branch with fewer issues of its own, weighting P0 above P1
+	// above the rest, or "tie".
+	Safer   string `json:"safer"`
+	Summary string `json:"summary"`
+	// PromptVersion is the prompt generation both reviews ran under; Compare
+	// never mixes generations, so its sides are always comparable.
+	PromptVersion string `json:"prompt_version"`
+}
+
+// Compare runs the issue finder on branchA and branchB and splits the findings
+// into those unique to each branch and those both share. The two reviews word
+// the same defect differently, so findings are paired by an auxiliary LLM call
+// rather than by fingerprint. Suppressions apply to both sides; the scout,
+// baseline and verification stages are not run.
+func (r *Runner) Compare(branchA, branchB string) (*ComparativeResult, error) {
+	branchA, branchB = strings.TrimSpace(branchA), strings.TrimSpace(branchB)
+	if branchA == "" || branchB == "" {
+		return nil, errors.New("compare needs two branch ids")
+	}
+	if branchA == branchB {
+		return nil, errors.New("compared branches must differ")
+	}
+	ctx := b.WithCallKind(context.Background(), b.CallAuxiliary)
+	if r.opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.MaxDuration)
+		defer cancel()
+	}
+
+	var found [2][]parsedIssue
+	for i, branchID := range []string{branchA, branchB} {
+		logx.Infof("Reviewing candidate branch %s", branchID)
+		issues, err := r.findIssues(ctx, branchID)
+		if err != nil {
+			return nil, fmt.Errorf("review of %s: %w", branchID, err)
+		}
+		issues, _ = r.suppressIssues(issues)
+		found[i] = dedupeIssues(issues)
+	}
+
+	pairs, err := r.matchIssues(ctx, found[0], found[1])
+	if err != nil {
+		logx.Warningf("Issue matching soft-failed; pairing only identical findings. err=%v", err)
+		pairs = fingerprintPairs(found[0], found[1])
+	}
+	result := compareIssues(found[0], found[1], pairs)
+	result.Task = r.opts.Task
+	result.BranchA, result.BranchB = branchA, branchB
+	result.PromptVersion = PromptVersion
+	switch weightA, weightB := issueWeight(result.OnlyA), issueWeight(result.OnlyB); {
+	case weightA < weightB:
+		result.Safer = branchA
+	case weightB < weightA:
+		result.Safer = branchB
+	default:
+		result.Safer = "tie"
+	}
+	result.Summary = fmt.Sprintf("%d issue(s) only in %s, %d only in %s, %d shared; safer: %s.",
+		len(result.OnlyA), branchA, len(result.OnlyB), branchB, len(result.Shared), result.Safer)
+	return result, nil
+}
+
+// findIssues runs the issue finder on branchID without a change analysis and
+// parses the issues it reports; a clean report yields none.
+func (r *Runner) findIssues(ctx context.Context, branchID string) ([]parsedIssue, error) {
+	reviewLog, err := r.runSingleReview(branchID, "")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(reviewLog.Report) == "" {
+		return nil, nil
+	}
+	hasIssue, err := r.hasRealIssue(ctx, reviewLog.Report)
+	if err != nil || !hasIssue {
+		return nil, err
+	}
+	return r.parseIssuesFromReport(ctx, reviewLog.Report)
+}
+
+// dedupeIssues drops repeats of an identical finding within one review.
+func dedupeIssues(issues []parsedIssue) []parsedIssue {
+	var out []parsedIssue
+	seen := make(map[string]bool)
+	for _, issue := range issues {
+		fingerprint := IssueFingerprint(issue.Text)
+		if !seen[fingerprint] {
+			seen[fingerprint] = true
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// issuePair links a finding on branch A to the same defect on branch B, by index.
+type issuePair struct {
+	A int `json:"a"`
+	B int `json:"b"`
+}
+
+// matchIssues asks which findings of a and b describe the same defect. Indices
+// in the reply are 1-based; out-of-range indices and second matches of an
+// already paired finding are dropped.
+func (r *Runner) matchIssues(ctx context.Context, issuesA, issuesB []parsedIssue) ([]issuePair, error) {
+	if len(issuesA) == 0 || len(issuesB) == 0 {
+		return nil, nil
+	}
+	if r.matchIssuesOverride != nil {
+		return r.matchIssuesOverride(issuesA, issuesB)
+	}
+	if r.brain == nil {
+		return nil, errors.New("brain is required for issue matching")
+	}
+	resp, err := r.brain.Complete(ctx, []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxIssueMatch)},
+		{Role: "user", Content: buildIssueMatchPrompt(issuesA, issuesB)},
+	}, nil, r.jsonCallOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		return nil, fmt.Errorf("issue match: %w", err)
+	}
+	var reply struct {
+		Matches []issuePair `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(extractJSONBlock(content)), &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse issue match JSON: %w", err)
+	}
+	var pairs []issuePair
+	usedA, usedB := map[int]bool{}, map[int]bool{}
+	for _, m := range reply.Matches {
+		ia, ib := m.A-1, m.B-1
+		if ia < 0 || ia >= len(issuesA) || ib < 0 || ib >= len(issuesB) || usedA[ia] || usedB[ib] {
+			continue
+		}
+		usedA[ia], usedB[ib] = true, true
+		pairs = append(pairs, issuePair{A: ia, B: ib})
+	}
+	return pairs, nil
+}
+
+// fingerprintPairs pairs only findings worded identically (up to case and
+// spacing); it is the fallback when the matching call fails.
+func fingerprintPairs(issuesA, issuesB []parsedIssue) []issuePair {
+	inB := make(map[string]int, len(issuesB))
+	for i, issue := range issuesB {
+		inB[IssueFingerprint(issue.Text)] = i
+	}
+	var pairs []issuePair
+	for i, issue := range issuesA {
+		if j, ok := inB[IssueFingerprint(issue.Text)]; ok {
+			pairs = append(pairs, issuePair{A: i, B: j})
+		}
+	}
+	return pairs
+}
+
+// compareIssues splits the two issue lists by the given pairs (0-based indices).
+func compareIssues(issuesA, issuesB []parsedIssue, pairs []issuePair) *ComparativeResult {
+	toCompared := func(issue parsedIssue) ComparedIssue {
+		return ComparedIssue{IssueText: issue.Text, Fingerprint: IssueFingerprint(issue.Text), Priority: issue.Priority}
+	}
+	pairedA := make(map[int]int, len(pairs))
+	pairedB := make(map[int]bool, len(pairs))
+	for _, p := range pairs {
+		pairedA[p.A] = p.B
+		pairedB[p.B] = true
+	}
+
+	result := &ComparativeResult{OnlyA: []ComparedIssue{}, OnlyB: []ComparedIssue{}, Shared: []ComparedIssue{}}
+	for i, issue := range issuesA {
+		j, shared := pairedA[i]
+		if !shared {
+			result.OnlyA = append(result.OnlyA, toCompared(issue))
+			continue
+		}
+		compared := toCompared(issue)
+		compared.MatchedText = issuesB[j].Text
+		result.Shared = append(result.Shared, compared)
+	}
+	for j, issue := range issuesB {
+		if !pairedB[j] {
+			result.OnlyB = append(result.OnlyB, toCompared(issue))
+		}
+	}
+	return result
+}
+
+// issueWeight scores a branch's unique issues so P0s dominate P1s, which in turn
+// dominate unrated and lower-priority findings.
+func issueWeight(issues []ComparedIssue) int {
+	weight := 0
+	for _, issue := range issues {
+		switch normalizePriority(issue.Priority) {
+		case priorityP0:
+			weight += 10000
+		case priorityP1:
+			weight += 100
+		default:
+			weight++
+		}
+	}
+	return weight
+}
@@ -0,0 +1,88 @@
+package prreview
+
+import (
+	"context"
+	"testing"
+
+	b "review_agent/internal/brain"
+	tools "review_agent/internal/tools"
+)
+
+func TestCompareSplitsIssuesByBranch(t *testing.T) {
+	issuesA := []parsedIssue{
+		{Text: "P1: retry loop never backs off", Priority: priorityP1},
+		{Text: "P1: log line leaks the token", Priority: priorityP1},
+	}
+	issuesB := []parsedIssue{
+		{Text: "P1: backoff is missing in client.retry, so failures hammer the API", Priority: priorityP1},
+		{Text: "P0: nil map write in cache.Put", Priority: priorityP0},
+	}
+	parses := [][]parsedIssue{issuesA, issuesB}
+
+	client := &fakeRunnerClient{}
+	handler := tools.NewToolHandler(client, "proj", "impl-a", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		Task:           "task",
+		ProjectName:    "proj",
+		ParentBranchID: "impl-a",
+		WorkspaceDir:   "/workspace",
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	runner.hasRealIssueOverride = func(string) (bool, error) { return true, nil }
+	runner.parseIssuesOverride = func(string) ([]parsedIssue, error) {
+		issues := parses[0]
+		parses = parses[1:]
+		return issues, nil
+	}
+	runner.matchIssuesOverride = func(a, b []parsedIssue) ([]issuePair, error) {
+		if len(a) != 2 || len(b) != 2 {
+			t.Fatalf("expected both deduplicated lists, got %d and %d", len(a), len(b))
+		}
+		return []issuePair{{A: 0, B: 0}}, nil
+	}
+
+	result, err := runner.Compare("impl-a", "impl-b")
+	if err != nil {
+		t.Fatalf("Compare error: %v", err)
+	}
+	if len(result.Shared) != 1 || result.Shared[0].IssueText != issuesA[0].Text || result.Shared[0].MatchedText != issuesB[0].Text {
+		t.Fatalf("expected the retry issue to be shared, got %+v", result.Shared)
+	}
+	if len(result.OnlyA) != 1 || result.OnlyA[0].IssueText != issuesA[1].Text {
+		t.Fatalf("unexpected issues only in A: %+v", result.OnlyA)
+	}
+	if len(result.OnlyB) != 1 || result.OnlyB[0].IssueText != issuesB[1].Text {
+		t.Fatalf("unexpected issues only in B: %+v", result.OnlyB)
+	}
+	if result.Safer != "impl-a" {
+		t.Fatalf("expected the branch without a unique P0 to be safer, got %q", result.Safer)
+	}
+	if result.PromptVersion != PromptVersion {
+		t.Fatalf("expected prompt_version %q on the comparison, got %q", PromptVersion, result.PromptVersion)
+	}
+
+	if _, err := runner.Compare("impl-a", "impl-a"); err == nil {
+		t.Fatal("expected an error comparing a branch with itself")
+	}
+}
+
+func TestCompareIssuesFallsBackToFingerprints(t *testing.T) {
+	a := []parsedIssue{{Text: "P1: retry loop never backs off"}, {Text: "P1: token logged"}}
+	b := []parsedIssue{{Text: "P0: nil map write"}, {Text: "P1: Retry loop  never backs off"}}
+	result := compareIssues(a, b, fingerprintPairs(a, b))
+	if len(result.Shared) != 1 || result.Shared[0].MatchedText != b[1].Text {
+		t.Fatalf("expected the identically worded finding to be shared, got %+v", result.Shared)
+	}
+	if len(result.OnlyA) != 1 || len(result.OnlyB) != 1 {
+		t.Fatalf("unexpected split: only_a=%+v only_b=%+v", result.OnlyA, result.OnlyB)
+	}
+}
+
+func TestMatchIssuesSkipsEmptySide(t *testing.T) {
+	runner := &Runner{}
+	if pairs, err := runner.matchIssues(context.Background(), nil, []parsedIssue{{Text: "x"}}); err != nil || pairs != nil {
+		t.Fatalf("an empty side needs no matching call, got %v, %v", pairs, err)
+	}
+}
@@ -0,0 +1,77 @@
+package prreview
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// headlineStatus is an issue status counted in Result.Headline, with the word
+// the headline uses for it.
+type headlineStatus struct {
+	status string
+	label  string
+}
+
+// headlinePriorities lists the priority buckets in display order; "P0/P1"
+// collects issues whose priority is unknown.
+var headlinePriorities = []string{"P0", "P1", "P0/P1"}
+
+var priorityLabel = regexp.MustCompile(`\bP[01]\b`)
+
+// headline templates a one-sentence summary of res without an LLM call, e.g.
+// "Review of PR #123: 2 confirmed P0, 1 unresolved P1 across 4 changed files
+// (8m32s)." review_agent and review_agent_v1.1 share this file;
+// headlineStatuses and issuePriority cover how their issue reports differ.
+func (r *Runner) headline(res *Result, elapsed time.Duration) string {
+	outcome := "clean, no blocking P0/P1 issues"
+	if res.Status == statusIssues {
+		var parts []string
+		for _, s := range headlineStatuses {
+			counts := map[string]int{}
+			for _, issue := range res.Issues {
+				if issue.Status == s.status {
+					counts[issuePriority(issue)]++
+				}
+			}
+			for _, priority := range headlinePriorities {
+				if n := counts[priority]; n > 0 {
+					parts = append(parts, fmt.Sprintf("%d %s %s", n, s.label, priority))
+				}
+			}
+		}
+		outcome = strings.Join(parts, ", ")
+		if len(parts) == 0 {
+			outcome = fmt.Sprintf("%d reported P0/P1 %s, none confirmed", len(res.Issues), plural(len(res.Issues), "issue"))
+		}
+	}
+	if n := r.opts.ChangedFiles; n > 0 {
+		outcome += fmt.Sprintf(" across %d changed %s", n, plural(n, "file"))
+	}
+	return fmt.Sprintf("Review of %s: %s (%s).", r.headlineSubject(), outcome, elapsed.Round(time.Second))
+}
+
+// headlineSubject is how Result.Headline names the reviewed change.
+func (r *Runner) headlineSubject() string {
+	if r.opts.Subject != "" {
+		return r.opts.Subject
+	}
+	return "branch " + r.opts.ParentBranchID
+}
+
+// priorityFromText returns the first P0 or P1 label in an issue's text, or
+// "P0/P1" when it has none.
+func priorityFromText(text string) string {
+	if label := priorityLabel.FindString(text); label != "" {
+		return label
+	}
+	return "P0/P1"
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
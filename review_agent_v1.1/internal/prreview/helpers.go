@@ -2,6 +2,7 @@ package prreview
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -61,6 +62,37 @@ const universalStudyLine = "**DEEP CODE EXPLORATION MANDATE**\n" +
 	"- Do not invent unsupported or hypothetical scenarios\n\n" +
 	"**REMEMBER**: Spend as much time and context as needed. Read every file that might be relevant. Trace every execution path. Leave no stone unturned."
 
+// lightStudyLine is the terse exploration instruction used for StudyLight runs.
+const lightStudyLine = "**CODE EXPLORATION**\n" +
+	"Read the changed code and its direct callers/callees before concluding. " +
+	"Confirm any trigger scenario is reachable in current code paths; do not invent hypothetical scenarios."
+
+// standardStudyLine keeps the core exploration checklist without the maximalist framing.
+const standardStudyLine = "**CODE EXPLORATION**\n" +
+	"Explore enough of the codebase to be confident in your conclusions:\n" +
+	"1. Read the full context of each changed function/struct/module, plus its callers and callees\n" +
+	"2. Trace the relevant execution paths, including error and cleanup paths\n" +
+	"3. Follow data flow and shared state; note concurrency and ordering constraints\n" +
+	"4. Check boundary conditions (empty/nil values, limits, timeouts, resource exhaustion)\n" +
+	"5. Consider cross-module and compatibility impact\n\n" +
+	"**SCENARIO VALIDATION**\n" +
+	"- Confirm the described trigger scenario is real and reachable in current code paths\n" +
+	"- If a behavior is by design (e.g., a performance tradeoff), call that out instead of proposing a fix\n" +
+	"- Do not invent unsupported or hypothetical scenarios"
+
+// studyLineFor returns the exploration block matching the requested intensity.
+// Unknown values fall back to the deep (universal) variant.
+func studyLineFor(intensity string) string {
+	switch intensity {
+	case StudyLight:
+		return lightStudyLine
+	case StudyStandard:
+		return standardStudyLine
+	default:
+		return universalStudyLine
+	}
+}
+
 const p0p1FocusBlock = "**P0/P1 FOCUS**\n" +
 	"- Report ONLY P0/P1 issues\n" +
 	"- Ignore general issues (style, refactors, maintainability, low-impact edge cases)\n" +
@@ -83,19 +115,18 @@ const p0p1FocusBlock = "**P0/P1 FOCUS**\n" +
 
 const p0p1VerdictGateBlock = "**P0/P1 SEVERITY GATE**\n" +
 	"- Your verdict is about whether issueText is a real P0/P1 issue, not just whether a behavior exists\n" +
-	"- **ENCOURAGE CONFIRMATION**: When the issue description is plausible and you can trace a real execution path that leads to the problem, CONFIRM it. Don't be overly conservative.\n" +
 	"- **CONFIRM IF**: The issue could cause crashes, data loss, security vulnerabilities, correctness regressions, or other serious impacts in production.\n" +
 	"- **CONFIRM IF**: You can identify a specific code path, condition, or scenario where the problem would manifest.\n" +
 	"- **REJECT ONLY IF**: Impact is clearly limited, behavior is explicitly by design, or the issue description is fundamentally incorrect.\n" +
 	"- Evaluate impact and fix feasibility; if impact is limited or behavior is a deliberate tradeoff/by design, REJECT\n" +
 	"- If only risky or unreasonable fixes exist, REJECT\n"
 
-func buildIssueFinderPrompt(task string, changeAnalysisPath string) string {
+func buildIssueFinderPrompt(task string, changeAnalysisPath string, intensity string) string {
 	var sb strings.Builder
 	sb.WriteString("Task: ")
 	sb.WriteString(task)
 	sb.WriteString("\n\n")
-	sb.WriteString(universalStudyLine)
+	sb.WriteString(studyLineFor(intensity))
 	sb.WriteString("\n\n")
 	if strings.TrimSpace(changeAnalysisPath) != "" {
 		sb.WriteString("Reference (read-only): Change Analysis at: ")
@@ -114,28 +145,7 @@ func buildIssueFinderPrompt(task string, changeAnalysisPath string) string {
 	sb.WriteString("     - Also run: git diff --name-status MERGE_BASE_SHA\n")
 	sb.WriteString("     - For each changed file, read the FULL file to understand context\n\n")
 
-	sb.WriteString("Step 2: Deep Context Understanding\n")
-	sb.WriteString("For EACH changed file, you MUST:\n")
-	sb.WriteString("1. Read the ENTIRE file (not just the diff lines) to understand:\n")
-	sb.WriteString("   - The module's purpose and design\n")
-	sb.WriteString("   - All data structures and their relationships\n")
-	sb.WriteString("   - All functions and their contracts\n")
-	sb.WriteString("   - Error handling patterns\n")
-	sb.WriteString("   - Concurrency patterns (locks, channels, async operations)\n\n")
-	sb.WriteString("2. Trace ALL call sites:\n")
-	sb.WriteString("   - Find every place that calls modified functions\n")
-	sb.WriteString("   - Understand the calling context and assumptions\n")
-	sb.WriteString("   - Check if callers handle errors correctly\n")
-	sb.WriteString("   - Verify callers aren't broken by the changes\n\n")
-	sb.WriteString("3. Trace ALL callees:\n")
-	sb.WriteString("   - Read every function called by modified code\n")
-	sb.WriteString("   - Understand their contracts and side effects\n")
-	sb.WriteString("   - Check if they can fail and how failures are handled\n\n")
-	sb.WriteString("4. Understand data flow:\n")
-	sb.WriteString("   - Where does input data come from?\n")
-	sb.WriteString("   - How is it validated and transformed?\n")
-	sb.WriteString("   - Where does output data go?\n")
-	sb.WriteString("   - Are there any shared state or global variables?\n\n")
+	writeIssueFinderContextStep(&sb, intensity)
 
 	sb.WriteString("Step 3: Systematic Bug Detection\n")
 	sb.WriteString("For each code change, systematically check:\n")
@@ -224,10 +234,10 @@ func buildIssueFinderPrompt(task string, changeAnalysisPath string) string {
 	return sb.String()
 }
 
-func buildScoutPrompt(task string, outputPath string) string {
+func buildScoutPrompt(task string, outputPath string, intensity string) string {
 	var sb strings.Builder
 	sb.WriteString("Role: SCOUT (Deep Change Analysis)\n\n")
-	sb.WriteString(universalStudyLine)
+	sb.WriteString(studyLineFor(intensity))
 	sb.WriteString("\n\n")
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
@@ -247,36 +257,7 @@ func buildScoutPrompt(task string, outputPath string) string {
 	sb.WriteString("     - Also run: git diff --name-status MERGE_BASE_SHA\n")
 	sb.WriteString("     - For EACH changed file, read the ENTIRE file (not just diff lines)\n\n")
 
-	sb.WriteString("**STEP 2: Deep Context Exploration**\n")
-	sb.WriteString("For EACH changed file, you MUST:\n\n")
-	sb.WriteString("1. **Read the Complete File**:\n")
-	sb.WriteString("   - Understand the module's architecture and design\n")
-	sb.WriteString("   - Identify all data structures, types, and their relationships\n")
-	sb.WriteString("   - Map all functions and their contracts\n")
-	sb.WriteString("   - Understand error handling patterns\n")
-	sb.WriteString("   - Identify concurrency patterns (locks, channels, async, goroutines)\n\n")
-
-	sb.WriteString("2. **Trace Call Graph**:\n")
-	sb.WriteString("   - Find ALL callers of modified functions (use grep, ripgrep, or IDE tools)\n")
-	sb.WriteString("   - Read each caller to understand usage patterns\n")
-	sb.WriteString("   - Find ALL callees (functions called by modified code)\n")
-	sb.WriteString("   - Read each callee to understand dependencies\n")
-	sb.WriteString("   - Map the complete call chain for each execution path\n\n")
-
-	sb.WriteString("3. **Data Flow Analysis**:\n")
-	sb.WriteString("   - Trace where input data originates (APIs, configs, databases, files)\n")
-	sb.WriteString("   - Understand data transformations and validations\n")
-	sb.WriteString("   - Trace where output data goes (storage, network, other modules)\n")
-	sb.WriteString("   - Identify shared state, global variables, and their access patterns\n")
-	sb.WriteString("   - Map data dependencies and ordering constraints\n\n")
-
-	sb.WriteString("4. **Identify Risk Patterns**:\n")
-	sb.WriteString("   - Concurrency: locks, channels, shared state, race conditions\n")
-	sb.WriteString("   - Resource management: memory, file handles, connections, cleanup\n")
-	sb.WriteString("   - Error handling: error propagation, recovery, edge cases\n")
-	sb.WriteString("   - State management: state machines, lifecycle, invariants\n")
-	sb.WriteString("   - API contracts: parameter validation, return value contracts\n")
-	sb.WriteString("   - Security: authentication, authorization, input validation, secrets\n\n")
+	writeScoutContextStep(&sb, intensity)
 
 	sb.WriteString("**STEP 3: Impact Analysis**\n")
 	sb.WriteString("For each change, analyze:\n")
@@ -338,6 +319,93 @@ func buildScoutPrompt(task string, outputPath string) string {
 	return sb.String()
 }
 
+// writeIssueFinderContextStep emits "Step 2" of the issue finder process. Only the
+// deep variant carries the full per-file mandate; lighter variants keep the intent.
+func writeIssueFinderContextStep(sb *strings.Builder, intensity string) {
+	switch intensity {
+	case StudyLight:
+		sb.WriteString("Step 2: Context\n")
+		sb.WriteString("Read each changed function in context and check its direct callers before judging it.\n\n")
+		return
+	case StudyStandard:
+		sb.WriteString("Step 2: Context Understanding\n")
+		sb.WriteString("For each changed file:\n")
+		sb.WriteString("1. Read the surrounding code to understand the module's contracts and error handling\n")
+		sb.WriteString("2. Check the call sites of modified functions and whether callers still hold\n")
+		sb.WriteString("3. Check callees that can fail and how those failures are handled\n\n")
+		return
+	}
+	sb.WriteString("Step 2: Deep Context Understanding\n")
+	sb.WriteString("For EACH changed file, you MUST:\n")
+	sb.WriteString("1. Read the ENTIRE file (not just the diff lines) to understand:\n")
+	sb.WriteString("   - The module's purpose and design\n")
+	sb.WriteString("   - All data structures and their relationships\n")
+	sb.WriteString("   - All functions and their contracts\n")
+	sb.WriteString("   - Error handling patterns\n")
+	sb.WriteString("   - Concurrency patterns (locks, channels, async operations)\n\n")
+	sb.WriteString("2. Trace ALL call sites:\n")
+	sb.WriteString("   - Find every place that calls modified functions\n")
+	sb.WriteString("   - Understand the calling context and assumptions\n")
+	sb.WriteString("   - Check if callers handle errors correctly\n")
+	sb.WriteString("   - Verify callers aren't broken by the changes\n\n")
+	sb.WriteString("3. Trace ALL callees:\n")
+	sb.WriteString("   - Read every function called by modified code\n")
+	sb.WriteString("   - Understand their contracts and side effects\n")
+	sb.WriteString("   - Check if they can fail and how failures are handled\n\n")
+	sb.WriteString("4. Understand data flow:\n")
+	sb.WriteString("   - Where does input data come from?\n")
+	sb.WriteString("   - How is it validated and transformed?\n")
+	sb.WriteString("   - Where does output data go?\n")
+	sb.WriteString("   - Are there any shared state or global variables?\n\n")
+}
+
+// writeScoutContextStep emits the scout's "STEP 2" exploration mandate for the given intensity.
+func writeScoutContextStep(sb *strings.Builder, intensity string) {
+	switch intensity {
+	case StudyLight:
+		sb.WriteString("**STEP 2: Context**\n")
+		sb.WriteString("Skim each changed file and note the functions and callers most affected by the change.\n\n")
+		return
+	case StudyStandard:
+		sb.WriteString("**STEP 2: Context Exploration**\n")
+		sb.WriteString("For each changed file:\n")
+		sb.WriteString("- Identify the key data structures and function contracts touched by the change\n")
+		sb.WriteString("- Find the callers of modified functions and how they use the results\n")
+		sb.WriteString("- Note shared state, concurrency, resource management and error handling risks\n\n")
+		return
+	}
+	sb.WriteString("**STEP 2: Deep Context Exploration**\n")
+	sb.WriteString("For EACH changed file, you MUST:\n\n")
+	sb.WriteString("1. **Read the Complete File**:\n")
+	sb.WriteString("   - Understand the module's architecture and design\n")
+	sb.WriteString("   - Identify all data structures, types, and their relationships\n")
+	sb.WriteString("   - Map all functions and their contracts\n")
+	sb.WriteString("   - Understand error handling patterns\n")
+	sb.WriteString("   - Identify concurrency patterns (locks, channels, async, goroutines)\n\n")
+
+	sb.WriteString("2. **Trace Call Graph**:\n")
+	sb.WriteString("   - Find ALL callers of modified functions (use grep, ripgrep, or IDE tools)\n")
+	sb.WriteString("   - Read each caller to understand usage patterns\n")
+	sb.WriteString("   - Find ALL callees (functions called by modified code)\n")
+	sb.WriteString("   - Read each callee to understand dependencies\n")
+	sb.WriteString("   - Map the complete call chain for each execution path\n\n")
+
+	sb.WriteString("3. **Data Flow Analysis**:\n")
+	sb.WriteString("   - Trace where input data originates (APIs, configs, databases, files)\n")
+	sb.WriteString("   - Understand data transformations and validations\n")
+	sb.WriteString("   - Trace where output data goes (storage, network, other modules)\n")
+	sb.WriteString("   - Identify shared state, global variables, and their access patterns\n")
+	sb.WriteString("   - Map data dependencies and ordering constraints\n\n")
+
+	sb.WriteString("4. **Identify Risk Patterns**:\n")
+	sb.WriteString("   - Concurrency: locks, channels, shared state, race conditions\n")
+	sb.WriteString("   - Resource management: memory, file handles, connections, cleanup\n")
+	sb.WriteString("   - Error handling: error propagation, recovery, edge cases\n")
+	sb.WriteString("   - State management: state machines, lifecycle, invariants\n")
+	sb.WriteString("   - API contracts: parameter validation, return value contracts\n")
+	sb.WriteString("   - Security: authentication, authorization, input validation, secrets\n\n")
+}
+
 func buildHasRealIssuePrompt(reportText string) string {
 	var sb strings.Builder
 	sb.WriteString("You are a strict triage parser for code review reports.\n\n")
@@ -353,10 +421,10 @@ func buildHasRealIssuePrompt(reportText string) string {
 }
 
 // buildReviewerPrompt creates the prompt for the Reviewer role (logic analysis).
-func buildLogicAnalystPrompt(task string, issueText string, changeAnalysisPath string) string {
+func buildLogicAnalystPrompt(task string, issueText string, changeAnalysisPath string, intensity string, stance string) string {
 	var sb strings.Builder
 	sb.WriteString("Verification Role: REVIEWER\n\n")
-	sb.WriteString(universalStudyLine)
+	sb.WriteString(studyLineFor(intensity))
 	sb.WriteString("\n\n")
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
@@ -371,10 +439,8 @@ func buildLogicAnalystPrompt(task string, issueText string, changeAnalysisPath s
 	sb.WriteString("YOUR ROLE: Analyze code logic to determine if this is a real P0/P1 issue.\n\n")
 	sb.WriteString("Simulate a group of senior programmers reviewing this code change.\n")
 	sb.WriteString("You have UNLIMITED time and context. Cost is NOT a concern. Your ONLY goal is to find the truth.\n\n")
-	sb.WriteString("**IMPORTANT: ENCOURAGE CONFIRMATION OF VALID ISSUES**\n")
-	sb.WriteString("- If the issue description is plausible and you can trace a real execution path, CONFIRM it.\n")
-	sb.WriteString("- Don't be overly conservative - if there's a reasonable chance the issue could occur in production, CONFIRM it.\n")
-	sb.WriteString("- Only REJECT if you can definitively prove the issue cannot occur or has no real impact.\n\n")
+	sb.WriteString(stanceBlock(stance))
+	sb.WriteString("\n")
 
 	sb.WriteString("**DEEP ANALYSIS REQUIREMENTS**:\n")
 	sb.WriteString("1. **Read ALL Related Code**:\n")
@@ -435,10 +501,10 @@ func buildLogicAnalystPrompt(task string, issueText string, changeAnalysisPath s
 }
 
 // buildTesterPrompt creates the prompt for the Tester role (reproduction).
-func buildTesterPrompt(task string, issueText string, changeAnalysisPath string) string {
+func buildTesterPrompt(task string, issueText string, changeAnalysisPath string, intensity string) string {
 	var sb strings.Builder
 	sb.WriteString("Verification Role: TESTER\n\n")
-	sb.WriteString(universalStudyLine)
+	sb.WriteString(studyLineFor(intensity))
 	sb.WriteString("\n\n")
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
@@ -519,12 +585,12 @@ func buildTesterPrompt(task string, issueText string, changeAnalysisPath string)
 }
 
 // buildExchangePrompt creates the prompt for Round 2+ (exchange opinions).
-func buildExchangePrompt(role string, task string, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string) string {
+func buildExchangePrompt(role string, task string, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string, intensity string) string {
 	normalizedRole := strings.ToLower(strings.TrimSpace(role))
 	displayRole := strings.ToUpper(role)
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Verification Role: %s (Round 2+ - Exchange)\n\n", displayRole))
-	sb.WriteString(universalStudyLine)
+	sb.WriteString(studyLineFor(intensity))
 	sb.WriteString("\n\n")
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
@@ -599,10 +665,10 @@ func buildExchangePrompt(role string, task string, issueText string, changeAnaly
 }
 
 // buildVerifyAgentPrompt creates a prompt for adversarial review (Round 1)
-func buildVerifyAgentPrompt(task string, issueText string, changeAnalysisPath string, reviewerOpinion string) string {
+func buildVerifyAgentPrompt(task string, issueText string, changeAnalysisPath string, reviewerOpinion string, intensity string, stance string) string {
 	var sb strings.Builder
 	sb.WriteString("Verification Role: VERIFY_AGENT (Adversarial Review)\n\n")
-	sb.WriteString(universalStudyLine)
+	sb.WriteString(studyLineFor(intensity))
 	sb.WriteString("\n\n")
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
@@ -626,11 +692,8 @@ func buildVerifyAgentPrompt(task string, issueText string, changeAnalysisPath st
 	sb.WriteString("3. **Verify assumptions**: Check if the issue description makes incorrect assumptions\n")
 	sb.WriteString("4. **Test alternative explanations**: Consider if the behavior is intentional or correct\n\n")
 	sb.WriteString("You have UNLIMITED time and context. Cost is NOT a concern. Your ONLY goal is to find the truth.\n\n")
-	sb.WriteString("**BALANCED ADVERSARIAL APPROACH**\n")
-	sb.WriteString("- Challenge the claim, but if you cannot find strong counter-evidence, CONFIRM the issue.\n")
-	sb.WriteString("- Don't reject issues based on weak assumptions or hypothetical safeguards that may not actually prevent the problem.\n")
-	sb.WriteString("- CONFIRM if: You cannot disprove the claim and the issue description is plausible with a traceable execution path.\n")
-	sb.WriteString("- REJECT only if: You can definitively show the issue cannot occur or is based on incorrect assumptions.\n\n")
+	sb.WriteString(stanceBlock(stance))
+	sb.WriteString("\n")
 
 	sb.WriteString("**ADVERSARIAL ANALYSIS REQUIREMENTS**:\n")
 	sb.WriteString("1. **Read ALL Related Code**:\n")
@@ -816,8 +879,138 @@ func buildIssueParserPrompt(reportText string) string {
 	return sb.String()
 }
 
-// buildSummaryReportPrompt creates a prompt for generating the review summary report
-func buildSummaryReportPrompt(task string, result *Result, outputPath string) string {
+// Issue priorities; priorityNone marks an issue the severity classifier does not
+// consider a defect at all.
+const (
+	priorityP0   = "P0"
+	priorityP1   = "P1"
+	priorityP2   = "P2"
+	priorityNone = "none"
+)
+
+// severityDefinitionsBlock carries the reviewer's P0/P1 definitions so the
+// classifier judges impact instead of echoing the finder's labels.
+const severityDefinitionsBlock = "Severity definitions:\n" +
+	"- P0 (Critical/Blocker): Reachable under default production configuration, and causes production unavailability; severe data loss/corruption; a security vulnerability; or a primary workflow is completely blocked with no practical workaround. Must be fixed immediately.\n" +
+	"- P1 (High): Reachable in realistic production scenarios (default or commonly enabled configs), and significantly impairs core/major functionality or violates user-facing contracts relied upon (including user-visible correctness errors), or causes a severe performance regression that impacts use; a workaround may exist but is costly/risky/high-friction. Must be fixed before release.\n" +
+	"- P2 (Medium/Low): A real defect whose impact is narrow, edge-case only, gated behind unusual configuration, or cheaply worked around.\n" +
+	"- Not an issue: Style, refactors, speculation without a code-causal chain, or behavior that is by design.\n"
+
+type severityDecision struct {
+	Priority string `json:"priority"`
+	Reason   string `json:"reason"`
+}
+
+// buildSeverityClassificationPrompt asks for an independent priority for one issue,
+// ignoring whatever label the finder attached to it.
+func buildSeverityClassificationPrompt(task string, issueText string) string {
+	var sb strings.Builder
+	sb.WriteString("You are an independent severity classifier for code review findings.\n\n")
+	sb.WriteString("Task / PR context:\n")
+	sb.WriteString(task)
+	sb.WriteString("\n\n")
+	sb.WriteString(severityDefinitionsBlock)
+	sb.WriteString("\n")
+	sb.WriteString("Rules:\n")
+	sb.WriteString("- Judge the described impact and reachability against the definitions above.\n")
+	sb.WriteString("- Ignore any priority label, urgency wording, or quota the finder attached to the issue; finders tend to over-label.\n")
+	sb.WriteString("- A P0/P1 rating needs a concrete trigger and blast radius in the issue text; without one, rate P2 or lower.\n\n")
+	sb.WriteString("Issue:\n")
+	sb.WriteString(issueText)
+	sb.WriteString("\n\n")
+	sb.WriteString("Reply ONLY with JSON: {\"priority\":\"P0|P1|P2|none\",\"reason\":\"one sentence\"}.\n")
+	return sb.String()
+}
+
+// buildBaselineCheckPrompt asks an agent checked out on the baseline branch
+// whether each issue found on the PR already exists there. The agent reads the
+// code as it stands rather than a diff, since the baseline is the diff's base.
+func buildBaselineCheckPrompt(task string, issues []parsedIssue) string {
+	var sb strings.Builder
+	sb.WriteString("Role: BASELINE CHECKER\n\n")
+	sb.WriteString("Task / PR context:\n")
+	sb.WriteString(task)
+	sb.WriteString("\n\n")
+	sb.WriteString("This checkout is the BASE branch the PR is compared against, not the PR itself.\n")
+	sb.WriteString("Do NOT diff against another branch: the issues below were found in the PR, and you decide whether each one already exists in THIS code.\n\n")
+	sb.WriteString("For each issue:\n")
+	sb.WriteString("- Locate the files, functions and lines it names (use grep/ripgrep; names may differ slightly if code moved).\n")
+	sb.WriteString("- Read that code as it stands here and trace the trigger the issue describes.\n")
+	sb.WriteString("- PRE-EXISTING: the same defect, with the same trigger and impact, is reachable in this code.\n")
+	sb.WriteString("- NEW: the code is absent, differs so the defect cannot occur, or you cannot find it. When unsure, answer NEW.\n\n")
+	for i, issue := range issues {
+		fmt.Fprintf(&sb, "ISSUE %d:\n<<<ISSUE>>>\n%s\n<<<END ISSUE>>>\n\n", i+1, strings.TrimSpace(issue.Text))
+	}
+	sb.WriteString(outputAwarenessBlock)
+	sb.WriteString("\n\n")
+	sb.WriteString("FINAL RESPONSE: for each issue give one sentence of evidence (file:line), then end with exactly one line per issue:\n")
+	sb.WriteString("ISSUE <n>: PRE-EXISTING\n")
+	sb.WriteString("or\n")
+	sb.WriteString("ISSUE <n>: NEW\n")
+	return sb.String()
+}
+
+// buildIssueMatchPrompt lists the findings of two reviews and asks which pairs
+// describe the same underlying defect, however differently they are worded.
+func buildIssueMatchPrompt(issuesA, issuesB []parsedIssue) string {
+	var sb strings.Builder
+	sb.WriteString("Two independent code reviews of alternative implementations of the same task produced the findings below.\n")
+	sb.WriteString("Pair each finding in list A with the finding in list B that describes the SAME defect: the same code location (file/function) and the same failure mode.\n")
+	sb.WriteString("Wording, priority labels and level of detail will differ; judge the defect, not the text. Leave a finding unpaired when no finding on the other side matches. Each finding is in at most one pair.\n\n")
+	for _, list := range []struct {
+		name   string
+		issues []parsedIssue
+	}{{"A", issuesA}, {"B", issuesB}} {
+		sb.WriteString("List " + list.name + ":\n")
+		for i, issue := range list.issues {
+			fmt.Fprintf(&sb, "%d. %s\n", i+1, strings.TrimSpace(issue.Text))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("Reply ONLY with JSON: {\"matches\":[{\"a\":<number in A>,\"b\":<number in B>}]}; use an empty list when nothing matches.\n")
+	return sb.String()
+}
+
+// normalizePriority maps labels such as "p1", "P0 (Critical)" or "not an issue"
+// onto the priority constants. It returns "" for anything unrecognized.
+func normalizePriority(raw string) string {
+	lower := strings.ToLower(strings.TrimSpace(raw))
+	switch {
+	case strings.HasPrefix(lower, "p0"):
+		return priorityP0
+	case strings.HasPrefix(lower, "p1"):
+		return priorityP1
+	case strings.HasPrefix(lower, "p2"), strings.HasPrefix(lower, "p3"):
+		return priorityP2
+	case lower == priorityNone, strings.HasPrefix(lower, "not an issue"), lower == "not_an_issue":
+		return priorityNone
+	}
+	return ""
+}
+
+func parseSeverityClassification(raw string) (severityDecision, error) {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return severityDecision{}, errors.New("empty severity response")
+	}
+	jsonBlock := extractJSONBlock(trimmed)
+	var decision severityDecision
+	if err := json.Unmarshal([]byte(jsonBlock), &decision); err != nil {
+		return severityDecision{}, fmt.Errorf("invalid severity JSON: %v (raw=%q)", err, truncateForError(trimmed))
+	}
+	priority := normalizePriority(decision.Priority)
+	if priority == "" {
+		return severityDecision{}, fmt.Errorf("unknown severity %q", decision.Priority)
+	}
+	decision.Priority = priority
+	decision.Reason = strings.TrimSpace(decision.Reason)
+	return decision, nil
+}
+
+// buildSummaryReportPrompt creates a prompt for generating the review summary report.
+// A non-empty stamp selects append mode: the new report becomes a section headed by
+// the stamp and the prior summary is kept verbatim below it.
+func buildSummaryReportPrompt(task string, result *Result, outputPath string, stamp string, prior string) string {
 	var sb strings.Builder
 	sb.WriteString("Task / PR context:\n")
 	sb.WriteString(task)
@@ -861,6 +1054,13 @@ func buildSummaryReportPrompt(task string, result *Result, outputPath string) st
 		for i, issue := range result.Issues {
 			sb.WriteString(fmt.Sprintf("### Issue %d\n", i+1))
 			sb.WriteString(fmt.Sprintf("**Status**: %s\n", issue.Status))
+			if issue.Priority != "" {
+				if issue.FinderPriority != "" && issue.FinderPriority != issue.Priority {
+					sb.WriteString(fmt.Sprintf("**Priority**: %s (finder labeled %s)\n", issue.Priority, issue.FinderPriority))
+				} else {
+					sb.WriteString(fmt.Sprintf("**Priority**: %s\n", issue.Priority))
+				}
+			}
 			sb.WriteString(fmt.Sprintf("**Issue Text**: %s\n\n", issue.IssueText))
 
 			if issue.Status == commentConfirmed {
@@ -970,8 +1170,15 @@ func buildSummaryReportPrompt(task string, result *Result, outputPath string) st
 	sb.WriteString("Write the summary report to: ")
 	sb.WriteString(outputPath)
 	sb.WriteString("\n\n")
+	if stamp != "" {
+		writeSummaryHistoryStep(&sb, stamp, prior)
+	}
 	sb.WriteString("Report Format:\n")
-	sb.WriteString("# CODE REVIEW SUMMARY\n\n")
+	if stamp != "" {
+		sb.WriteString(fmt.Sprintf("# CODE REVIEW SUMMARY (%s)\n\n", stamp))
+	} else {
+		sb.WriteString("# CODE REVIEW SUMMARY\n\n")
+	}
 	sb.WriteString("## Review Overview\n")
 	sb.WriteString("- Task description\n")
 	sb.WriteString("- Overall status and summary\n")
@@ -995,3 +1202,18 @@ func buildSummaryReportPrompt(task string, result *Result, outputPath string) st
 
 	return sb.String()
 }
+
+// writeSummaryHistoryStep tells the agent to prepend this run's report to the
+// existing summary instead of replacing it.
+func writeSummaryHistoryStep(sb *strings.Builder, stamp string, prior string) {
+	sb.WriteString("History mode: this file keeps a running review history.\n")
+	sb.WriteString(fmt.Sprintf("- Put the new report first, under the heading `# CODE REVIEW SUMMARY (%s)`.\n", stamp))
+	if strings.TrimSpace(prior) == "" {
+		sb.WriteString("- There is no earlier summary yet, so the new section is the whole file.\n\n")
+		return
+	}
+	sb.WriteString("- Below the new section, add a `---` line and then the previous summary exactly as shown here. Do not edit, reorder or trim it.\n\n")
+	sb.WriteString("<<<PREVIOUS_SUMMARY\n")
+	sb.WriteString(strings.TrimSpace(prior))
+	sb.WriteString("\nPREVIOUS_SUMMARY\n\n")
+}
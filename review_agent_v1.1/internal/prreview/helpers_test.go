@@ -7,7 +7,7 @@ import (
 
 func TestBuildIssueFinderPromptContainsInstructions(t *testing.T) {
 	task := "https://github.com/org/repo/pull/42"
-	got := buildIssueFinderPrompt(task, "/workspace/change_analysis.md")
+	got := buildIssueFinderPrompt(task, "/workspace/change_analysis.md", StudyDeep)
 
 	required := []string{
 		"Task: " + task,
@@ -16,7 +16,6 @@ func TestBuildIssueFinderPromptContainsInstructions(t *testing.T) {
 		"Review the code changes against the base branch",
 		"git merge-base HEAD BASE_BRANCH",
 		"git diff MERGE_BASE_SHA",
-		"Analyze the code changes and identify critical P0/P1 issues only.",
 		"FINAL RESPONSE:",
 		"critical P0/P1 issue report",
 		"Do not include non-critical issues or general commentary.",
@@ -32,6 +31,62 @@ func TestBuildIssueFinderPromptContainsInstructions(t *testing.T) {
 	}
 }
 
+func TestBuildIssueFinderPromptHonorsStudyIntensity(t *testing.T) {
+	deep := buildIssueFinderPrompt("task", "", StudyDeep)
+	light := buildIssueFinderPrompt("task", "", StudyLight)
+	standard := buildIssueFinderPrompt("task", "", StudyStandard)
+
+	if !strings.Contains(light, lightStudyLine) || strings.Contains(light, universalStudyLine) {
+		t.Fatalf("light prompt should use the terse study line")
+	}
+	if !strings.Contains(standard, standardStudyLine) {
+		t.Fatalf("standard prompt missing standard study line")
+	}
+	if strings.Contains(light, "Step 2: Deep Context Understanding") {
+		t.Fatalf("light prompt should not include the deep context mandate")
+	}
+	if !(len(light) < len(standard) && len(standard) < len(deep)) {
+		t.Fatalf("expected light < standard < deep, got %d/%d/%d", len(light), len(standard), len(deep))
+	}
+	if !strings.Contains(light, "FINAL RESPONSE:") {
+		t.Fatalf("light prompt should keep the final response contract")
+	}
+}
+
+func TestBuildSummaryReportPromptAppendKeepsPriorSummary(t *testing.T) {
+	result := &Result{Status: statusClean, Summary: "Clean PR"}
+	prior := "# CODE REVIEW SUMMARY (2024-01-01T00:00:00Z)\nold findings"
+
+	got := buildSummaryReportPrompt("task", result, "/ws/review_summary.md", "2024-02-01T00:00:00Z", prior)
+	if !strings.Contains(got, "# CODE REVIEW SUMMARY (2024-02-01T00:00:00Z)") {
+		t.Fatalf("append prompt missing timestamped heading")
+	}
+	if !strings.Contains(got, "<<<PREVIOUS_SUMMARY\n"+prior+"\nPREVIOUS_SUMMARY") {
+		t.Fatalf("append prompt should embed the prior summary verbatim")
+	}
+
+	overwrite := buildSummaryReportPrompt("task", result, "/ws/review_summary.md", "", "")
+	if strings.Contains(overwrite, "History mode") || !strings.Contains(overwrite, "# CODE REVIEW SUMMARY\n") {
+		t.Fatalf("overwrite prompt should keep the original format")
+	}
+}
+
+func TestParseSeverityClassificationNormalizesPriority(t *testing.T) {
+	got, err := parseSeverityClassification("```json\n{\"priority\":\"p2 (medium)\",\"reason\":\" narrow \"}\n```")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Priority != priorityP2 || got.Reason != "narrow" {
+		t.Fatalf("unexpected decision: %#v", got)
+	}
+	if _, err := parseSeverityClassification(`{"priority":"urgent"}`); err == nil {
+		t.Fatalf("expected error for unknown priority")
+	}
+	if !strings.Contains(buildSeverityClassificationPrompt("task", "issue"), severityDefinitionsBlock) {
+		t.Fatalf("classification prompt should carry the severity definitions")
+	}
+}
+
 func TestBuildHasRealIssuePromptContainsContractAndSentinel(t *testing.T) {
 	prompt := buildHasRealIssuePrompt("No P0/P1 issues found")
 	required := []string{
@@ -50,12 +105,11 @@ func TestBuildHasRealIssuePromptContainsContractAndSentinel(t *testing.T) {
 }
 
 func TestBuildReviewerPromptContainsRoleDirectives(t *testing.T) {
-	prompt := buildLogicAnalystPrompt("some task", "some issue", "/workspace/change_analysis.md")
+	prompt := buildLogicAnalystPrompt("some task", "some issue", "/workspace/change_analysis.md", StudyDeep, "")
 	requiredPhrases := []string{
 		"REVIEWER",
 		universalStudyLine,
 		"Simulate a group of senior programmers",
-		"Chesterton's Fence",
 		"VERDICT",
 		"Change Analysis at:",
 		p0p1VerdictGateBlock,
@@ -69,8 +123,32 @@ func TestBuildReviewerPromptContainsRoleDirectives(t *testing.T) {
 	}
 }
 
+func TestReviewerAndVerifyPromptsStance(t *testing.T) {
+	reviewer := buildLogicAnalystPrompt("task", "issue", "", StudyDeep, "")
+	verify := buildVerifyAgentPrompt("task", "issue", "", "", StudyDeep, "")
+	if !strings.Contains(reviewer, stanceBlocks[DefaultStance]) || !strings.Contains(verify, stanceBlocks[DefaultStance]) {
+		t.Fatal("unset stance should apply the default to the reviewer and the verify agent")
+	}
+	reviewer = buildLogicAnalystPrompt("task", "issue", "", StudyDeep, StanceConfirming)
+	verify = buildVerifyAgentPrompt("task", "issue", "", "", StudyDeep, StanceConfirming)
+	for name, prompt := range map[string]string{"reviewer": reviewer, "verify": verify} {
+		if !strings.Contains(prompt, stanceBlocks[StanceConfirming]) {
+			t.Errorf("%s prompt missing confirming stance", name)
+		}
+		if strings.Contains(prompt, stanceBlocks[StanceSkeptical]) || strings.Contains(prompt, stanceBlocks[StanceBalanced]) {
+			t.Errorf("%s prompt kept the default stance", name)
+		}
+	}
+	if stance, err := ParseStance(" Balanced "); err != nil || stance != StanceBalanced {
+		t.Fatalf("ParseStance should normalize case and space, got %q, %v", stance, err)
+	}
+	if _, err := ParseStance("lenient"); err == nil {
+		t.Fatal("expected an unknown stance to be rejected")
+	}
+}
+
 func TestBuildTesterPromptContainsRoleDirectives(t *testing.T) {
-	prompt := buildTesterPrompt("some task", "some issue", "/workspace/change_analysis.md")
+	prompt := buildTesterPrompt("some task", "some issue", "/workspace/change_analysis.md", StudyDeep)
 	requiredPhrases := []string{
 		"TESTER",
 		universalStudyLine,
@@ -94,7 +172,7 @@ func TestBuildTesterPromptContainsRoleDirectives(t *testing.T) {
 }
 
 func TestBuildExchangePromptIncludesSelfPeerAndReviewerGuidance(t *testing.T) {
-	prompt := buildExchangePrompt("reviewer", "task", "issue", "/workspace/change_analysis.md", "my old verdict", "peer said hello")
+	prompt := buildExchangePrompt("reviewer", "task", "issue", "/workspace/change_analysis.md", "my old verdict", "peer said hello", StudyDeep)
 	required := []string{
 		"my old verdict",
 		"peer said hello",
@@ -112,7 +190,7 @@ func TestBuildExchangePromptIncludesSelfPeerAndReviewerGuidance(t *testing.T) {
 }
 
 func TestBuildExchangePromptProvidesTesterGuidance(t *testing.T) {
-	prompt := buildExchangePrompt("tester", "task", "issue", "/workspace/change_analysis.md", "my reproduction log", "peer logic view")
+	prompt := buildExchangePrompt("tester", "task", "issue", "/workspace/change_analysis.md", "my reproduction log", "peer logic view", StudyDeep)
 	required := []string{
 		"my reproduction log",
 		"peer logic view",
@@ -149,14 +227,14 @@ func TestBuildAlignmentPromptContainsInputs(t *testing.T) {
 }
 
 func TestBuildScoutPromptWritesToPath(t *testing.T) {
-	prompt := buildScoutPrompt("task", "/workspace/change_analysis.md")
+	prompt := buildScoutPrompt("task", "/workspace/change_analysis.md", StudyDeep)
 	required := []string{
 		"Role: SCOUT",
 		universalStudyLine,
 		"Write the analysis to:",
 		"/workspace/change_analysis.md",
 		"# CHANGE ANALYSIS",
-		"High-Risk Areas (ranked)",
+		"High-Risk Areas (ranked by severity)",
 		"Before -> After",
 		"git merge-base HEAD BASE_BRANCH",
 		"git diff --name-status MERGE_BASE_SHA",
@@ -0,0 +1,57 @@
+package prreview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromptVersion names the prompt generation this package renders (the
+// quantity-mandate finder). review_agent carries the SOP-based v1.0 generation.
+// Results and baseline caches record it as prompt_version.
+const PromptVersion = "v1.1"
+
+// Prompt stages that RenderPrompt can render.
+const (
+	StageFinder   = "finder"
+	StageScout    = "scout"
+	StageReviewer = "reviewer"
+	StageTester   = "tester"
+)
+
+// SampleTask is the task RenderPrompt falls back to when none is given.
+const SampleTask = "Review the PR that adds exponential backoff to the HTTP client's retry loop."
+
+const (
+	sampleIssueText    = "ISSUE: retry loop never resets the backoff after a success (client/retry.go:42), so later failures wait the maximum delay. Severity: P1"
+	sampleAnalysisPath = "/workspace/" + changeAnalysisFilename
+)
+
+// RenderPrompt returns the prompt this version sends for stage at the given study
+// intensity (empty means StudyDeep, the CLI default), using task and fixed sample
+// inputs so the prompt generations can be compared in isolation.
+func RenderPrompt(stage, task, intensity string) (string, error) {
+	task = strings.TrimSpace(task)
+	if task == "" {
+		task = SampleTask
+	}
+	intensity = strings.ToLower(strings.TrimSpace(intensity))
+	switch intensity {
+	case "":
+		intensity = StudyDeep
+	case StudyLight, StudyStandard, StudyDeep:
+	default:
+		return "", fmt.Errorf("unknown study intensity %q (want light, standard or deep)", intensity)
+	}
+	switch strings.ToLower(strings.TrimSpace(stage)) {
+	case StageFinder:
+		return buildIssueFinderPrompt(task, sampleAnalysisPath, intensity), nil
+	case StageScout:
+		return buildScoutPrompt(task, sampleAnalysisPath, intensity), nil
+	case StageReviewer:
+		return buildLogicAnalystPrompt(task, sampleIssueText, sampleAnalysisPath, intensity, ""), nil
+	case StageTester:
+		return buildTesterPrompt(task, sampleIssueText, sampleAnalysisPath, intensity), nil
+	default:
+		return "", fmt.Errorf("unknown prompt stage %q (want finder, scout, reviewer or tester)", stage)
+	}
+}
@@ -0,0 +1,29 @@
+package prreview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPromptCoversEachStageAndIntensity(t *testing.T) {
+	for _, stage := range []string{StageFinder, StageScout, StageReviewer, StageTester} {
+		prompt, err := RenderPrompt(stage, "", "")
+		if err != nil {
+			t.Fatalf("%s: %v", stage, err)
+		}
+		if !strings.Contains(prompt, SampleTask) {
+			t.Fatalf("%s: expected the sample task in %q", stage, prompt)
+		}
+	}
+	deep, _ := RenderPrompt(StageFinder, "task", "")
+	light, _ := RenderPrompt(StageFinder, "task", StudyLight)
+	if deep == light {
+		t.Fatal("expected study intensity to change the finder prompt")
+	}
+	if _, err := RenderPrompt(StageFinder, "", "extreme"); err == nil {
+		t.Fatal("expected unknown intensity to be rejected")
+	}
+	if _, err := RenderPrompt("summary", "", ""); err == nil {
+		t.Fatal("expected unknown stage to be rejected")
+	}
+}
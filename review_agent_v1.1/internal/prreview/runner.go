@@ -1,6 +1,7 @@
 package prreview
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	b "review_agent/internal/brain"
+	"review_agent/internal/exitcodes"
 	"review_agent/internal/logx"
 	"review_agent/internal/streaming"
 	t "review_agent/internal/tools"
@@ -22,6 +24,21 @@ const (
 	commentUnresolved = "unresolved"
 )
 
+// Study intensities select how verbose the exploration mandates in agent prompts are.
+const (
+	StudyLight    = "light"
+	StudyStandard = "standard"
+	StudyDeep     = "deep"
+)
+
+// Summary modes control how review_summary.md treats a summary left by an earlier run.
+const (
+	SummaryOverwrite = "overwrite"
+	SummaryAppend    = "append"
+)
+
+const summaryFilename = "review_summary.md"
+
 // Options configures the PR review workflow.
 type Options struct {
 	Task           string
@@ -30,6 +47,45 @@ type Options struct {
 	WorkspaceDir   string
 	SkipScout      bool
 	SkipTester     bool
+	// StudyIntensity is one of StudyLight, StudyStandard or StudyDeep (default).
+	StudyIntensity string
+	// SummaryMode is SummaryOverwrite (default) or SummaryAppend, which keeps earlier
+	// summaries below a new timestamped section.
+	SummaryMode string
+	// ReclassifySeverity re-rates each parsed issue with an independent severity
+	// prompt and drops those that fall below P1.
+	ReclassifySeverity bool
+	// MaxDuration is the deadline applied to the run's LLM calls; zero means none.
+	MaxDuration time.Duration
+	// JSONMode asks the provider to enforce JSON output on the triage, parse,
+	// severity and alignment calls; unsupported deployments fall back silently.
+	JSONMode bool
+	// AuxSystemPrompts overrides the system prompts of the auxiliary JSON calls,
+	// keyed by AuxIssueCheck, AuxAlignment, AuxIssueSplit or AuxSeverity.
+	AuxSystemPrompts map[string]string
+	// Suppressions move matching parsed issues to Result.SuppressedIssues
+	// instead of reporting them.
+	Suppressions []SuppressionRule
+	// MaxAuxInputChars is the longest report the has_issue and issue-split calls
+	// receive in one piece; longer reports are split at section markers and the
+	// answers merged. Zero means DefaultMaxAuxInputChars.
+	MaxAuxInputChars int
+	// Stance is the reviewer and verify-agent bias: StanceSkeptical (default),
+	// StanceBalanced or StanceConfirming.
+	Stance string
+	// BaselineBranchID, when set, has an agent on this branch check whether each
+	// issue found is already in the baseline code, and moves those to
+	// Result.PreExistingIssues, so only issues the PR introduces are reported.
+	BaselineBranchID string
+	// BaselineCacheDir keeps each baseline's per-issue verdicts so later runs
+	// against the same baseline branch only check new issues; empty disables caching.
+	BaselineCacheDir string
+	// Subject names the reviewed change in Result.Headline, e.g. "PR #123"; empty
+	// means the parent branch.
+	Subject string
+	// ChangedFiles is how many files the change touches, when the caller knows;
+	// zero leaves the count out of Result.Headline.
+	ChangedFiles int
 }
 
 // Result captures the high-level outcome plus supporting artifacts.
@@ -43,6 +99,19 @@ type Result struct {
 	LatestBranchID   string            `json:"latest_branch_id,omitempty"`
 	SummaryBranchID  string            `json:"summary_branch_id,omitempty"`
 	ReviewStatistics *ReviewStatistics `json:"review_statistics,omitempty"`
+	SuppressedIssues []SuppressedIssue `json:"suppressed_issues,omitempty"`
+	// PreExistingIssues are issues the baseline check found already present in
+	// the baseline branch's code.
+	PreExistingIssues []PreExistingIssue `json:"pre_existing_issues,omitempty"`
+	PromptVersion     string             `json:"prompt_version,omitempty"`
+	// Headline is a one-sentence TL;DR of the outcome with confirmed and
+	// unresolved issues broken down by priority.
+	Headline string `json:"headline,omitempty"`
+	// TokenUsage counts this process's own LLM calls, all auxiliary JSON calls;
+	// the review itself runs in MCP branches, which report no tokens.
+	TokenUsage map[string]b.Usage `json:"token_usage,omitempty"`
+	// EstimatedCostUSD prices TokenUsage when a price table was given.
+	EstimatedCostUSD map[string]float64 `json:"estimated_cost_usd,omitempty"`
 }
 
 // ReviewStatistics tracks the review process statistics
@@ -81,6 +150,13 @@ type IssueStatistic struct {
 	AbnormalSteps     []string `json:"abnormal_steps,omitempty"`
 }
 
+// HasFindings reports whether the review ended with P0/P1 issues, which the
+// CLI turns into the Findings exit status under --fail-on-issues. v1.1 does
+// not verify issues, so every reported one counts.
+func (r *Result) HasFindings() bool {
+	return r != nil && r.Status == statusIssues
+}
+
 // ReviewerLog records the raw output from each review_code run.
 type ReviewerLog struct {
 	BranchID string `json:"branch_id"`
@@ -97,6 +173,22 @@ type Transcript struct {
 	VerdictReason string `json:"verdict_reason,omitempty"`
 }
 
+// headlineStatuses are the issue statuses Result.Headline counts, in order.
+var headlineStatuses = []headlineStatus{
+	{commentConfirmed, "confirmed"},
+	{commentUnresolved, "unresolved"},
+}
+
+// issuePriority is the headline bucket of an issue: its classified priority, or
+// the label in its text when it has none.
+func issuePriority(issue IssueReport) string {
+	switch issue.Priority {
+	case priorityP0, priorityP1:
+		return issue.Priority
+	}
+	return priorityFromText(issue.IssueText)
+}
+
 // IssueReport stores the consensus outcome for a single ISSUE block.
 type IssueReport struct {
 	IssueText                 string     `json:"issue_text"`
@@ -111,9 +203,32 @@ type IssueReport struct {
 	VerifyAgentRound3BranchID string     `json:"verify_agent_round3_branch_id,omitempty"`
 	ExchangeRounds            int        `json:"exchange_rounds,omitempty"`
 	VerdictExplanation        string     `json:"verdict_explanation,omitempty"`
+	Priority                  string     `json:"priority,omitempty"`
+	// FinderPriority keeps the finder's own label when severity reclassification ran.
+	FinderPriority string `json:"finder_priority,omitempty"`
+	SeverityReason string `json:"severity_reason,omitempty"`
 	// Keep Tester fields for backward compatibility
 	TesterRound1BranchID string `json:"tester_round1_branch_id,omitempty"`
 	TesterRound2BranchID string `json:"tester_round2_branch_id,omitempty"`
+	// Fingerprint is the IssueFingerprint a suppression rule can name.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Rounds lists the branch of every round that ran for this issue, in order.
+	// The fixed Round1/2/3 fields above are kept for existing consumers.
+	Rounds []RoundBranch `json:"rounds,omitempty"`
+}
+
+// Round roles recorded in IssueReport.Rounds.
+const (
+	RoleReviewer    = "reviewer"
+	RoleTester      = "tester"
+	RoleVerifyAgent = "verify_agent"
+)
+
+// RoundBranch is one round of an issue's verification and the branch it ran on.
+type RoundBranch struct {
+	Role     string `json:"role"`
+	Round    int    `json:"round"`
+	BranchID string `json:"branch_id"`
 }
 
 // Runner executes the two-phase PR review workflow.
@@ -128,6 +243,12 @@ type Runner struct {
 	alignmentOverride func(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error)
 	// hasRealIssueOverride is a test hook to avoid network calls in Run().
 	hasRealIssueOverride func(reportText string) (bool, error)
+	// parseIssuesOverride is a test hook to avoid network calls in parseIssueChunk().
+	parseIssuesOverride func(reportText string) ([]parsedIssue, error)
+	// matchIssuesOverride is a test hook to avoid network calls in matchIssues().
+	matchIssuesOverride func(a, b []parsedIssue) ([]issuePair, error)
+	// severityOverride is a test hook to avoid network calls in classifySeverity().
+	severityOverride func(issueText string) (severityDecision, error)
 
 	// Statistics tracking
 	statistics *ReviewStatistics
@@ -145,7 +266,49 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	opts.Task = strings.TrimSpace(opts.Task)
 	opts.ProjectName = strings.TrimSpace(opts.ProjectName)
 	opts.ParentBranchID = strings.TrimSpace(opts.ParentBranchID)
+	opts.Subject = strings.TrimSpace(opts.Subject)
 	opts.WorkspaceDir = strings.TrimSpace(opts.WorkspaceDir)
+	opts.BaselineBranchID = strings.TrimSpace(opts.BaselineBranchID)
+	if opts.BaselineBranchID != "" && opts.BaselineBranchID == opts.ParentBranchID {
+		return nil, errors.New("baseline branch must differ from the parent branch")
+	}
+	opts.StudyIntensity = strings.ToLower(strings.TrimSpace(opts.StudyIntensity))
+	switch opts.StudyIntensity {
+	case "":
+		opts.StudyIntensity = StudyDeep
+	case StudyLight, StudyStandard, StudyDeep:
+	default:
+		return nil, fmt.Errorf("unknown study intensity %q (want light, standard or deep)", opts.StudyIntensity)
+	}
+	opts.SummaryMode = strings.ToLower(strings.TrimSpace(opts.SummaryMode))
+	switch opts.SummaryMode {
+	case "":
+		opts.SummaryMode = SummaryOverwrite
+	case SummaryOverwrite, SummaryAppend:
+	default:
+		return nil, fmt.Errorf("unknown summary mode %q (want overwrite or append)", opts.SummaryMode)
+	}
+	stance, err := ParseStance(opts.Stance)
+	if err != nil {
+		return nil, err
+	}
+	opts.Stance = stance
+	auxPrompts, err := normalizeAuxSystemPrompts(opts.AuxSystemPrompts)
+	if err != nil {
+		return nil, err
+	}
+	opts.AuxSystemPrompts = auxPrompts
+	if opts.MaxAuxInputChars < 0 {
+		return nil, fmt.Errorf("max aux input chars must not be negative (got %d)", opts.MaxAuxInputChars)
+	}
+	if opts.MaxAuxInputChars == 0 {
+		opts.MaxAuxInputChars = DefaultMaxAuxInputChars
+	}
+	suppressions, err := compileSuppressions(opts.Suppressions)
+	if err != nil {
+		return nil, err
+	}
+	opts.Suppressions = suppressions
 	if opts.Task == "" {
 		return nil, errors.New("task description is required")
 	}
@@ -155,6 +318,13 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	if opts.ParentBranchID == "" {
 		return nil, errors.New("parent branch id is required")
 	}
+	if opts.WorkspaceDir == "" {
+		users := "the issue finder (reads " + handler.ReviewArtifactName() + "), the summary report (writes " + summaryFilename + ")"
+		if !opts.SkipScout {
+			users += ", the scout (writes " + changeAnalysisFilename + ")"
+		}
+		return nil, workspaceDirError(users)
+	}
 	return &Runner{
 		brain:    brain,
 		handler:  handler,
@@ -174,12 +344,22 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 // Run executes the workflow and returns the structured result.
 func (r *Runner) Run() (*Result, error) {
 	logx.Infof("Starting PR review workflow for parent %s", r.opts.ParentBranchID)
+	// Every local LLM call is an auxiliary JSON call for token accounting; the
+	// review itself runs in MCP branches.
+	ctx := b.WithCallKind(context.Background(), b.CallAuxiliary)
+	if r.opts.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.opts.MaxDuration)
+		defer cancel()
+	}
+	started := time.Now()
 	parent := r.opts.ParentBranchID
 
 	result := &Result{
-		Task:         r.opts.Task,
-		ReviewerLogs: []ReviewerLog{},
-		Issues:       []IssueReport{},
+		Task:          r.opts.Task,
+		ReviewerLogs:  []ReviewerLog{},
+		Issues:        []IssueReport{},
+		PromptVersion: PromptVersion,
 	}
 
 	scoutBranchID := parent
@@ -215,11 +395,12 @@ func (r *Runner) Run() (*Result, error) {
 		result.Status = statusClean
 		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
 		r.attachBranchRange(result)
+		result.Headline = r.headline(result, time.Since(started))
 		return result, nil
 	}
 
 	// Check if the report actually describes a real issue
-	hasIssue, err := r.hasRealIssue(reviewLog.Report)
+	hasIssue, err := r.hasRealIssue(ctx, reviewLog.Report)
 	if err != nil {
 		return nil, err
 	}
@@ -227,20 +408,39 @@ func (r *Runner) Run() (*Result, error) {
 		result.Status = statusClean
 		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
 		r.attachBranchRange(result)
+		result.Headline = r.headline(result, time.Since(started))
 		return result, nil
 	}
 
 	// Parse and split issues from the review report
-	issues, err := r.parseIssuesFromReport(reviewLog.Report)
+	issues, err := r.parseIssuesFromReport(ctx, reviewLog.Report)
 	if err != nil {
 		logx.Warningf("Failed to parse issues from report, treating as single issue: %v", err)
-		issues = []string{reviewLog.Report}
+		issues = []parsedIssue{{Text: reviewLog.Report}}
+	}
+
+	if r.opts.ReclassifySeverity {
+		issues = r.reclassifyIssues(ctx, issues)
+	}
+	issues, result.SuppressedIssues = r.suppressIssues(issues)
+
+	if len(issues) > 0 && r.opts.BaselineBranchID != "" {
+		r.recordStepStart("baseline")
+		baselineStartTime := time.Now()
+		if baseline, err := r.baselinePreExisting(issues); err != nil {
+			logx.Warningf("Baseline check soft-failed; reporting all issues. err=%v", err)
+			r.recordAbnormalStep("baseline", fmt.Sprintf("Baseline check soft-failed: %v", err))
+		} else {
+			issues, result.PreExistingIssues = splitPreExisting(issues, baseline)
+		}
+		r.recordStepEnd("baseline", time.Since(baselineStartTime))
 	}
 
 	if len(issues) == 0 {
 		result.Status = statusClean
-		result.Summary = "Clean PR: Not found any blocking P0/P1 issues."
+		result.Summary = "Clean PR: Not found any blocking P0/P1 issues." + suppressedNote(result.SuppressedIssues) + preExistingNote(result.PreExistingIssues)
 		r.attachBranchRange(result)
+		result.Headline = r.headline(result, time.Since(started))
 		return result, nil
 	}
 
@@ -248,11 +448,16 @@ func (r *Runner) Run() (*Result, error) {
 	logx.Infof("Parsed %d issues from review report", numIssues)
 
 	// Convert issues to IssueReport without verification
-	for _, issueText := range issues {
+	for _, issue := range issues {
 		result.Issues = append(result.Issues, IssueReport{
-			IssueText:              issueText,
+			IssueText:              issue.Text,
 			Status:                 statusIssues, // Mark as unresolved since we skip verification
 			ReviewerRound1BranchID: reviewLog.BranchID,
+			Priority:               issue.Priority,
+			FinderPriority:         issue.FinderPriority,
+			SeverityReason:         issue.SeverityReason,
+			Fingerprint:            IssueFingerprint(issue.Text),
+			Rounds:                 []RoundBranch{{Role: RoleReviewer, Round: 1, BranchID: reviewLog.BranchID}},
 		})
 	}
 
@@ -263,7 +468,7 @@ func (r *Runner) Run() (*Result, error) {
 	}
 
 	result.Status = statusIssues
-	result.Summary = fmt.Sprintf("Identified %d P0/P1 issues.", len(result.Issues))
+	result.Summary = fmt.Sprintf("Identified %d P0/P1 issues.", len(result.Issues)) + suppressedNote(result.SuppressedIssues) + preExistingNote(result.PreExistingIssues)
 	r.attachBranchRange(result)
 
 	// Finalize statistics
@@ -284,6 +489,7 @@ func (r *Runner) Run() (*Result, error) {
 		logx.Infof("Summary report successfully generated in branch: %s", summaryBranchID)
 	}
 
+	result.Headline = r.headline(result, time.Since(started))
 	return result, nil
 }
 
@@ -327,7 +533,9 @@ func (r *Runner) recordAbnormalStep(stepName string, description string) {
 	})
 }
 
-// finalizeStatistics finalizes the statistics
+// finalizeStatistics finalizes the statistics. Per-issue round counts come
+// from IssueReport.Rounds, so they stay right however many exchange rounds an
+// issue went through. Steps counts reviewer and verify-agent rounds.
 func (r *Runner) finalizeStatistics(result *Result) {
 	if r.statistics == nil {
 		return
@@ -338,33 +546,45 @@ func (r *Runner) finalizeStatistics(result *Result) {
 	// Calculate issue statistics
 	for _, issue := range result.Issues {
 		stat := IssueStatistic{
-			IssueText:      issue.IssueText,
-			ReviewerRounds: 1,
-			TesterRounds:   1,
-		}
-		if issue.ExchangeRounds > 0 {
-			if issue.ExchangeRounds == 1 {
-				stat.ReviewerRounds = 2
-			} else if issue.ExchangeRounds == 2 {
-				stat.ReviewerRounds = 3
-			}
-		}
-		if issue.Beta.BranchID != "" {
-			stat.VerifyAgentRounds = issue.ExchangeRounds + 1 // Round 1 + exchange rounds
+			IssueText:         issue.IssueText,
+			ReviewerRounds:    recordedRounds(issue.Rounds, RoleReviewer),
+			TesterRounds:      recordedRounds(issue.Rounds, RoleTester),
+			VerifyAgentRounds: recordedRounds(issue.Rounds, RoleVerifyAgent),
 		}
 		stat.Steps = stat.ReviewerRounds + stat.VerifyAgentRounds
 		r.statistics.IssueStatistics[issue.IssueText] = stat
 	}
 }
 
+// recordedRounds counts the rounds of role that ran, i.e. left a branch.
+func recordedRounds(rounds []RoundBranch, role string) int {
+	n := 0
+	for _, round := range rounds {
+		if round.Role == role && round.BranchID != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// workspaceDirError names the stages that need the workspace dir and how to set it.
+func workspaceDirError(users string) error {
+	return exitcodes.NewConfigError(fmt.Errorf("workspace dir is required by %s: set WORKSPACE_DIR to the agent's workspace path", users))
+}
+
 // generateSummaryReport creates a summary report in the parent branch
 func (r *Runner) generateSummaryReport(parentBranchID string, result *Result) (string, error) {
 	if strings.TrimSpace(r.opts.WorkspaceDir) == "" {
-		return "", errors.New("workspace dir is required for summary report output")
+		return "", workspaceDirError("the summary report (writes " + summaryFilename + ")")
 	}
 
-	reportPath := filepath.Join(r.opts.WorkspaceDir, "review_summary.md")
-	prompt := buildSummaryReportPrompt(r.opts.Task, result, reportPath)
+	reportPath := filepath.Join(r.opts.WorkspaceDir, summaryFilename)
+	stamp, prior := "", ""
+	if r.opts.SummaryMode == SummaryAppend {
+		stamp = time.Now().UTC().Format(time.RFC3339)
+		prior = r.readPriorSummary(parentBranchID, reportPath)
+	}
+	prompt := buildSummaryReportPrompt(r.opts.Task, result, reportPath, stamp, prior)
 
 	data, err := r.executeAgent("codex", prompt, parentBranchID)
 	if err != nil {
@@ -380,6 +600,20 @@ func (r *Runner) generateSummaryReport(parentBranchID string, result *Result) (s
 	return branchID, nil
 }
 
+// readPriorSummary returns the summary an earlier run left on the branch. A missing
+// or unreadable file just starts a fresh history.
+func (r *Runner) readPriorSummary(branchID, reportPath string) string {
+	artifact, err := r.callTool("read_artifact", map[string]any{
+		"branch_id": branchID,
+		"path":      reportPath,
+	})
+	if err != nil {
+		logx.Infof("No prior summary at %s on branch %s (%v); starting a new history.", reportPath, branchID, err)
+		return ""
+	}
+	return strings.TrimSpace(stringField(artifact, "content"))
+}
+
 func (r *Runner) attachBranchRange(res *Result) {
 	if res == nil {
 		return
@@ -397,7 +631,7 @@ func (r *Runner) attachBranchRange(res *Result) {
 }
 
 func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath string) (ReviewerLog, error) {
-	prompt := buildIssueFinderPrompt(r.opts.Task, changeAnalysisPath)
+	prompt := buildIssueFinderPrompt(r.opts.Task, changeAnalysisPath, r.opts.StudyIntensity)
 	data, err := r.executeAgent("review_code", prompt, parentBranchID)
 	if err != nil {
 		return ReviewerLog{}, err
@@ -405,7 +639,7 @@ func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath strin
 	branchID := stringField(data, "branch_id")
 	reviewLog := strings.TrimSpace(stringField(data, "review_report"))
 	if reviewLog == "" {
-		return ReviewerLog{}, fmt.Errorf("review_code did not include code_review.log contents")
+		return ReviewerLog{}, fmt.Errorf("review_code did not include %s contents", r.handler.ReviewArtifactName())
 	}
 	return ReviewerLog{
 		BranchID: branchID,
@@ -415,7 +649,7 @@ func (r *Runner) runSingleReview(parentBranchID string, changeAnalysisPath strin
 
 // runVerifyAgentReview runs an adversarial review using the same review mechanism
 func (r *Runner) runVerifyAgentReview(issueText string, changeAnalysisPath string, parentBranchID string, reviewerOpinion string) (Transcript, error) {
-	prompt := buildVerifyAgentPrompt(r.opts.Task, issueText, changeAnalysisPath, reviewerOpinion)
+	prompt := buildVerifyAgentPrompt(r.opts.Task, issueText, changeAnalysisPath, reviewerOpinion, r.opts.StudyIntensity, r.opts.Stance)
 
 	agent := "codex"
 	data, err := r.executeAgent(agent, prompt, parentBranchID)
@@ -434,9 +668,9 @@ func (r *Runner) runVerifyAgentReview(issueText string, changeAnalysisPath strin
 func (r *Runner) runRole(role string, issueText string, changeAnalysisPath string, parentBranchID string) (Transcript, error) {
 	var prompt string
 	if role == "reviewer" {
-		prompt = buildLogicAnalystPrompt(r.opts.Task, issueText, changeAnalysisPath)
+		prompt = buildLogicAnalystPrompt(r.opts.Task, issueText, changeAnalysisPath, r.opts.StudyIntensity, r.opts.Stance)
 	} else {
-		prompt = buildTesterPrompt(r.opts.Task, issueText, changeAnalysisPath)
+		prompt = buildTesterPrompt(r.opts.Task, issueText, changeAnalysisPath, r.opts.StudyIntensity)
 	}
 
 	agent := "codex"
@@ -454,7 +688,7 @@ func (r *Runner) runRole(role string, issueText string, changeAnalysisPath strin
 
 // runExchange executes Round 2 with both the agent's and peer's opinions.
 func (r *Runner) runExchange(role string, issueText string, changeAnalysisPath string, selfOpinion string, peerOpinion string, parentBranchID string) (Transcript, error) {
-	prompt := buildExchangePrompt(role, r.opts.Task, issueText, changeAnalysisPath, selfOpinion, peerOpinion)
+	prompt := buildExchangePrompt(role, r.opts.Task, issueText, changeAnalysisPath, selfOpinion, peerOpinion, r.opts.StudyIntensity)
 
 	agent := "codex"
 	data, err := r.executeAgent(agent, prompt, parentBranchID)
@@ -520,10 +754,10 @@ const changeAnalysisFilename = "change_analysis.md"
 
 func (r *Runner) runScout(parentBranchID string) (string, string, error) {
 	if strings.TrimSpace(r.opts.WorkspaceDir) == "" {
-		return "", "", errors.New("workspace dir is required for scout output")
+		return "", "", workspaceDirError("the scout (writes " + changeAnalysisFilename + ")")
 	}
 	analysisPath := filepath.Join(r.opts.WorkspaceDir, changeAnalysisFilename)
-	prompt := buildScoutPrompt(r.opts.Task, analysisPath)
+	prompt := buildScoutPrompt(r.opts.Task, analysisPath, r.opts.StudyIntensity)
 
 	resp, err := r.executeAgent("codex", prompt, parentBranchID)
 	if err != nil {
@@ -544,25 +778,34 @@ func (r *Runner) runScout(parentBranchID string) (string, string, error) {
 	return branchID, analysisPath, nil
 }
 
-func (r *Runner) hasRealIssue(reportText string) (bool, error) {
+// hasRealIssue asks whether the report describes any real issue; reports over the
+// auxiliary input limit are checked chunk by chunk, and any positive chunk wins.
+func (r *Runner) hasRealIssue(ctx context.Context, reportText string) (bool, error) {
+	return withChunking(reportText, r.opts.MaxAuxInputChars, func(chunk string) (bool, error) {
+		return r.hasRealIssueChunk(ctx, chunk)
+	}, anyTrue)
+}
+
+func (r *Runner) hasRealIssueChunk(ctx context.Context, reportText string) (bool, error) {
 	if r.hasRealIssueOverride != nil {
 		return r.hasRealIssueOverride(reportText)
 	}
 	prompt := buildHasRealIssuePrompt(reportText)
-	resp, err := r.brain.Complete([]b.ChatMessage{
-		{Role: "system", Content: "Analyze code review reports. Reply only with JSON."},
+	resp, err := r.brain.Complete(ctx, []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxIssueCheck)},
 		{Role: "user", Content: prompt},
-	}, nil)
+	}, nil, r.jsonCallOptions()...)
 	if err != nil {
 		return false, err
 	}
-	if resp == nil || len(resp.Choices) == 0 {
-		return false, fmt.Errorf("LLM response is empty or has no choices")
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		return false, fmt.Errorf("issue check: %w", err)
 	}
 	type issueCheck struct {
 		HasIssue bool `json:"has_issue"`
 	}
-	jsonBlock := extractJSONBlock(resp.Choices[0].Message.Content)
+	jsonBlock := extractJSONBlock(content)
 	var check issueCheck
 	if err := json.Unmarshal([]byte(jsonBlock), &check); err != nil {
 		return false, fmt.Errorf("failed to parse has_issue JSON: %w", err)
@@ -584,7 +827,7 @@ func (r *Runner) determineVerdict(transcript Transcript) (verdictDecision, error
 	}, nil
 }
 
-func (r *Runner) checkAlignment(issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
+func (r *Runner) checkAlignment(ctx context.Context, issueText string, alpha Transcript, beta Transcript) (alignmentVerdict, error) {
 	if r.alignmentOverride != nil {
 		return r.alignmentOverride(issueText, alpha, beta)
 	}
@@ -592,20 +835,17 @@ func (r *Runner) checkAlignment(issueText string, alpha Transcript, beta Transcr
 		return alignmentVerdict{}, errors.New("brain is required for alignment check")
 	}
 	prompt := buildAlignmentPrompt(issueText, alpha, beta)
-	resp, err := r.brain.Complete([]b.ChatMessage{
-		{Role: "system", Content: "Return JSON alignment verdicts for two transcripts. Reply only with JSON."},
+	resp, err := r.brain.Complete(ctx, []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxAlignment)},
 		{Role: "user", Content: prompt},
-	}, nil)
+	}, nil, r.jsonCallOptions()...)
 	if err != nil {
 		return alignmentVerdict{}, err
 	}
-	content := ""
-	if resp != nil && len(resp.Choices) > 0 {
-		content = resp.Choices[0].Message.Content
-	}
-	if strings.TrimSpace(content) == "" {
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
 		logx.Errorf("Alignment LLM returned empty content (issue=%q)", streaming.PromptPreview(issueText))
-		return alignmentVerdict{}, errors.New("alignment returned empty content")
+		return alignmentVerdict{}, fmt.Errorf("alignment: %w", err)
 	}
 	verdict, err := parseAlignment(content)
 	if err != nil {
@@ -615,6 +855,14 @@ func (r *Runner) checkAlignment(issueText string, alpha Transcript, beta Transcr
 	return verdict, nil
 }
 
+// jsonCallOptions returns the Complete options for auxiliary calls that must reply with JSON.
+func (r *Runner) jsonCallOptions() []b.CompleteOption {
+	if !r.opts.JSONMode {
+		return nil
+	}
+	return []b.CompleteOption{b.WithJSONMode()}
+}
+
 type eventHelper struct {
 	streamer *streaming.JSONStreamer
 	nextID   int64
@@ -718,21 +966,77 @@ func summarizeIssueCounts(reports []IssueReport) (confirmed, unresolved int) {
 	return
 }
 
+// parsedIssue is one issue split out of the review report, with its priority.
+type parsedIssue struct {
+	Text           string
+	Priority       string
+	FinderPriority string
+	SeverityReason string
+}
+
 // parseIssuesFromReport parses the review report to extract individual issues.
-// It uses LLM to identify and separate distinct P0/P1 issues from the report text.
-func (r *Runner) parseIssuesFromReport(reportText string) ([]string, error) {
-	// Use LLM to parse issues from the report
-	prompt := buildIssueParserPrompt(reportText)
-	resp, err := r.brain.Complete([]b.ChatMessage{
-		{Role: "system", Content: "Parse code review reports and extract individual P0/P1 issues. Reply only with JSON."},
+// It uses LLM to identify and separate distinct P0/P1 issues from the report text;
+// reports over the auxiliary input limit are parsed chunk by chunk.
+func (r *Runner) parseIssuesFromReport(ctx context.Context, reportText string) ([]parsedIssue, error) {
+	issues, err := withChunking(reportText, r.opts.MaxAuxInputChars, func(chunk string) ([]parsedIssue, error) {
+		return r.parseIssueChunk(ctx, chunk)
+	}, mergeParsedIssues)
+	if err != nil {
+		return nil, err
+	}
+
+	// If LLM explicitly returned empty array (e.g., "No P0/P1 issues found"), return empty
+	// Note: This should be rare since hasRealIssue already filtered these out
+	if len(issues) == 0 {
+		// Check if report explicitly says no issues
+		lowerReport := strings.ToLower(reportText)
+		if strings.Contains(lowerReport, "no p0/p1 issues found") ||
+			strings.Contains(lowerReport, "no p0/p1 issue") {
+			return []parsedIssue{}, nil
+		}
+		// Otherwise, fallback to treating entire report as single issue
+		logx.Warningf("LLM returned no issues with text, treating entire report as single issue")
+		return []parsedIssue{{Text: reportText}}, nil
+	}
+	return issues, nil
+}
+
+// mergeParsedIssues concatenates per-chunk issue lists, dropping repeats of the
+// same issue text (compared by IssueFingerprint).
+func mergeParsedIssues(results [][]parsedIssue) []parsedIssue {
+	seen := make(map[string]bool)
+	merged := []parsedIssue{}
+	for _, issues := range results {
+		for _, issue := range issues {
+			fingerprint := IssueFingerprint(issue.Text)
+			if seen[fingerprint] {
+				continue
+			}
+			seen[fingerprint] = true
+			merged = append(merged, issue)
+		}
+	}
+	return merged
+}
+
+// parseIssueChunk splits one report chunk into issues. A chunk whose reply is not
+// valid JSON becomes a single issue; a chunk without issues yields none.
+func (r *Runner) parseIssueChunk(ctx context.Context, chunk string) ([]parsedIssue, error) {
+	if r.parseIssuesOverride != nil {
+		return r.parseIssuesOverride(chunk)
+	}
+	prompt := buildIssueParserPrompt(chunk)
+	resp, err := r.brain.Complete(ctx, []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxIssueSplit)},
 		{Role: "user", Content: prompt},
-	}, nil)
+	}, nil, r.jsonCallOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp == nil || len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("LLM response is empty or has no choices")
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		return nil, fmt.Errorf("issue split: %w", err)
 	}
 
 	type issueList struct {
@@ -742,42 +1046,67 @@ func (r *Runner) parseIssuesFromReport(reportText string) ([]string, error) {
 		} `json:"issues"`
 	}
 
-	jsonBlock := extractJSONBlock(resp.Choices[0].Message.Content)
+	jsonBlock := extractJSONBlock(content)
 	var list issueList
 	if err := json.Unmarshal([]byte(jsonBlock), &list); err != nil {
-		// Fallback: treat entire report as single issue
-		logx.Warningf("Failed to parse JSON from LLM response, treating entire report as single issue: %v", err)
-		return []string{reportText}, nil
-	}
-
-	// If LLM explicitly returned empty array (e.g., "No P0/P1 issues found"), return empty
-	// Note: This should be rare since hasRealIssue already filtered these out
-	if len(list.Issues) == 0 {
-		// Check if report explicitly says no issues
-		lowerReport := strings.ToLower(reportText)
-		if strings.Contains(lowerReport, "no p0/p1 issues found") ||
-			strings.Contains(lowerReport, "no p0/p1 issue") {
-			return []string{}, nil
-		}
-		// Otherwise, fallback to treating entire report as single issue
-		logx.Warningf("LLM returned empty issues array, treating entire report as single issue")
-		return []string{reportText}, nil
+		// Fallback: treat the whole chunk as single issue
+		logx.Warningf("Failed to parse JSON from LLM response, treating the report chunk as single issue: %v", err)
+		return []parsedIssue{{Text: chunk}}, nil
 	}
 
-	issues := make([]string, 0, len(list.Issues))
+	issues := make([]parsedIssue, 0, len(list.Issues))
 	for _, issue := range list.Issues {
 		if strings.TrimSpace(issue.Text) != "" {
-			issues = append(issues, strings.TrimSpace(issue.Text))
+			issues = append(issues, parsedIssue{
+				Text:     strings.TrimSpace(issue.Text),
+				Priority: normalizePriority(issue.Priority),
+			})
 		}
 	}
+	return issues, nil
+}
 
-	if len(issues) == 0 {
-		// All issues had empty text, fallback to entire report
-		logx.Warningf("All parsed issues had empty text, treating entire report as single issue")
-		return []string{reportText}, nil
+// reclassifyIssues replaces the finder's priority with an independent rating and
+// drops issues rated below P1. An issue keeps its finder label when the
+// classifier fails, so a flaky call never hides a finding.
+func (r *Runner) reclassifyIssues(ctx context.Context, issues []parsedIssue) []parsedIssue {
+	kept := make([]parsedIssue, 0, len(issues))
+	for _, issue := range issues {
+		decision, err := r.classifySeverity(ctx, issue.Text)
+		if err != nil {
+			logx.Warningf("Severity reclassification soft-failed; keeping finder priority %q. err=%v", issue.Priority, err)
+			kept = append(kept, issue)
+			continue
+		}
+		issue.FinderPriority = issue.Priority
+		issue.Priority = decision.Priority
+		issue.SeverityReason = decision.Reason
+		if decision.Priority != priorityP0 && decision.Priority != priorityP1 {
+			logx.Infof("Dropping issue reclassified as %s (finder said %q): %s", decision.Priority, issue.FinderPriority, streaming.PromptPreview(issue.Text))
+			continue
+		}
+		kept = append(kept, issue)
 	}
+	return kept
+}
 
-	return issues, nil
+func (r *Runner) classifySeverity(ctx context.Context, issueText string) (severityDecision, error) {
+	if r.severityOverride != nil {
+		return r.severityOverride(issueText)
+	}
+	prompt := buildSeverityClassificationPrompt(r.opts.Task, issueText)
+	resp, err := r.brain.Complete(ctx, []b.ChatMessage{
+		{Role: "system", Content: r.auxSystemPrompt(AuxSeverity)},
+		{Role: "user", Content: prompt},
+	}, nil, r.jsonCallOptions()...)
+	if err != nil {
+		return severityDecision{}, err
+	}
+	content, err := b.RequireNonEmptyChoice(resp)
+	if err != nil {
+		return severityDecision{}, fmt.Errorf("severity classification: %w", err)
+	}
+	return parseSeverityClassification(content)
 }
 
 // filterDuplicateVerifyBranches filters out issues that have verify branch IDs
@@ -1,12 +1,15 @@
 package prreview
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"testing"
 
 	b "review_agent/internal/brain"
+	"review_agent/internal/exitcodes"
 	tools "review_agent/internal/tools"
 )
 
@@ -16,10 +19,13 @@ type fakeRunnerClient struct {
 	next             int
 	parallelCalls    []parallelCall
 	branchReadInputs []branchReadInput
+	// output, when set, is every branch's output (an agent's response).
+	output string
 }
 
 type parallelCall struct {
 	agent  string
+	parent string
 	prompt string
 }
 
@@ -39,6 +45,7 @@ func (c *fakeRunnerClient) ParallelExplore(projectName, parentBranchID string, p
 	branchID := fmt.Sprintf("branch-%d", c.next)
 	c.parallelCalls = append(c.parallelCalls, parallelCall{
 		agent:  agent,
+		parent: parentBranchID,
 		prompt: prompt,
 	})
 	return map[string]any{
@@ -72,8 +79,14 @@ func (c *fakeRunnerClient) BranchReadFile(branchID, filePath string) (map[string
 }
 
 func (c *fakeRunnerClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	output := "ok"
+	if c.output != "" {
+		output = c.output
+	}
 	return map[string]any{
-		"output": "ok",
+		"output": output,
 	}, nil
 }
 
@@ -124,3 +137,108 @@ func TestRunSkipsScoutWhenFlagSet(t *testing.T) {
 		}
 	}
 }
+
+func TestReclassifyIssuesOverridesFinderLabels(t *testing.T) {
+	runner := &Runner{}
+	runner.severityOverride = func(issueText string) (severityDecision, error) {
+		switch issueText {
+		case "nil deref on startup":
+			return severityDecision{Priority: priorityP1, Reason: "crashes a common path"}, nil
+		case "log wording":
+			return severityDecision{Priority: priorityNone, Reason: "cosmetic"}, nil
+		}
+		return severityDecision{}, errors.New("classifier unavailable")
+	}
+
+	got := runner.reclassifyIssues(context.Background(), []parsedIssue{
+		{Text: "nil deref on startup", Priority: priorityP0},
+		{Text: "log wording", Priority: priorityP0},
+		{Text: "race in cache", Priority: priorityP1},
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 issues kept, got %#v", got)
+	}
+	if got[0].Priority != priorityP1 || got[0].FinderPriority != priorityP0 || got[0].SeverityReason == "" {
+		t.Fatalf("reclassified issue not updated: %#v", got[0])
+	}
+	if got[1].Text != "race in cache" || got[1].Priority != priorityP1 || got[1].FinderPriority != "" {
+		t.Fatalf("classifier failure should keep the finder label: %#v", got[1])
+	}
+}
+
+func TestNewRunnerReportsMissingWorkspaceDirAsConfigError(t *testing.T) {
+	handler := tools.NewToolHandler(&fakeRunnerClient{}, "proj", "parent", "")
+	_, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{Task: "task", ProjectName: "proj", ParentBranchID: "parent"})
+	if err == nil || !strings.Contains(err.Error(), "WORKSPACE_DIR") {
+		t.Fatalf("expected a missing workspace dir to be rejected, got %v", err)
+	}
+	if got := exitcodes.Code(err); got != exitcodes.Config {
+		t.Fatalf("expected the Config exit status, got %d", got)
+	}
+}
+
+func TestFinalizeStatisticsCountsRecordedRounds(t *testing.T) {
+	runner := &Runner{statistics: &ReviewStatistics{IssueStatistics: make(map[string]IssueStatistic)}}
+	result := &Result{Issues: []IssueReport{
+		{IssueText: "P1: single pass", Rounds: []RoundBranch{{Role: RoleReviewer, Round: 1, BranchID: "branch-1"}}},
+		{IssueText: "P0: four exchanges", Rounds: []RoundBranch{
+			{Role: RoleReviewer, Round: 1, BranchID: "branch-2"},
+			{Role: RoleVerifyAgent, Round: 1, BranchID: "branch-3"},
+			{Role: RoleReviewer, Round: 2, BranchID: "branch-4"},
+			{Role: RoleVerifyAgent, Round: 2, BranchID: "branch-5"},
+			{Role: RoleReviewer, Round: 3, BranchID: "branch-6"},
+			{Role: RoleVerifyAgent, Round: 3, BranchID: "branch-7"},
+			{Role: RoleReviewer, Round: 4, BranchID: "branch-8"},
+			{Role: RoleTester, Round: 1, BranchID: "branch-9"},
+			{Role: RoleVerifyAgent, Round: 4, BranchID: ""},
+		}},
+	}}
+	runner.finalizeStatistics(result)
+
+	single := runner.statistics.IssueStatistics["P1: single pass"]
+	if single.ReviewerRounds != 1 || single.VerifyAgentRounds != 0 || single.Steps != 1 {
+		t.Fatalf("unexpected statistic for a single pass: %+v", single)
+	}
+	many := runner.statistics.IssueStatistics["P0: four exchanges"]
+	if many.ReviewerRounds != 4 || many.VerifyAgentRounds != 3 || many.TesterRounds != 1 {
+		t.Fatalf("expected 4 reviewer, 3 verify-agent and 1 tester rounds, got %+v", many)
+	}
+	if many.Steps != 7 {
+		t.Fatalf("expected steps to count reviewer and verify-agent rounds only, got %d", many.Steps)
+	}
+}
+
+func TestParseIssuesFromReportMergesAndDedupsChunks(t *testing.T) {
+	report := syntheticReport(8, 900)
+	runner := &Runner{opts: Options{MaxAuxInputChars: 2000}}
+	runner.parseIssuesOverride = func(chunk string) ([]parsedIssue, error) {
+		var issues []parsedIssue
+		for n := 1; n <= 8; n++ {
+			if strings.Contains(chunk, fmt.Sprintf("defect number %d\n", n)) {
+				issues = append(issues, parsedIssue{Text: fmt.Sprintf("P1: defect number %d", n), Priority: priorityP1})
+			}
+		}
+		// Every chunk also echoes a cross-cutting finding; it must be reported once.
+		issues = append(issues, parsedIssue{Text: "P0: shared  state race", Priority: priorityP0})
+		return issues, nil
+	}
+	issues, err := runner.parseIssuesFromReport(context.Background(), report)
+	if err != nil {
+		t.Fatalf("parseIssuesFromReport error: %v", err)
+	}
+	if len(issues) != 9 {
+		t.Fatalf("expected 8 issues plus one deduplicated shared issue, got %d: %+v", len(issues), issues)
+	}
+	seen := make(map[string]int)
+	for _, issue := range issues {
+		seen[issue.Text]++
+	}
+	for n := 1; n <= 8; n++ {
+		if text := fmt.Sprintf("P1: defect number %d", n); seen[text] != 1 {
+			t.Fatalf("expected %q once, got %d", text, seen[text])
+		}
+	}
+	if seen["P0: shared  state race"] != 1 {
+		t.Fatalf("expected the shared issue once, got %+v", issues)
+	}
+}
@@ -0,0 +1,156 @@
+package prreview
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"review_agent/internal/logx"
+	"review_agent/internal/streaming"
+)
+
+// SuppressionRule quiets a known-noise finding, like an entry in a lint baseline.
+// A rule matches either by Pattern, a regular expression over the issue text, or
+// by Fingerprint, the value IssueFingerprint reports for a previous finding.
+type SuppressionRule struct {
+	ID          string `json:"id,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// SuppressedIssue is a parsed issue that matched a suppression rule and was
+// left out of Result.Issues.
+type SuppressedIssue struct {
+	IssueText   string `json:"issue_text"`
+	Fingerprint string `json:"fingerprint"`
+	Rule        string `json:"rule"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// suppressionFile is the on-disk shape of --suppress-file.
+type suppressionFile struct {
+	Suppressions []SuppressionRule `json:"suppressions"`
+}
+
+// LoadSuppressions reads suppression rules from a JSON file of the form
+// {"suppressions": [{"id": ..., "pattern": ..., "reason": ...}]}.
+func LoadSuppressions(path string) ([]SuppressionRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read suppressions: %w", err)
+	}
+	var file suppressionFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse suppressions %s: %w", path, err)
+	}
+	rules, err := compileSuppressions(file.Suppressions)
+	if err != nil {
+		return nil, fmt.Errorf("suppressions %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// compileSuppressions validates rules and compiles their patterns.
+func compileSuppressions(rules []SuppressionRule) ([]SuppressionRule, error) {
+	out := make([]SuppressionRule, 0, len(rules))
+	for i, rule := range rules {
+		rule.ID = strings.TrimSpace(rule.ID)
+		rule.Pattern = strings.TrimSpace(rule.Pattern)
+		rule.Fingerprint = strings.ToLower(strings.TrimSpace(rule.Fingerprint))
+		label := rule.ID
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		if (rule.Pattern == "") == (rule.Fingerprint == "") {
+			return nil, fmt.Errorf("suppression %s needs exactly one of pattern or fingerprint", label)
+		}
+		if rule.Pattern != "" {
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("suppression %s: invalid pattern: %w", label, err)
+			}
+			rule.re = re
+		}
+		out = append(out, rule)
+	}
+	return out, nil
+}
+
+// IssueFingerprint identifies an issue by its text, ignoring case and whitespace
+// differences, so a finding can be suppressed on later runs.
+func IssueFingerprint(text string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(text)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// name labels the rule in results and logs.
+func (s SuppressionRule) name() string {
+	switch {
+	case s.ID != "":
+		return s.ID
+	case s.Pattern != "":
+		return "pattern:" + s.Pattern
+	default:
+		return "fingerprint:" + s.Fingerprint
+	}
+}
+
+func (s SuppressionRule) matches(text, fingerprint string) bool {
+	if s.re != nil {
+		return s.re.MatchString(text)
+	}
+	return s.Fingerprint == fingerprint
+}
+
+// suppressIssues splits off the issues matched by a suppression rule, logging
+// each one so quieted findings stay auditable.
+func (r *Runner) suppressIssues(issues []parsedIssue) ([]parsedIssue, []SuppressedIssue) {
+	if len(r.opts.Suppressions) == 0 {
+		return issues, nil
+	}
+	var (
+		kept       []parsedIssue
+		suppressed []SuppressedIssue
+	)
+	for _, issue := range issues {
+		fingerprint := IssueFingerprint(issue.Text)
+		matched := false
+		for _, rule := range r.opts.Suppressions {
+			if !rule.matches(issue.Text, fingerprint) {
+				continue
+			}
+			logx.Infof("Suppressed issue %s by rule %s: %s", fingerprint, rule.name(), streaming.PromptPreview(issue.Text))
+			suppressed = append(suppressed, SuppressedIssue{
+				IssueText:   issue.Text,
+				Fingerprint: fingerprint,
+				Rule:        rule.name(),
+				Reason:      rule.Reason,
+			})
+			matched = true
+			break
+		}
+		if !matched {
+			kept = append(kept, issue)
+		}
+	}
+	return kept, suppressed
+}
+
+// suppressedNote is appended to the run summary when findings were suppressed.
+func suppressedNote(suppressed []SuppressedIssue) string {
+	if len(suppressed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" %d known-noise finding(s) suppressed.", len(suppressed))
+}
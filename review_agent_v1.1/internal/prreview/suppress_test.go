@@ -0,0 +1,61 @@
+package prreview
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSuppressIssuesByPatternAndFingerprint(t *testing.T) {
+	known := "P1: cache.Put writes to a nil map"
+	path := filepath.Join(t.TempDir(), "suppress.json")
+	src := `{"suppressions": [
+		{"id": "bounded-chan", "pattern": "(?i)unbounded channel", "reason": "events channel is bounded by the worker pool"},
+		{"fingerprint": "` + IssueFingerprint("  p1: CACHE.put writes to a nil   map ") + `"}
+	]}`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadSuppressions(path)
+	if err != nil {
+		t.Fatalf("LoadSuppressions error: %v", err)
+	}
+
+	runner := &Runner{opts: Options{Suppressions: rules}}
+	kept, suppressed := runner.suppressIssues([]parsedIssue{
+		{Text: "P0: Unbounded channel in dispatcher"},
+		{Text: known},
+		{Text: "P1: retry loop never backs off"},
+	})
+	if len(kept) != 1 || kept[0].Text != "P1: retry loop never backs off" {
+		t.Fatalf("unexpected kept issues: %+v", kept)
+	}
+	if len(suppressed) != 2 {
+		t.Fatalf("expected 2 suppressed issues, got %+v", suppressed)
+	}
+	if suppressed[0].Rule != "bounded-chan" || suppressed[0].Reason == "" {
+		t.Fatalf("pattern suppression not recorded: %+v", suppressed[0])
+	}
+	if suppressed[1].Fingerprint != IssueFingerprint(known) || suppressed[1].Rule != "fingerprint:"+IssueFingerprint(known) {
+		t.Fatalf("fingerprint suppression not recorded: %+v", suppressed[1])
+	}
+}
+
+func TestLoadSuppressionsRejectsInvalidRules(t *testing.T) {
+	dir := t.TempDir()
+	cases := map[string]string{
+		"both.json":    `{"suppressions": [{"pattern": "x", "fingerprint": "abc"}]}`,
+		"neither.json": `{"suppressions": [{"id": "empty"}]}`,
+		"regex.json":   `{"suppressions": [{"pattern": "("}]}`,
+		"unknown.json": `{"suppressions": [{"regex": "x"}]}`,
+	}
+	for name, src := range cases {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSuppressions(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
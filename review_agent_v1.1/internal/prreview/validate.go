@@ -0,0 +1,34 @@
+package prreview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"review_agent/internal/jsonshape"
+)
+
+// ValidateResult checks that data is a JSON document in a shape this version
+// emits: a Result, or the ComparativeResult of --compare-branches, which is
+// told apart by its branch_a field. Every mismatch is reported: unknown
+// fields, missing required (non-omitempty) fields, and values of the wrong
+// JSON type.
+func ValidateResult(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("result is not valid JSON: %w", err)
+	}
+	typ, kind := reflect.TypeOf(Result{}), "result"
+	if obj, ok := raw.(map[string]any); ok {
+		if _, ok := obj["branch_a"]; ok {
+			typ, kind = reflect.TypeOf(ComparativeResult{}), "comparative result"
+		}
+	}
+	problems := jsonshape.Problems(raw, typ)
+	if len(problems) > 0 {
+		return errors.New(kind + " schema mismatch: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package prreview
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateResultAcceptsMarshaledOutputs(t *testing.T) {
+	outputs := map[string]any{
+		"result": &Result{
+			Task:         "task",
+			Status:       statusIssues,
+			Summary:      "summary",
+			ReviewerLogs: []ReviewerLog{{BranchID: "b1", Report: "report"}},
+			Issues:       []IssueReport{{IssueText: "issue", Status: statusIssues}},
+		},
+		"comparative result": &ComparativeResult{
+			Task:    "task",
+			BranchA: "a",
+			BranchB: "b",
+			OnlyA:   []ComparedIssue{{IssueText: "issue", Fingerprint: "f1"}},
+			OnlyB:   []ComparedIssue{},
+			Shared:  []ComparedIssue{{IssueText: "shared", Fingerprint: "f2", MatchedText: "same"}},
+			Safer:   "b",
+			Summary: "summary",
+		},
+	}
+	for name, out := range outputs {
+		data, err := json.Marshal(out)
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", name, err)
+		}
+		if err := ValidateResult(data); err != nil {
+			t.Errorf("%s: expected valid output, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateResultReportsUnknownAndMissingFields(t *testing.T) {
+	cases := map[string][]string{
+		`{"task":"t","status":"clean","reviewer_logs":[],"issues":[{"issue_text":"x","status":"confirmed","alpha":{},"legacy":true}],"extra":1}`: {
+			"result schema mismatch",
+			"missing field summary",
+			"unknown field extra",
+			"unknown field issues[0].legacy",
+		},
+		`{"task":"t","branch_a":"a","branch_b":"b","only_a":[{"issue_text":"x"}],"only_b":[],"shared":"none","safer":"a","prompt_version":"v1.1"}`: {
+			"comparative result schema mismatch",
+			"missing field summary",
+			"missing field only_a[0].fingerprint",
+			"shared: expected array",
+		},
+	}
+	for raw, wants := range cases {
+		err := ValidateResult([]byte(raw))
+		if err == nil {
+			t.Errorf("%s: expected schema mismatch", raw)
+			continue
+		}
+		for _, want := range wants {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("error %q missing %q", err.Error(), want)
+			}
+		}
+	}
+	if err := ValidateResult([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
@@ -26,6 +26,12 @@ type JSONStreamer struct {
 	mu       sync.Mutex
 	sequence int64
 	threadID string
+
+	// lastEmit and the lastItem fields feed the stall watchdog.
+	lastEmit        time.Time
+	lastItem        string
+	lastItemStarted time.Time
+	lastItemDone    bool
 }
 
 func NewJSONStreamer(enabled bool, w io.Writer) *JSONStreamer {
@@ -121,6 +127,9 @@ func (s *JSONStreamer) EmitItemStarted(itemID, kind, name string, args map[strin
 		"name":    name,
 		"args":    args,
 	}
+	s.mu.Lock()
+	s.lastItem, s.lastItemStarted, s.lastItemDone = name, time.Now(), false
+	s.mu.Unlock()
 	s.emit("item.started", payload)
 }
 
@@ -139,9 +148,26 @@ func (s *JSONStreamer) EmitItemCompleted(itemID, status string, duration time.Du
 	if summary != "" {
 		payload["summary"] = summarize(summary, assistantPreviewLimit)
 	}
+	s.mu.Lock()
+	s.lastItemDone = true
+	s.mu.Unlock()
 	s.emit("item.completed", payload)
 }
 
+// EmitPollTick reports one poll of a running branch, so consumers can see
+// progress between item.started and item.completed.
+func (s *JSONStreamer) EmitPollTick(branchID string, attempt int, elapsed time.Duration, status string) {
+	if !s.Enabled() {
+		return
+	}
+	s.emit("poll.tick", map[string]any{
+		"branch_id":  branchID,
+		"attempt":    attempt,
+		"elapsed_ms": elapsed.Milliseconds(),
+		"status":     status,
+	})
+}
+
 func (s *JSONStreamer) EmitThreadCompleted(status, summary string, finalReport map[string]any) {
 	if !s.Enabled() {
 		return
@@ -172,6 +198,49 @@ func (s *JSONStreamer) EmitError(scope, message string, extra map[string]any) {
 	s.emit("error", payload)
 }
 
+// EmitStallWarning reports that the run has been quiet for idle. It names the
+// most recent item so consumers can tell a slow branch poll from a dead process.
+func (s *JSONStreamer) EmitStallWarning(idle time.Duration) {
+	if !s.Enabled() {
+		return
+	}
+	payload := map[string]any{
+		"idle_ms": idle.Milliseconds(),
+	}
+	if item, elapsed, running := s.LastItem(); item != "" {
+		payload["last_item"] = item
+		payload["last_item_running"] = running
+		if running {
+			payload["last_item_elapsed_ms"] = elapsed.Milliseconds()
+		}
+	}
+	s.emit("thread.stall_warning", payload)
+}
+
+// LastEmit returns the time of the most recent event; zero before the first one.
+func (s *JSONStreamer) LastEmit() time.Time {
+	if !s.Enabled() {
+		return time.Time{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastEmit
+}
+
+// LastItem returns the name of the latest item.started, how long ago it started,
+// and whether it is still running.
+func (s *JSONStreamer) LastItem() (string, time.Duration, bool) {
+	if !s.Enabled() {
+		return "", 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastItem == "" {
+		return "", 0, false
+	}
+	return s.lastItem, time.Since(s.lastItemStarted), !s.lastItemDone
+}
+
 func (s *JSONStreamer) emit(eventType string, payload map[string]any) {
 	if !s.Enabled() {
 		return
@@ -179,6 +248,7 @@ func (s *JSONStreamer) emit(eventType string, payload map[string]any) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.sequence++
+	s.lastEmit = time.Now()
 
 	envelope := make(map[string]any, len(payload)+4)
 	for k, v := range payload {
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"review_agent/internal/config"
 	"review_agent/internal/logx"
+	"review_agent/internal/streaming"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +16,8 @@ type ToolExecutionError struct {
 	Msg         string
 	Instruction string
 	Details     map[string]any
+	// Kind classifies the failure (see ErrorKindMCP); empty when unclassified.
+	Kind string
 }
 
 func (e ToolExecutionError) Error() string { return e.Msg }
@@ -30,7 +33,6 @@ var _ agentClient = (*MCPClient)(nil)
 
 const (
 	reviewCodeAgent            = "review_code"
-	reviewArtifactName         = "code_review.log"
 	reviewMaxAttempts          = 3
 	instructionFinishedWithErr = "FINISHED_WITH_ERROR"
 )
@@ -63,12 +65,22 @@ func (t *BranchTracker) Range() map[string]string {
 	return map[string]string{"start_branch_id": t.start, "latest_branch_id": t.latest}
 }
 
+// DefaultReviewArtifactName is the review log review_code must write when no
+// REVIEW_ARTIFACT_NAME is configured.
+const DefaultReviewArtifactName = "code_review.log"
+
 type ToolHandler struct {
-	client        agentClient
-	cfg           *config.AgentConfig // nil = use defaults (for tests)
-	defaultProj   string
-	branchTracker *BranchTracker
-	workspaceDir  string
+	client         agentClient
+	cfg            *config.AgentConfig // nil = use defaults (for tests)
+	defaultProj    string
+	branchTracker  *BranchTracker
+	workspaceDir   string
+	reviewArtifact string
+	transformers   []ResponseTransformer
+	sleepFunc      func(time.Duration)
+
+	// streamer receives poll.tick events while checkStatus waits; nil disables them.
+	streamer *streaming.JSONStreamer
 }
 
 // NewToolHandler creates a handler without config. Uses hardcoded defaults.
@@ -86,16 +98,41 @@ func NewToolHandler(client agentClient, defaultProject string, startBranch strin
 // NewToolHandlerWithConfig creates a handler with config. Use this in production.
 func NewToolHandlerWithConfig(client agentClient, cfg *config.AgentConfig, startBranch string) *ToolHandler {
 	return &ToolHandler{
-		client:        client,
-		cfg:           cfg,
-		defaultProj:   cfg.ProjectName,
-		branchTracker: NewBranchTracker(startBranch),
-		workspaceDir:  strings.TrimSpace(cfg.WorkspaceDir),
+		client:         client,
+		cfg:            cfg,
+		defaultProj:    cfg.ProjectName,
+		branchTracker:  NewBranchTracker(startBranch),
+		workspaceDir:   strings.TrimSpace(cfg.WorkspaceDir),
+		reviewArtifact: strings.TrimSpace(cfg.ReviewArtifactName),
 	}
 }
 
 func (h *ToolHandler) BranchRange() map[string]string { return h.branchTracker.Range() }
 
+// SetStreamer makes checkStatus emit a poll.tick event on each poll of a branch.
+func (h *ToolHandler) SetStreamer(streamer *streaming.JSONStreamer) {
+	h.streamer = streamer
+}
+
+// ResponseTransformer rewrites the response text extracted from an agent run, e.g.
+// to strip ANSI codes or redact sandbox paths, before callers see it.
+type ResponseTransformer func(agent, response string) string
+
+// AddResponseTransformer registers fn to post-process agent responses. Transformers
+// run in registration order; register them before the handler is in use.
+func (h *ToolHandler) AddResponseTransformer(fn ResponseTransformer) {
+	if fn != nil {
+		h.transformers = append(h.transformers, fn)
+	}
+}
+
+func (h *ToolHandler) transformResponse(agent, response string) string {
+	for _, fn := range h.transformers {
+		response = fn(agent, response)
+	}
+	return response
+}
+
 // ToolCall mirrors brain.ToolCall, but we keep it generic here if needed.
 type ToolCall struct {
 	ID       string `json:"id"`
@@ -160,27 +197,35 @@ func (h *ToolHandler) executeAgent(arguments map[string]any) (map[string]any, er
 	return result, err
 }
 
-func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
-	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
-	resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
-	if err != nil {
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
-			Instruction: instructionFinishedWithErr,
-		}
-	}
-	if isErr, ok := resp["isError"].(bool); ok && isErr {
-		errMsg := resp["error"]
-		if errMsg == nil {
-			return nil, "", ToolExecutionError{
-				Msg:         fmt.Sprintf("ParallelExplore returned error (details: %v)", resp),
+// startAgent issues parallel_explore, re-issuing it while the server reports a
+// retryable error.
+func (h *ToolHandler) startAgent(agent, project, parent, prompt string) (map[string]any, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := h.client.ParallelExplore(project, parent, []string{prompt}, agent, 1)
+		if err != nil {
+			return nil, ToolExecutionError{
+				Msg:         fmt.Sprintf("ParallelExplore failed: %v - %v", err, resp),
 				Instruction: instructionFinishedWithErr,
 			}
 		}
-		return nil, "", ToolExecutionError{
-			Msg:         fmt.Sprintf("ParallelExplore returned error: %v", errMsg),
-			Instruction: instructionFinishedWithErr,
+		if isErr, ok := resp["isError"].(bool); !ok || !isErr {
+			return resp, nil
 		}
+		te := newMCPToolError(resp)
+		if te.Kind != ErrorKindMCPRetryable || attempt >= mcpRetryAttempts {
+			return nil, te
+		}
+		wait := mcpRetryWait(attempt)
+		logx.Warningf("ParallelExplore reported a retryable error (attempt %d/%d): %s. Retrying in %ds...", attempt, mcpRetryAttempts, te.Msg, int(wait.Seconds()))
+		h.sleep(wait)
+	}
+}
+
+func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[string]any, string, error) {
+	logx.Infof("Executing agent %s on project %s from parent %s", agent, project, parent)
+	resp, err := h.startAgent(agent, project, parent, prompt)
+	if err != nil {
+		return nil, "", err
 	}
 	branchID := ExtractBranchID(resp)
 	if branchID == "" {
@@ -241,7 +286,7 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 	if strings.TrimSpace(responseText) == "" {
 		return nil, "", ToolExecutionError{Msg: "branch_output returned no textual output"}
 	}
-	result["response"] = strings.TrimSpace(responseText)
+	result["response"] = h.transformResponse(agent, strings.TrimSpace(responseText))
 
 	return result, branchID, nil
 }
@@ -249,7 +294,7 @@ func (h *ToolHandler) runAgentOnce(agent, project, parent, prompt string) (map[s
 func (h *ToolHandler) executeReviewAgent(project, parent, prompt string) (map[string]any, error) {
 	artifactPath := h.reviewLogPath()
 	if artifactPath == "" {
-		return nil, ToolExecutionError{Msg: "workspace directory not configured for review_code validation"}
+		return nil, ToolExecutionError{Msg: fmt.Sprintf("workspace dir is not configured, so review_code's %s cannot be checked: set WORKSPACE_DIR to the agent's workspace path", h.ReviewArtifactName())}
 	}
 	var lastBranch string
 	for attempt := 1; attempt <= reviewMaxAttempts; attempt++ {
@@ -290,7 +335,15 @@ func (h *ToolHandler) reviewLogPath() string {
 	if strings.TrimSpace(h.workspaceDir) == "" {
 		return ""
 	}
-	return filepath.Join(h.workspaceDir, reviewArtifactName)
+	return filepath.Join(h.workspaceDir, h.ReviewArtifactName())
+}
+
+// ReviewArtifactName is the file name review_code runs are expected to write.
+func (h *ToolHandler) ReviewArtifactName() string {
+	if h.reviewArtifact != "" {
+		return h.reviewArtifact
+	}
+	return DefaultReviewArtifactName
 }
 
 func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, error) {
@@ -326,6 +379,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 	sleep := time.Duration(poll * float64(time.Second))
 
 	logx.Infof("Checking status for branch %s (timeout=%ds)", branchID, int(timeout))
+	pollStart := time.Now()
 	for attempt := 1; ; attempt++ {
 		resp, err := h.client.GetBranch(branchID)
 		if err != nil {
@@ -352,6 +406,7 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 		}
 
 		status := stringsLower(resp["status"])
+		h.streamer.EmitPollTick(branchID, attempt, time.Now().Sub(pollStart), status)
 		latest_snap_id := stringsLower(resp["latest_snap_id"])
 		hasNewSnapshot := true
 		parent_branch_id := stringsLower(resp["parent_id"])
@@ -405,11 +460,19 @@ func (h *ToolHandler) checkStatus(arguments map[string]any) (map[string]any, err
 			}
 		}
 		logx.Infof("Branch %s still active (status=%s). Sleeping %.1fs.", branchID, status, sleep.Seconds())
-		time.Sleep(sleep)
+		h.sleep(sleep)
 		sleep = time.Duration(minFloat(float64(sleep/time.Second)*backoffFactor, maxPoll)) * time.Second
 	}
 }
 
+func (h *ToolHandler) sleep(d time.Duration) {
+	if h != nil && h.sleepFunc != nil {
+		h.sleepFunc(d)
+		return
+	}
+	time.Sleep(d)
+}
+
 func (h *ToolHandler) readArtifact(arguments map[string]any) (map[string]any, error) {
 	branchID, _ := arguments["branch_id"].(string)
 	path, _ := arguments["path"].(string)
@@ -438,44 +501,6 @@ func (h *ToolHandler) branchOutput(arguments map[string]any) (map[string]any, er
 	return h.client.BranchOutput(branchID, fullOutput)
 }
 
-func ExtractBranchID(m map[string]any) string {
-	if m == nil {
-		return ""
-	}
-
-	if pe, ok := m["parallel_explore"].(map[string]any); ok {
-		if branches, ok := pe["branches"].([]any); ok {
-			for _, item := range branches {
-				if nested, _ := item.(map[string]any); nested != nil {
-					if id := ExtractBranchID(nested); id != "" {
-						return id
-					}
-				}
-			}
-		}
-	}
-	if branches, ok := m["branches"].([]any); ok {
-		for _, item := range branches {
-			if nested, _ := item.(map[string]any); nested != nil {
-				if id := ExtractBranchID(nested); id != "" {
-					return id
-				}
-			}
-		}
-	}
-	if b, ok := m["branch"].(map[string]any); ok {
-		if id := ExtractBranchID(b); id != "" {
-			return id
-		}
-	}
-	for _, k := range []string{"branch_id", "id"} {
-		if v, ok := m[k].(string); ok && v != "" {
-			return v
-		}
-	}
-	return ""
-}
-
 func branchOutputString(payload map[string]any) string {
 	if payload == nil {
 		return ""
@@ -501,6 +526,9 @@ func (h *ToolHandler) errorPayload(err error) map[string]any {
 		if len(te.Details) > 0 {
 			payload["details"] = te.Details
 		}
+		if te.Kind != "" {
+			payload["kind"] = te.Kind
+		}
 		if len(payload) == 0 {
 			payload["message"] = "tool execution error"
 		}
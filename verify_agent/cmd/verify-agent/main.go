@@ -2,14 +2,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	b "verify_agent/internal/brain"
 	cfg "verify_agent/internal/config"
+	"verify_agent/internal/exitcodes"
 	"verify_agent/internal/logx"
 	"verify_agent/internal/streaming"
 	"verify_agent/internal/tools"
@@ -17,14 +23,32 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate-result" {
+		os.Exit(runValidateResult(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		os.Exit(runBatch(os.Args[2:]))
+	}
+
 	bugDesc := flag.String("bug", "", "Bug description to verify")
 	parent := flag.String("parent-branch-id", "", "Branch UUID to fork from (required)")
 	project := flag.String("project-name", "", "Override project name")
 	headless := flag.Bool("headless", false, "Headless mode (no interactive prompt)")
 	streamJSON := flag.Bool("stream-json", false, "Emit workflow events as NDJSON (implies headless)")
 	codeContext := flag.String("code-context", "", "Optional: additional code context")
+	diffFile := flag.String("diff-file", "", "Optional: unified diff of the change under scrutiny, added to the code context")
 	isFalsePositive := flag.Bool("false-positive", false, "Treat bug as false positive (虚假报警) - agent will try to refute it")
-	flag.Parse()
+	failOnIssues := flag.Bool("fail-on-issues", false, "Exit with status 2 when the bug is confirmed or the --false-positive assumption is overturned")
+	reprompt := flag.Bool("reprompt-on-parse-failure", false, "Re-ask Task 1 once for the JSON assertion if it cannot be parsed")
+	flagReversals := flag.Bool("flag-assumption-reversals", false, "Report a bug confirmed despite --false-positive as assumption_overturned instead of bug_confirmed")
+	requireEvidence := flag.Bool("require-test-evidence", false, "Return bug_confirmed only when the generated test reports BUG_CONFIRMED; an inconclusive test yields cannot_disprove")
+	inconclusive := flag.String("inconclusive-policy", "", "Status for an inconclusive test when the bug is assumed real: assume_real (bug_confirmed), cannot_disprove or bug_wrong; default assume_real, or cannot_disprove with --require-test-evidence")
+	diagnostics := flag.Bool("diagnostics", false, "When Task 1 cannot formalize the bug, report structured diagnostics (missing file references, ambiguous terms, reason)")
+	changedSymbols := flag.Bool("changed-symbols", false, "Extract the changed functions/types from the diff into symbols.json after Task 1 and name them in the Task 2/3 prompts")
+	pipelineMode := flag.String("pipeline-mode", verify.PipelineSequential, "Task 2/3 ordering: sequential (Task 3 sees the reachability analysis) or fanout (run both concurrently from Task 1's branch)")
+	stallWarning := flag.Duration("stall-warning-interval", 5*time.Minute, "Report a stall warning after this long without any event or log line; 0 disables the watchdog")
+	profile := flag.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	exitcodes.ParseFlags()
 
 	streamEnabled := streamJSON != nil && *streamJSON
 	if streamEnabled {
@@ -32,10 +56,10 @@ func main() {
 		logx.SetLevel(logx.Error)
 	}
 
-	conf, err := cfg.FromEnv()
+	conf, err := cfg.Load(*profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
 	if *project != "" {
@@ -43,11 +67,19 @@ func main() {
 	}
 	if conf.ProjectName == "" {
 		fmt.Fprintln(os.Stderr, "Project name required via PROJECT_NAME or --project-name")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
 	}
 	if *parent == "" {
 		fmt.Fprintln(os.Stderr, "--parent-branch-id is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+
+	var diff string
+	if *diffFile != "" {
+		if diff, err = verify.ReadDiffFile(*diffFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitcodes.Usage)
+		}
 	}
 
 	bug := strings.TrimSpace(*bugDesc)
@@ -59,7 +91,11 @@ func main() {
 	}
 	if bug == "" {
 		fmt.Fprintln(os.Stderr, "bug description is required")
-		os.Exit(1)
+		os.Exit(exitcodes.Usage)
+	}
+
+	if conf.RunSubdir != "" {
+		fmt.Fprintf(os.Stderr, "Using per-run workspace: %s\n", conf.WorkspaceDir)
 	}
 
 	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
@@ -71,14 +107,23 @@ func main() {
 		streamer = streaming.NewJSONStreamer(true, os.Stdout)
 		streamer.EmitThreadStarted(bug, conf.ProjectName, *parent, *headless)
 	}
+	handler.SetStreamer(streamer)
 
 	opts := verify.Options{
-		BugDescription:  bug,
-		ProjectName:     conf.ProjectName,
-		ParentBranchID:  *parent,
-		WorkspaceDir:    conf.WorkspaceDir,
-		CodeContext:     strings.TrimSpace(*codeContext),
-		IsFalsePositive: *isFalsePositive,
+		BugDescription:          bug,
+		ProjectName:             conf.ProjectName,
+		ParentBranchID:          *parent,
+		WorkspaceDir:            conf.WorkspaceDir,
+		CodeContext:             strings.TrimSpace(*codeContext),
+		IsFalsePositive:         *isFalsePositive,
+		RepromptOnParseFailure:  *reprompt,
+		FlagAssumptionReversals: *flagReversals,
+		PipelineMode:            *pipelineMode,
+		RequireTestEvidence:     *requireEvidence,
+		InconclusivePolicy:      *inconclusive,
+		ChangedSymbols:          *changedSymbols,
+		Diagnostics:             *diagnostics,
+		Diff:                    diff,
 	}
 	runner, err := verify.NewRunner(brain, handler, streamer, opts)
 	if err != nil {
@@ -87,17 +132,21 @@ func main() {
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "init error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Config)
 	}
 
+	watchdog := streaming.StartWatchdog(streamer, *stallWarning, logx.LastOutput, func(idle time.Duration) {
+		logx.Warningf("No output for %s; the run is still waiting on a branch or LLM call.", idle.Round(time.Second))
+	})
 	result, err := runner.Run()
+	watchdog.Stop()
 	if err != nil {
 		if streamer != nil && streamer.Enabled() {
 			streamer.EmitError("workflow", err.Error(), nil)
 			streamer.EmitThreadCompleted("error", err.Error(), nil)
 		}
 		fmt.Fprintf(os.Stderr, "workflow error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitcodes.Code(err))
 	}
 
 	status := "completed"
@@ -109,6 +158,8 @@ func main() {
 			status = "bug_confirmed"
 		case "cannot_disprove":
 			status = "cannot_disprove"
+		case "assumption_overturned":
+			status = "assumption_overturned"
 		case "error":
 			status = "error"
 		}
@@ -117,6 +168,7 @@ func main() {
 				"bug_description": result.BugDescription,
 				"status":          result.Status,
 				"summary":         result.Summary,
+				"headline":        result.Headline,
 				"task1_result":    result.Task1Result,
 				"task2_result":    result.Task2Result,
 				"task3_result":    result.Task3Result,
@@ -126,4 +178,190 @@ func main() {
 
 	out, _ := json.MarshalIndent(result, "", "  ")
 	fmt.Fprintln(os.Stderr, string(out))
+	if *failOnIssues && result.HasFindings() {
+		os.Exit(exitcodes.Findings)
+	}
+}
+
+// runValidateResult implements the `validate-result --file X` subcommand.
+func runValidateResult(args []string) int {
+	fs := flag.NewFlagSet("validate-result", flag.ContinueOnError)
+	file := fs.String("file", "", "Path to a result or batch report JSON file (\"-\" reads stdin)")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return exitcodes.Usage
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", *file, err)
+		return exitcodes.Runtime
+	}
+	if err := verify.ValidateResult(data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitcodes.Runtime
+	}
+	fmt.Fprintf(os.Stderr, "%s: valid result\n", *file)
+	return exitcodes.Success
+}
+
+// runBatch implements the `batch --file bugs.json` subcommand: it verifies every
+// bug in the file through a bounded worker pool and prints a BatchReport.
+func runBatch(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	file := fs.String("file", "", "JSON array of bugs: [{\"bug_description\": ..., \"code_context\": ..., \"false_positive\": ..., \"parent_branch_id\": ..., \"diff_file\": ...}]")
+	parent := fs.String("parent-branch-id", "", "Branch UUID to fork from, unless a bug sets its own parent_branch_id")
+	project := fs.String("project-name", "", "Override project name")
+	maxParallel := fs.Int("max-parallel", verify.DefaultMaxParallel, "Verify at most this many bugs at once; the rest wait for a free slot")
+	isFalsePositive := fs.Bool("false-positive", false, "Treat bugs as false positives unless a bug sets false_positive")
+	failOnIssues := fs.Bool("fail-on-issues", false, "Exit with status 2 when any bug is confirmed or has its false-positive assumption overturned")
+	reprompt := fs.Bool("reprompt-on-parse-failure", false, "Re-ask Task 1 once for the JSON assertion if it cannot be parsed")
+	flagReversals := fs.Bool("flag-assumption-reversals", false, "Report a bug confirmed despite --false-positive as assumption_overturned instead of bug_confirmed")
+	requireEvidence := fs.Bool("require-test-evidence", false, "Return bug_confirmed only when the generated test reports BUG_CONFIRMED")
+	inconclusive := fs.String("inconclusive-policy", "", "Status for an inconclusive test when the bug is assumed real: assume_real, cannot_disprove or bug_wrong")
+	diagnostics := fs.Bool("diagnostics", false, "Report structured diagnostics for bugs Task 1 cannot formalize")
+	diffFile := fs.String("diff-file", "", "Unified diff of the change under scrutiny, unless a bug sets its own diff_file")
+	changedSymbols := fs.Bool("changed-symbols", false, "Name the changed functions/types in the Task 2/3 prompts")
+	pipelineMode := fs.String("pipeline-mode", verify.PipelineSequential, "Task 2/3 ordering: sequential or fanout")
+	profile := fs.String("profile", "", "Config profile from profiles.yaml (or $PROFILES_FILE); environment variables still take precedence")
+	deadline := fs.Duration("batch-deadline", 0, "Stop the whole batch after this long (e.g. 45m): unstarted bugs are reported as skipped_deadline and running ones are cancelled; 0 means no limit")
+	grace := fs.Duration("interrupt-grace", verify.DefaultInterruptGrace, "After SIGINT/SIGTERM or the deadline, wait this long for running bugs before reporting them as interrupted; 0 waits indefinitely")
+	stallWarning := fs.Duration("stall-warning-interval", 5*time.Minute, "Report a stall warning after this long without any event or log line; 0 disables the watchdog")
+	if err := fs.Parse(args); err != nil {
+		return exitcodes.Usage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(os.Stderr, "--file is required")
+		return exitcodes.Usage
+	}
+	if *maxParallel < 1 {
+		fmt.Fprintf(os.Stderr, "error: --max-parallel must be at least 1 (got %d)\n", *maxParallel)
+		return exitcodes.Usage
+	}
+	if *deadline < 0 {
+		fmt.Fprintf(os.Stderr, "error: --batch-deadline must not be negative (got %s)\n", *deadline)
+		return exitcodes.Usage
+	}
+	if *grace < 0 {
+		fmt.Fprintf(os.Stderr, "error: --interrupt-grace must not be negative (got %s)\n", *grace)
+		return exitcodes.Usage
+	}
+
+	conf, err := cfg.Load(*profile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return exitcodes.Config
+	}
+	if *project != "" {
+		conf.ProjectName = *project
+	}
+	if conf.ProjectName == "" {
+		fmt.Fprintln(os.Stderr, "Project name required via PROJECT_NAME or --project-name")
+		return exitcodes.Usage
+	}
+	bugs, err := verify.LoadBatch(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		return exitcodes.Config
+	}
+	for i, bug := range bugs {
+		if bug.ParentBranchID == "" && *parent == "" {
+			fmt.Fprintf(os.Stderr, "bug #%d has no parent_branch_id and --parent-branch-id is not set\n", i+1)
+			return exitcodes.Usage
+		}
+	}
+
+	if conf.RunSubdir != "" {
+		fmt.Fprintf(os.Stderr, "Using per-run workspace: %s\n", conf.WorkspaceDir)
+	}
+
+	brain := b.NewLLMBrain(conf.AzureAPIKey, conf.AzureEndpoint, conf.AzureDeployment, conf.AzureAPIVersion, 3)
+	mcp := tools.NewMCPClient(conf.MCPBaseURL)
+	// The first SIGINT/SIGTERM cancels the batch; restoring the default handling
+	// right away lets a second one kill the process during the grace period.
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	go func() {
+		<-sigCtx.Done()
+		stopSignals()
+	}()
+	ctx := sigCtx
+	if *deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *deadline)
+		defer cancel()
+	}
+	watchdog := streaming.StartWatchdog(nil, *stallWarning, logx.LastOutput, func(idle time.Duration) {
+		logx.Warningf("No output for %s; the run is still waiting on a branch or LLM call.", idle.Round(time.Second))
+	})
+	report := verify.RunBatch(ctx, bugs, *maxParallel, *grace, func(ctx context.Context, bug verify.BatchBug) (*verify.Result, error) {
+		parentID := *parent
+		if bug.ParentBranchID != "" {
+			parentID = bug.ParentBranchID
+		}
+		falsePositive := *isFalsePositive
+		if bug.IsFalsePositive != nil {
+			falsePositive = *bug.IsFalsePositive
+		}
+		diffPath := *diffFile
+		if bug.DiffFile != "" {
+			diffPath = bug.DiffFile
+		}
+		var diff string
+		if diffPath != "" {
+			d, err := verify.ReadDiffFile(diffPath)
+			if err != nil {
+				return nil, err
+			}
+			diff = d
+		}
+		// Each bug gets its own handler so branch lineage is tracked per bug.
+		handler := tools.NewToolHandlerWithConfig(mcp, &conf, parentID)
+		runner, err := verify.NewRunner(brain, handler, nil, verify.Options{
+			BugDescription:          bug.BugDescription,
+			ProjectName:             conf.ProjectName,
+			ParentBranchID:          parentID,
+			WorkspaceDir:            conf.WorkspaceDir,
+			CodeContext:             strings.TrimSpace(bug.CodeContext),
+			IsFalsePositive:         falsePositive,
+			RepromptOnParseFailure:  *reprompt,
+			FlagAssumptionReversals: *flagReversals,
+			PipelineMode:            *pipelineMode,
+			RequireTestEvidence:     *requireEvidence,
+			InconclusivePolicy:      *inconclusive,
+			ChangedSymbols:          *changedSymbols,
+			Diagnostics:             *diagnostics,
+			Diff:                    diff,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return runner.RunContext(ctx)
+	})
+	watchdog.Stop()
+
+	out, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(out))
+	if sigCtx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Batch interrupted; the report above holds the bugs that finished.")
+		return exitcodes.Interrupted
+	}
+	if report.AllFailed() {
+		fmt.Fprintln(os.Stderr, "Every bug in the batch failed; see the error results above.")
+		return exitcodes.Runtime
+	}
+	if *failOnIssues && report.HasFindings() {
+		return exitcodes.Findings
+	}
+	return exitcodes.Success
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("AZURE_OPENAI_API_KEY", "test-key")
+	t.Setenv("AZURE_OPENAI_BASE_URL", "https://example.openai.azure.com")
+	t.Setenv("AZURE_OPENAI_DEPLOYMENT", "test-deployment")
+	t.Setenv("AZURE_OPENAI_API_VERSION", "")
+	t.Setenv("MCP_BASE_URL", "http://localhost:8000/mcp/sse")
+	t.Setenv("MCP_POLL_INITIAL_SECONDS", "2")
+	t.Setenv("MCP_POLL_MAX_SECONDS", "30")
+	t.Setenv("MCP_POLL_BACKOFF_FACTOR", "")
+	t.Setenv("PROJECT_NAME", "test-project")
+	t.Setenv("WORKSPACE_DIR", "/tmp/workspace")
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+}
+
+func TestFromEnv_WorkspacePerRunUsesSubdir(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKSPACE_PER_RUN", "true")
+
+	conf, err := FromEnv()
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if !strings.HasPrefix(conf.RunSubdir, "run-") {
+		t.Fatalf("expected run- prefixed subdir, got %q", conf.RunSubdir)
+	}
+	if conf.WorkspaceDir != "/tmp/workspace/"+conf.RunSubdir {
+		t.Fatalf("expected workspace scoped to run subdir, got %q", conf.WorkspaceDir)
+	}
+}
+
+func TestFromEnv_WorkspacePerRunRejectsInvalidBool(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("WORKSPACE_PER_RUN", "sometimes")
+
+	if _, err := FromEnv(); err == nil {
+		t.Fatalf("expected error for invalid WORKSPACE_PER_RUN")
+	}
+}
@@ -1,10 +1,12 @@
 package tools
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExecuteAgentReviewCodeRetriesMissingLog(t *testing.T) {
@@ -29,7 +31,7 @@ func TestExecuteAgentReviewCodeRetriesMissingLog(t *testing.T) {
 		"project_name":     "proj",
 	}
 
-	res, err := handler.executeAgent(args)
+	res, err := handler.executeAgent(context.Background(), args)
 	if err != nil {
 		t.Fatalf("executeAgent returned error: %v", err)
 	}
@@ -75,7 +77,7 @@ func TestExecuteAgentReviewCodeFailsAfterMaxAttempts(t *testing.T) {
 		"project_name":     "proj",
 	}
 
-	_, err := handler.executeAgent(args)
+	_, err := handler.executeAgent(context.Background(), args)
 	if err == nil {
 		t.Fatalf("expected error after max attempts, got nil")
 	}
@@ -199,6 +201,7 @@ type branchReadResult struct {
 
 type fakeMCPClient struct {
 	parallelExploreCalls int
+	exploreErrors        []map[string]any
 	readResults          []branchReadResult
 	branchReadInputs     []branchReadInput
 	branchOutputInputs   []branchOutputInput
@@ -213,6 +216,11 @@ type branchOutputInput struct {
 
 func (f *fakeMCPClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
 	f.parallelExploreCalls++
+	if len(f.exploreErrors) > 0 {
+		resp := f.exploreErrors[0]
+		f.exploreErrors = f.exploreErrors[1:]
+		return resp, nil
+	}
 	branchID := fmt.Sprintf("branch-%d", f.parallelExploreCalls)
 	return map[string]any{
 		"branch_id": branchID,
@@ -268,3 +276,44 @@ func (f *fakeMCPClient) BranchOutput(branchID string, fullOutput bool) (map[stri
 func notFoundErr(attempt int) error {
 	return fmt.Errorf("MCP HTTP 404: attempt %d not found", attempt)
 }
+
+func TestTransformResponseAppliesTransformersInOrder(t *testing.T) {
+	handler := &ToolHandler{}
+	var agents []string
+	handler.AddResponseTransformer(func(agent, response string) string {
+		agents = append(agents, agent)
+		return response + "a"
+	})
+	handler.AddResponseTransformer(func(agent, response string) string { return response + "b" })
+
+	if got := handler.transformResponse("codex", "ok "); got != "ok ab" {
+		t.Fatalf("expected transformers to run in registration order, got %q", got)
+	}
+	if len(agents) != 1 || agents[0] != "codex" {
+		t.Fatalf("expected transformer to see agent codex once, got %v", agents)
+	}
+}
+
+func TestStartAgentRetriesRetryableMCPError(t *testing.T) {
+	busy := map[string]any{
+		"isError": true,
+		"error":   map[string]any{"code": "capacity", "message": "no runners free", "retryable": true},
+	}
+	client := &fakeMCPClient{exploreErrors: []map[string]any{busy, busy}}
+	var sleeps []time.Duration
+	handler := &ToolHandler{client: client, branchTracker: NewBranchTracker("parent"), sleepFunc: func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}}
+
+	resp, err := handler.startAgent(context.Background(), "codex", "proj", "parent", "prompt")
+	if err != nil {
+		t.Fatalf("startAgent returned error: %v", err)
+	}
+	if client.parallelExploreCalls != 3 || ExtractBranchID(resp) != "branch-3" {
+		t.Fatalf("expected the third parallel_explore call to succeed, got %d calls and %#v", client.parallelExploreCalls, resp)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; len(sleeps) != 2 || sleeps[0] != want[0] || sleeps[1] != want[1] {
+		t.Fatalf("sleeps=%v, want %v", sleeps, want)
+	}
+}
@@ -0,0 +1,245 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"verify_agent/internal/logx"
+)
+
+// DefaultMaxParallel is how many bugs a batch verifies at once when unset.
+const DefaultMaxParallel = 4
+
+// statusSkippedDeadline marks a batch bug that was never started because the
+// batch deadline passed first.
+const statusSkippedDeadline = "skipped_deadline"
+
+// statusSkippedInterrupted marks a batch bug that was never started because the
+// batch was interrupted (SIGINT/SIGTERM) first.
+const statusSkippedInterrupted = "skipped_interrupted"
+
+// statusInterrupted marks a batch bug that was still running when the grace
+// period after a deadline or interrupt ran out.
+const statusInterrupted = "interrupted"
+
+// DefaultInterruptGrace is how long a batch waits for running bugs to wind down
+// once it is interrupted or its deadline passes.
+const DefaultInterruptGrace = 30 * time.Second
+
+// BatchBug is one entry of a batch file. Unset fields fall back to the batch-wide
+// flags of the same name.
+type BatchBug struct {
+	BugDescription  string `json:"bug_description"`
+	CodeContext     string `json:"code_context,omitempty"`
+	IsFalsePositive *bool  `json:"false_positive,omitempty"`
+	ParentBranchID  string `json:"parent_branch_id,omitempty"`
+	DiffFile        string `json:"diff_file,omitempty"`
+}
+
+// BatchReport is the output of a batch run: one Result per input bug, in input
+// order, and the number of results per status.
+type BatchReport struct {
+	Results []*Result      `json:"results"`
+	Total   int            `json:"total"`
+	Counts  map[string]int `json:"counts"`
+	// Headline summarizes the counts in one sentence.
+	Headline string `json:"headline,omitempty"`
+}
+
+// HasFindings reports whether any bug in the batch has findings.
+func (r *BatchReport) HasFindings() bool {
+	for _, result := range r.Results {
+		if result.HasFindings() {
+			return true
+		}
+	}
+	return false
+}
+
+// AllFailed reports whether the batch verified nothing: it has bugs and every
+// one of them ended in an error result.
+func (r *BatchReport) AllFailed() bool {
+	return r.Total > 0 && r.Counts[statusError] == r.Total
+}
+
+// LoadBatch reads a JSON array of BatchBug entries.
+func LoadBatch(path string) ([]BatchBug, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read batch: %w", err)
+	}
+	var bugs []BatchBug
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&bugs); err != nil {
+		return nil, fmt.Errorf("parse batch %s: %w", path, err)
+	}
+	if len(bugs) == 0 {
+		return nil, fmt.Errorf("batch %s has no bugs", path)
+	}
+	for i, bug := range bugs {
+		if strings.TrimSpace(bug.BugDescription) == "" {
+			return nil, fmt.Errorf("batch %s: bug #%d has no bug_description", path, i+1)
+		}
+	}
+	return bugs, nil
+}
+
+// RunBatch verifies bugs with at most maxParallel running at once; the next bug
+// starts only when a running one finishes. A bug whose verify fails (or panics)
+// gets a Result with status "error" and does not stop the others. Once ctx ends
+// no further bugs are started: they get status "skipped_deadline", or
+// "skipped_interrupted" when ctx was cancelled rather than timed out. Bugs
+// already running see the cancelled ctx and finish with whatever they return
+// (their MCP branches are not cancelled and keep running remotely);
+// any still running grace later are reported as "interrupted" and abandoned, so
+// the results finished so far are not held hostage. A non-positive grace waits
+// for them indefinitely.
+func RunBatch(ctx context.Context, bugs []BatchBug, maxParallel int, grace time.Duration, verifyBug func(context.Context, BatchBug) (*Result, error)) *BatchReport {
+	if maxParallel <= 0 {
+		maxParallel = DefaultMaxParallel
+	}
+	started := time.Now()
+	var (
+		mu        sync.Mutex
+		results   = make([]*Result, len(bugs))
+		abandoned bool
+	)
+	slots := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, bug := range bugs {
+		if !acquireSlot(ctx, slots) {
+			status := skippedStatus(ctx)
+			logx.Warningf("Batch stopped (%v); skipping %d unstarted bug(s).", ctx.Err(), len(bugs)-i)
+			mu.Lock()
+			for j := i; j < len(bugs); j++ {
+				results[j] = &Result{
+					BugDescription: strings.TrimSpace(bugs[j].BugDescription),
+					Status:         status,
+					Summary:        fmt.Sprintf("not started: %v", ctx.Err()),
+					PromptVersion:  PromptVersion,
+				}
+			}
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func(i int, bug BatchBug) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			res := verifyOne(ctx, i, bug, verifyBug)
+			mu.Lock()
+			defer mu.Unlock()
+			if !abandoned {
+				results[i] = res
+			}
+		}(i, bug)
+	}
+	waitForBugs(ctx, &wg, grace)
+
+	mu.Lock()
+	abandoned = true
+	for i, res := range results {
+		if res == nil {
+			results[i] = &Result{
+				BugDescription: strings.TrimSpace(bugs[i].BugDescription),
+				Status:         statusInterrupted,
+				Summary:        fmt.Sprintf("still running %s after the batch stopped (%v); abandoned", grace, ctx.Err()),
+				PromptVersion:  PromptVersion,
+			}
+		}
+	}
+	mu.Unlock()
+
+	report := &BatchReport{Results: results, Total: len(results), Counts: make(map[string]int)}
+	for _, res := range results {
+		report.Counts[res.Status]++
+		if res.Headline == "" {
+			res.Headline = buildHeadline(res, 0)
+		}
+	}
+	report.Headline = buildBatchHeadline(report, time.Since(started))
+	return report
+}
+
+// waitForBugs waits for the running bugs, but no longer than grace past the end
+// of ctx (indefinitely when grace is not positive).
+func waitForBugs(ctx context.Context, wg *sync.WaitGroup, grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+	if grace <= 0 {
+		<-done
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(grace):
+		logx.Warningf("Batch bugs still running %s after the batch stopped; reporting them as interrupted.", grace)
+	}
+}
+
+func skippedStatus(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return statusSkippedDeadline
+	}
+	return statusSkippedInterrupted
+}
+
+// acquireSlot takes a slot, reporting false if ctx ended first. An ended ctx
+// wins even when a slot is free, so nothing starts after the deadline.
+func acquireSlot(ctx context.Context, slots chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func verifyOne(ctx context.Context, i int, bug BatchBug, verifyBug func(context.Context, BatchBug) (*Result, error)) (res *Result) {
+	defer func() {
+		if p := recover(); p != nil {
+			res = errorResult(bug, fmt.Errorf("panic: %v", p))
+		}
+		if res.Status == statusError {
+			logx.Errorf("Batch bug #%d failed: %s", i+1, res.Summary)
+		} else {
+			logx.Infof("Batch bug #%d finished: %s", i+1, res.Status)
+		}
+	}()
+	res, err := verifyBug(ctx, bug)
+	if err == nil && res == nil {
+		err = errors.New("verify returned no result")
+	}
+	if err != nil {
+		return errorResult(bug, err)
+	}
+	return res
+}
+
+func errorResult(bug BatchBug, err error) *Result {
+	return &Result{
+		BugDescription: strings.TrimSpace(bug.BugDescription),
+		Status:         statusError,
+		Summary:        err.Error(),
+		PromptVersion:  PromptVersion,
+	}
+}
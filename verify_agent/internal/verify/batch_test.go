@@ -0,0 +1,158 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunBatchBoundsConcurrencyAndKeepsOrder(t *testing.T) {
+	var bugs []BatchBug
+	for i := 0; i < 10; i++ {
+		bugs = append(bugs, BatchBug{BugDescription: fmt.Sprintf("bug %d", i)})
+	}
+	var (
+		mu            sync.Mutex
+		running, peak int
+	)
+	report := RunBatch(context.Background(), bugs, 3, 0, func(_ context.Context, bug BatchBug) (*Result, error) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+		// Later bugs finish first, so completion order differs from input order.
+		var n int
+		fmt.Sscanf(bug.BugDescription, "bug %d", &n)
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		switch n {
+		case 4:
+			return nil, errors.New("MCP HTTP 503")
+		case 7:
+			panic("nil map")
+		}
+		return &Result{BugDescription: bug.BugDescription, Status: statusBugConfirmed}, nil
+	})
+
+	if peak > 3 {
+		t.Fatalf("expected at most 3 bugs in flight, saw %d", peak)
+	}
+	if len(report.Results) != 10 || report.Total != 10 {
+		t.Fatalf("expected 10 results, got %d (total %d)", len(report.Results), report.Total)
+	}
+	for i, res := range report.Results {
+		if res.BugDescription != fmt.Sprintf("bug %d", i) {
+			t.Fatalf("result %d is for %q; input order not preserved", i, res.BugDescription)
+		}
+	}
+	if report.Results[4].Status != statusError || report.Results[4].Summary != "MCP HTTP 503" {
+		t.Fatalf("expected bug 4 to record its error, got %+v", report.Results[4])
+	}
+	if report.Results[7].Status != statusError {
+		t.Fatalf("expected bug 7's panic to be recorded, got %+v", report.Results[7])
+	}
+	if report.Counts[statusBugConfirmed] != 8 || report.Counts[statusError] != 2 {
+		t.Fatalf("unexpected counts %v", report.Counts)
+	}
+	if !strings.HasPrefix(report.Headline, "Batch of 10 bugs: 8 bug_confirmed, 2 error (") {
+		t.Fatalf("unexpected batch headline %q", report.Headline)
+	}
+	if got := report.Results[4].Headline; got != `Verification of "bug 4": verification failed.` {
+		t.Fatalf("unexpected headline for the failed bug: %q", got)
+	}
+	if !report.HasFindings() {
+		t.Fatal("expected confirmed bugs to count as findings")
+	}
+	wrong := &BatchReport{Results: []*Result{{Status: statusBugWrong}, {Status: statusError}}}
+	if wrong.HasFindings() {
+		t.Fatal("expected refuted and failed bugs not to count as findings")
+	}
+	if report.AllFailed() {
+		t.Fatal("a batch with verified bugs has not failed outright")
+	}
+	failed := RunBatch(context.Background(), bugs[:2], 2, 0, func(context.Context, BatchBug) (*Result, error) {
+		return nil, errors.New("MCP HTTP 503")
+	})
+	if !failed.AllFailed() {
+		t.Fatalf("expected a batch whose every bug errored to count as failed, got %v", failed.Counts)
+	}
+}
+
+func TestRunBatchSkipsUnstartedBugsAfterDeadline(t *testing.T) {
+	bugs := []BatchBug{{BugDescription: "bug 0"}, {BugDescription: "bug 1"}, {BugDescription: "bug 2"}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report := RunBatch(ctx, bugs, 1, 0, func(ctx context.Context, bug BatchBug) (*Result, error) {
+		<-ctx.Done() // the first bug runs past the deadline
+		return nil, ctx.Err()
+	})
+
+	if report.Results[0].Status != statusError {
+		t.Fatalf("expected the in-flight bug to be cancelled, got %+v", report.Results[0])
+	}
+	for _, res := range report.Results[1:] {
+		if res.Status != statusSkippedDeadline {
+			t.Fatalf("expected unstarted bugs to be skipped, got %+v", res)
+		}
+	}
+	if report.Counts[statusSkippedDeadline] != 2 || report.Total != 3 {
+		t.Fatalf("unexpected counts %v", report.Counts)
+	}
+}
+
+func TestLoadBatchRejectsEmptyDescriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bugs.json")
+	if err := os.WriteFile(path, []byte(`[{"bug_description": "nil deref in Get", "false_positive": true}, {"code_context": "x"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBatch(path); err == nil {
+		t.Fatal("expected an error for a bug without a description")
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"bug_description": "nil deref in Get", "false_positive": true}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bugs, err := LoadBatch(path)
+	if err != nil {
+		t.Fatalf("LoadBatch error: %v", err)
+	}
+	if len(bugs) != 1 || bugs[0].IsFalsePositive == nil || !*bugs[0].IsFalsePositive {
+		t.Fatalf("unexpected bugs %+v", bugs)
+	}
+}
+
+func TestRunBatchKeepsFinishedResultsWhenInterrupted(t *testing.T) {
+	bugs := []BatchBug{{BugDescription: "done"}, {BugDescription: "stuck"}, {BugDescription: "queued"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	defer close(release)
+	report := RunBatch(ctx, bugs, 2, 20*time.Millisecond, func(ctx context.Context, bug BatchBug) (*Result, error) {
+		if bug.BugDescription == "done" {
+			<-ctx.Done() // finishes after the interrupt, so "queued" never gets its slot
+			return &Result{BugDescription: bug.BugDescription, Status: statusBugConfirmed}, nil
+		}
+		cancel()  // Ctrl-C arrives while this bug runs...
+		<-release // ...and it ignores the cancelled ctx
+		return &Result{BugDescription: bug.BugDescription, Status: statusBugConfirmed}, nil
+	})
+
+	want := []string{statusBugConfirmed, statusInterrupted, statusSkippedInterrupted}
+	for i, res := range report.Results {
+		if res.Status != want[i] {
+			t.Fatalf("result %d: expected %q, got %+v", i, want[i], res)
+		}
+	}
+	if report.Counts[statusBugConfirmed] != 1 || report.Total != 3 {
+		t.Fatalf("unexpected counts %v", report.Counts)
+	}
+}
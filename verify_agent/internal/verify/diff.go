@@ -0,0 +1,53 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MaxDiffBytes caps how much of a --diff-file goes into the prompts; the rest
+// is left for the agents to read from the branch.
+const MaxDiffBytes = 64 * 1024
+
+// ReadDiffFile reads a unified diff for Options.Diff.
+func ReadDiffFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read diff: %w", err)
+	}
+	diff := strings.TrimSpace(string(data))
+	if diff == "" {
+		return "", fmt.Errorf("diff file %s is empty", path)
+	}
+	return diff, nil
+}
+
+// withDiff appends the diff under scrutiny to the code context, cut at a line
+// boundary once it exceeds MaxDiffBytes.
+func withDiff(codeContext string, diff string) string {
+	diff = strings.TrimSpace(diff)
+	if diff == "" {
+		return codeContext
+	}
+	note := ""
+	if len(diff) > MaxDiffBytes {
+		cut := strings.LastIndex(diff[:MaxDiffBytes], "\n")
+		if cut <= 0 {
+			cut = MaxDiffBytes
+		}
+		note = fmt.Sprintf("\n[diff truncated: first %d of %d bytes shown; read the rest from the repository]", cut, len(diff))
+		diff = diff[:cut]
+	}
+	var sb strings.Builder
+	if codeContext != "" {
+		sb.WriteString(codeContext)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("The following changes are under scrutiny; the bug, if real, is expected to be introduced or exposed by them:\n")
+	sb.WriteString("```diff\n")
+	sb.WriteString(diff)
+	sb.WriteString("\n```")
+	sb.WriteString(note)
+	return sb.String()
+}
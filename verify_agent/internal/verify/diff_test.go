@@ -0,0 +1,38 @@
+package verify
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithDiffAppendsAndCapsTheDiff(t *testing.T) {
+	if got := withDiff("ctx", "  "); got != "ctx" {
+		t.Fatalf("expected no change without a diff, got %q", got)
+	}
+	got := withDiff("cache.go owns the map", "--- a/cache.go\n+++ b/cache.go\n@@ -1 +1 @@\n-x\n+y")
+	if !strings.HasPrefix(got, "cache.go owns the map\n\n") || !strings.Contains(got, "under scrutiny") || !strings.Contains(got, "```diff\n--- a/cache.go") {
+		t.Fatalf("unexpected context:\n%s", got)
+	}
+
+	line := "+" + strings.Repeat("x", 99) + "\n"
+	big := strings.Repeat(line, MaxDiffBytes/len(line)+10)
+	got = withDiff("", big)
+	if len(got) > MaxDiffBytes+500 || !strings.Contains(got, "[diff truncated: first ") {
+		t.Fatalf("expected a capped diff with a note, got %d bytes", len(got))
+	}
+	if !strings.Contains(got, strings.TrimSuffix(line, "\n")+"\n```") {
+		t.Fatal("expected the diff to be cut at a line boundary")
+	}
+}
+
+func TestReadDiffFileRejectsEmptyFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "change.diff")
+	if err := os.WriteFile(path, []byte("\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDiffFile(path); err == nil {
+		t.Fatal("expected an error for an empty diff")
+	}
+}
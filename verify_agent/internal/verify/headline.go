@@ -0,0 +1,72 @@
+package verify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// headlineBugChars caps how much of the bug description a headline quotes.
+const headlineBugChars = 60
+
+// statusPhrases words each Result status for a headline.
+var statusPhrases = map[string]string{
+	statusBugConfirmed:         "bug confirmed",
+	statusBugWrong:             "not a real bug",
+	statusCannotDisprove:       "could not be disproved",
+	statusAssumptionOverturned: "false-positive assumption overturned, bug is real",
+	statusError:                "verification failed",
+	statusSkippedDeadline:      "not started before the batch deadline",
+	statusSkippedInterrupted:   "not started, batch interrupted",
+	statusInterrupted:          "abandoned after the batch stopped",
+}
+
+// buildHeadline templates a one-sentence summary of res, e.g. `Verification of
+// "nil deref in Get": bug confirmed (3m10s).` A non-positive elapsed leaves the
+// duration out.
+func buildHeadline(res *Result, elapsed time.Duration) string {
+	bug := strings.TrimSpace(res.BugDescription)
+	if line, _, ok := strings.Cut(bug, "\n"); ok {
+		bug = strings.TrimSpace(line) + "..."
+	}
+	if runes := []rune(bug); len(runes) > headlineBugChars {
+		bug = strings.TrimSpace(string(runes[:headlineBugChars])) + "..."
+	}
+	outcome, ok := statusPhrases[res.Status]
+	if !ok {
+		outcome = res.Status
+	}
+	return fmt.Sprintf("Verification of %q: %s%s.", bug, outcome, durationSuffix(elapsed))
+}
+
+// buildBatchHeadline summarizes a batch as its status counts, e.g. "Batch of 10
+// bugs: 8 bug_confirmed, 2 error (12m3s)."
+func buildBatchHeadline(report *BatchReport, elapsed time.Duration) string {
+	statuses := make([]string, 0, len(report.Counts))
+	for status := range report.Counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if report.Counts[statuses[i]] != report.Counts[statuses[j]] {
+			return report.Counts[statuses[i]] > report.Counts[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = fmt.Sprintf("%d %s", report.Counts[status], status)
+	}
+	noun := "bugs"
+	if report.Total == 1 {
+		noun = "bug"
+	}
+	return fmt.Sprintf("Batch of %d %s: %s%s.", report.Total, noun, strings.Join(parts, ", "), durationSuffix(elapsed))
+}
+
+func durationSuffix(elapsed time.Duration) string {
+	if elapsed <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", elapsed.Round(time.Second))
+}
@@ -0,0 +1,26 @@
+package verify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHeadline(t *testing.T) {
+	cases := []struct {
+		res     *Result
+		elapsed time.Duration
+		want    string
+	}{
+		{&Result{BugDescription: "nil deref in Get", Status: statusBugConfirmed}, 3*time.Minute + 10*time.Second,
+			`Verification of "nil deref in Get": bug confirmed (3m10s).`},
+		{&Result{BugDescription: "cache key drops tenant\nsee store.go:42", Status: statusBugWrong}, 0,
+			`Verification of "cache key drops tenant...": not a real bug.`},
+		{&Result{BugDescription: "Retry loop in the uploader never backs off when the server answers 429 repeatedly", Status: "custom"}, 0,
+			`Verification of "Retry loop in the uploader never backs off when the server a...": custom.`},
+	}
+	for _, tc := range cases {
+		if got := buildHeadline(tc.res, tc.elapsed); got != tc.want {
+			t.Errorf("buildHeadline = %q, want %q", got, tc.want)
+		}
+	}
+}
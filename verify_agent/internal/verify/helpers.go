@@ -42,8 +42,9 @@ const realBugStudyLine = "Read as much as you can, you have unlimited read quota
 	"- Focus on correctness first, efficiency second\n" +
 	"- Your final report MUST clearly state: (1) Why the bug is real, (2) What the actual bug behavior is, (3) Evidence supporting your confirmation"
 
-// buildFormalizationPrompt creates the prompt for Task 1: Bug Claim Formalization Agent
-func buildFormalizationPrompt(bugDescription string, codeContext string, isFalsePositive bool) string {
+// buildFormalizationPrompt creates the prompt for Task 1: Bug Claim Formalization Agent.
+// withDiagnostics asks an INVALID verdict to also spell out what the description lacks.
+func buildFormalizationPrompt(bugDescription string, codeContext string, isFalsePositive bool, withDiagnostics bool) string {
 	var sb strings.Builder
 	sb.WriteString("Task 1: Bug Claim Formalization Agent\n\n")
 
@@ -105,6 +106,17 @@ func buildFormalizationPrompt(bugDescription string, codeContext string, isFalse
 		sb.WriteString("## Reason\n")
 		sb.WriteString("<Why the bug claim cannot be formalized>\n\n")
 	}
+	if withDiagnostics {
+		sb.WriteString("## Diagnostics\n")
+		sb.WriteString("<What the bug description would need in order to be formalized, for whoever wrote it>\n")
+		sb.WriteString("```json\n")
+		sb.WriteString("{\n")
+		sb.WriteString("  \"missing_file_references\": [\"<files, functions or types the description should name but does not>\"],\n")
+		sb.WriteString("  \"ambiguous_terms\": [\"<terms in the description that could mean more than one thing here>\"],\n")
+		sb.WriteString("  \"unformalizable_reason\": \"<one sentence: why no precondition/path/postcondition could be extracted>\"\n")
+		sb.WriteString("}\n")
+		sb.WriteString("```\n\n")
+	}
 	sb.WriteString("If STATUS is VALID, provide:\n")
 	sb.WriteString("## Judgment\n")
 	sb.WriteString("<Your judgment: The bug claim is valid and can be formalized>\n\n")
@@ -121,8 +133,31 @@ func buildFormalizationPrompt(bugDescription string, codeContext string, isFalse
 	return sb.String()
 }
 
+// buildFormalizationRepromptPrompt asks the Task 1 agent, continuing on its own branch,
+// to restate its VALID formalization as a bare JSON assertion block.
+func buildFormalizationRepromptPrompt(parseErr string) string {
+	var sb strings.Builder
+	sb.WriteString("Task 1 (follow-up): Formalized Assertion Output\n\n")
+	sb.WriteString("Your previous response judged the bug claim VALID, but its formalized assertion could not be parsed")
+	if strings.TrimSpace(parseErr) != "" {
+		sb.WriteString(" (")
+		sb.WriteString(strings.TrimSpace(parseErr))
+		sb.WriteString(")")
+	}
+	sb.WriteString(".\n\n")
+	sb.WriteString("Do NOT redo the analysis. Output ONLY the JSON assertion block for the formalization you already made, exactly in this shape:\n")
+	sb.WriteString("```json\n")
+	sb.WriteString("{\n")
+	sb.WriteString("  \"precondition\": \"<conditions that must be true>\",\n")
+	sb.WriteString("  \"path\": \"<execution path or code flow>\",\n")
+	sb.WriteString("  \"postcondition\": \"<incorrect state or behavior>\"\n")
+	sb.WriteString("}\n")
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
 // buildReachabilityPrompt creates the prompt for Task 2: Reachability Analysis Agent
-func buildReachabilityPrompt(formalizedAssertion string, codeContext string, isFalsePositive bool) string {
+func buildReachabilityPrompt(formalizedAssertion string, codeContext string, symbols []ChangedSymbol, isFalsePositive bool) string {
 	var sb strings.Builder
 	sb.WriteString("Task 2: Reachability Analysis Agent\n\n")
 
@@ -141,6 +176,7 @@ func buildReachabilityPrompt(formalizedAssertion string, codeContext string, isF
 		sb.WriteString(codeContext)
 		sb.WriteString("\n\n")
 	}
+	writeChangedSymbols(&sb, symbols)
 	if isFalsePositive {
 		sb.WriteString("YOUR TASK: Determine if the precondition and path described in the assertion are reachable with valid inputs, and FIND THE PROBLEM with the claim.\n\n")
 		sb.WriteString("**REMEMBER: This bug claim is a FALSE POSITIVE. Your job is to find why the claimed state cannot be reached.**\n\n")
@@ -208,7 +244,7 @@ func buildReachabilityPrompt(formalizedAssertion string, codeContext string, isF
 }
 
 // buildTestGeneratorPrompt creates the prompt for Task 3: Test Generator Agent
-func buildTestGeneratorPrompt(formalizedAssertion string, reachabilityAnalysis string, codeContext string, isFalsePositive bool) string {
+func buildTestGeneratorPrompt(formalizedAssertion string, reachabilityAnalysis string, codeContext string, symbols []ChangedSymbol, isFalsePositive bool) string {
 	var sb strings.Builder
 	sb.WriteString("Task 3: Test Generator Agent\n\n")
 
@@ -230,6 +266,7 @@ func buildTestGeneratorPrompt(formalizedAssertion string, reachabilityAnalysis s
 		sb.WriteString(codeContext)
 		sb.WriteString("\n\n")
 	}
+	writeChangedSymbols(&sb, symbols)
 	if isFalsePositive {
 		sb.WriteString("YOUR TASK: Generate a minimal test case that can verify or refute the bug claim, and FIND THE PROBLEM with the claim.\n\n")
 		sb.WriteString("**REMEMBER: This bug claim is a FALSE POSITIVE. Your job is to demonstrate why it's wrong through testing.**\n\n")
@@ -323,7 +360,8 @@ type FormalizedAssertion struct {
 	Postcondition string `json:"postcondition"`
 }
 
-// parseFormalizedAssertion extracts the JSON assertion from the response
+// parseFormalizedAssertion extracts the JSON assertion from the response. When no
+// usable JSON is present it falls back to "Precondition:/Path:/Postcondition:" lines.
 func parseFormalizedAssertion(response string) (FormalizedAssertion, error) {
 	var assertion FormalizedAssertion
 
@@ -333,11 +371,82 @@ func parseFormalizedAssertion(response string) (FormalizedAssertion, error) {
 		return assertion, fmt.Errorf("no JSON block found in response")
 	}
 
-	if err := json.Unmarshal([]byte(jsonBlock), &assertion); err != nil {
-		return assertion, fmt.Errorf("failed to parse JSON: %w", err)
+	jsonErr := json.Unmarshal([]byte(jsonBlock), &assertion)
+	if jsonErr == nil && assertion != (FormalizedAssertion{}) {
+		return assertion, nil
+	}
+	if labeled, ok := extractLabeledAssertion(response); ok {
+		return labeled, nil
+	}
+	if jsonErr != nil {
+		return assertion, fmt.Errorf("failed to parse JSON: %w", jsonErr)
+	}
+	return assertion, fmt.Errorf("JSON block has no precondition, path or postcondition")
+}
+
+// extractLabeledAssertion reads an assertion written as labeled prose lines such
+// as "- **Precondition**: cache is empty" instead of a JSON block. Continuation lines are appended to the current field until a blank line,
+// heading, or the next label. All three fields must be present.
+func extractLabeledAssertion(response string) (FormalizedAssertion, bool) {
+	var assertion FormalizedAssertion
+	var current *string
+	for _, rawLine := range strings.Split(response, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") {
+			current = nil
+			continue
+		}
+		if label, value, ok := splitAssertionLabel(line); ok {
+			switch label {
+			case "precondition":
+				current = &assertion.Precondition
+			case "path":
+				current = &assertion.Path
+			case "postcondition":
+				current = &assertion.Postcondition
+			}
+			if *current != "" {
+				// First occurrence wins; later mentions are usually analysis prose.
+				current = nil
+				continue
+			}
+			*current = value
+			continue
+		}
+		if current != nil {
+			if *current == "" {
+				*current = line
+			} else {
+				*current += " " + line
+			}
+		}
 	}
+	ok := assertion.Precondition != "" && assertion.Path != "" && assertion.Postcondition != ""
+	return assertion, ok
+}
 
-	return assertion, nil
+// splitAssertionLabel recognizes "Label: value" lines, tolerating list markers,
+// bold markers and quoted JSON-style keys.
+func splitAssertionLabel(line string) (string, string, bool) {
+	line = strings.TrimLeft(line, "-*+> \t")
+	if i := strings.Index(line, ". "); i > 0 && i <= 3 && strings.Trim(line[:i], "0123456789") == "" {
+		line = strings.TrimSpace(line[i+2:])
+	}
+	colon := strings.Index(line, ":")
+	if colon <= 0 {
+		return "", "", false
+	}
+	label := strings.ToLower(strings.Trim(line[:colon], "*_\"` "))
+	switch label {
+	case "precondition", "path", "postcondition":
+	default:
+		return "", "", false
+	}
+	value := strings.TrimSpace(line[colon+1:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "**"))
+	value = strings.TrimSuffix(value, ",")
+	value = strings.Trim(value, "\"")
+	return label, value, true
 }
 
 func extractJSONBlock(raw string) string {
@@ -419,3 +528,200 @@ func extractStatus(response string, validStatuses []string) string {
 	}
 	return ""
 }
+
+// Header names accepted for Task 3's test sections, in order of preference. The
+// prompt asks for the first of each; the rest are variants agents drift into.
+var (
+	testCaseHeaders      = []string{"Test Case", "Test Code", "Reproduction Test", "Test"}
+	testExecutionHeaders = []string{"Test Execution", "Test Output", "Test Results", "Test Result", "Execution"}
+)
+
+// diagnosticsHeaders are the accepted titles of Task 1's diagnostics section.
+var diagnosticsHeaders = []string{"Diagnostics", "Diagnostic"}
+
+// extractDiagnostics reads the JSON block of Task 1's Diagnostics section. When
+// the block is missing, malformed or empty, the prose reason stands in as the
+// unformalizable reason; nil means there is nothing to report at all.
+func extractDiagnostics(response string, reason string) *Diagnostics {
+	var diag Diagnostics
+	if section := extractMarkdownSection(response, diagnosticsHeaders...); section != "" {
+		if block := extractJSONBlock(section); block != "" {
+			if json.Unmarshal([]byte(block), &diag) != nil {
+				diag = Diagnostics{}
+			}
+		}
+	}
+	diag.MissingFileReferences = nonEmptyStrings(diag.MissingFileReferences)
+	diag.AmbiguousTerms = nonEmptyStrings(diag.AmbiguousTerms)
+	diag.UnformalizableReason = strings.TrimSpace(diag.UnformalizableReason)
+	if diag.UnformalizableReason == "" {
+		diag.UnformalizableReason = strings.TrimSpace(reason)
+	}
+	if len(diag.MissingFileReferences) == 0 && len(diag.AmbiguousTerms) == 0 && diag.UnformalizableReason == "" {
+		return nil
+	}
+	return &diag
+}
+
+func nonEmptyStrings(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// extractTestCode returns the first fenced block of the Test Case section, or
+// the raw section text when the agent did not fence its code. It returns nil
+// when there is no such section.
+func extractTestCode(response string) *TestCode {
+	section := extractMarkdownSection(response, testCaseHeaders...)
+	if section == "" {
+		return nil
+	}
+	if lang, code, ok := firstFencedBlock(section); ok {
+		return &TestCode{Language: lang, Content: code}
+	}
+	return &TestCode{Content: section}
+}
+
+// extractTestOutput returns the first fenced block of the Test Execution
+// section, falling back to the raw section text.
+func extractTestOutput(response string) string {
+	section := extractMarkdownSection(response, testExecutionHeaders...)
+	if _, out, ok := firstFencedBlock(section); ok {
+		return out
+	}
+	return section
+}
+
+// extractMarkdownSection returns the body of the first heading (any level, any
+// case, optionally followed by ":" or a parenthetical) named by one of names,
+// trying names in order. Unlike extractSection it does not end the section on a
+// "#" line inside a code fence, and keeps deeper sub-headings.
+func extractMarkdownSection(response string, names ...string) string {
+	lines := strings.Split(response, "\n")
+	for _, name := range names {
+		start, level := -1, 0
+		var fence string
+		for i, line := range lines {
+			if fence, _ = trackFence(fence, line); fence != "" {
+				continue
+			}
+			if l, title := markdownHeading(line); l > 0 && headingNames(title, name) {
+				start, level = i+1, l
+				break
+			}
+		}
+		if start < 0 {
+			continue
+		}
+		var body strings.Builder
+		fence = ""
+		for _, line := range lines[start:] {
+			var inFence bool
+			fence, inFence = trackFence(fence, line)
+			if !inFence {
+				if l, _ := markdownHeading(line); l > 0 && l <= level {
+					break
+				}
+			}
+			body.WriteString(line)
+			body.WriteString("\n")
+		}
+		if section := strings.TrimSpace(body.String()); section != "" {
+			return section
+		}
+	}
+	return ""
+}
+
+// firstFencedBlock returns the info-string language and content of the first
+// ``` or ~~~ block in text. An unterminated block runs to the end of text.
+func firstFencedBlock(text string) (string, string, bool) {
+	var (
+		fence, lang string
+		content     strings.Builder
+		found       bool
+	)
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence == "" {
+			if marker := fenceMarker(trimmed); marker != "" {
+				fence = marker
+				lang = strings.ToLower(strings.TrimSpace(strings.TrimLeft(trimmed, marker[:1])))
+				if fields := strings.Fields(lang); len(fields) > 0 {
+					lang = fields[0]
+				}
+				found = true
+			}
+			continue
+		}
+		if closesFence(fence, trimmed) {
+			break
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+	if !found {
+		return "", "", false
+	}
+	return lang, strings.TrimRight(content.String(), "\n"), true
+}
+
+// trackFence updates the open fence marker for line and reports whether line is
+// part of a fenced block (including its opening and closing lines).
+func trackFence(open, line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if open == "" {
+		if marker := fenceMarker(trimmed); marker != "" {
+			return marker, true
+		}
+		return "", false
+	}
+	if closesFence(open, trimmed) {
+		return "", true
+	}
+	return open, true
+}
+
+// fenceMarker returns the run of three or more backticks or tildes opening line.
+func fenceMarker(line string) string {
+	for _, ch := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, ch))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// closesFence reports whether the trimmed line closes a block opened by open: a
+// bare run of the same character at least as long.
+func closesFence(open, trimmed string) bool {
+	return strings.HasPrefix(trimmed, open) && strings.Trim(trimmed, open[:1]) == ""
+}
+
+// markdownHeading returns the level and title of an ATX heading, or 0.
+func markdownHeading(line string) (int, string) {
+	trimmed := strings.TrimSpace(line)
+	level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+	if level == 0 || level > 6 || (len(trimmed) > level && trimmed[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.Trim(trimmed[level:], "# *"))
+}
+
+// headingNames reports whether title is name, ignoring case, trailing ":" and a
+// trailing qualifier such as "(Go)".
+func headingNames(title, name string) bool {
+	title = strings.ToLower(strings.TrimSpace(title))
+	name = strings.ToLower(name)
+	if !strings.HasPrefix(title, name) {
+		return false
+	}
+	rest := strings.TrimSpace(title[len(name):])
+	return rest == "" || strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "(") || strings.HasPrefix(rest, "-")
+}
@@ -0,0 +1,104 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFormalizedAssertionFencedJSON(t *testing.T) {
+	response := "# STATUS: VALID\n\n## Formalized Assertion\n```json\n{\n  \"precondition\": \"cache is empty\",\n  \"path\": \"Get -> loadMissing\",\n  \"postcondition\": \"nil dereference\"\n}\n```\n\n## Analysis\nok"
+	got, err := parseFormalizedAssertion(response)
+	if err != nil {
+		t.Fatalf("parseFormalizedAssertion error: %v", err)
+	}
+	want := FormalizedAssertion{Precondition: "cache is empty", Path: "Get -> loadMissing", Postcondition: "nil dereference"}
+	if got != want {
+		t.Fatalf("expected %#v, got %#v", want, got)
+	}
+}
+
+func TestParseFormalizedAssertionProseLabels(t *testing.T) {
+	response := "# STATUS: VALID\n\n## Formalized Assertion\n" +
+		"- **Precondition**: cache is empty and\n  the loader is disabled\n" +
+		"- **Path:** Get -> loadMissing\n" +
+		"3. Postcondition: nil dereference in loadMissing\n\n" +
+		"## Analysis\nPath: this line is outside the assertion section"
+	got, err := parseFormalizedAssertion(response)
+	if err != nil {
+		t.Fatalf("parseFormalizedAssertion error: %v", err)
+	}
+	if got.Precondition != "cache is empty and the loader is disabled" {
+		t.Fatalf("unexpected precondition %q", got.Precondition)
+	}
+	if got.Postcondition != "nil dereference in loadMissing" {
+		t.Fatalf("unexpected postcondition %q", got.Postcondition)
+	}
+	if got.Path != "Get -> loadMissing" {
+		t.Fatalf("unexpected path %q", got.Path)
+	}
+}
+
+func TestParseFormalizedAssertionMissingFieldFails(t *testing.T) {
+	response := "# STATUS: VALID\n\nPrecondition: cache is empty\nPath: Get\n"
+	if _, err := parseFormalizedAssertion(response); err == nil {
+		t.Fatalf("expected error when postcondition is missing")
+	}
+}
+
+func TestExtractTestCodeAndOutputFromFencedSections(t *testing.T) {
+	response := "# STATUS: BUG_CONFIRMED\n\n" +
+		"### Test Case (Go)\nSave as cache_test.go:\n```go\nfunc TestGet(t *testing.T) {\n\t## not a heading\n}\n```\n\n" +
+		"## test output:\n~~~\n--- FAIL: TestGet\npanic: nil map\n~~~\n\n" +
+		"## Analysis\nGet panics."
+	code := extractTestCode(response)
+	if code == nil || code.Language != "go" {
+		t.Fatalf("expected Go test code, got %+v", code)
+	}
+	if code.Content != "func TestGet(t *testing.T) {\n\t## not a heading\n}" {
+		t.Fatalf("unexpected test code %q", code.Content)
+	}
+	if got := extractTestOutput(response); got != "--- FAIL: TestGet\npanic: nil map" {
+		t.Fatalf("unexpected test output %q", got)
+	}
+}
+
+func TestExtractTestCodeFallsBackToSectionText(t *testing.T) {
+	response := "## Test Case\nCall Get on an empty cache.\n\n## Test Execution\npanic: nil map\n\n## Analysis\nok"
+	code := extractTestCode(response)
+	if code == nil || code.Language != "" || code.Content != "Call Get on an empty cache." {
+		t.Fatalf("expected the raw section as test code, got %+v", code)
+	}
+	if got := extractTestOutput(response); got != "panic: nil map" {
+		t.Fatalf("unexpected test output %q", got)
+	}
+	if extractTestCode("## Analysis\nno test") != nil {
+		t.Fatal("expected no test code without a Test Case section")
+	}
+}
+
+func TestExtractDiagnosticsParsesJSONAndFallsBackToReason(t *testing.T) {
+	response := "# STATUS: INVALID\n\n## Reason\nToo vague.\n\n## Diagnostics\nThe report never says which cache.\n```json\n" +
+		"{\"missing_file_references\": [\"cache.go\", \" \"], \"ambiguous_terms\": [\"the cache\"], \"unformalizable_reason\": \"No entry point is named.\"}\n```\n\n## Analysis\nx"
+	diag := extractDiagnostics(response, "Too vague.")
+	if diag == nil || len(diag.MissingFileReferences) != 1 || diag.MissingFileReferences[0] != "cache.go" ||
+		len(diag.AmbiguousTerms) != 1 || diag.UnformalizableReason != "No entry point is named." {
+		t.Fatalf("unexpected diagnostics %+v", diag)
+	}
+
+	diag = extractDiagnostics("# STATUS: INVALID\n\n## Reason\nToo vague.", "Too vague.")
+	if diag == nil || diag.UnformalizableReason != "Too vague." || diag.AmbiguousTerms != nil {
+		t.Fatalf("expected the prose reason as fallback, got %+v", diag)
+	}
+	if diag := extractDiagnostics("## Diagnostics\n```json\n{not json}\n```", ""); diag != nil {
+		t.Fatalf("expected nil without usable diagnostics or reason, got %+v", diag)
+	}
+}
+
+func TestFormalizationPromptRequestsDiagnosticsOnlyWhenEnabled(t *testing.T) {
+	if strings.Contains(buildFormalizationPrompt("bug", "", false, false), "## Diagnostics") {
+		t.Fatal("expected no diagnostics section by default")
+	}
+	if !strings.Contains(buildFormalizationPrompt("bug", "", true, true), "missing_file_references") {
+		t.Fatal("expected the diagnostics JSON shape when enabled")
+	}
+}
@@ -1,10 +1,12 @@
 package verify
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -19,6 +21,29 @@ const (
 	statusBugConfirmed   = "bug_confirmed"
 	statusCannotDisprove = "cannot_disprove"
 	statusError          = "error"
+	// statusAssumptionOverturned marks a run whose test confirmed a bug the caller
+	// steered the agent to treat as a false positive.
+	statusAssumptionOverturned = "assumption_overturned"
+)
+
+// Pipeline modes order Task 2 (reachability) and Task 3 (test generation).
+// Sequential feeds Task 2's analysis into Task 3; fanout starts both from Task 1's
+// branch at once, trading that context for latency. In fanout an UNREACHABLE or
+// INVALID reachability verdict outranks the test, and BUG_REFUTED outranks the rest.
+const (
+	PipelineSequential = "sequential"
+	PipelineFanout     = "fanout"
+)
+
+// Inconclusive policies map a TEST_INCONCLUSIVE Task 3 in real-bug mode
+// (IsFalsePositive=false) to a final status. assume_real trusts the caller's
+// assumption and reports bug_confirmed, trading precision for recall;
+// cannot_disprove keeps bug_confirmed for reproduced bugs only; bug_wrong treats
+// a bug the test could not show as not real.
+const (
+	InconclusiveAssumeReal     = "assume_real"
+	InconclusiveCannotDisprove = "cannot_disprove"
+	InconclusiveBugWrong       = "bug_wrong"
 )
 
 // Options configures the verify workflow.
@@ -29,18 +54,67 @@ type Options struct {
 	WorkspaceDir    string
 	CodeContext     string // Optional: additional code context
 	IsFalsePositive bool   // If true, treat bug as false positive (虚假报警); if false, verify as real bug
+	// RepromptOnParseFailure re-asks Task 1 once for a bare JSON assertion before downgrading to INVALID.
+	RepromptOnParseFailure bool
+	// FlagAssumptionReversals reports a bug confirmed despite IsFalsePositive as
+	// assumption_overturned instead of bug_confirmed, so it gets a human look.
+	FlagAssumptionReversals bool
+	// PipelineMode is PipelineSequential (default) or PipelineFanout, which runs
+	// Task 2 and Task 3 concurrently from Task 1's branch and reconciles them.
+	PipelineMode string
+	// RequireTestEvidence reserves bug_confirmed for a Task 3 that reported
+	// BUG_CONFIRMED itself: an inconclusive or unlabeled test yields cannot_disprove
+	// instead of a confirmation assumed from IsFalsePositive=false.
+	RequireTestEvidence bool
+	// InconclusivePolicy is one of the Inconclusive* policies. Empty means
+	// assume_real, or cannot_disprove when RequireTestEvidence is set.
+	InconclusivePolicy string
+	// ChangedSymbols runs a quick step after Task 1 that lists the functions and
+	// types the change modifies, and names them in the Task 2 and Task 3 prompts.
+	// The step soft-fails: without a usable list the tasks run unanchored.
+	ChangedSymbols bool
+	// Diagnostics asks Task 1 for a structured account of what an unformalizable
+	// bug description is missing, reported as Result.Diagnostics.
+	Diagnostics bool
+	// Diff is a unified diff of the change the bug is suspected in. NewRunner
+	// appends it (capped at MaxDiffBytes) to CodeContext.
+	Diff string
 }
 
+// PromptVersion identifies the generation of the Task 1-3 prompts and is
+// recorded on every Result; bump it when a prompt change could shift statuses.
+const PromptVersion = "v1.0"
+
 // Result captures the verification outcome.
 type Result struct {
-	BugDescription string       `json:"bug_description"`
-	Status         string       `json:"status"`
-	Summary        string       `json:"summary"`
-	Task1Result    *Task1Result `json:"task1_result,omitempty"`
-	Task2Result    *Task2Result `json:"task2_result,omitempty"`
-	Task3Result    *Task3Result `json:"task3_result,omitempty"`
-	StartBranchID  string       `json:"start_branch_id,omitempty"`
-	LatestBranchID string       `json:"latest_branch_id,omitempty"`
+	BugDescription string          `json:"bug_description"`
+	Status         string          `json:"status"`
+	Summary        string          `json:"summary"`
+	Task1Result    *Task1Result    `json:"task1_result,omitempty"`
+	Task2Result    *Task2Result    `json:"task2_result,omitempty"`
+	Task3Result    *Task3Result    `json:"task3_result,omitempty"`
+	ChangedSymbols []ChangedSymbol `json:"changed_symbols,omitempty"`
+	Diagnostics    *Diagnostics    `json:"diagnostics,omitempty"`
+	StartBranchID  string          `json:"start_branch_id,omitempty"`
+	LatestBranchID string          `json:"latest_branch_id,omitempty"`
+	PromptVersion  string          `json:"prompt_version,omitempty"`
+	// Headline is a one-sentence TL;DR of the outcome, templated without an LLM call.
+	Headline string `json:"headline,omitempty"`
+}
+
+// HasFindings reports whether the run confirmed the bug, or overturned the
+// caller's false-positive assumption; under --fail-on-issues the CLI turns
+// either into the Findings exit status.
+func (r *Result) HasFindings() bool {
+	return r != nil && (r.Status == statusBugConfirmed || r.Status == statusAssumptionOverturned)
+}
+
+// Diagnostics explains why Task 1 could not formalize a bug claim, so the
+// finder that wrote the description can improve it.
+type Diagnostics struct {
+	MissingFileReferences []string `json:"missing_file_references,omitempty"`
+	AmbiguousTerms        []string `json:"ambiguous_terms,omitempty"`
+	UnformalizableReason  string   `json:"unformalizable_reason,omitempty"`
 }
 
 // Task1Result represents the output of Task 1: Bug Claim Formalization
@@ -53,6 +127,7 @@ type Task1Result struct {
 	Response            string               `json:"response"`
 	Reason              string               `json:"reason,omitempty"`
 	Analysis            string               `json:"analysis,omitempty"`
+	RepromptBranchID    string               `json:"reprompt_branch_id,omitempty"`
 }
 
 // Task2Result represents the output of Task 2: Reachability Analysis
@@ -77,7 +152,17 @@ type Task3Result struct {
 	Response            string `json:"response"`
 	TestCase            string `json:"test_case,omitempty"`
 	TestExecution       string `json:"test_execution,omitempty"`
-	Analysis            string `json:"analysis,omitempty"`
+	// TestCode and TestOutput are the fenced blocks of the two sections above,
+	// or their raw text when the agent did not fence them.
+	TestCode   *TestCode `json:"test_code,omitempty"`
+	TestOutput string    `json:"test_output,omitempty"`
+	Analysis   string    `json:"analysis,omitempty"`
+}
+
+// TestCode is the test Task 3 generated, ready to be saved and re-run.
+type TestCode struct {
+	Language string `json:"language,omitempty"`
+	Content  string `json:"content"`
 }
 
 // Runner executes the three-phase bug verification workflow.
@@ -87,6 +172,10 @@ type Runner struct {
 	opts     Options
 	streamer *streaming.JSONStreamer
 	events   *eventHelper
+
+	// symbols are the changed-symbols anchors for Tasks 2 and 3; set once before
+	// they start and only read afterwards.
+	symbols []ChangedSymbol
 }
 
 // NewRunner validates options and constructs a workflow runner.
@@ -101,6 +190,7 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	opts.ProjectName = strings.TrimSpace(opts.ProjectName)
 	opts.ParentBranchID = strings.TrimSpace(opts.ParentBranchID)
 	opts.WorkspaceDir = strings.TrimSpace(opts.WorkspaceDir)
+	opts.CodeContext = withDiff(opts.CodeContext, opts.Diff)
 	if opts.BugDescription == "" {
 		return nil, errors.New("bug description is required")
 	}
@@ -110,6 +200,28 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 	if opts.ParentBranchID == "" {
 		return nil, errors.New("parent branch id is required")
 	}
+	opts.PipelineMode = strings.ToLower(strings.TrimSpace(opts.PipelineMode))
+	switch opts.PipelineMode {
+	case "":
+		opts.PipelineMode = PipelineSequential
+	case PipelineSequential, PipelineFanout:
+	default:
+		return nil, fmt.Errorf("unknown pipeline mode %q (want sequential or fanout)", opts.PipelineMode)
+	}
+	opts.InconclusivePolicy = strings.ToLower(strings.TrimSpace(opts.InconclusivePolicy))
+	switch opts.InconclusivePolicy {
+	case "":
+	case InconclusiveAssumeReal:
+		if opts.RequireTestEvidence {
+			return nil, errors.New("inconclusive policy assume_real conflicts with RequireTestEvidence")
+		}
+	case InconclusiveCannotDisprove, InconclusiveBugWrong:
+	default:
+		return nil, fmt.Errorf("unknown inconclusive policy %q (want assume_real, cannot_disprove or bug_wrong)", opts.InconclusivePolicy)
+	}
+	if opts.ChangedSymbols && opts.WorkspaceDir == "" {
+		return nil, workspaceDirError()
+	}
 	return &Runner{
 		brain:    brain,
 		handler:  handler,
@@ -121,21 +233,44 @@ func NewRunner(brain *b.LLMBrain, handler *t.ToolHandler, streamer *streaming.JS
 
 // Run executes the three-task workflow and returns the structured result.
 func (r *Runner) Run() (*Result, error) {
+	return r.RunContext(context.Background())
+}
+
+// RunContext is Run bounded by ctx: once ctx ends, the running agent stops
+// waiting for its branch and the workflow fails with ctx's error. The MCP
+// server has no call to cancel a branch, so the agent itself keeps running
+// remotely until it finishes; only the local wait is abandoned.
+func (r *Runner) RunContext(ctx context.Context) (*Result, error) {
+	started := time.Now()
+	result, err := r.run(ctx)
+	if result != nil {
+		result.Headline = buildHeadline(result, time.Since(started))
+	}
+	return result, err
+}
+
+func (r *Runner) run(ctx context.Context) (*Result, error) {
 	logx.Infof("Starting bug verification workflow for bug: %s", r.opts.BugDescription)
 	parent := r.opts.ParentBranchID
 
 	result := &Result{
 		BugDescription: r.opts.BugDescription,
+		PromptVersion:  PromptVersion,
 	}
 
 	// Task 1: Bug Claim Formalization
 	logx.Infof("Task 1: Formalizing bug claim")
-	task1Result, err := r.runTask1(parent)
+	task1Result, err := r.runTask1(ctx, parent)
 	if err != nil {
 		return nil, fmt.Errorf("task 1 failed: %w", err)
 	}
 	result.Task1Result = task1Result
 
+	// Tell the finder why the claim could not be formalized
+	if r.opts.Diagnostics && (task1Result.Status == "INVALID" || task1Result.FormalizedAssertion == nil) {
+		result.Diagnostics = extractDiagnostics(task1Result.Response, task1Result.Reason)
+	}
+
 	// Check if Task 1 found the bug claim invalid
 	if task1Result.Status == "INVALID" {
 		result.Status = statusBugWrong
@@ -165,34 +300,81 @@ func (r *Runner) Run() (*Result, error) {
 		return result, nil
 	}
 
+	if r.opts.ChangedSymbols {
+		logx.Infof("Extracting changed symbols")
+		symbols, err := r.runChangedSymbols(ctx, parent)
+		if err != nil {
+			logx.Warningf("Changed-symbols step soft-failed; continuing without anchors. err=%v", err)
+		}
+		r.symbols = symbols
+		result.ChangedSymbols = symbols
+	}
+
+	if r.opts.PipelineMode == PipelineFanout {
+		if err := r.runFanout(ctx, result, task1Result); err != nil {
+			return nil, err
+		}
+		r.attachBranchRange(result)
+		return result, nil
+	}
+
 	// Task 2: Reachability Analysis
 	logx.Infof("Task 2: Analyzing reachability")
-	task2Result, err := r.runTask2(parent, task1Result.FormalizedAssertion, task1Result.Response)
+	task2Result, err := r.runTask2(ctx, parent, task1Result.FormalizedAssertion, task1Result.Response)
 	if err != nil {
 		return nil, fmt.Errorf("task 2 failed: %w", err)
 	}
 	result.Task2Result = task2Result
 
 	// Check if Task 2 found the state unreachable
-	if task2Result.Status == "UNREACHABLE" || task2Result.Status == "INVALID" {
-		result.Status = statusBugWrong
-		if r.opts.IsFalsePositive {
-			result.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE (unreachable): %s", task2Result.Reason)
-		} else {
-			result.Summary = fmt.Sprintf("Bug state is unreachable: %s", task2Result.Reason)
-		}
+	if task2Refutes(task2Result) {
+		r.applyUnreachable(result, task2Result)
 		r.attachBranchRange(result)
 		return result, nil
 	}
 
 	// Task 3: Test Generator
 	logx.Infof("Task 3: Generating test case")
-	task3Result, err := r.runTask3(parent, task1Result.FormalizedAssertion, task2Result.Response)
+	task3Result, err := r.runTask3(ctx, parent, task1Result.FormalizedAssertion, task2Result.Response)
 	if err != nil {
 		return nil, fmt.Errorf("task 3 failed: %w", err)
 	}
 	result.Task3Result = task3Result
+	r.applyTestVerdict(result, task3Result)
+	r.attachBranchRange(result)
+	return result, nil
+}
+
+// task2Refutes reports whether the reachability analysis rules the bug state out.
+func task2Refutes(task2 *Task2Result) bool {
+	return task2.Status == "UNREACHABLE" || task2.Status == "INVALID"
+}
+
+// applyUnreachable records a bug_wrong verdict from an unreachable bug state.
+func (r *Runner) applyUnreachable(result *Result, task2Result *Task2Result) {
+	result.Status = statusBugWrong
+	if r.opts.IsFalsePositive {
+		result.Summary = fmt.Sprintf("Bug claim confirmed as FALSE POSITIVE (unreachable): %s", task2Result.Reason)
+	} else {
+		result.Summary = fmt.Sprintf("Bug state is unreachable: %s", task2Result.Reason)
+	}
+}
+
+// inconclusivePolicy resolves InconclusivePolicy, whose empty value depends on
+// RequireTestEvidence.
+func (o Options) inconclusivePolicy() string {
+	switch {
+	case o.InconclusivePolicy != "":
+		return o.InconclusivePolicy
+	case o.RequireTestEvidence:
+		return InconclusiveCannotDisprove
+	default:
+		return InconclusiveAssumeReal
+	}
+}
 
+// applyTestVerdict sets the final status and summary from Task 3's outcome.
+func (r *Runner) applyTestVerdict(result *Result, task3Result *Task3Result) {
 	// Determine final result based on IsFalsePositive assumption
 	if r.opts.IsFalsePositive {
 		// We assume the bug is FALSE. We've tried to prove it wrong.
@@ -214,6 +396,9 @@ func (r *Runner) Run() (*Result, error) {
 			// this proves the assumption was WRONG - the bug is actually REAL
 			// We must report bug_confirmed because the evidence is irrefutable
 			result.Status = statusBugConfirmed
+			if r.opts.FlagAssumptionReversals {
+				result.Status = statusAssumptionOverturned
+			}
 			summaryText := task3Result.Judgment
 			if summaryText == "" {
 				summaryText = task3Result.Analysis
@@ -254,19 +439,72 @@ func (r *Runner) Run() (*Result, error) {
 			}
 			result.Summary = fmt.Sprintf("Bug claim refuted: %s", summaryText)
 		} else {
-			// TEST_INCONCLUSIVE - we couldn't confirm it through testing
-			// Since we assume it's real, we still lean towards it being real
-			result.Status = statusBugConfirmed
-			result.Summary = "Bug claim assumed REAL: Test was inconclusive, but assumption and evidence suggest it is a real bug"
+			// TEST_INCONCLUSIVE - we couldn't confirm it through testing; the
+			// policy decides how far the real-bug assumption carries.
+			switch r.opts.inconclusivePolicy() {
+			case InconclusiveCannotDisprove:
+				result.Status = statusCannotDisprove
+				result.Summary = "Bug claim not reproduced: Test was inconclusive, and a confirmation requires test evidence"
+			case InconclusiveBugWrong:
+				result.Status = statusBugWrong
+				result.Summary = "Bug claim treated as not real: Test was inconclusive, and the inconclusive policy is bug_wrong"
+			default:
+				result.Status = statusBugConfirmed
+				result.Summary = "Bug claim assumed REAL: Test was inconclusive, but assumption and evidence suggest it is a real bug"
+			}
 		}
 	}
-	r.attachBranchRange(result)
-	return result, nil
 }
 
-func (r *Runner) runTask1(parentBranchID string) (*Task1Result, error) {
-	prompt := buildFormalizationPrompt(r.opts.BugDescription, r.opts.CodeContext, r.opts.IsFalsePositive)
-	data, err := r.executeAgent("codex", prompt, parentBranchID)
+// runFanout runs Task 2 and Task 3 concurrently from Task 1's branch, then
+// applies the fanout precedence: unreachable first, then the test's verdict
+// (where BUG_REFUTED already wins over the assumption).
+func (r *Runner) runFanout(ctx context.Context, result *Result, task1Result *Task1Result) error {
+	base := task1Result.BranchID
+	if base == "" {
+		base = r.opts.ParentBranchID
+	}
+	logx.Infof("Tasks 2 and 3: Analyzing reachability and generating a test concurrently from branch %s", base)
+
+	var (
+		wg                 sync.WaitGroup
+		task2Result        *Task2Result
+		task3Result        *Task3Result
+		task2Err, task3Err error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		task2Result, task2Err = r.runTask2(ctx, base, task1Result.FormalizedAssertion, task1Result.Response)
+	}()
+	go func() {
+		defer wg.Done()
+		task3Result, task3Err = r.runTask3(ctx, base, task1Result.FormalizedAssertion, fanoutReachabilityNote)
+	}()
+	wg.Wait()
+	if task2Err != nil {
+		return fmt.Errorf("task 2 failed: %w", task2Err)
+	}
+	if task3Err != nil {
+		return fmt.Errorf("task 3 failed: %w", task3Err)
+	}
+	result.Task2Result = task2Result
+	result.Task3Result = task3Result
+
+	if task2Refutes(task2Result) {
+		r.applyUnreachable(result, task2Result)
+		return nil
+	}
+	r.applyTestVerdict(result, task3Result)
+	return nil
+}
+
+// fanoutReachabilityNote stands in for the Task 2 analysis Task 3 normally receives.
+const fanoutReachabilityNote = "Not available: reachability is being analyzed concurrently. Establish from the code whether the precondition and path can occur while building the test."
+
+func (r *Runner) runTask1(ctx context.Context, parentBranchID string) (*Task1Result, error) {
+	prompt := buildFormalizationPrompt(r.opts.BugDescription, r.opts.CodeContext, r.opts.IsFalsePositive, r.opts.Diagnostics)
+	data, err := r.executeAgent(ctx, "codex", prompt, parentBranchID)
 	if err != nil {
 		return nil, err
 	}
@@ -298,6 +536,12 @@ func (r *Runner) runTask1(parentBranchID string) (*Task1Result, error) {
 	if status == "VALID" {
 		// Try to parse the formalized assertion
 		assertion, err := parseFormalizedAssertion(response)
+		if err != nil && r.opts.RepromptOnParseFailure && branchID != "" {
+			logx.Warningf("Failed to parse formalized assertion: %v. Re-prompting Task 1 from branch %s.", err, branchID)
+			var repromptBranchID string
+			assertion, repromptBranchID, err = r.repromptFormalization(ctx, branchID, err)
+			result.RepromptBranchID = repromptBranchID
+		}
 		if err != nil {
 			logx.Warningf("Failed to parse formalized assertion: %v. Response preview: %s", err, truncateString(response, 500))
 			// If we can't parse the assertion, treat it as INVALID
@@ -321,13 +565,31 @@ func (r *Runner) runTask1(parentBranchID string) (*Task1Result, error) {
 	return result, nil
 }
 
-func (r *Runner) runTask2(parentBranchID string, assertion *FormalizedAssertion, task1Response string) (*Task2Result, error) {
+// repromptFormalization forks from the Task 1 branch and asks only for the JSON
+// assertion block. It is attempted at most once per run.
+func (r *Runner) repromptFormalization(ctx context.Context, task1BranchID string, parseErr error) (FormalizedAssertion, string, error) {
+	prompt := buildFormalizationRepromptPrompt(parseErr.Error())
+	data, err := r.executeAgent(ctx, "codex", prompt, task1BranchID)
+	if err != nil {
+		return FormalizedAssertion{}, "", fmt.Errorf("%v; re-prompt failed: %w", parseErr, err)
+	}
+	branchID := stringField(data, "branch_id")
+	response := strings.TrimSpace(stringField(data, "response"))
+	assertion, err := parseFormalizedAssertion(response)
+	if err != nil {
+		return FormalizedAssertion{}, branchID, fmt.Errorf("%v; re-prompt still unparseable: %w", parseErr, err)
+	}
+	logx.Infof("Recovered formalized assertion from re-prompt branch %s", branchID)
+	return assertion, branchID, nil
+}
+
+func (r *Runner) runTask2(ctx context.Context, parentBranchID string, assertion *FormalizedAssertion, task1Response string) (*Task2Result, error) {
 	// Format the formalized assertion as a string
 	assertionStr := fmt.Sprintf("Precondition: %s\nPath: %s\nPostcondition: %s",
 		assertion.Precondition, assertion.Path, assertion.Postcondition)
 
-	prompt := buildReachabilityPrompt(assertionStr, r.opts.CodeContext, r.opts.IsFalsePositive)
-	data, err := r.executeAgent("codex", prompt, parentBranchID)
+	prompt := buildReachabilityPrompt(assertionStr, r.opts.CodeContext, r.symbols, r.opts.IsFalsePositive)
+	data, err := r.executeAgent(ctx, "codex", prompt, parentBranchID)
 	if err != nil {
 		return nil, err
 	}
@@ -368,13 +630,13 @@ func (r *Runner) runTask2(parentBranchID string, assertion *FormalizedAssertion,
 	return result, nil
 }
 
-func (r *Runner) runTask3(parentBranchID string, assertion *FormalizedAssertion, task2Response string) (*Task3Result, error) {
+func (r *Runner) runTask3(ctx context.Context, parentBranchID string, assertion *FormalizedAssertion, task2Response string) (*Task3Result, error) {
 	// Format the formalized assertion as a string
 	assertionStr := fmt.Sprintf("Precondition: %s\nPath: %s\nPostcondition: %s",
 		assertion.Precondition, assertion.Path, assertion.Postcondition)
 
-	prompt := buildTestGeneratorPrompt(assertionStr, task2Response, r.opts.CodeContext, r.opts.IsFalsePositive)
-	data, err := r.executeAgent("codex", prompt, parentBranchID)
+	prompt := buildTestGeneratorPrompt(assertionStr, task2Response, r.opts.CodeContext, r.symbols, r.opts.IsFalsePositive)
+	data, err := r.executeAgent(ctx, "codex", prompt, parentBranchID)
 	if err != nil {
 		return nil, err
 	}
@@ -385,9 +647,12 @@ func (r *Runner) runTask3(parentBranchID string, assertion *FormalizedAssertion,
 	if status == "" {
 		// Default based on IsFalsePositive assumption
 		// If IsFalsePositive=true, assume BUG_REFUTED (prove it's false)
-		// If IsFalsePositive=false, assume BUG_CONFIRMED (prove it's real)
+		// If IsFalsePositive=false, assume BUG_CONFIRMED (prove it's real),
+		// unless a confirmation needs the test to report it
 		if r.opts.IsFalsePositive {
 			status = "BUG_REFUTED"
+		} else if r.opts.inconclusivePolicy() != InconclusiveAssumeReal {
+			status = "TEST_INCONCLUSIVE"
 		} else {
 			status = "BUG_CONFIRMED"
 		}
@@ -405,24 +670,26 @@ func (r *Runner) runTask3(parentBranchID string, assertion *FormalizedAssertion,
 	result.Judgment = extractJudgment(response)
 
 	// Extract test case, execution, and analysis
-	result.TestCase = extractSection(response, "Test Case")
-	result.TestExecution = extractSection(response, "Test Execution")
+	result.TestCase = extractMarkdownSection(response, testCaseHeaders...)
+	result.TestExecution = extractMarkdownSection(response, testExecutionHeaders...)
+	result.TestCode = extractTestCode(response)
+	result.TestOutput = extractTestOutput(response)
 	result.Analysis = extractSection(response, "Analysis")
 
 	return result, nil
 }
 
-func (r *Runner) executeAgent(agent, prompt, parentBranchID string) (map[string]any, error) {
+func (r *Runner) executeAgent(ctx context.Context, agent, prompt, parentBranchID string) (map[string]any, error) {
 	args := map[string]any{
 		"agent":            agent,
 		"prompt":           prompt,
 		"project_name":     r.opts.ProjectName,
 		"parent_branch_id": parentBranchID,
 	}
-	return r.callTool("execute_agent", args)
+	return r.callTool(ctx, "execute_agent", args)
 }
 
-func (r *Runner) callTool(name string, args map[string]any) (map[string]any, error) {
+func (r *Runner) callTool(ctx context.Context, name string, args map[string]any) (map[string]any, error) {
 	payload, _ := json.Marshal(args)
 	tc := t.ToolCall{Type: "function"}
 	tc.Function.Name = name
@@ -437,13 +704,18 @@ func (r *Runner) callTool(name string, args map[string]any) (map[string]any, err
 		}
 	}()
 
-	resp := r.handler.Handle(tc)
+	resp := r.handler.HandleContext(ctx, tc)
 	if resp == nil {
 		return nil, errors.New("tool handler returned nil response")
 	}
 	status, _ := resp["status"].(string)
 	if status != "success" {
 		errMsg := extractError(resp)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			// Keep ctx's error in the chain so callers can tell a deadline or an
+			// interrupt from a failed agent.
+			return nil, fmt.Errorf("%s failed: %w (%s)", name, ctxErr, errMsg)
+		}
 		return nil, fmt.Errorf("%s failed: %s", name, errMsg)
 	}
 	data, _ := resp["data"].(map[string]any)
@@ -0,0 +1,342 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	b "verify_agent/internal/brain"
+	tools "verify_agent/internal/tools"
+)
+
+// fanoutClient answers each task from a canned response and holds Task 2 and
+// Task 3 until both have started, so the fanout test fails if they run in series.
+type fanoutClient struct {
+	mu        sync.Mutex
+	parents   map[string]string
+	responses map[string]string
+	started   sync.WaitGroup
+}
+
+func (c *fanoutClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	task := strings.Fields(prompts[0])[1] // "Task N: ..."
+	branchID := "task" + strings.TrimSuffix(task, ":")
+	c.mu.Lock()
+	c.parents[branchID] = parentBranchID
+	c.mu.Unlock()
+	if branchID != "task1" {
+		c.started.Done()
+		done := make(chan struct{})
+		go func() { c.started.Wait(); close(done) }()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("%s ran without its sibling task", branchID)
+		}
+	}
+	return map[string]any{"branch_id": branchID}, nil
+}
+
+func (c *fanoutClient) GetBranch(branchID string) (map[string]any, error) {
+	return map[string]any{"id": branchID, "status": "succeed"}, nil
+}
+
+func (c *fanoutClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	return map[string]any{"content": ""}, nil
+}
+
+func (c *fanoutClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	return map[string]any{"output": c.responses[branchID]}, nil
+}
+
+func TestRunFanoutRunsTasksConcurrentlyFromTask1(t *testing.T) {
+	client := &fanoutClient{
+		parents: make(map[string]string),
+		responses: map[string]string{
+			"task1": "# STATUS: VALID\n\n## Formalized Assertion\n```json\n{\"precondition\": \"cache is empty\", \"path\": \"Get\", \"postcondition\": \"nil dereference\"}\n```",
+			"task2": "# STATUS: REACHABLE\n\n## Reachability Analysis\nGet is exported.",
+			"task3": "# STATUS: BUG_REFUTED\n\n## Judgment\nGet initializes the cache first.",
+		},
+	}
+	client.started.Add(2)
+	handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		BugDescription: "nil dereference in Get",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		PipelineMode:   PipelineFanout,
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+
+	result, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+	if client.parents["task2"] != "task1" || client.parents["task3"] != "task1" {
+		t.Fatalf("expected tasks 2 and 3 to fork from task1, got %v", client.parents)
+	}
+	if result.Task2Result == nil || result.Task3Result == nil {
+		t.Fatalf("expected both task results, got %+v", result)
+	}
+	if result.Status != statusBugWrong {
+		t.Fatalf("expected BUG_REFUTED to win over REACHABLE, got status %q (%s)", result.Status, result.Summary)
+	}
+}
+
+// runningClient starts branches that never finish.
+type runningClient struct{ fanoutClient }
+
+func (c *runningClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	return map[string]any{"branch_id": "task1"}, nil
+}
+
+func (c *runningClient) GetBranch(branchID string) (map[string]any, error) {
+	return map[string]any{"id": branchID, "status": "running"}, nil
+}
+
+func TestRunContextStopsPollingWhenContextEnds(t *testing.T) {
+	handler := tools.NewToolHandler(&runningClient{}, "proj", "parent", "/workspace")
+	runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		BugDescription: "nil dereference in Get",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+	})
+	if err != nil {
+		t.Fatalf("NewRunner error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err = runner.RunContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("RunContext kept polling for %s after the deadline", elapsed)
+	}
+}
+
+func TestNewRunnerRejectsUnknownPipelineMode(t *testing.T) {
+	handler := tools.NewToolHandler(&fanoutClient{}, "proj", "parent", "/workspace")
+	_, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+		BugDescription: "bug",
+		ProjectName:    "proj",
+		ParentBranchID: "parent",
+		PipelineMode:   "parallel",
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unknown pipeline mode")
+	}
+}
+
+func TestNewRunnerRequiresWorkspaceDirForChangedSymbols(t *testing.T) {
+	handler := tools.NewToolHandler(&fanoutClient{}, "proj", "parent", "")
+	opts := Options{BugDescription: "bug", ProjectName: "proj", ParentBranchID: "parent"}
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, opts); err != nil {
+		t.Fatalf("a plain run needs no workspace dir, got %v", err)
+	}
+	opts.ChangedSymbols = true
+	_, err := NewRunner(&b.LLMBrain{}, handler, nil, opts)
+	if err == nil || !strings.Contains(err.Error(), "--changed-symbols") || !strings.Contains(err.Error(), "WORKSPACE_DIR") {
+		t.Fatalf("expected an actionable workspace dir error, got %v", err)
+	}
+}
+
+func TestRequireTestEvidenceDowngradesInconclusiveConfirmation(t *testing.T) {
+	inconclusive := &Task3Result{Status: "TEST_INCONCLUSIVE"}
+
+	result := &Result{}
+	(&Runner{}).applyTestVerdict(result, inconclusive)
+	if result.Status != statusBugConfirmed {
+		t.Fatalf("expected the assumed-real default, got %q", result.Status)
+	}
+
+	strict := &Runner{opts: Options{RequireTestEvidence: true}}
+	result = &Result{}
+	strict.applyTestVerdict(result, inconclusive)
+	if result.Status != statusCannotDisprove {
+		t.Fatalf("expected cannot_disprove without test evidence, got %q", result.Status)
+	}
+	result = &Result{}
+	strict.applyTestVerdict(result, &Task3Result{Status: "BUG_CONFIRMED", Judgment: "reproduced"})
+	if result.Status != statusBugConfirmed {
+		t.Fatalf("expected a reproduced bug to stay confirmed, got %q", result.Status)
+	}
+}
+
+func TestFlagAssumptionReversalsMarksOverturnedFalsePositive(t *testing.T) {
+	confirmed := &Task3Result{Status: "BUG_CONFIRMED", Judgment: "reproduced"}
+
+	result := &Result{}
+	(&Runner{opts: Options{IsFalsePositive: true}}).applyTestVerdict(result, confirmed)
+	if result.Status != statusBugConfirmed {
+		t.Fatalf("expected bug_confirmed without the flag, got %q", result.Status)
+	}
+
+	flagged := &Runner{opts: Options{IsFalsePositive: true, FlagAssumptionReversals: true}}
+	result = &Result{}
+	flagged.applyTestVerdict(result, confirmed)
+	if result.Status != statusAssumptionOverturned || !strings.Contains(result.Summary, "ASSUMPTION WAS WRONG") {
+		t.Fatalf("expected assumption_overturned, got %q (%s)", result.Status, result.Summary)
+	}
+	result = &Result{}
+	flagged.applyTestVerdict(result, &Task3Result{Status: "BUG_REFUTED"})
+	if result.Status != statusBugWrong {
+		t.Fatalf("expected a refuted false positive to stay bug_wrong, got %q", result.Status)
+	}
+	result = &Result{}
+	(&Runner{opts: Options{FlagAssumptionReversals: true}}).applyTestVerdict(result, confirmed)
+	if result.Status != statusBugConfirmed {
+		t.Fatalf("expected the flag to leave assumed-real bugs alone, got %q", result.Status)
+	}
+}
+
+func TestInconclusivePolicyMapsInconclusiveRealBugs(t *testing.T) {
+	cases := []struct {
+		opts Options
+		want string
+	}{
+		{Options{}, statusBugConfirmed},
+		{Options{InconclusivePolicy: InconclusiveCannotDisprove}, statusCannotDisprove},
+		{Options{InconclusivePolicy: InconclusiveBugWrong}, statusBugWrong},
+		{Options{InconclusivePolicy: InconclusiveBugWrong, RequireTestEvidence: true}, statusBugWrong},
+		{Options{InconclusivePolicy: InconclusiveBugWrong, IsFalsePositive: true}, statusBugWrong},
+	}
+	for _, tc := range cases {
+		result := &Result{}
+		(&Runner{opts: tc.opts}).applyTestVerdict(result, &Task3Result{Status: "TEST_INCONCLUSIVE"})
+		if result.Status != tc.want {
+			t.Fatalf("%+v: expected %q, got %q", tc.opts, tc.want, result.Status)
+		}
+	}
+
+	handler := tools.NewToolHandler(&fanoutClient{}, "proj", "parent", "/workspace")
+	base := Options{BugDescription: "bug", ProjectName: "proj", ParentBranchID: "parent"}
+	bad := base
+	bad.InconclusivePolicy = "coin_flip"
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, bad); err == nil {
+		t.Fatal("expected an error for an unknown inconclusive policy")
+	}
+	conflict := base
+	conflict.InconclusivePolicy, conflict.RequireTestEvidence = InconclusiveAssumeReal, true
+	if _, err := NewRunner(&b.LLMBrain{}, handler, nil, conflict); err == nil {
+		t.Fatal("expected assume_real to conflict with RequireTestEvidence")
+	}
+}
+
+// repromptClient answers Task 1 and its formalization re-prompt from canned
+// responses and records the parent each of them forked from.
+type repromptClient struct {
+	mu          sync.Mutex
+	task1       string
+	reprompt    string
+	repromptErr error
+	parents     map[string]string
+}
+
+func (c *repromptClient) ParallelExplore(projectName, parentBranchID string, prompts []string, agent string, numBranches int) (map[string]any, error) {
+	branchID := "task1"
+	if strings.HasPrefix(prompts[0], "Task 1 (follow-up)") {
+		branchID = "reprompt"
+	}
+	c.mu.Lock()
+	c.parents[branchID] = parentBranchID
+	c.mu.Unlock()
+	if branchID == "reprompt" && c.repromptErr != nil {
+		return nil, c.repromptErr
+	}
+	return map[string]any{"branch_id": branchID}, nil
+}
+
+func (c *repromptClient) GetBranch(branchID string) (map[string]any, error) {
+	return map[string]any{"id": branchID, "status": "succeed"}, nil
+}
+
+func (c *repromptClient) BranchReadFile(branchID, filePath string) (map[string]any, error) {
+	return map[string]any{"content": ""}, nil
+}
+
+func (c *repromptClient) BranchOutput(branchID string, fullOutput bool) (map[string]any, error) {
+	if branchID == "reprompt" {
+		return map[string]any{"output": c.reprompt}, nil
+	}
+	return map[string]any{"output": c.task1}, nil
+}
+
+func TestRunTask1RepromptsUnparseableAssertion(t *testing.T) {
+	const unparseable = "# STATUS: VALID\n\n## Formalized Assertion\nThe cache is empty when Get dereferences it."
+	const assertionJSON = "```json\n{\"precondition\": \"cache is empty\", \"path\": \"Get\", \"postcondition\": \"nil dereference\"}\n```"
+	cases := []struct {
+		name         string
+		reprompt     string
+		repromptErr  error
+		disabled     bool
+		wantStatus   string
+		wantReprompt string
+		wantReason   []string
+	}{
+		{name: "recovered", reprompt: assertionJSON, wantStatus: "VALID", wantReprompt: "reprompt"},
+		{
+			name:         "still unparseable",
+			reprompt:     "The precondition is an empty cache.",
+			wantStatus:   "INVALID",
+			wantReprompt: "reprompt",
+			wantReason:   []string{"failed to parse JSON", "re-prompt still unparseable"},
+		},
+		{
+			name:        "re-prompt fails",
+			repromptErr: errors.New("mcp unavailable"),
+			wantStatus:  "INVALID",
+			wantReason:  []string{"failed to parse JSON", "re-prompt failed", "mcp unavailable"},
+		},
+		{name: "disabled", reprompt: assertionJSON, disabled: true, wantStatus: "INVALID", wantReason: []string{"failed to parse JSON"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &repromptClient{task1: unparseable, reprompt: tc.reprompt, repromptErr: tc.repromptErr, parents: make(map[string]string)}
+			handler := tools.NewToolHandler(client, "proj", "parent", "/workspace")
+			runner, err := NewRunner(&b.LLMBrain{}, handler, nil, Options{
+				BugDescription:         "nil dereference in Get",
+				ProjectName:            "proj",
+				ParentBranchID:         "parent",
+				RepromptOnParseFailure: !tc.disabled,
+			})
+			if err != nil {
+				t.Fatalf("NewRunner error: %v", err)
+			}
+
+			result, err := runner.runTask1(context.Background(), "parent")
+			if err != nil {
+				t.Fatalf("runTask1 error: %v", err)
+			}
+			if result.Status != tc.wantStatus {
+				t.Fatalf("expected status %s, got %s (%s)", tc.wantStatus, result.Status, result.Reason)
+			}
+			if result.RepromptBranchID != tc.wantReprompt {
+				t.Fatalf("expected re-prompt branch %q, got %q", tc.wantReprompt, result.RepromptBranchID)
+			}
+			if tc.wantStatus == "VALID" && (result.FormalizedAssertion == nil || result.FormalizedAssertion.Path != "Get") {
+				t.Fatalf("expected the re-prompt's assertion, got %+v", result.FormalizedAssertion)
+			}
+			for _, want := range tc.wantReason {
+				if !strings.Contains(result.Reason, want) {
+					t.Errorf("reason %q missing %q", result.Reason, want)
+				}
+			}
+			parent, reprompted := client.parents["reprompt"]
+			if tc.disabled && reprompted {
+				t.Fatal("expected no re-prompt with RepromptOnParseFailure off")
+			}
+			if !tc.disabled && parent != "task1" {
+				t.Fatalf("expected the re-prompt to fork from task1, got parents %v", client.parents)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"verify_agent/internal/logx"
+)
+
+const (
+	symbolsFilename = "symbols.json"
+	maxSymbols      = 20
+)
+
+// ChangedSymbol is a function, method or type the change under verification
+// touches, as listed by the changed-symbols step.
+type ChangedSymbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+	File string `json:"file,omitempty"`
+}
+
+// workspaceDirError explains that ChangedSymbols has nowhere to write symbols.json.
+func workspaceDirError() error {
+	return fmt.Errorf("workspace dir is required by --changed-symbols (writes %s): set WORKSPACE_DIR to the agent's workspace path", symbolsFilename)
+}
+
+// runChangedSymbols asks an agent for the symbols the diff changes and reads
+// them back from symbols.json. Callers treat an error as "no anchors".
+func (r *Runner) runChangedSymbols(ctx context.Context, parentBranchID string) ([]ChangedSymbol, error) {
+	if r.opts.WorkspaceDir == "" {
+		return nil, workspaceDirError()
+	}
+	outputPath := filepath.Join(r.opts.WorkspaceDir, symbolsFilename)
+	data, err := r.executeAgent(ctx, "codex", buildChangedSymbolsPrompt(r.opts.BugDescription, outputPath), parentBranchID)
+	if err != nil {
+		return nil, err
+	}
+	branchID := stringField(data, "branch_id")
+	artifact, err := r.callTool(ctx, "read_artifact", map[string]any{
+		"branch_id": branchID,
+		"path":      outputPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := parseChangedSymbols(stringField(artifact, "content"))
+	if err != nil {
+		return nil, err
+	}
+	logx.Infof("Changed-symbols step listed %d symbol(s) on branch %s", len(symbols), branchID)
+	return symbols, nil
+}
+
+func buildChangedSymbolsPrompt(bugDescription string, outputPath string) string {
+	var sb strings.Builder
+	sb.WriteString("Role: CHANGED-SYMBOLS EXTRACTOR (quick pass; do not analyze the bug)\n\n")
+	sb.WriteString("Bug under verification:\n")
+	sb.WriteString(bugDescription)
+	sb.WriteString("\n\n")
+	sb.WriteString("List the functions, methods and types the current change modifies:\n")
+	sb.WriteString("  1) Find the merge-base with the base branch: git merge-base HEAD main (or master, or the upstream ref).\n")
+	sb.WriteString("  2) Run: git diff MERGE_BASE_SHA and note each changed function/method/type by its name in the code.\n")
+	sb.WriteString("  3) If there is no diff, list the symbols the bug description names instead.\n")
+	sb.WriteString(fmt.Sprintf("Keep at most %d, the ones most related to the bug first.\n\n", maxSymbols))
+	sb.WriteString("Write ONLY this JSON to: ")
+	sb.WriteString(outputPath)
+	sb.WriteString("\n")
+	sb.WriteString("{\"symbols\": [{\"name\": \"Cache.Get\", \"kind\": \"method\", \"file\": \"pkg/cache/cache.go\"}]}\n")
+	return sb.String()
+}
+
+// parseChangedSymbols accepts {"symbols": [...]} or a bare array, whose entries
+// are objects or plain names. Duplicates and blank names are dropped.
+func parseChangedSymbols(raw string) ([]ChangedSymbol, error) {
+	block := extractJSONBlock(raw)
+	var entries []json.RawMessage
+	var wrapped struct {
+		Symbols []json.RawMessage `json:"symbols"`
+	}
+	if err := json.Unmarshal([]byte(block), &wrapped); err == nil && wrapped.Symbols != nil {
+		entries = wrapped.Symbols
+	} else if err := json.Unmarshal([]byte(block), &entries); err != nil {
+		return nil, fmt.Errorf("parse changed symbols: %w", err)
+	}
+	var (
+		out  []ChangedSymbol
+		seen = make(map[string]bool)
+	)
+	for _, entry := range entries {
+		var sym ChangedSymbol
+		if err := json.Unmarshal(entry, &sym); err != nil {
+			if err := json.Unmarshal(entry, &sym.Name); err != nil {
+				continue
+			}
+		}
+		sym.Name = strings.TrimSpace(sym.Name)
+		sym.Kind = strings.TrimSpace(sym.Kind)
+		sym.File = strings.TrimSpace(sym.File)
+		key := sym.Name + "\x00" + sym.File
+		if sym.Name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, sym)
+		if len(out) == maxSymbols {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("changed-symbols output lists no symbols")
+	}
+	return out, nil
+}
+
+// writeChangedSymbols adds the anchor list to a Task 2/3 prompt; nothing is
+// written when the step was off or failed.
+func writeChangedSymbols(sb *strings.Builder, symbols []ChangedSymbol) {
+	if len(symbols) == 0 {
+		return
+	}
+	sb.WriteString("Changed Symbols (the bug concerns these changed symbols; start from them instead of rediscovering the change):\n")
+	for _, sym := range symbols {
+		sb.WriteString("- ")
+		if sym.Kind != "" {
+			sb.WriteString(sym.Kind)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(sym.Name)
+		if sym.File != "" {
+			sb.WriteString(" (")
+			sb.WriteString(sym.File)
+			sb.WriteString(")")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,48 @@
+package verify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseChangedSymbolsAcceptsObjectsArraysAndNames(t *testing.T) {
+	wrapped := "Wrote symbols.json:\n```json\n{\"symbols\": [{\"name\": \"Cache.Get\", \"kind\": \"method\", \"file\": \"cache.go\"}, {\"name\": \" \"}, {\"name\": \"Cache.Get\", \"file\": \" cache.go \"}]}\n```"
+	symbols, err := parseChangedSymbols(wrapped)
+	if err != nil {
+		t.Fatalf("parseChangedSymbols error: %v", err)
+	}
+	if len(symbols) != 1 || symbols[0] != (ChangedSymbol{Name: "Cache.Get", Kind: "method", File: "cache.go"}) {
+		t.Fatalf("expected one deduplicated symbol, got %+v", symbols)
+	}
+
+	symbols, err = parseChangedSymbols(`["newCache", {"name": "entry", "kind": "type"}]`)
+	if err != nil {
+		t.Fatalf("parseChangedSymbols error: %v", err)
+	}
+	if len(symbols) != 2 || symbols[0].Name != "newCache" || symbols[1].Kind != "type" {
+		t.Fatalf("unexpected symbols %+v", symbols)
+	}
+
+	for _, raw := range []string{"", "no diff found", `{"symbols": []}`} {
+		if _, err := parseChangedSymbols(raw); err == nil {
+			t.Fatalf("expected an error for %q", raw)
+		}
+	}
+}
+
+func TestPromptsNameChangedSymbolsOnlyWhenPresent(t *testing.T) {
+	symbols := []ChangedSymbol{{Name: "Cache.Get", Kind: "method", File: "cache.go"}, {Name: "evict"}}
+	const anchor = "the bug concerns these changed symbols"
+
+	reach := buildReachabilityPrompt("Path: Get", "", symbols, false)
+	if !strings.Contains(reach, anchor) || !strings.Contains(reach, "- method Cache.Get (cache.go)\n- evict\n") {
+		t.Fatalf("expected the reachability prompt to list the symbols:\n%s", reach)
+	}
+	testGen := buildTestGeneratorPrompt("Path: Get", "reachable", "", symbols, false)
+	if !strings.Contains(testGen, anchor) {
+		t.Fatalf("expected the test-generator prompt to list the symbols:\n%s", testGen)
+	}
+	if strings.Contains(buildReachabilityPrompt("Path: Get", "", nil, false), anchor) {
+		t.Fatal("expected no symbols section without symbols")
+	}
+}
@@ -0,0 +1,33 @@
+package verify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"verify_agent/internal/jsonshape"
+)
+
+// ValidateResult checks that data is a JSON document in a shape this version
+// emits: a Result, or the BatchReport of the batch subcommand, which is told
+// apart by its results field. Every mismatch is reported: unknown fields,
+// missing required (non-omitempty) fields, and values of the wrong JSON type.
+func ValidateResult(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("result is not valid JSON: %w", err)
+	}
+	typ, kind := reflect.TypeOf(Result{}), "result"
+	if obj, ok := raw.(map[string]any); ok {
+		if _, ok := obj["results"]; ok {
+			typ, kind = reflect.TypeOf(BatchReport{}), "batch report"
+		}
+	}
+	problems := jsonshape.Problems(raw, typ)
+	if len(problems) > 0 {
+		return errors.New(kind + " schema mismatch: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
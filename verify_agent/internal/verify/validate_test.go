@@ -0,0 +1,59 @@
+package verify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateResultAcceptsMarshaledResult(t *testing.T) {
+	data, err := json.Marshal(&Result{
+		BugDescription: "nil map write",
+		Status:         statusBugConfirmed,
+		Summary:        "confirmed",
+		Task1Result:    &Task1Result{},
+		Diagnostics:    &Diagnostics{AmbiguousTerms: []string{"cache"}},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := ValidateResult(data); err != nil {
+		t.Fatalf("expected valid result, got %v", err)
+	}
+}
+
+func TestValidateResultReportsUnknownAndMissingFields(t *testing.T) {
+	err := ValidateResult([]byte(`{"bug_description":"b","status":1,"legacy":true}`))
+	if err == nil {
+		t.Fatal("expected schema mismatch")
+	}
+	for _, want := range []string{"missing field summary", "status: expected string", "unknown field legacy"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err.Error(), want)
+		}
+	}
+}
+
+func TestValidateResultChecksBatchReports(t *testing.T) {
+	data, err := json.Marshal(&BatchReport{
+		Results: []*Result{{BugDescription: "nil map write", Status: statusBugConfirmed, Summary: "confirmed"}},
+		Total:   1,
+		Counts:  map[string]int{statusBugConfirmed: 1},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if err := ValidateResult(data); err != nil {
+		t.Fatalf("expected valid batch report, got %v", err)
+	}
+
+	err = ValidateResult([]byte(`{"results":[{"bug_description":"b","status":"error"}],"counts":{"error":"1"}}`))
+	if err == nil {
+		t.Fatal("expected schema mismatch")
+	}
+	for _, want := range []string{"batch report schema mismatch", "missing field total", "missing field results[0].summary", "counts.error: expected number"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err.Error(), want)
+		}
+	}
+}